@@ -0,0 +1,199 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditDecision is what happened to a command when it was validated or run.
+type AuditDecision int
+
+const (
+	// AuditAllowed means the command passed security validation
+	AuditAllowed AuditDecision = iota + 1
+	// AuditBlocked means the command failed security validation
+	AuditBlocked
+	// AuditRejected means the command passed security validation but failed another check
+	// before running, such as its required environment variables or tools
+	AuditRejected
+	// AuditDeclined means the command was declined when asked for confirmation
+	AuditDeclined
+	// AuditTimedOut means the command was stopped for exceeding its timeout
+	AuditTimedOut
+)
+
+// String returns the name of the decision: "ALLOWED", "BLOCKED", "REJECTED", "DECLINED", or
+// "TIMED_OUT".
+func (a AuditDecision) String() string {
+	switch a {
+	case AuditAllowed:
+		return "ALLOWED"
+	case AuditBlocked:
+		return "BLOCKED"
+	case AuditRejected:
+		return "REJECTED"
+	case AuditDeclined:
+		return "DECLINED"
+	case AuditTimedOut:
+		return "TIMED_OUT"
+	}
+
+	return fmt.Sprintf("AuditDecision(%d)", int(a))
+}
+
+// AuditEvent records a decision about a command, for keeping a security audit log.
+type AuditEvent struct {
+	// Time is when the decision was made
+	Time time.Time
+	// Command is the command, joined and with secrets redacted like TaskResult.Command
+	Command string
+	// Section is the path of the section holding the command, such as "MyDoc > Quick Start"
+	Section string
+	// Decision is what happened to the command
+	Decision AuditDecision
+	// Rule names the setting or check behind the decision, such as "BlockedCommands",
+	// "AllowedShells", "Environment", or "Timeout" (empty for allowed commands)
+	Rule string
+	// Reason is the error behind the decision (empty for allowed commands)
+	Reason string
+}
+
+// auditEventJSON is the JSON representation of an AuditEvent.
+type auditEventJSON struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	Section  string    `json:"section"`
+	Decision string    `json:"decision"`
+	Rule     string    `json:"rule"`
+	Reason   string    `json:"reason"`
+}
+
+// MarshalJSON encodes the event with snake_case keys and the decision by name.
+func (a AuditEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(auditEventJSON{
+		Time:     a.Time,
+		Command:  a.Command,
+		Section:  a.Section,
+		Decision: a.Decision.String(),
+		Rule:     a.Rule,
+		Reason:   a.Reason,
+	})
+}
+
+// AuditSink receives the audit events of the commands a runner validates and runs.
+type AuditSink interface {
+	// Record stores an event. Errors are logged as warnings and do not affect the command
+	Record(event AuditEvent) error
+}
+
+// FileAuditSink is an AuditSink that appends events to a file as JSON lines, one event per line.
+// It is safe for concurrent use.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink opens the file at path for appending audit events, creating it if needed.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+
+	return &FileAuditSink{file: file}, nil
+}
+
+// Record appends the event to the file as a line of JSON.
+func (f *FileAuditSink) Record(event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.file.Write(append(line, '\n'))
+
+	return err
+}
+
+// Close closes the file.
+func (f *FileAuditSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// ruleError is a validation error naming the rule of the ExecutionConfig that caused it.
+type ruleError struct {
+	rule string
+	err  error
+}
+
+func ruleErr(rule string, err error) error {
+	return &ruleError{rule: rule, err: err}
+}
+
+func (r *ruleError) Error() string { return r.err.Error() }
+
+func (r *ruleError) Unwrap() error { return r.err }
+
+// auditRule returns the rule behind a validation error, or "" if it names none.
+func auditRule(err error) string {
+	var ruleErr *ruleError
+	if errors.As(err, &ruleErr) {
+		return ruleErr.rule
+	}
+
+	return ""
+}
+
+// auditSection returns the section path of a plan as shown in audit events.
+func auditSection(plan CommandPlan) string {
+	if len(plan.Path) == 0 {
+		return plan.Context.Name
+	}
+
+	return strings.Join(plan.Path, " > ")
+}
+
+// recordAudit sends an event about a command to the AuditSink of config, if there is one. Errors
+// writing it are logged as warnings.
+func recordAudit(config ExecutionConfig, plan CommandPlan, command string, decision AuditDecision, rule string, reason error) {
+	if config.AuditSink == nil {
+		return
+	}
+
+	event := AuditEvent{
+		Time:     time.Now(),
+		Command:  command,
+		Section:  auditSection(plan),
+		Decision: decision,
+		Rule:     rule,
+	}
+	if reason != nil {
+		event.Reason = reason.Error()
+	}
+
+	if err := config.AuditSink.Record(event); err != nil {
+		logf(config.Logger, "[Section: %s] - Warning: failed to write audit event: %v", plan.Context.Name, err)
+	}
+}
+
+// auditValidation records the outcome of validating a command: allowed if err is nil, and
+// blocked by the rule behind err otherwise.
+func auditValidation(config ExecutionConfig, plan CommandPlan, command string, err error) {
+	if err != nil {
+		recordAudit(config, plan, command, AuditBlocked, auditRule(err), err)
+		return
+	}
+
+	recordAudit(config, plan, command, AuditAllowed, "", nil)
+}
@@ -0,0 +1,232 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingSink keeps the audit events recorded to it, failing with err if set.
+type recordingSink struct {
+	mu     sync.Mutex
+	err    error
+	events []AuditEvent
+}
+
+func (r *recordingSink) Record(event AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestTaskRunner_RunAudit(t *testing.T) {
+	tests := []struct {
+		name             string
+		plan             CommandPlan
+		expectedDecision AuditDecision
+		expectedRule     string
+		expectedStatus   TaskStatus
+	}{
+		{
+			name:             "Passing-Allowed",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"true"}},
+			expectedDecision: AuditAllowed,
+			expectedStatus:   COMPLETED,
+		},
+		{
+			name:             "Passing-Blocked",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"sudo", "reboot"}},
+			expectedDecision: AuditBlocked,
+			expectedRule:     "BlockedCommands",
+			expectedStatus:   FAILED,
+		},
+		{
+			name:             "Passing-BlockedShell",
+			plan:             CommandPlan{Shell: "fish", Args: []string{"true"}},
+			expectedDecision: AuditBlocked,
+			expectedRule:     "AllowedShells",
+			expectedStatus:   FAILED,
+		},
+		{
+			name:             "Passing-EnvironmentRejected",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"true"}, Environment: []string{"DOYOUCOMPUTE_TEST_UNSET"}},
+			expectedDecision: AuditRejected,
+			expectedRule:     "Environment",
+			expectedStatus:   FAILED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			config := DefaultSecureConfig()
+			config.AuditSink = sink
+
+			tc.plan.Context = SectionInfo{Name: "Build"}
+			tc.plan.Path = []string{"MyDoc", "Build"}
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+			if result.Status != tc.expectedStatus {
+				t.Fatalf("Expected status %s, got %s (%v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			// A rejected command was allowed by security validation first
+			expected := []AuditDecision{tc.expectedDecision}
+			if tc.expectedDecision == AuditRejected {
+				expected = []AuditDecision{AuditAllowed, AuditRejected}
+			}
+
+			if len(sink.events) != len(expected) {
+				t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(sink.events), sink.events)
+			}
+
+			for idx, decision := range expected {
+				if sink.events[idx].Decision != decision {
+					t.Errorf("Expected event %d to be %s, got %s", idx, decision, sink.events[idx].Decision)
+				}
+			}
+
+			event := sink.events[len(sink.events)-1]
+			if event.Rule != tc.expectedRule {
+				t.Errorf("Expected rule '%s', got '%s'", tc.expectedRule, event.Rule)
+			}
+
+			if event.Command != result.Command {
+				t.Errorf("Expected command '%s', got '%s'", result.Command, event.Command)
+			}
+
+			if event.Section != "MyDoc > Build" {
+				t.Errorf("Expected section 'MyDoc > Build', got '%s'", event.Section)
+			}
+
+			if event.Time.IsZero() {
+				t.Error("Expected event to have a time")
+			}
+
+			if (tc.expectedDecision == AuditAllowed) != (event.Reason == "") {
+				t.Errorf("Unexpected reason '%s' for %s", event.Reason, event.Decision)
+			}
+		})
+	}
+}
+
+func TestValidateCommandPlanAudit(t *testing.T) {
+	sink := &recordingSink{}
+	config := DefaultSecureConfig()
+	config.AuditSink = sink
+	config.RedactEnvVars = []string{"TOKEN"}
+
+	plan := CommandPlan{
+		Shell:     "sh",
+		Args:      []string{"sudo", "echo", "secret-value"},
+		Context:   SectionInfo{Name: "Install"},
+		EnvValues: map[string]string{"TOKEN": "secret-value"},
+	}
+
+	if err := ValidateCommandPlan(plan, config); err == nil {
+		t.Fatal("Expected validation to fail")
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(sink.events))
+	}
+
+	event := sink.events[0]
+	if event.Decision != AuditBlocked || event.Rule != "BlockedCommands" {
+		t.Errorf("Expected BLOCKED by BlockedCommands, got %s by '%s'", event.Decision, event.Rule)
+	}
+
+	if strings.Contains(event.Command, "secret-value") {
+		t.Errorf("Expected command to be redacted, got '%s'", event.Command)
+	}
+
+	if event.Section != "Install" {
+		t.Errorf("Expected section 'Install', got '%s'", event.Section)
+	}
+}
+
+func TestRecordAuditFailure(t *testing.T) {
+	logger := &recordingLogger{}
+	config := DefaultSecureConfig()
+	config.AuditSink = &recordingSink{err: errors.New("disk full")}
+	config.Logger = logger
+
+	result := newTestTaskRunner(t, config).Run(CommandPlan{Shell: "sh", Args: []string{"true"}, Context: SectionInfo{Name: "Build"}})
+	if result.Status != COMPLETED {
+		t.Fatalf("Expected a failing sink not to fail the command, got %s (%v)", result.Status, result.Error)
+	}
+
+	expected := "[Section: Build] - Warning: failed to write audit event: disk full"
+	found := false
+	for _, message := range logger.messages {
+		if message == expected {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected warning '%s', got %v", expected, logger.messages)
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileAuditSink(path)
+	if err != nil {
+		t.Fatalf("Failed to open sink: %v", err)
+	}
+
+	config := DefaultSecureConfig()
+	config.AuditSink = sink
+
+	runner := newTestTaskRunner(t, config)
+	runner.Run(CommandPlan{Shell: "sh", Args: []string{"true"}, Context: SectionInfo{Name: "Build"}})
+	runner.Run(CommandPlan{Shell: "sh", Args: []string{"sudo", "reboot"}, Context: SectionInfo{Name: "Deploy"}})
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Failed to close sink: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), content)
+	}
+
+	expected := []map[string]string{
+		{"section": "Build", "decision": "ALLOWED", "rule": "", "command": "true"},
+		{"section": "Deploy", "decision": "BLOCKED", "rule": "BlockedCommands", "command": "sudo reboot"},
+	}
+
+	for idx, line := range lines {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", idx, err)
+		}
+
+		for key, value := range expected[idx] {
+			if event[key] != value {
+				t.Errorf("Line %d: expected %s '%s', got '%v'", idx, key, value, event[key])
+			}
+		}
+
+		if _, ok := event["time"]; !ok {
+			t.Errorf("Line %d: expected a time", idx)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrCommandDeclined is the error of commands that were not run because they were declined
+// when asked for confirmation.
+var ErrCommandDeclined = errors.New("command declined")
+
+// ErrNotInteractive is returned by PromptConfirmer when it has to read the answer from
+// standard input and standard input is not a terminal.
+var ErrNotInteractive = errors.New("cannot ask for confirmation: standard input is not a terminal")
+
+// ConfirmMode selects the commands a runner asks to confirm before running them.
+type ConfirmMode int
+
+const (
+	// ConfirmBeforeRun asks to confirm every command
+	ConfirmBeforeRun ConfirmMode = iota + 1
+	// ConfirmDangerous asks to confirm the commands BlockDangerousCommands would block, for when
+	// it is turned off. Like blocking, it uses the default lists when neither BlockedCommands nor
+	// BlockedPatterns is set
+	ConfirmDangerous
+)
+
+// String returns the name of the mode: "all" or "dangerous".
+func (c ConfirmMode) String() string {
+	switch c {
+	case ConfirmBeforeRun:
+		return "all"
+	case ConfirmDangerous:
+		return "dangerous"
+	}
+
+	return fmt.Sprintf("ConfirmMode(%d)", int(c))
+}
+
+// Confirmer decides whether a command runs when the runner is configured to ask first.
+type Confirmer interface {
+	// Confirm reports whether the command of plan should run. command is the command as it is
+	// reported, with secrets redacted, so it is safe to show. An error fails the command
+	Confirm(command string, plan CommandPlan) (bool, error)
+}
+
+// ConfirmingRunner is implemented by runners that can ask to confirm commands before running them.
+type ConfirmingRunner interface {
+	Runner
+	// Confirming returns a copy of the runner that asks to confirm the commands selected by mode
+	Confirming(mode ConfirmMode) Runner
+}
+
+// PromptConfirmer asks to confirm commands with a y/N prompt, which is the default Confirmer.
+// Only "y" and "yes" run the command; anything else, including no answer, declines it.
+type PromptConfirmer struct {
+	// In is read for the answers (nil means standard input, which must be a terminal)
+	In io.Reader
+	// Out is where the questions are written (nil means standard error)
+	Out io.Writer
+}
+
+// Confirm asks whether to run the command and reads the answer from a single line.
+func (p PromptConfirmer) Confirm(command string, plan CommandPlan) (bool, error) {
+	in, out := p.In, p.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	if in == nil {
+		if !isTerminal(os.Stdin) {
+			return false, ErrNotInteractive
+		}
+		in = os.Stdin
+	}
+
+	fmt.Fprintf(out, "Run '%s' in section '%s'? [y/N] ", command, plan.Context.Name)
+
+	answer, err := readLine(in)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// isTerminal reports whether a file is a terminal rather than a pipe or regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// readLine reads up to the next line break one byte at a time, so nothing after the line is
+// consumed and the next answer can be read from the same reader.
+func readLine(r io.Reader) (string, error) {
+	var line strings.Builder
+	buf := make([]byte, 1)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return line.String(), nil
+			}
+			line.WriteByte(buf[0])
+		}
+
+		if err != nil {
+			return line.String(), err
+		}
+	}
+}
+
+// confirmMu keeps questions about commands running concurrently from overlapping, so
+// confirmers do not have to be safe for concurrent use.
+var confirmMu sync.Mutex
+
+// confirmCommand reports whether a command should run according to the confirmation settings of
+// config, asking about its redacted command. Commands that do not need confirmation always run.
+func confirmCommand(plan CommandPlan, command string, config ExecutionConfig) (bool, error) {
+	switch config.Confirm {
+	case ConfirmBeforeRun:
+	case ConfirmDangerous:
+		if config.BlockDangerousCommands || checkBlockedCommand(plan, config) == nil {
+			return true, nil
+		}
+	default:
+		return true, nil
+	}
+
+	confirmer := config.Confirmer
+	if confirmer == nil {
+		confirmer = PromptConfirmer{}
+	}
+
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+
+	return confirmer.Confirm(command, plan)
+}
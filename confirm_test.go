@@ -0,0 +1,277 @@
+package doyoucompute
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// scriptedConfirmer answers confirmations from a list, recording the commands it was asked about.
+type scriptedConfirmer struct {
+	answers []bool
+	err     error
+	asked   []string
+}
+
+func (s *scriptedConfirmer) Confirm(command string, plan CommandPlan) (bool, error) {
+	s.asked = append(s.asked, command)
+	if s.err != nil {
+		return false, s.err
+	}
+
+	answer := s.answers[0]
+	s.answers = s.answers[1:]
+
+	return answer, nil
+}
+
+func TestTaskRunner_RunConfirm(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           ConfirmMode
+		blockDangerous bool
+		dryRun         bool
+		plan           CommandPlan
+		answers        []bool
+		confirmErr     error
+		expectedStatus TaskStatus
+		expectedAsked  int
+		errorMessage   string
+	}{
+		{
+			name:           "Runs without confirmation by default",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"true"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Runs confirmed command",
+			mode:           ConfirmBeforeRun,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"false"}},
+			answers:        []bool{true},
+			expectedStatus: FAILED,
+			expectedAsked:  1,
+			errorMessage:   "exit status 1",
+		},
+		{
+			name:           "Skips declined command",
+			mode:           ConfirmBeforeRun,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"false"}},
+			answers:        []bool{false},
+			expectedStatus: SKIPPED,
+			expectedAsked:  1,
+			errorMessage:   "command declined",
+		},
+		{
+			name:           "Fails when confirmation fails",
+			mode:           ConfirmBeforeRun,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"true"}},
+			confirmErr:     ErrNotInteractive,
+			expectedStatus: FAILED,
+			expectedAsked:  1,
+			errorMessage:   "confirmation failed: cannot ask for confirmation: standard input is not a terminal",
+		},
+		{
+			name:           "Asks about dangerous command",
+			mode:           ConfirmDangerous,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "start", "&&", "false"}},
+			answers:        []bool{false},
+			expectedStatus: SKIPPED,
+			expectedAsked:  1,
+			errorMessage:   "command declined",
+		},
+		{
+			name:           "Asks about dangerous pipeline stage",
+			mode:           ConfirmDangerous,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "hi", "|", "false"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"echo", "hi"}}, {Shell: "sh", Args: []string{"false"}}}},
+			answers:        []bool{false},
+			expectedStatus: SKIPPED,
+			expectedAsked:  1,
+			errorMessage:   "command declined",
+		},
+		{
+			name:           "Runs safe command without asking",
+			mode:           ConfirmDangerous,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "false"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Blocks dangerous command without asking when blocking is on",
+			mode:           ConfirmDangerous,
+			blockDangerous: true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"false"}},
+			expectedStatus: FAILED,
+			errorMessage:   "security validation failed: dangerous command blocked: false",
+		},
+		{
+			name:           "Does not ask during dry runs",
+			mode:           ConfirmBeforeRun,
+			dryRun:         true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"true"}},
+			expectedStatus: COMPLETED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			confirmer := &scriptedConfirmer{answers: tc.answers, err: tc.confirmErr}
+
+			config := DefaultSecureConfig()
+			config.Logger = nil
+			config.BlockDangerousCommands = tc.blockDangerous
+			config.BlockCommand("false")
+			config.Confirmer = confirmer
+			config.DryRun = tc.dryRun
+
+			runner := newTestTaskRunner(t, config)
+			result := runner.Confirming(tc.mode).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if len(confirmer.asked) != tc.expectedAsked {
+				t.Errorf("Expected %d confirmation(s), got %v", tc.expectedAsked, confirmer.asked)
+			}
+
+			var errMessage string
+			if result.Error != nil {
+				errMessage = result.Error.Error()
+			}
+
+			if errMessage != tc.errorMessage {
+				t.Errorf("Expected error %q, got %q", tc.errorMessage, errMessage)
+			}
+
+			if tc.expectedStatus == SKIPPED && (!errors.Is(result.Error, ErrCommandDeclined) || result.ExitCode != ExitCodeNotStarted) {
+				t.Errorf("Expected declined command not to start, got exit code %d and error %v", result.ExitCode, result.Error)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunConfirmDangerousDefaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		plan          CommandPlan
+		expectedAsked []string
+	}{
+		{
+			name:          "Asks about default blocked command",
+			plan:          CommandPlan{Shell: "sh", Args: []string{"sudo", "reboot"}},
+			expectedAsked: []string{"sudo reboot"},
+		},
+		{
+			name:          "Asks about default blocked pattern",
+			plan:          CommandPlan{Shell: "sh", Args: []string{"rm", "-rf", "/"}},
+			expectedAsked: []string{"rm -rf /"},
+		},
+		{
+			name: "Runs safe command without asking",
+			plan: CommandPlan{Shell: "sh", Args: []string{"true"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			confirmer := &scriptedConfirmer{answers: []bool{false}}
+
+			runner := newTestTaskRunner(t, ExecutionConfig{Confirm: ConfirmDangerous, Confirmer: confirmer})
+			result := runner.Run(tc.plan)
+
+			if !slices.Equal(confirmer.asked, tc.expectedAsked) {
+				t.Errorf("Expected confirmation of %q, got %q", tc.expectedAsked, confirmer.asked)
+			}
+
+			if len(tc.expectedAsked) > 0 && !errors.Is(result.Error, ErrCommandDeclined) {
+				t.Errorf("Expected declined command, got %v (error: %v)", result.Status, result.Error)
+			}
+		})
+	}
+}
+
+func TestPromptConfirmer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []bool
+	}{
+		{
+			name:     "Yes",
+			input:    "y\n",
+			expected: []bool{true},
+		},
+		{
+			name:     "Yes in full with whitespace",
+			input:    " YES \n",
+			expected: []bool{true},
+		},
+		{
+			name:     "Defaults to no",
+			input:    "\n",
+			expected: []bool{false},
+		},
+		{
+			name:     "No answer",
+			input:    "",
+			expected: []bool{false},
+		},
+		{
+			name:     "Reads one answer per question",
+			input:    "y\nn\nyes",
+			expected: []bool{true, false, true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			confirmer := PromptConfirmer{In: strings.NewReader(tc.input), Out: out}
+			plan := CommandPlan{Args: []string{"make", "deploy"}, Context: SectionInfo{Name: "Release"}}
+
+			for idx, expected := range tc.expected {
+				confirmed, err := confirmer.Confirm("make deploy", plan)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if confirmed != expected {
+					t.Errorf("Expected answer %d to be %v, got %v", idx+1, expected, confirmed)
+				}
+			}
+
+			expectedPrompt := strings.Repeat("Run 'make deploy' in section 'Release'? [y/N] ", len(tc.expected))
+			if out.String() != expectedPrompt {
+				t.Errorf("Expected prompt %q, got %q", expectedPrompt, out.String())
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunConfirmRedacted(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	config := DefaultSecureConfig()
+	config.Logger = nil
+	config.Confirm = ConfirmBeforeRun
+	config.Confirmer = PromptConfirmer{In: strings.NewReader("n\n"), Out: out}
+	config.RedactPatterns = []*regexp.Regexp{regexp.MustCompile("ghp_[A-Za-z0-9]+")}
+
+	runner := newTestTaskRunner(t, config)
+	result := runner.Run(CommandPlan{
+		Shell:   "sh",
+		Args:    []string{"curl", "-H", "'Authorization: token ghp_abc123'", "https://api.github.com"},
+		Context: SectionInfo{Name: "Release"},
+	})
+
+	if !errors.Is(result.Error, ErrCommandDeclined) {
+		t.Fatalf("Expected declined command, got %v (error: %v)", result.Status, result.Error)
+	}
+
+	expectedPrompt := "Run 'curl -H 'Authorization: token ***' https://api.github.com' in section 'Release'? [y/N] "
+	if out.String() != expectedPrompt {
+		t.Errorf("Expected prompt %q, got %q", expectedPrompt, out.String())
+	}
+}
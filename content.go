@@ -1,10 +1,20 @@
 package doyoucompute
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
+	"slices"
 	"strings"
+	"time"
 )
 
+// ErrRemoteDigestMismatch is returned when remote content no longer matches its pinned digest.
+var ErrRemoteDigestMismatch = errors.New("remote content does not match pinned digest")
+
 // ContentType represents the different types of content elements that can be
 // processed and rendered in runnable documentation. Each type corresponds to
 // a specific markdown or documentation construct that may require different
@@ -62,8 +72,87 @@ const (
 	// FrontmatterType represents YAML/TOML frontmatter metadata
 	// typically found at the beginning of markdown documents
 	FrontmatterType
+
+	// PipelineType represents executables whose output is piped
+	// from one stage into the next
+	PipelineType
+	// SectionRefType represents a reference to a section in another document
+	SectionRefType
+	// ImageType represents embedded images
+	ImageType
+	// BoldType represents strongly emphasized inline text
+	BoldType
+	// ItalicType represents emphasized inline text
+	ItalicType
+	// StrikethroughType represents struck-out inline text
+	StrikethroughType
+	// AdmonitionType represents callout blocks such as notes and warnings
+	AdmonitionType
+	// CollapsibleType represents content that is hidden behind an expandable summary
+	CollapsibleType
+	// HTMLBlockType represents raw HTML passed through verbatim
+	HTMLBlockType
+	// MermaidType represents mermaid diagrams
+	MermaidType
+	// LineBreakType represents hard line breaks within paragraphs
+	LineBreakType
+	// MathInlineType represents inline math expressions
+	MathInlineType
+	// MathBlockType represents display math blocks
+	MathBlockType
+	// CrossRefType represents links to sections within the same document
+	CrossRefType
+	// TableOfContentsType represents a generated list of links to the document's sections
+	TableOfContentsType
+	// BadgeType represents status badges that link to more detail
+	BadgeType
 )
 
+var contentTypeNames = map[ContentType]string{
+	HeaderType:          "Header",
+	LinkType:            "Link",
+	TextType:            "Text",
+	CodeType:            "Code",
+	CodeBlockType:       "CodeBlock",
+	TableRowType:        "TableRow",
+	BlockQuoteType:      "BlockQuote",
+	ExecutableType:      "Executable",
+	RemoteType:          "Remote",
+	CommentType:         "Comment",
+	ListType:            "List",
+	TableType:           "Table",
+	ParagraphType:       "Paragraph",
+	SectionType:         "Section",
+	DocumentType:        "Document",
+	FrontmatterType:     "Frontmatter",
+	PipelineType:        "Pipeline",
+	SectionRefType:      "SectionRef",
+	ImageType:           "Image",
+	BoldType:            "Bold",
+	ItalicType:          "Italic",
+	StrikethroughType:   "Strikethrough",
+	AdmonitionType:      "Admonition",
+	CollapsibleType:     "Collapsible",
+	HTMLBlockType:       "HTMLBlock",
+	MermaidType:         "Mermaid",
+	LineBreakType:       "LineBreak",
+	MathInlineType:      "MathInline",
+	MathBlockType:       "MathBlock",
+	CrossRefType:        "CrossRef",
+	TableOfContentsType: "TableOfContents",
+	BadgeType:           "Badge",
+}
+
+// String returns the name of the content type, matching the name of the node that produces it
+// (e.g., "CodeBlock"). Types without a name, such as those of custom nodes, are formatted as "ContentType(n)".
+func (c ContentType) String() string {
+	if name, ok := contentTypeNames[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ContentType(%d)", int(c))
+}
+
 // CodeBlockExecType represents how a code block should be processed during
 // documentation generation - either as static display content or as executable code
 type CodeBlockExecType int // todo: this name is awful
@@ -130,6 +219,80 @@ func (t Text) Materialize() (MaterializedContent, error) {
 	}, nil
 }
 
+// MARK: LineBreak
+
+// LineBreak represents a hard line break within a paragraph.
+type LineBreak struct{}
+
+// Type returns the ContentType for this line break element.
+func (l LineBreak) Type() ContentType { return LineBreakType }
+
+// Materialize converts the line break into an empty MaterializedContent;
+// renderers decide how the break is written.
+func (l LineBreak) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:     l.Type(),
+		Content:  "",
+		Metadata: map[string]interface{}{},
+	}, nil
+}
+
+// MARK: Emphasis
+
+// Bold represents inline text with strong emphasis.
+type Bold string
+
+// Type returns the ContentType for this bold element.
+func (b Bold) Type() ContentType { return BoldType }
+
+// Materialize converts the bold text into a MaterializedContent with its string content
+// and the emphasis style stored in metadata under the "Style" key.
+func (b Bold) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:    b.Type(),
+		Content: string(b),
+		Metadata: map[string]interface{}{
+			"Style": "bold",
+		},
+	}, nil
+}
+
+// Italic represents inline text with emphasis.
+type Italic string
+
+// Type returns the ContentType for this italic element.
+func (i Italic) Type() ContentType { return ItalicType }
+
+// Materialize converts the italic text into a MaterializedContent with its string content
+// and the emphasis style stored in metadata under the "Style" key.
+func (i Italic) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:    i.Type(),
+		Content: string(i),
+		Metadata: map[string]interface{}{
+			"Style": "italic",
+		},
+	}, nil
+}
+
+// Strikethrough represents inline text that has been struck out, such as a deprecated option.
+type Strikethrough string
+
+// Type returns the ContentType for this strikethrough element.
+func (s Strikethrough) Type() ContentType { return StrikethroughType }
+
+// Materialize converts the struck-out text into a MaterializedContent with its string content
+// and the style stored in metadata under the "Style" key.
+func (s Strikethrough) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:    s.Type(),
+		Content: string(s),
+		Metadata: map[string]interface{}{
+			"Style": "strikethrough",
+		},
+	}, nil
+}
+
 // MARK: Link
 
 // Link represents a hyperlink element with display text and a target URL.
@@ -155,6 +318,207 @@ func (l Link) Materialize() (MaterializedContent, error) {
 	}, nil
 }
 
+// MARK: Badge
+
+// Badge represents a status badge, an image that links to more detail, such as a CI run
+// or a license, commonly placed at the top of a README.
+type Badge struct {
+	// Label holds the alternative text for the badge image
+	Label string
+	// Url holds the target the badge links to
+	Url string
+	// ImageUrl holds the location of the badge image, typically a shields.io URL
+	ImageUrl string
+}
+
+// Type returns the ContentType for this badge element.
+func (b Badge) Type() ContentType { return BadgeType }
+
+// Materialize converts the badge into a MaterializedContent with the label as content and
+// the link and image URLs stored in metadata under the "Url" and "ImageUrl" keys.
+// Returns an error if the label is empty or either URL is invalid; the image must be an absolute
+// http(s) URL, while the link may be relative (e.g., to a LICENSE file in the repository).
+func (b Badge) Materialize() (MaterializedContent, error) {
+	if b.Label == "" {
+		return MaterializedContent{}, errors.New("badge label cannot be empty")
+	}
+
+	if b.Url == "" {
+		return MaterializedContent{}, fmt.Errorf("badge '%s' url cannot be empty", b.Label)
+	}
+	if _, err := url.Parse(b.Url); err != nil {
+		return MaterializedContent{}, fmt.Errorf("badge '%s' url is invalid: %w", b.Label, err)
+	}
+
+	imageUrl, err := url.Parse(b.ImageUrl)
+	if err != nil {
+		return MaterializedContent{}, fmt.Errorf("badge '%s' image url is invalid: %w", b.Label, err)
+	}
+	if (imageUrl.Scheme != "http" && imageUrl.Scheme != "https") || imageUrl.Host == "" {
+		return MaterializedContent{}, fmt.Errorf("badge '%s' image url must be an absolute http(s) url, got '%s'", b.Label, b.ImageUrl)
+	}
+
+	return MaterializedContent{
+		Type:    b.Type(),
+		Content: b.Label,
+		Metadata: map[string]interface{}{
+			"Url":      b.Url,
+			"ImageUrl": b.ImageUrl,
+		},
+	}, nil
+}
+
+// splitGitHubRepo splits an "owner/name" repository into its path-escaped parts.
+func splitGitHubRepo(repo string) (string, string, error) {
+	owner, name, found := strings.Cut(repo, "/")
+	if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid GitHub repository '%s': expected owner/name", repo)
+	}
+
+	return url.PathEscape(owner), url.PathEscape(name), nil
+}
+
+// NewGitHubActionsBadge creates a shields.io build status badge for a GitHub Actions workflow,
+// linking to the workflow's runs. The repo is given as "owner/name" and the workflow as its file
+// name (e.g., "ci.yml").
+func NewGitHubActionsBadge(repo, workflow string) (Badge, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return Badge{}, err
+	}
+
+	if workflow == "" {
+		return Badge{}, errors.New("workflow cannot be empty")
+	}
+	workflow = url.PathEscape(workflow)
+
+	return Badge{
+		Label:    "build",
+		Url:      fmt.Sprintf("https://github.com/%s/%s/actions/workflows/%s", owner, name, workflow),
+		ImageUrl: fmt.Sprintf("https://img.shields.io/github/actions/workflow/status/%s/%s/%s", owner, name, workflow),
+	}, nil
+}
+
+// NewGoReportCardBadge creates a Go Report Card badge for a module path
+// (e.g., "github.com/MoonMoon1919/doyoucompute"), linking to the module's report.
+func NewGoReportCardBadge(module string) (Badge, error) {
+	if module == "" {
+		return Badge{}, errors.New("module path cannot be empty")
+	}
+
+	return Badge{
+		Label:    "Go Report Card",
+		Url:      "https://goreportcard.com/report/" + module,
+		ImageUrl: "https://goreportcard.com/badge/" + module,
+	}, nil
+}
+
+// NewLicenseBadge creates a shields.io license badge for a GitHub repository given as
+// "owner/name", linking to the repository's LICENSE file.
+func NewLicenseBadge(repo string) (Badge, error) {
+	owner, name, err := splitGitHubRepo(repo)
+	if err != nil {
+		return Badge{}, err
+	}
+
+	return Badge{
+		Label:    "license",
+		Url:      fmt.Sprintf("https://github.com/%s/%s/blob/HEAD/LICENSE", owner, name),
+		ImageUrl: fmt.Sprintf("https://img.shields.io/github/license/%s/%s", owner, name),
+	}, nil
+}
+
+// MARK: CrossRef
+
+// CrossRef represents a link to a section elsewhere in the same document. The anchor is
+// computed from the target section's heading at render time, so the link always resolves.
+type CrossRef struct {
+	// Text holds the display text for the link, defaulting to the target section name
+	Text string
+	// Target holds the name of the section being linked to
+	Target string
+}
+
+// Type returns the ContentType for this cross reference element.
+func (c CrossRef) Type() ContentType { return CrossRefType }
+
+// Materialize converts the cross reference into a MaterializedContent with the display text
+// as content and the target section name stored in metadata under the "Target" key.
+// Returns an error if the target is empty.
+func (c CrossRef) Materialize() (MaterializedContent, error) {
+	if c.Target == "" {
+		return MaterializedContent{}, errors.New("cross reference target cannot be empty")
+	}
+
+	text := c.Text
+	if text == "" {
+		text = c.Target
+	}
+
+	return MaterializedContent{
+		Type:    c.Type(),
+		Content: text,
+		Metadata: map[string]interface{}{
+			"Target": c.Target,
+		},
+	}, nil
+}
+
+// MARK: TableOfContents
+
+// TableOfContents is a placeholder for a nested list of links to the sections of the document.
+// The entries are generated at render time, so sections added after the placeholder are listed.
+type TableOfContents struct {
+	// MaxDepth limits how deeply nested sections are listed; 0 lists every level
+	MaxDepth int
+}
+
+// Type returns the ContentType for this table of contents element.
+func (t TableOfContents) Type() ContentType { return TableOfContentsType }
+
+// Materialize converts the table of contents into a MaterializedContent with the maximum
+// depth stored in metadata under the "MaxDepth" key. Returns an error if the depth is negative.
+func (t TableOfContents) Materialize() (MaterializedContent, error) {
+	if t.MaxDepth < 0 {
+		return MaterializedContent{}, fmt.Errorf("table of contents depth cannot be negative, got %d", t.MaxDepth)
+	}
+
+	return MaterializedContent{
+		Type: t.Type(),
+		Metadata: map[string]interface{}{
+			"MaxDepth": t.MaxDepth,
+		},
+	}, nil
+}
+
+// MARK: Image
+
+// Image represents an embedded image with alternative text, a source URL, and an optional title.
+type Image struct {
+	// AltText describes the image for readers who cannot see it
+	AltText string
+	// Url holds the location of the image
+	Url string
+	// Title is optional text shown when hovering over the image
+	Title string
+}
+
+// Type returns the ContentType for this image element.
+func (i Image) Type() ContentType { return ImageType }
+
+// Materialize converts the image into a MaterializedContent with the alt text as content
+// and the URL and title stored in metadata under the "Url" and "Title" keys.
+func (i Image) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:    i.Type(),
+		Content: i.AltText,
+		Metadata: map[string]interface{}{
+			"Url":   i.Url,
+			"Title": i.Title,
+		},
+	}, nil
+}
+
 // MARK: Code
 
 // Code represents inline code content in documentation. It is defined as a string type
@@ -185,17 +549,29 @@ type CodeBlock struct {
 	BlockType string
 	// Cmd contains the lines or components of the code block content
 	Cmd []string
+	// JoinWith is placed between the elements of Cmd. Use "\n" when Cmd holds lines,
+	// such as a multi-line script or pretty-printed JSON. Defaults to a space.
+	JoinWith string
+}
+
+// joinCmd joins command components with joinWith, defaulting to a space.
+func joinCmd(cmd []string, joinWith string) string {
+	if joinWith == "" {
+		joinWith = " "
+	}
+
+	return strings.Join(cmd, joinWith)
 }
 
 // Type returns the ContentType for this code block element.
 func (c CodeBlock) Type() ContentType { return CodeBlockType }
 
 // Materialize converts the code block into a MaterializedContent by joining
-// the Cmd slice with spaces as the content and storing the BlockType in metadata.
+// the Cmd slice with JoinWith as the content and storing the BlockType in metadata.
 func (c CodeBlock) Materialize() (MaterializedContent, error) {
 	return MaterializedContent{
 		Type:    c.Type(),
-		Content: strings.Join(c.Cmd, " "),
+		Content: joinCmd(c.Cmd, c.JoinWith),
 		Metadata: map[string]interface{}{
 			"BlockType": c.BlockType,
 		},
@@ -221,8 +597,226 @@ func (b BlockQuote) Materialize() (MaterializedContent, error) {
 	}, nil
 }
 
+// MARK: Admonition
+
+// AdmonitionKind represents the different kinds of callouts GitHub can render.
+type AdmonitionKind int
+
+const (
+	// NOTE highlights information users should take into account, even when skimming
+	NOTE AdmonitionKind = iota + 1
+	// TIP offers optional information to help a user be more successful
+	TIP
+	// IMPORTANT marks crucial information necessary for users to succeed
+	IMPORTANT
+	// WARNING marks critical content demanding immediate user attention due to potential risks
+	WARNING
+	// CAUTION warns about the negative potential consequences of an action
+	CAUTION
+)
+
+// String returns the marker name for the kind (e.g., "NOTE").
+// Returns an empty string for unknown kinds.
+func (k AdmonitionKind) String() string {
+	switch k {
+	case NOTE:
+		return "NOTE"
+	case TIP:
+		return "TIP"
+	case IMPORTANT:
+		return "IMPORTANT"
+	case WARNING:
+		return "WARNING"
+	case CAUTION:
+		return "CAUTION"
+	}
+
+	return ""
+}
+
+// Valid returns an error if the kind is not one of the supported admonition kinds.
+func (k AdmonitionKind) Valid() error {
+	if k.String() == "" {
+		return fmt.Errorf("invalid admonition kind: %d", k)
+	}
+
+	return nil
+}
+
+// Admonition represents a GitHub-style callout block (e.g., > [!NOTE]) with a kind and body text.
+type Admonition struct {
+	// Kind specifies which callout style is used
+	Kind AdmonitionKind
+	// Body holds the text of the callout, which may span multiple lines
+	Body string
+}
+
+// Type returns the ContentType for this admonition element.
+func (a Admonition) Type() ContentType { return AdmonitionType }
+
+// Materialize converts the admonition into a MaterializedContent with the body as content
+// and the kind name stored in metadata under the "Kind" key.
+// Returns an error if the kind is invalid.
+func (a Admonition) Materialize() (MaterializedContent, error) {
+	if err := a.Kind.Valid(); err != nil {
+		return MaterializedContent{}, err
+	}
+
+	return MaterializedContent{
+		Type:    a.Type(),
+		Content: a.Body,
+		Metadata: map[string]interface{}{
+			"Kind": a.Kind.String(),
+		},
+	}, nil
+}
+
+// MARK: HTMLBlock
+
+// HTMLBlock represents a raw HTML snippet (e.g., an anchor target or a sized image)
+// that is passed through to markdown output verbatim.
+type HTMLBlock string
+
+// Type returns the ContentType for this HTML block element.
+func (h HTMLBlock) Type() ContentType { return HTMLBlockType }
+
+// Materialize converts the HTML block into a MaterializedContent with its string content.
+// HTML is processed as-is without transformation.
+func (h HTMLBlock) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:     h.Type(),
+		Content:  string(h),
+		Metadata: map[string]interface{}{},
+	}, nil
+}
+
+// MARK: Math
+
+// MathInline represents an inline LaTeX math expression (e.g., $x^2$).
+type MathInline string
+
+// Type returns the ContentType for this inline math element.
+func (m MathInline) Type() ContentType { return MathInlineType }
+
+// Materialize converts the expression into a MaterializedContent with its string content.
+// The expression is processed as-is; renderers handle any escaping.
+func (m MathInline) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:     m.Type(),
+		Content:  string(m),
+		Metadata: map[string]interface{}{},
+	}, nil
+}
+
+// MathBlock represents a display LaTeX math block, rendered with each line preserved.
+type MathBlock struct {
+	// Lines holds the LaTeX source, one line per entry
+	Lines []string
+}
+
+// Type returns the ContentType for this math block element.
+func (m MathBlock) Type() ContentType { return MathBlockType }
+
+// Materialize converts the block into a MaterializedContent with its lines joined by newlines
+// as content and "math" stored in metadata under the "BlockType" key.
+func (m MathBlock) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{
+		Type:    m.Type(),
+		Content: strings.Join(m.Lines, "\n"),
+		Metadata: map[string]interface{}{
+			"BlockType": "math",
+		},
+	}, nil
+}
+
+// MARK: Mermaid
+
+// mermaidDiagramKeywords are the declarations a mermaid diagram can start with.
+var mermaidDiagramKeywords = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "stateDiagram", "stateDiagram-v2",
+	"erDiagram", "journey", "gantt", "pie", "gitGraph", "mindmap", "timeline", "quadrantChart",
+	"requirementDiagram", "C4Context", "C4Container", "C4Component", "C4Dynamic", "C4Deployment",
+	"sankey-beta", "xychart-beta", "block-beta",
+}
+
+// Mermaid represents a mermaid diagram, rendered as a fenced block with each line preserved.
+type Mermaid struct {
+	// Lines holds the diagram source, one line per entry
+	Lines []string
+}
+
+// Type returns the ContentType for this mermaid diagram.
+func (m Mermaid) Type() ContentType { return MermaidType }
+
+// Valid performs a light syntax check, returning an error if the diagram is empty or its
+// first line (ignoring blank lines and %% comments) does not declare a known diagram type.
+func (m Mermaid) Valid() error {
+	for _, line := range m.Lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+
+		keyword := strings.Fields(trimmed)[0]
+		for _, known := range mermaidDiagramKeywords {
+			if keyword == known {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("mermaid diagram must start with a diagram type (e.g., graph, flowchart, sequenceDiagram), got '%s'", keyword)
+	}
+
+	return errors.New("mermaid diagram cannot be empty")
+}
+
+// Materialize converts the diagram into a MaterializedContent with its lines joined by newlines
+// as content and "mermaid" stored in metadata under the "BlockType" key.
+// Returns an error if the diagram fails validation.
+func (m Mermaid) Materialize() (MaterializedContent, error) {
+	if err := m.Valid(); err != nil {
+		return MaterializedContent{}, err
+	}
+
+	return MaterializedContent{
+		Type:    m.Type(),
+		Content: strings.Join(m.Lines, "\n"),
+		Metadata: map[string]interface{}{
+			"BlockType": "mermaid",
+		},
+	}, nil
+}
+
 // MARK: Executable
 
+// ExecutablePhase marks an executable as a regular step of its section or as part of the
+// section's setup or teardown.
+type ExecutablePhase int
+
+const (
+	// PhaseMain is a regular step of its section. It is the default.
+	PhaseMain ExecutablePhase = iota + 1
+	// PhaseSetup runs before the other commands of its section. If it fails, the rest of the
+	// section, including its teardown, is skipped.
+	PhaseSetup
+	// PhaseTeardown runs after the other commands of its section, even when they failed.
+	PhaseTeardown
+)
+
+// String returns the name of the phase.
+func (p ExecutablePhase) String() string {
+	switch p {
+	case 0, PhaseMain:
+		return "main"
+	case PhaseSetup:
+		return "setup"
+	case PhaseTeardown:
+		return "teardown"
+	}
+
+	return fmt.Sprintf("ExecutablePhase(%d)", int(p))
+}
+
 // Executable represents a code block that can be executed while running documentation as a script.
 // This is the core component that enables "runnable documentation" by storing commands
 // that will be executed, while representing them as code blocks in rendered output.
@@ -231,51 +825,227 @@ type Executable struct {
 	Shell string
 	// Cmd contains the command and arguments to be executed
 	Cmd []string
+	// Steps holds commands run one after another as a single logical step, each as its own
+	// command and arguments, in place of Cmd. A failing step skips the steps after it
+	Steps [][]string
 	// Environment variables that must be set for the command to be run
 	Environment []string
+	// EnvValues holds environment variables to set for the command, on top of those it inherits
+	EnvValues map[string]string
+	// JoinWith is placed between the elements of Cmd. Use "\n" when Cmd holds the lines
+	// of a multi-line script. Defaults to a space, where Cmd holds a command and its arguments.
+	JoinWith string
+	// Timeout overrides the runner's configured timeout for this command (0 means use the runner's)
+	Timeout time.Duration
+	// WorkingDir is the directory the command runs in (empty means the runner's working directory)
+	WorkingDir string
+	// Platforms restricts the command to these operating systems, named like GOOS values such as
+	// "darwin", "linux", or "windows" (empty means every platform)
+	Platforms []string
+	// SkipIfEnv skips the command when any of these conditions holds. A condition is either a
+	// variable name, which holds when the variable is set, or NAME=value
+	SkipIfEnv []string
+	// OnlyIfEnv skips the command unless all of these conditions hold, in the same form as SkipIfEnv
+	OnlyIfEnv []string
+	// Requires lists the tools the command needs, as executable names looked up on the PATH
+	Requires []string
+	// Phase marks the command as part of its section's setup or teardown (zero means PhaseMain)
+	Phase ExecutablePhase
+	// Tags label the command, such as "setup" or "destructive", so runs can include or exclude
+	// it. The command also carries the tags of the sections it is in
+	Tags []string
 }
 
 // Type returns the ContentType for this executable element.
 func (e Executable) Type() ContentType { return ExecutableType }
 
 // Materialize converts the executable into a MaterializedContent with the joined command
-// as content and execution metadata including the shell, executable flag, and original command.
+// as content and execution metadata including the shell, original command, injected environment values,
+// timeout, working directory, platforms, environment conditions, required tools, phase, and tags.
+// When JoinWith is set to something other than a space, Cmd is a script rather than an argument
+// list, so the "Command" metadata holds the joined script as a single element for the shell to run.
+// Executables with Steps have one step per line as content and the steps in the "Steps" metadata.
 func (e Executable) Materialize() (MaterializedContent, error) {
+	if len(e.Steps) > 0 && len(e.Cmd) > 0 {
+		return MaterializedContent{}, errors.New("executable cannot have both Cmd and Steps")
+	}
+
+	content := joinCmd(e.Cmd, e.JoinWith)
+
+	command := e.Cmd
+	if e.JoinWith != "" && e.JoinWith != " " {
+		command = []string{content}
+	}
+
+	if len(e.Steps) > 0 {
+		lines := make([]string, len(e.Steps))
+		for idx, step := range e.Steps {
+			if len(step) == 0 {
+				return MaterializedContent{}, fmt.Errorf("executable step %d has no command", idx+1)
+			}
+			lines[idx] = joinCmd(step, " ")
+		}
+		content = strings.Join(lines, "\n")
+	}
+
 	return MaterializedContent{
 		Type:    e.Type(),
-		Content: strings.Join(e.Cmd, " "),
+		Content: content,
 		Metadata: map[string]interface{}{
 			"Shell":       e.Shell,
-			"Command":     e.Cmd,
+			"Command":     command,
 			"Environment": e.Environment,
+			"EnvValues":   e.EnvValues,
+			"Timeout":     e.Timeout,
+			"WorkingDir":  e.WorkingDir,
+			"Platforms":   e.Platforms,
+			"SkipIfEnv":   e.SkipIfEnv,
+			"OnlyIfEnv":   e.OnlyIfEnv,
+			"Requires":    e.Requires,
+			"Phase":       e.Phase,
+			"Steps":       e.Steps,
+			"Tags":        e.Tags,
+		},
+	}, nil
+}
+
+// MARK: Pipeline
+
+// Pipeline represents a sequence of executables whose output is piped from one stage into
+// the next (e.g., `curl ... | jq .name`). Unlike writing the pipe inside a single Executable,
+// every stage is validated and executed on its own instead of being handed to a shell as one string.
+type Pipeline struct {
+	// Stages contains the executables to connect, in the order data flows through them
+	Stages []Executable
+}
+
+// Type returns the ContentType for this pipeline element.
+func (p Pipeline) Type() ContentType { return PipelineType }
+
+// Materialize converts the pipeline into a MaterializedContent with the stages joined by
+// pipes as content. The metadata holds the shell of the first stage for rendering, plus the
+// command, shell, working directory, and injected environment values of every stage, the combined
+// required environment variables, environment conditions, tools, and tags, the longest stage timeout, and the
+// platforms every stage can run on, since the stages run together.
+// Returns an error if the pipeline has no stages or its stages have no platform in common.
+func (p Pipeline) Materialize() (MaterializedContent, error) {
+	if len(p.Stages) == 0 {
+		return MaterializedContent{}, errors.New("pipeline has no stages")
+	}
+
+	stages := make([]string, len(p.Stages))
+	commands := make([][]string, len(p.Stages))
+	shells := make([]string, len(p.Stages))
+	workingDirs := make([]string, len(p.Stages))
+	envValues := make([]map[string]string, len(p.Stages))
+	var environment, skipIfEnv, onlyIfEnv, requires, tags []string
+	var timeout time.Duration
+	var platforms []string
+
+	for idx, stage := range p.Stages {
+		stages[idx] = strings.Join(stage.Cmd, " ")
+		commands[idx] = stage.Cmd
+		shells[idx] = stage.Shell
+		workingDirs[idx] = stage.WorkingDir
+		envValues[idx] = stage.EnvValues
+		environment = append(environment, stage.Environment...)
+		skipIfEnv = append(skipIfEnv, stage.SkipIfEnv...)
+		onlyIfEnv = append(onlyIfEnv, stage.OnlyIfEnv...)
+		requires = append(requires, stage.Requires...)
+		tags = append(tags, stage.Tags...)
+		timeout = max(timeout, stage.Timeout)
+
+		var ok bool
+		if platforms, ok = intersectPlatforms(platforms, stage.Platforms); !ok {
+			return MaterializedContent{}, fmt.Errorf("pipeline stage %d (%s) shares no platform with the stages before it", idx+1, stages[idx])
+		}
+	}
+
+	return MaterializedContent{
+		Type:    p.Type(),
+		Content: strings.Join(stages, " | "),
+		Metadata: map[string]interface{}{
+			"Shell":            p.Stages[0].Shell,
+			"Stages":           commands,
+			"StageShells":      shells,
+			"StageWorkingDirs": workingDirs,
+			"StageEnvValues":   envValues,
+			"Environment":      environment,
+			"Timeout":          timeout,
+			"Platforms":        platforms,
+			"SkipIfEnv":        skipIfEnv,
+			"OnlyIfEnv":        onlyIfEnv,
+			"Requires":         requires,
+			"Tags":             tags,
 		},
 	}, nil
 }
 
+// intersectPlatforms narrows a platform constraint by another one, where an empty constraint
+// means every platform. Returns false if the constraints have no platform in common.
+func intersectPlatforms(current []string, next []string) ([]string, bool) {
+	if len(next) == 0 {
+		return current, true
+	}
+
+	if len(current) == 0 {
+		return next, true
+	}
+
+	var common []string
+	for _, platform := range current {
+		if slices.Contains(next, platform) {
+			common = append(common, platform)
+		}
+	}
+
+	return common, len(common) > 0
+}
+
 // MARK: Remote
 
 // Remote represents content that is sourced from external locations such as local files
-// in a docs folder, GitHub repositories, or other remote sources.
+// in a docs folder, GitHub repositories, or other remote sources. NewRemoteURL creates one
+// that downloads its content from a URL.
 type Remote struct {
 	// Reader provides access to the remote content data
 	Reader io.Reader
+	// Pin optionally holds the expected sha256 hex digest of the content. When set, content
+	// whose digest differs is rejected so upstream changes cannot silently alter renders.
+	Pin string
 }
 
 // Type returns the ContentType for this remote content element.
 func (r Remote) Type() ContentType { return RemoteType }
 
-// Materialize reads all content from the Reader and converts it into a MaterializedContent.
-// Returns an error if the content cannot be read from the remote source.
+// ContentDigest returns the sha256 hex digest of content, in the form expected by Remote.Pin.
+func ContentDigest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Materialize reads all content from the Reader and converts it into a MaterializedContent
+// with the content's digest stored in metadata under the "Digest" key.
+// Returns an error if the content cannot be read from the remote source or does not match the Pin.
 func (r Remote) Materialize() (MaterializedContent, error) {
 	content, err := io.ReadAll(r.Reader)
 	if err != nil {
 		return MaterializedContent{}, err
 	}
 
+	digest := ContentDigest(string(content))
+
+	if r.Pin != "" && !strings.EqualFold(strings.TrimSpace(r.Pin), digest) {
+		return MaterializedContent{}, fmt.Errorf("%w: expected %s, got %s (update the Pin to %s if the new content is expected)", ErrRemoteDigestMismatch, r.Pin, digest, digest)
+	}
+
 	return MaterializedContent{
-		Type:     r.Type(),
-		Content:  string(content),
-		Metadata: map[string]interface{}{},
+		Type:    r.Type(),
+		Content: string(content),
+		Metadata: map[string]interface{}{
+			"Digest": digest,
+		},
 	}, nil
 }
 
@@ -285,19 +1055,53 @@ func (r Remote) Materialize() (MaterializedContent, error) {
 type TableRow struct {
 	// Values contains the content for each column in this table row
 	Values []string
+	// Cells contains rich content, such as links or inline code, for each column.
+	// When set, Cells are rendered instead of Values.
+	Cells []Node
 }
 
 // Type returns the ContentType for this table row element.
 func (t TableRow) Type() ContentType { return TableRowType }
 
+// Rich returns true if the row's columns are nodes rather than plain strings.
+func (t TableRow) Rich() bool { return len(t.Cells) > 0 }
+
 // Materialize converts the table row into a MaterializedContent with empty content
-// and the row values stored in metadata under the "Items" key.
+// and the row values stored in metadata under the "Items" key. For rich rows, "Items"
+// holds the materialized text of each cell and the cells themselves are stored under "Cells".
 func (t TableRow) Materialize() (MaterializedContent, error) {
+	if !t.Rich() {
+		return MaterializedContent{
+			Type:    t.Type(),
+			Content: "",
+			Metadata: map[string]interface{}{
+				"Items": t.Values,
+			},
+		}, nil
+	}
+
+	items := make([]string, len(t.Cells))
+
+	for idx, cell := range t.Cells {
+		contenter, ok := cell.(Contenter)
+		if !ok {
+			return MaterializedContent{}, fmt.Errorf("table cell %d cannot be materialized", idx+1)
+		}
+
+		content, err := contenter.Materialize()
+		if err != nil {
+			return MaterializedContent{}, err
+		}
+
+		items[idx] = content.Content
+	}
+
 	return MaterializedContent{
 		Type:    t.Type(),
 		Content: "",
 		Metadata: map[string]interface{}{
-			"Items": t.Values,
+			"Items": items,
+			"Cells": t.Cells,
 		},
 	}, nil
 }
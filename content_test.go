@@ -94,6 +94,56 @@ func TestTextMaterialize(t *testing.T) {
 	}
 }
 
+func TestEmphasisMaterialize(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       Contenter
+		expectedType  ContentType
+		expectedStyle string
+		errorMessage  string
+	}{
+		{
+			name:          "Passing-Bold",
+			content:       Bold("important"),
+			expectedType:  BoldType,
+			expectedStyle: "bold",
+		},
+		{
+			name:          "Passing-Italic",
+			content:       Italic("subtle"),
+			expectedType:  ItalicType,
+			expectedStyle: "italic",
+		},
+		{
+			name:          "Passing-Strikethrough",
+			content:       Strikethrough("--legacy"),
+			expectedType:  StrikethroughType,
+			expectedStyle: "strikethrough",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return tc.content
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != tc.expectedType {
+						t.Errorf("Expected Type to be %d, got %d", tc.expectedType, m.Type)
+					}
+
+					if m.Metadata["Style"] != tc.expectedStyle {
+						t.Errorf("Expected Style to be %s, got %v", tc.expectedStyle, m.Metadata["Style"])
+					}
+				},
+			)
+		})
+	}
+}
+
 func TestLinkMaterialize(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -139,6 +189,260 @@ func TestLinkMaterialize(t *testing.T) {
 	}
 }
 
+func TestBadgeMaterialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		badge        Badge
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			badge:        Badge{Label: "build", Url: "https://github.com/o/r/actions", ImageUrl: "https://img.shields.io/badge/build-passing-green"},
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-RelativeLink",
+			badge:        Badge{Label: "license", Url: "LICENSE", ImageUrl: "https://img.shields.io/badge/license-MIT-blue"},
+			errorMessage: "",
+		},
+		{
+			name:         "Failing-EmptyLabel",
+			badge:        Badge{Url: "LICENSE", ImageUrl: "https://img.shields.io/badge/license-MIT-blue"},
+			errorMessage: "badge label cannot be empty",
+		},
+		{
+			name:         "Failing-EmptyUrl",
+			badge:        Badge{Label: "license", ImageUrl: "https://img.shields.io/badge/license-MIT-blue"},
+			errorMessage: "badge 'license' url cannot be empty",
+		},
+		{
+			name:         "Failing-InvalidUrl",
+			badge:        Badge{Label: "license", Url: "https://exa mple.com", ImageUrl: "https://img.shields.io/badge/license-MIT-blue"},
+			errorMessage: "badge 'license' url is invalid: parse \"https://exa mple.com\": invalid character \" \" in host name",
+		},
+		{
+			name:         "Failing-RelativeImage",
+			badge:        Badge{Label: "license", Url: "LICENSE", ImageUrl: "badges/license.svg"},
+			errorMessage: "badge 'license' image url must be an absolute http(s) url, got 'badges/license.svg'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return tc.badge
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != BadgeType {
+						t.Errorf("Expected Type to be %d, got %d", BadgeType, m.Type)
+					}
+
+					if m.Content != tc.badge.Label {
+						t.Errorf("Expected content to be %s, got %s", tc.badge.Label, m.Content)
+					}
+
+					if val := m.Metadata["Url"]; val != tc.badge.Url {
+						t.Errorf("Expected Url to be %s, got %s", tc.badge.Url, val)
+					}
+
+					if val := m.Metadata["ImageUrl"]; val != tc.badge.ImageUrl {
+						t.Errorf("Expected ImageUrl to be %s, got %s", tc.badge.ImageUrl, val)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestNewBadges(t *testing.T) {
+	tests := []struct {
+		name         string
+		build        func() (Badge, error)
+		expected     Badge
+		errorMessage string
+	}{
+		{
+			name:  "Passing-GitHubActions",
+			build: func() (Badge, error) { return NewGitHubActionsBadge("MoonMoon1919/doyoucompute", "ci.yml") },
+			expected: Badge{
+				Label:    "build",
+				Url:      "https://github.com/MoonMoon1919/doyoucompute/actions/workflows/ci.yml",
+				ImageUrl: "https://img.shields.io/github/actions/workflow/status/MoonMoon1919/doyoucompute/ci.yml",
+			},
+		},
+		{
+			name:  "Passing-GitHubActionsEscapesWorkflow",
+			build: func() (Badge, error) { return NewGitHubActionsBadge("o/r", "build and test.yml") },
+			expected: Badge{
+				Label:    "build",
+				Url:      "https://github.com/o/r/actions/workflows/build%20and%20test.yml",
+				ImageUrl: "https://img.shields.io/github/actions/workflow/status/o/r/build%20and%20test.yml",
+			},
+		},
+		{
+			name:         "Failing-GitHubActionsNoWorkflow",
+			build:        func() (Badge, error) { return NewGitHubActionsBadge("o/r", "") },
+			errorMessage: "workflow cannot be empty",
+		},
+		{
+			name:  "Passing-GoReportCard",
+			build: func() (Badge, error) { return NewGoReportCardBadge("github.com/MoonMoon1919/doyoucompute") },
+			expected: Badge{
+				Label:    "Go Report Card",
+				Url:      "https://goreportcard.com/report/github.com/MoonMoon1919/doyoucompute",
+				ImageUrl: "https://goreportcard.com/badge/github.com/MoonMoon1919/doyoucompute",
+			},
+		},
+		{
+			name:         "Failing-GoReportCardEmpty",
+			build:        func() (Badge, error) { return NewGoReportCardBadge("") },
+			errorMessage: "module path cannot be empty",
+		},
+		{
+			name:  "Passing-License",
+			build: func() (Badge, error) { return NewLicenseBadge("MoonMoon1919/doyoucompute") },
+			expected: Badge{
+				Label:    "license",
+				Url:      "https://github.com/MoonMoon1919/doyoucompute/blob/HEAD/LICENSE",
+				ImageUrl: "https://img.shields.io/github/license/MoonMoon1919/doyoucompute",
+			},
+		},
+		{
+			name:         "Failing-LicenseNoOwner",
+			build:        func() (Badge, error) { return NewLicenseBadge("doyoucompute") },
+			errorMessage: "invalid GitHub repository 'doyoucompute': expected owner/name",
+		},
+		{
+			name:         "Failing-LicenseTooManyParts",
+			build:        func() (Badge, error) { return NewLicenseBadge("github.com/o/r") },
+			errorMessage: "invalid GitHub repository 'github.com/o/r': expected owner/name",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			badge, err := tc.build()
+
+			checkErrors(tc.errorMessage, err, t)
+			if badge != tc.expected {
+				t.Errorf("Expected badge %+v, got %+v", tc.expected, badge)
+			}
+		})
+	}
+}
+
+func TestCrossRefMaterialize(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		target          string
+		expectedContent string
+		errorMessage    string
+	}{
+		{
+			name:            "Passing",
+			text:            "the install steps",
+			target:          "Installation",
+			expectedContent: "the install steps",
+			errorMessage:    "",
+		},
+		{
+			name:            "Passing-DefaultText",
+			target:          "Installation",
+			expectedContent: "Installation",
+			errorMessage:    "",
+		},
+		{
+			name:         "Failing-EmptyTarget",
+			text:         "nowhere",
+			errorMessage: "cross reference target cannot be empty",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return CrossRef{Text: tc.text, Target: tc.target}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != CrossRefType {
+						t.Errorf("Expected Type to be %d, got %d", CrossRefType, m.Type)
+					}
+
+					if m.Content != tc.expectedContent {
+						t.Errorf("Expected content to be %s, got %s", tc.expectedContent, m.Content)
+					}
+
+					if val, ok := m.Metadata["Target"]; ok {
+						if val != tc.target {
+							t.Errorf("Expected Target to be %s, got %s", tc.target, val)
+						}
+					} else {
+						t.Errorf("Did not find Target")
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestImageMaterialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		altText      string
+		url          string
+		title        string
+		errorMessage string
+	}{
+		{
+			name:    "Passing",
+			altText: "Architecture diagram",
+			url:     "docs/arch.png",
+			title:   "Architecture",
+		},
+		{
+			name:    "Passing-NoTitle",
+			altText: "Build status",
+			url:     "https://example.com/badge.svg",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return Image{AltText: tc.altText, Url: tc.url, Title: tc.title}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != ImageType {
+						t.Errorf("Expected Type to be %d, got %d", ImageType, m.Type)
+					}
+
+					if m.Content != tc.altText {
+						t.Errorf("Expected Content to be %s, got %s", tc.altText, m.Content)
+					}
+
+					if val := m.Metadata["Url"]; val != tc.url {
+						t.Errorf("Expected Url to be %s, got %v", tc.url, val)
+					}
+
+					if val := m.Metadata["Title"]; val != tc.title {
+						t.Errorf("Expected Title to be %s, got %v", tc.title, val)
+					}
+				},
+			)
+		})
+	}
+}
+
 func TestCodeMaterialize(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -176,16 +480,27 @@ func TestCodeMaterialize(t *testing.T) {
 
 func TestCodeBlockMaterialize(t *testing.T) {
 	tests := []struct {
-		name         string
-		blockType    string
-		content      []string
-		errorMessage string
+		name            string
+		blockType       string
+		content         []string
+		joinWith        string
+		expectedContent string
+		errorMessage    string
 	}{
 		{
-			name:         "Passing",
-			blockType:    "sh",
-			content:      []string{"go", "vet"},
-			errorMessage: "",
+			name:            "Passing",
+			blockType:       "sh",
+			content:         []string{"go", "vet"},
+			expectedContent: "go vet",
+			errorMessage:    "",
+		},
+		{
+			name:            "Passing-JoinWithNewline",
+			blockType:       "json",
+			content:         []string{"{", `  "name": "doyoucompute"`, "}"},
+			joinWith:        "\n",
+			expectedContent: "{\n  \"name\": \"doyoucompute\"\n}",
+			errorMessage:    "",
 		},
 	}
 
@@ -194,7 +509,7 @@ func TestCodeBlockMaterialize(t *testing.T) {
 			testMaterialize(
 				t,
 				func() Contenter {
-					return CodeBlock{BlockType: tc.blockType, Cmd: tc.content}
+					return CodeBlock{BlockType: tc.blockType, Cmd: tc.content, JoinWith: tc.joinWith}
 				},
 				tc.errorMessage,
 				func(m MaterializedContent, t *testing.T) {
@@ -202,8 +517,8 @@ func TestCodeBlockMaterialize(t *testing.T) {
 						t.Errorf("Expected Type to be %d, got %d", CodeBlockType, m.Type)
 					}
 
-					if m.Content != strings.Join(tc.content, " ") {
-						t.Errorf("Expected content to be %s, got %s", strings.Join(tc.content, " "), m.Content)
+					if m.Content != tc.expectedContent {
+						t.Errorf("Expected content to be %q, got %q", tc.expectedContent, m.Content)
 					}
 
 					if val, ok := m.Metadata["BlockType"]; ok {
@@ -219,19 +534,27 @@ func TestCodeBlockMaterialize(t *testing.T) {
 	}
 }
 
-func TestExecutableMaterialize(t *testing.T) {
+func TestAdmonitionMaterialize(t *testing.T) {
 	tests := []struct {
 		name         string
-		blockType    string
-		content      []string
+		kind         AdmonitionKind
+		body         string
+		expectedKind string
 		errorMessage string
 	}{
 		{
 			name:         "Passing",
-			blockType:    "sh",
-			content:      []string{"go", "vet"},
+			kind:         WARNING,
+			body:         "This deletes everything.\nBack up first.",
+			expectedKind: "WARNING",
 			errorMessage: "",
 		},
+		{
+			name:         "Fail-InvalidKind",
+			kind:         AdmonitionKind(42),
+			body:         "Nope",
+			errorMessage: "invalid admonition kind: 42",
+		},
 	}
 
 	for _, tc := range tests {
@@ -239,7 +562,216 @@ func TestExecutableMaterialize(t *testing.T) {
 			testMaterialize(
 				t,
 				func() Contenter {
-					return Executable{Shell: tc.blockType, Cmd: tc.content}
+					return Admonition{Kind: tc.kind, Body: tc.body}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != AdmonitionType {
+						t.Errorf("Expected Type to be %d, got %d", AdmonitionType, m.Type)
+					}
+
+					if m.Content != tc.body {
+						t.Errorf("Expected Content to be %s, got %s", tc.body, m.Content)
+					}
+
+					if m.Metadata["Kind"] != tc.expectedKind {
+						t.Errorf("Expected Kind to be %s, got %v", tc.expectedKind, m.Metadata["Kind"])
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestHTMLBlockMaterialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			content:      "<p align=\"center\">\n  <img src=\"logo.png\">\n</p>",
+			errorMessage: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return HTMLBlock(tc.content)
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != HTMLBlockType {
+						t.Errorf("Expected Type to be %d, got %d", HTMLBlockType, m.Type)
+					}
+
+					if m.Content != tc.content {
+						t.Errorf("Expected Content to be %s, got %s", tc.content, m.Content)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestMermaidMaterialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		lines        []string
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			lines:        []string{"graph TD", "    A[Start] --> B[Render]", "    B --> C[Compare]"},
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-LeadingComment",
+			lines:        []string{"", "%% generated", "sequenceDiagram", "    Alice->>Bob: Hi"},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-Empty",
+			lines:        []string{"", "  "},
+			errorMessage: "mermaid diagram cannot be empty",
+		},
+		{
+			name:         "Fail-UnknownDiagram",
+			lines:        []string{"A --> B"},
+			errorMessage: "mermaid diagram must start with a diagram type (e.g., graph, flowchart, sequenceDiagram), got 'A'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return Mermaid{Lines: tc.lines}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != MermaidType {
+						t.Errorf("Expected Type to be %d, got %d", MermaidType, m.Type)
+					}
+
+					if m.Content != strings.Join(tc.lines, "\n") {
+						t.Errorf("Expected Content to be %s, got %s", strings.Join(tc.lines, "\n"), m.Content)
+					}
+
+					if m.Metadata["BlockType"] != "mermaid" {
+						t.Errorf("Expected BlockType to be mermaid, got %v", m.Metadata["BlockType"])
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestMathMaterialize(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         Contenter
+		expectedType    ContentType
+		expectedContent string
+		errorMessage    string
+	}{
+		{
+			name:            "Passing-Inline",
+			content:         MathInline("e = mc^2"),
+			expectedType:    MathInlineType,
+			expectedContent: "e = mc^2",
+		},
+		{
+			name:            "Passing-Block",
+			content:         MathBlock{Lines: []string{`\sum_{i=1}^{n} i`, `= \frac{n(n+1)}{2}`}},
+			expectedType:    MathBlockType,
+			expectedContent: "\\sum_{i=1}^{n} i\n= \\frac{n(n+1)}{2}",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return tc.content
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != tc.expectedType {
+						t.Errorf("Expected Type to be %d, got %d", tc.expectedType, m.Type)
+					}
+
+					if m.Content != tc.expectedContent {
+						t.Errorf("Expected Content to be %s, got %s", tc.expectedContent, m.Content)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestExecutableMaterialize(t *testing.T) {
+	tests := []struct {
+		name            string
+		blockType       string
+		content         []string
+		joinWith        string
+		steps           [][]string
+		expectedContent string
+		expectedCommand []string
+		errorMessage    string
+	}{
+		{
+			name:            "Passing",
+			blockType:       "sh",
+			content:         []string{"go", "vet"},
+			expectedContent: "go vet",
+			expectedCommand: []string{"go", "vet"},
+			errorMessage:    "",
+		},
+		{
+			name:            "Passing-JoinWithNewline",
+			blockType:       "bash",
+			content:         []string{"set -e", "go vet ./...", "go test ./..."},
+			joinWith:        "\n",
+			expectedContent: "set -e\ngo vet ./...\ngo test ./...",
+			expectedCommand: []string{"set -e\ngo vet ./...\ngo test ./..."},
+			errorMessage:    "",
+		},
+		{
+			name:            "Passing-Steps",
+			blockType:       "bash",
+			steps:           [][]string{{"mkdir", "-p", "build"}, {"cmake", "-S", ".", "-B", "build"}},
+			expectedContent: "mkdir -p build\ncmake -S . -B build",
+			errorMessage:    "",
+		},
+		{
+			name:         "Failing-StepsAndCmd",
+			blockType:    "bash",
+			content:      []string{"make"},
+			steps:        [][]string{{"mkdir", "-p", "build"}},
+			errorMessage: "executable cannot have both Cmd and Steps",
+		},
+		{
+			name:         "Failing-EmptyStep",
+			blockType:    "bash",
+			steps:        [][]string{{"mkdir", "-p", "build"}, {}},
+			errorMessage: "executable step 2 has no command",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return Executable{Shell: tc.blockType, Cmd: tc.content, JoinWith: tc.joinWith, Steps: tc.steps}
 				},
 				tc.errorMessage,
 				func(m MaterializedContent, t *testing.T) {
@@ -247,8 +779,8 @@ func TestExecutableMaterialize(t *testing.T) {
 						t.Errorf("Expected Type to be %d, got %d", ExecutableType, m.Type)
 					}
 
-					if m.Content != strings.Join(tc.content, " ") {
-						t.Errorf("Expected content to be %s, got %s", strings.Join(tc.content, " "), m.Content)
+					if m.Content != tc.expectedContent {
+						t.Errorf("Expected content to be %q, got %q", tc.expectedContent, m.Content)
 					}
 
 					if val, ok := m.Metadata["Shell"]; ok {
@@ -260,12 +792,105 @@ func TestExecutableMaterialize(t *testing.T) {
 					}
 
 					if val, ok := m.Metadata["Command"]; ok {
-						if !reflect.DeepEqual(val, tc.content) {
-							t.Errorf("Expected Command to be %s got %s", tc.content, val)
+						if !reflect.DeepEqual(val, tc.expectedCommand) {
+							t.Errorf("Expected Command to be %s got %s", tc.expectedCommand, val)
 						}
 					} else {
 						t.Errorf("Did not find Command")
 					}
+
+					if steps := m.Metadata["Steps"].([][]string); !reflect.DeepEqual(steps, tc.steps) {
+						t.Errorf("Expected Steps to be %v, got %v", tc.steps, steps)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestPipelineMaterialize(t *testing.T) {
+	tests := []struct {
+		name              string
+		stages            []Executable
+		expectedContent   string
+		expectedPlatforms []string
+		errorMessage      string
+	}{
+		{
+			name: "Passing",
+			stages: []Executable{
+				{Shell: "bash", Cmd: []string{"curl", "-s", "$URL"}, Environment: []string{"URL"}},
+				{Shell: "sh", Cmd: []string{"jq", ".name"}},
+			},
+			expectedContent: "curl -s $URL | jq .name",
+			errorMessage:    "",
+		},
+		{
+			name: "Passing-CommonPlatforms",
+			stages: []Executable{
+				{Shell: "bash", Cmd: []string{"pbpaste"}, Platforms: []string{"darwin", "linux"}},
+				{Shell: "sh", Cmd: []string{"wc", "-l"}},
+			},
+			expectedContent:   "pbpaste | wc -l",
+			expectedPlatforms: []string{"darwin", "linux"},
+		},
+		{
+			name: "Passing-NarrowedPlatforms",
+			stages: []Executable{
+				{Shell: "bash", Cmd: []string{"cat", "log"}, Platforms: []string{"darwin", "linux"}},
+				{Shell: "sh", Cmd: []string{"pbcopy"}, Platforms: []string{"darwin", "windows"}},
+			},
+			expectedContent:   "cat log | pbcopy",
+			expectedPlatforms: []string{"darwin"},
+		},
+		{
+			name: "Fail-NoCommonPlatform",
+			stages: []Executable{
+				{Shell: "bash", Cmd: []string{"apt-get", "install", "jq"}, Platforms: []string{"linux"}},
+				{Shell: "sh", Cmd: []string{"pbcopy"}, Platforms: []string{"darwin"}},
+			},
+			errorMessage: "pipeline stage 2 (pbcopy) shares no platform with the stages before it",
+		},
+		{
+			name:         "Fail-NoStages",
+			stages:       []Executable{},
+			errorMessage: "pipeline has no stages",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return Pipeline{Stages: tc.stages}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if m.Type != PipelineType {
+						t.Errorf("Expected Type to be %d, got %d", PipelineType, m.Type)
+					}
+
+					if m.Content != tc.expectedContent {
+						t.Errorf("Expected content to be %s, got %s", tc.expectedContent, m.Content)
+					}
+
+					if val := m.Metadata["Shell"]; val != tc.stages[0].Shell {
+						t.Errorf("Expected Shell to be %s, got %v", tc.stages[0].Shell, val)
+					}
+
+					expectedStages := [][]string{tc.stages[0].Cmd, tc.stages[1].Cmd}
+					if val := m.Metadata["Stages"]; !reflect.DeepEqual(val, expectedStages) {
+						t.Errorf("Expected Stages to be %v, got %v", expectedStages, val)
+					}
+
+					if val := m.Metadata["StageShells"]; !reflect.DeepEqual(val, []string{"bash", "sh"}) {
+						t.Errorf("Expected StageShells to be %v, got %v", []string{"bash", "sh"}, val)
+					}
+
+					if val := m.Metadata["Platforms"].([]string); !reflect.DeepEqual(val, tc.expectedPlatforms) {
+						t.Errorf("Expected Platforms to be %v, got %v", tc.expectedPlatforms, val)
+					}
 				},
 			)
 		})
@@ -276,6 +901,7 @@ func TestRemoteMaterialize(t *testing.T) {
 	tests := []struct {
 		name         string
 		content      string
+		pin          string
 		errorMessage string
 	}{
 		{
@@ -283,6 +909,18 @@ func TestRemoteMaterialize(t *testing.T) {
 			content:      "doot",
 			errorMessage: "",
 		},
+		{
+			name:         "Passing-PinMatches",
+			content:      "doot",
+			pin:          "690e8ea126c616b60d6572705492457fcd9c1105e597afba9a5153c6f44f2d62",
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-PinMismatch",
+			content:      "doot doot",
+			pin:          "690e8ea126c616b60d6572705492457fcd9c1105e597afba9a5153c6f44f2d62",
+			errorMessage: "remote content does not match pinned digest: expected 690e8ea126c616b60d6572705492457fcd9c1105e597afba9a5153c6f44f2d62, got bdc8546a591f21ec79ba8902dd89d547112efda842b30983d58e7a2749c0778c (update the Pin to bdc8546a591f21ec79ba8902dd89d547112efda842b30983d58e7a2749c0778c if the new content is expected)",
+		},
 	}
 
 	for _, tc := range tests {
@@ -290,7 +928,7 @@ func TestRemoteMaterialize(t *testing.T) {
 			testMaterialize(
 				t,
 				func() Contenter {
-					return Remote{Reader: strings.NewReader(tc.content)}
+					return Remote{Reader: strings.NewReader(tc.content), Pin: tc.pin}
 				},
 				tc.errorMessage,
 				func(m MaterializedContent, t *testing.T) {
@@ -298,6 +936,10 @@ func TestRemoteMaterialize(t *testing.T) {
 						t.Errorf("Expected Type to be %d, got %d", RemoteType, m.Type)
 					}
 
+					if m.Metadata["Digest"] != ContentDigest(tc.content) {
+						t.Errorf("Expected Digest to be %s, got %v", ContentDigest(tc.content), m.Metadata["Digest"])
+					}
+
 					if m.Content != tc.content {
 						t.Errorf("Expected Content to be %s, got %s", tc.content, m.Content)
 					}
@@ -350,6 +992,48 @@ func TestTableRowMaterialize(t *testing.T) {
 	}
 }
 
+func TestRichTableRowMaterialize(t *testing.T) {
+	tests := []struct {
+		name          string
+		cells         []Node
+		expectedItems []string
+		errorMessage  string
+	}{
+		{
+			name:          "Passing",
+			cells:         []Node{Code("--force"), Link{Text: "docs", Url: "https://example.com"}},
+			expectedItems: []string{"--force", "docs"},
+			errorMessage:  "",
+		},
+		{
+			name:         "Fail-StructureCell",
+			cells:        []Node{Text("ok"), NewList(BULLET)},
+			errorMessage: "table cell 2 cannot be materialized",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMaterialize(
+				t,
+				func() Contenter {
+					return TableRow{Cells: tc.cells}
+				},
+				tc.errorMessage,
+				func(m MaterializedContent, t *testing.T) {
+					if !reflect.DeepEqual(m.Metadata["Items"], tc.expectedItems) {
+						t.Errorf("Expected Items to be %v, got %v", tc.expectedItems, m.Metadata["Items"])
+					}
+
+					if !reflect.DeepEqual(m.Metadata["Cells"], tc.cells) {
+						t.Errorf("Expected Cells to be %v, got %v", tc.cells, m.Metadata["Cells"])
+					}
+				},
+			)
+		})
+	}
+}
+
 func TestCommmentMaterialize(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -0,0 +1,248 @@
+package doyoucompute
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultDiffContext is the number of unchanged lines shown around each change of a diff.
+const DefaultDiffContext = 3
+
+// maxDiffEdits bounds the edits searched for when diffing, keeping the memory of the search
+// small. Past it, the differing lines are shown as removed and added in one piece.
+const maxDiffEdits = 1000
+
+// diffLine is a line of an edit script: kept (' '), removed ('-'), or added ('+').
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the unified diff turning before into after, compared line by line, with
+// up to context unchanged lines around each change. The header names them by beforeLabel and
+// afterLabel. Returns "" if they are identical.
+func UnifiedDiff(before, after, beforeLabel, afterLabel string, context int) string {
+	if before == after {
+		return ""
+	}
+
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", beforeLabel, afterLabel)
+
+	// beforeLine and afterLine count the lines of each side before lines[idx]
+	beforeLine, afterLine := 0, 0
+	idx := 0
+	for idx < len(lines) {
+		if lines[idx].kind == ' ' {
+			beforeLine++
+			afterLine++
+			idx++
+			continue
+		}
+
+		// A hunk starts context lines before a change and runs until a gap of unchanged lines
+		// too wide to share context
+		start := max(idx-context, 0)
+		beforeLine -= idx - start
+		afterLine -= idx - start
+
+		end := idx
+		for end < len(lines) {
+			next := end
+			for next < len(lines) && lines[next].kind == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			for next < len(lines) && lines[next].kind != ' ' {
+				next++
+			}
+			end = next
+		}
+
+		writeHunk(&builder, lines[start:end], beforeLine, afterLine)
+
+		for _, line := range lines[start:end] {
+			if line.kind != '+' {
+				beforeLine++
+			}
+			if line.kind != '-' {
+				afterLine++
+			}
+		}
+		idx = end
+	}
+
+	return builder.String()
+}
+
+// writeHunk writes a hunk of lines, preceded by its header. beforeLine and afterLine count the
+// lines of each side before the hunk.
+func writeHunk(builder *strings.Builder, lines []diffLine, beforeLine, afterLine int) {
+	beforeCount, afterCount := 0, 0
+	for _, line := range lines {
+		if line.kind != '+' {
+			beforeCount++
+		}
+		if line.kind != '-' {
+			afterCount++
+		}
+	}
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(beforeLine, beforeCount), hunkRange(afterLine, afterCount))
+
+	for _, line := range lines {
+		builder.WriteByte(line.kind)
+		builder.WriteString(line.text)
+		if !strings.HasSuffix(line.text, "\n") {
+			builder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of a hunk on one side, whose lines start after the first
+// preceding lines. Empty ranges are numbered by the line before them.
+func hunkRange(preceding, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", preceding)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", preceding+1)
+	}
+
+	return fmt.Sprintf("%d,%d", preceding+1, count)
+}
+
+// splitLines splits content into lines, each keeping its newline.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the edit script turning before into after. Lines the two share at their
+// start and end are kept without searching, so small changes to large files stay cheap.
+func diffLines(before, after []string) []diffLine {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(before)+len(after)-prefix-suffix)
+	for _, text := range before[:prefix] {
+		lines = append(lines, diffLine{kind: ' ', text: text})
+	}
+
+	lines = append(lines, shortestEdit(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+
+	for _, text := range before[len(before)-suffix:] {
+		lines = append(lines, diffLine{kind: ' ', text: text})
+	}
+
+	return lines
+}
+
+// shortestEdit finds the shortest edit script turning before into after with Myers' algorithm.
+// If it takes more than maxDiffEdits edits, every line is removed and added instead.
+func shortestEdit(before, after []string) []diffLine {
+	n, m := len(before), len(after)
+	limit := min(n+m, maxDiffEdits)
+
+	// frontier[k+offset] is the furthest index into before reached on diagonal k. The frontier
+	// before each round d is kept for diagonals -d-1 to d+1 to walk the edits back afterwards
+	offset := limit + 1
+	frontier := make([]int, 2*limit+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), frontier[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && frontier[offset+k-1] < frontier[offset+k+1]) {
+				x = frontier[offset+k+1]
+			} else {
+				x = frontier[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && before[x] == after[y] {
+				x++
+				y++
+			}
+			frontier[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		lines := make([]diffLine, 0, n+m)
+		for _, text := range before {
+			lines = append(lines, diffLine{kind: '-', text: text})
+		}
+		for _, text := range after {
+			lines = append(lines, diffLine{kind: '+', text: text})
+		}
+
+		return lines
+	}
+
+	// Walk back from the end, collecting the script in reverse
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		previous := trace[d]
+		at := func(k int) int { return previous[k+d+1] }
+
+		k := x - y
+		previousK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			previousK = k + 1
+		}
+		previousX := at(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			reversed = append(reversed, diffLine{kind: ' ', text: before[x]})
+		}
+
+		if d > 0 {
+			if x == previousX {
+				y--
+				reversed = append(reversed, diffLine{kind: '+', text: after[y]})
+			} else {
+				x--
+				reversed = append(reversed, diffLine{kind: '-', text: before[x]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for idx, line := range reversed {
+		lines[len(reversed)-1-idx] = line
+	}
+
+	return lines
+}
@@ -0,0 +1,133 @@
+package doyoucompute
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		context  int
+		expected string
+	}{
+		{
+			name:     "Passing-Identical",
+			before:   "one\ntwo\n",
+			after:    "one\ntwo\n",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:    "Passing-OneLineChanged",
+			before:  "a\nb\nc\nd\ne\n",
+			after:   "a\nb\nC\nd\ne\n",
+			context: 1,
+			expected: `--- before
++++ after
+@@ -2,3 +2,3 @@
+ b
+-c
++C
+ d
+`,
+		},
+		{
+			name:    "Passing-SeparateHunks",
+			before:  "a\nb\nc\nd\ne\nf\ng\nh\n",
+			after:   "A\nb\nc\nd\ne\nf\ng\nH\n",
+			context: 1,
+			expected: `--- before
++++ after
+@@ -1,2 +1,2 @@
+-a
++A
+ b
+@@ -7,2 +7,2 @@
+ g
+-h
++H
+`,
+		},
+		{
+			name:    "Passing-CloseChangesShareAHunk",
+			before:  "a\nb\nc\nd\n",
+			after:   "A\nb\nc\nD\n",
+			context: 1,
+			expected: `--- before
++++ after
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+-d
++D
+`,
+		},
+		{
+			name:    "Passing-NoContext",
+			before:  "x\ny\n",
+			after:   "y\nx\n",
+			context: 0,
+			expected: `--- before
++++ after
+@@ -1 +0,0 @@
+-x
+@@ -2,0 +2 @@
++x
+`,
+		},
+		{
+			name:    "Passing-EmptyBefore",
+			before:  "",
+			after:   "new\n",
+			context: 3,
+			expected: `--- before
++++ after
+@@ -0,0 +1 @@
++new
+`,
+		},
+		{
+			name:    "Passing-NoNewlineAtEnd",
+			before:  "a\nb\n",
+			after:   "a\nb",
+			context: 3,
+			expected: `--- before
++++ after
+@@ -1,2 +1,2 @@
+ a
+-b
++b
+\ No newline at end of file
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := UnifiedDiff(tc.before, tc.after, "before", "after", tc.context)
+			if diff != tc.expected {
+				t.Errorf("Expected diff\n%s\ngot\n%s", tc.expected, diff)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffLargeRewrite(t *testing.T) {
+	// More edits than the search allows fall back to replacing every differing line
+	before := strings.Repeat("old\n", maxDiffEdits)
+	after := strings.Repeat("new\n", maxDiffEdits)
+
+	diff := UnifiedDiff(before, after, "before", "after", 3)
+	if removed := strings.Count(diff, "\n-old"); removed != maxDiffEdits {
+		t.Errorf("Expected %d removed lines, got %d", maxDiffEdits, removed)
+	}
+
+	if added := strings.Count(diff, "\n+new"); added != maxDiffEdits {
+		t.Errorf("Expected %d added lines, got %d", maxDiffEdits, added)
+	}
+}
@@ -0,0 +1,377 @@
+package doyoucompute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrContainerImageNotFound is the error of commands that could not run because their
+// container image does not exist or could not be pulled.
+var ErrContainerImageNotFound = errors.New("container image not found")
+
+// ErrContainerImageRequired is the error of docker runners created without a container image.
+var ErrContainerImageRequired = errors.New("container image is required")
+
+// ErrDockerDaemon is the error of commands that could not run because docker itself failed,
+// such as when the daemon is not running.
+var ErrDockerDaemon = errors.New("docker daemon error")
+
+// exitCodeDockerRun is the exit code docker run uses when it fails to start the container,
+// as opposed to the exit code of the command inside it.
+const exitCodeDockerRun = 125
+
+// dockerStderrLimit caps how much of docker's stderr is kept to classify its errors when
+// output capture is disabled.
+const dockerStderrLimit = 64 * 1024
+
+var (
+	imageNotFoundMessages = []string{"pull access denied", "manifest unknown", "No such image", "not found"}
+	daemonMessages        = []string{"Cannot connect to the Docker daemon", "error during connect", "Is the docker daemon running"}
+)
+
+// DockerConfig holds the settings of the containers a DockerRunner runs commands in.
+type DockerConfig struct {
+	// Image is the image every command runs in
+	Image string
+
+	// Mounts are passed to docker run as volumes, in its host:container[:options] form
+	Mounts []string
+
+	// EnvPassthrough names host environment variables to pass into the container
+	EnvPassthrough []string
+
+	// WorkingDir is the directory commands run in inside the container (empty means the
+	// image's). Relative working directories set on individual commands are resolved against it
+	WorkingDir string
+
+	// Binary is the docker executable (empty means "docker")
+	Binary string
+}
+
+// DockerRunner implements the Runner interface by running every command in a new container,
+// so documents run the same way regardless of what is installed on the host.
+//
+// Commands go through the same checks as with a TaskRunner before they are wrapped in docker
+// run, except for their working directory and required tools, which refer to the container.
+// Environment variables required by a command are passed through from the host, and injected
+// values are handed to docker through its environment rather than its arguments, so they do
+// not show up in the process list.
+type DockerRunner struct {
+	docker DockerConfig
+	host   TaskRunner
+}
+
+// NewDockerRunner creates a DockerRunner that runs commands in containers configured by docker,
+// applying the timeout, security, and output settings of config. Returns an error if
+// docker has no image or the regular expressions of config are invalid.
+func NewDockerRunner(docker DockerConfig, config ExecutionConfig) (DockerRunner, error) {
+	if strings.TrimSpace(docker.Image) == "" {
+		return DockerRunner{}, ErrContainerImageRequired
+	}
+
+	host, err := NewTaskRunner(config)
+	if err != nil {
+		return DockerRunner{}, err
+	}
+
+	return DockerRunner{
+		docker: docker,
+		host:   host,
+	}, nil
+}
+
+// WithCommandFactory returns a copy of the runner that creates the docker command with factory,
+// such as to inspect the docker invocation without running it.
+func (d DockerRunner) WithCommandFactory(factory CommandFactory) DockerRunner {
+	d.host = d.host.WithCommandFactory(factory)
+
+	return d
+}
+
+// Buffered returns a copy of the runner with BufferOutput enabled.
+func (d DockerRunner) Buffered() Runner {
+	d.host.config.BufferOutput = true
+
+	return d
+}
+
+// executionConfig returns the config the runner was created with.
+func (d DockerRunner) executionConfig() ExecutionConfig {
+	return d.host.config
+}
+
+// WithOutput returns a copy of the runner with Stdout and Stderr set.
+func (d DockerRunner) WithOutput(stdout, stderr io.Writer) Runner {
+	d.host.config.Stdout, d.host.config.Stderr = stdout, stderr
+
+	return d
+}
+
+// DryRun returns a copy of the runner with DryRun enabled.
+func (d DockerRunner) DryRun() Runner {
+	d.host.config.DryRun = true
+
+	return d
+}
+
+// Confirming returns a copy of the runner with Confirm set to mode.
+func (d DockerRunner) Confirming(mode ConfirmMode) Runner {
+	d.host.config.Confirm = mode
+
+	return d
+}
+
+// Run executes a command plan in a container. It is equivalent to RunContext with
+// context.Background().
+func (d DockerRunner) Run(plan CommandPlan) TaskResult {
+	return d.RunContext(context.Background(), plan)
+}
+
+// RunContext executes a command plan in a container, stopping the docker client if ctx is
+// cancelled or the command's timeout passes.
+func (d DockerRunner) RunContext(ctx context.Context, plan CommandPlan) TaskResult {
+	config := d.host.config
+	injected := d.host.planEnv(plan)
+	result := TaskResult{
+		SectionName: plan.Context.Name,
+		Command:     newRedactor(config, plan.Environment, injected).redact(strings.Join(plan.Args, " ")),
+		ExitCode:    ExitCodeNotStarted,
+	}
+
+	// Containers run Linux unless a platform is configured
+	platform := config.Platform
+	if platform == "" {
+		platform = "linux"
+	}
+
+	if !plan.RunsOn(platform) {
+		result.Status = SKIPPED
+		result.Error = fmt.Errorf("%w: runs on %s, current platform is %s", ErrPlatformNotSupported, strings.Join(plan.Platforms, ", "), platform)
+		return result
+	}
+
+	if err := checkConditions(plan, injected); err != nil {
+		result.Status = SKIPPED
+		result.Error = err
+		return result
+	}
+
+	// Working directories are paths inside the container, so they are not checked on the host
+	validationErr := validateCommandPlan(withoutWorkingDirs(plan), config, d.host.rules)
+	auditValidation(config, plan, result.Command, validationErr)
+	if validationErr != nil {
+		result.Error = fmt.Errorf("security validation failed: %w", validationErr)
+		result.Status = FAILED
+		return result
+	}
+
+	if err := validateEnvironment(plan.Environment, injected, true); err != nil {
+		result.Error = fmt.Errorf("environment validation failed: %w", err)
+		result.Status = FAILED
+		recordAudit(config, plan, result.Command, AuditRejected, "Environment", result.Error)
+		return result
+	}
+
+	if len(plan.Args) == 0 {
+		result.Error = errors.New("no command specified")
+		result.Status = FAILED
+		return result
+	}
+
+	args, err := d.dockerArgs(plan, injected)
+	if err != nil {
+		result.Error = err
+		result.Status = FAILED
+		return result
+	}
+
+	binary := d.docker.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+
+	// The command is confirmed as written rather than as the docker invocation running it
+	if !config.DryRun {
+		if confirmed, err := confirmCommand(plan, result.Command, config); err != nil {
+			result.Error = fmt.Errorf("confirmation failed: %w", err)
+			result.Status = FAILED
+			return result
+		} else if !confirmed {
+			logf(config.Logger, "[Section: %s] - Command declined, not running: '%s'", plan.Context.Name, result.Command)
+			result.Error = ErrCommandDeclined
+			result.Status = SKIPPED
+			recordAudit(config, plan, result.Command, AuditDeclined, "Confirm", result.Error)
+			return result
+		}
+	}
+
+	// The plan has passed every check, so the host runner only has to run docker itself. It
+	// always captures stderr, which is needed to tell docker's own errors apart
+	runner := d.host
+	runner.rules = commandRules{}
+	runner.config = ExecutionConfig{
+		Timeout:        config.Timeout,
+		CaptureOutput:  true,
+		MaxOutputBytes: config.MaxOutputBytes,
+		DryRun:         config.DryRun,
+		BufferOutput:   config.BufferOutput,
+		RedactEnvVars:  config.RedactEnvVars,
+		RedactPatterns: config.RedactPatterns,
+		Logger:         config.Logger,
+
+		SuppressPassthrough: config.SuppressPassthrough,
+		Stdout:              config.Stdout,
+		Stderr:              config.Stderr,
+	}
+	if config.CaptureOutput {
+		runner.config.OutputLimit = config.OutputLimit
+	} else {
+		runner.config.MaxOutputBytes = dockerStderrLimit
+	}
+	if handler := config.OutputHandler; handler != nil {
+		runner.config.OutputHandler = func(_ CommandPlan, line string, isStderr bool) {
+			handler(plan, line, isStderr)
+		}
+	}
+
+	dockerResult := runner.RunContext(ctx, CommandPlan{
+		Shell:     binary,
+		Args:      append([]string{binary}, args...),
+		Context:   plan.Context,
+		Path:      plan.Path,
+		EnvValues: injected,
+		Timeout:   plan.Timeout,
+	})
+
+	result.Status = dockerResult.Status
+	result.Error = dockerResult.Error
+	result.ExitCode = dockerResult.ExitCode
+	result.Duration = dockerResult.Duration
+	result.DryRun = dockerResult.DryRun
+
+	if config.CaptureOutput {
+		result.Stdout = dockerResult.Stdout
+		result.Stderr = dockerResult.Stderr
+		result.OutputTruncated = dockerResult.OutputTruncated
+	}
+
+	if result.Status == FAILED {
+		result.Error = dockerError(result.Error, result.ExitCode, dockerResult.Stderr)
+	} else if result.Status == TIMEOUT {
+		recordAudit(config, plan, result.Command, AuditTimedOut, "Timeout", result.Error)
+	}
+
+	return result
+}
+
+// withoutWorkingDirs returns a copy of the plan without the working directories of the
+// command and its stages.
+func withoutWorkingDirs(plan CommandPlan) CommandPlan {
+	plan.WorkingDir = ""
+
+	if len(plan.Stages) > 0 {
+		stages := make([]CommandPlan, len(plan.Stages))
+		for idx, stage := range plan.Stages {
+			stage.WorkingDir = ""
+			stages[idx] = stage
+		}
+		plan.Stages = stages
+	}
+
+	return plan
+}
+
+// dockerArgs builds the arguments of the docker run invocation for a plan. Environment variables
+// are named without values, so docker takes them from its own environment.
+func (d DockerRunner) dockerArgs(plan CommandPlan, injected map[string]string) ([]string, error) {
+	args := []string{"run", "--rm"}
+
+	workingDir := plan.WorkingDir
+	if workingDir != "" && !path.IsAbs(workingDir) && d.docker.WorkingDir != "" {
+		workingDir = path.Join(d.docker.WorkingDir, workingDir)
+	}
+	if workingDir == "" {
+		workingDir = d.docker.WorkingDir
+	}
+	if workingDir != "" {
+		args = append(args, "-w", workingDir)
+	}
+
+	for _, mount := range d.docker.Mounts {
+		args = append(args, "-v", mount)
+	}
+
+	seen := map[string]bool{}
+	for _, names := range [][]string{d.docker.EnvPassthrough, plan.Environment, envNames(injected)} {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				args = append(args, "-e", name)
+			}
+		}
+	}
+
+	args = append(args, d.docker.Image)
+
+	if len(plan.Stages) > 0 {
+		// Pipelines are handed to a shell in the container, with each stage's working directory
+		// applied in a subshell
+		stages := make([]CommandPlan, len(plan.Stages))
+		for idx, stage := range plan.Stages {
+			stage.EnvValues = nil
+			if stage.WorkingDir != "" && !path.IsAbs(stage.WorkingDir) && workingDir != "" {
+				stage.WorkingDir = path.Join(workingDir, stage.WorkingDir)
+			}
+			stages[idx] = stage
+		}
+
+		line, err := ShellScript{}.commandLine(CommandPlan{Stages: stages})
+		if err != nil {
+			return nil, err
+		}
+
+		return append(args, "sh", "-c", line), nil
+	}
+
+	if flag, ok := shellCommandFlags[plan.Shell]; ok {
+		return append(args, plan.Shell, flag, strings.Join(plan.Args, " ")), nil
+	}
+
+	if invocation, ok := multiLineScript(plan.Shell, plan.Args); ok {
+		return append(args, invocation...), nil
+	}
+
+	return append(args, plan.Args...), nil
+}
+
+// dockerError tells apart the failures of docker itself, which exits with exitCodeDockerRun
+// when it cannot start the container, from those of the command it ran. Output of commands
+// that ran in the container is never classified, even when it reads like a docker error.
+func dockerError(err error, exitCode int, stderr string) error {
+	containsAny := func(messages []string) bool {
+		for _, message := range messages {
+			if strings.Contains(stderr, message) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch {
+	case exitCode != exitCodeDockerRun && exitCode != ExitCodeNotStarted:
+		return err
+	case containsAny(daemonMessages):
+		return fmt.Errorf("%w: %w", ErrDockerDaemon, err)
+	case exitCode == ExitCodeNotStarted:
+		return err
+	case containsAny(imageNotFoundMessages):
+		return fmt.Errorf("%w: %w", ErrContainerImageNotFound, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrDockerDaemon, err)
+	}
+}
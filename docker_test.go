@@ -0,0 +1,271 @@
+package doyoucompute
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDockerRunner_Run(t *testing.T) {
+	t.Setenv("TOKEN", "secret")
+
+	tests := []struct {
+		name           string
+		docker         DockerConfig
+		plan           CommandPlan
+		fake           string
+		expectedArgs   []string
+		expectedEnv    []string
+		expectedStatus TaskStatus
+		expectedErr    error
+		errorMessage   string
+	}{
+		{
+			name: "Shell command",
+			docker: DockerConfig{
+				Image:          "golang:1.23",
+				Mounts:         []string{"/src:/src"},
+				EnvPassthrough: []string{"HOME"},
+				WorkingDir:     "/src",
+			},
+			plan: CommandPlan{
+				Shell:       "bash",
+				Args:        []string{"go", "test", "./..."},
+				WorkingDir:  "pkg",
+				Environment: []string{"TOKEN"},
+				EnvValues:   map[string]string{"MODE": "ci"},
+			},
+			expectedArgs:   []string{"docker", "run", "--rm", "-w", "/src/pkg", "-v", "/src:/src", "-e", "HOME", "-e", "TOKEN", "-e", "MODE", "golang:1.23", "bash", "-c", "go test ./..."},
+			expectedEnv:    []string{"MODE=ci"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Interpreter receives arguments directly",
+			docker:         DockerConfig{Image: "python:3"},
+			plan:           CommandPlan{Shell: "python3", Args: []string{"python3", "-c", "print(1)"}},
+			expectedArgs:   []string{"docker", "run", "--rm", "python:3", "python3", "-c", "print(1)"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Multi-line script runs inline",
+			docker:         DockerConfig{Image: "python:3"},
+			plan:           CommandPlan{Shell: "python3", Args: []string{"x = 1\nprint(x + 1)"}},
+			expectedArgs:   []string{"docker", "run", "--rm", "python:3", "python3", "-c", "x = 1\nprint(x + 1)"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:   "Pipeline",
+			docker: DockerConfig{Image: "alpine", Binary: "podman"},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"curl", "-s", "example.com", "|", "jq", ".name"},
+				Stages: []CommandPlan{
+					{Shell: "bash", Args: []string{"curl", "-s", "example.com"}},
+					{Shell: "bash", Args: []string{"jq", ".name"}, WorkingDir: "/data"},
+				},
+			},
+			expectedArgs:   []string{"podman", "run", "--rm", "alpine", "sh", "-c", "curl -s example.com | (cd /data && jq .name)"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Security validation happens before wrapping",
+			docker:         DockerConfig{Image: "alpine"},
+			plan:           CommandPlan{Shell: "bash", Args: []string{"sudo", "reboot"}},
+			expectedStatus: FAILED,
+			errorMessage:   "security validation failed: dangerous command blocked: sudo",
+		},
+		{
+			name:           "Image not found",
+			docker:         DockerConfig{Image: "nope"},
+			plan:           CommandPlan{Shell: "bash", Args: []string{"echo", "hi"}},
+			fake:           "echo \"docker: Error response from daemon: pull access denied for nope, repository does not exist\" >&2; exit 125",
+			expectedArgs:   []string{"docker", "run", "--rm", "nope", "bash", "-c", "echo hi"},
+			expectedStatus: FAILED,
+			expectedErr:    ErrContainerImageNotFound,
+		},
+		{
+			name:           "Daemon not running",
+			docker:         DockerConfig{Image: "alpine"},
+			plan:           CommandPlan{Shell: "bash", Args: []string{"echo", "hi"}},
+			fake:           "echo \"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?\" >&2; exit 125",
+			expectedArgs:   []string{"docker", "run", "--rm", "alpine", "bash", "-c", "echo hi"},
+			expectedStatus: FAILED,
+			expectedErr:    ErrDockerDaemon,
+		},
+		{
+			name:           "Command output that reads like a docker error",
+			docker:         DockerConfig{Image: "alpine"},
+			plan:           CommandPlan{Shell: "bash", Args: []string{"curl", "http://localhost"}},
+			fake:           "echo \"error during connect: not found\" >&2; exit 1",
+			expectedArgs:   []string{"docker", "run", "--rm", "alpine", "bash", "-c", "curl http://localhost"},
+			expectedStatus: FAILED,
+			errorMessage:   "exit status 1",
+		},
+		{
+			name:           "Command failure",
+			docker:         DockerConfig{Image: "alpine"},
+			plan:           CommandPlan{Shell: "bash", Args: []string{"false"}},
+			fake:           "exit 2",
+			expectedArgs:   []string{"docker", "run", "--rm", "alpine", "bash", "-c", "false"},
+			expectedStatus: FAILED,
+			errorMessage:   "exit status 2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var invoked []string
+			var cmd *exec.Cmd
+			factory := func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				invoked = append([]string{name}, args...)
+
+				fake := tc.fake
+				if fake == "" {
+					fake = "true"
+				}
+				cmd = exec.CommandContext(ctx, "sh", "-c", fake)
+
+				return cmd
+			}
+
+			runner, err := NewDockerRunner(tc.docker, DefaultSecureConfig())
+			if err != nil {
+				t.Fatalf("Failed to create docker runner: %v", err)
+			}
+
+			result := runner.WithCommandFactory(factory).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Fatalf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if !reflect.DeepEqual(invoked, tc.expectedArgs) {
+				t.Errorf("Expected docker invocation %q, got %q", tc.expectedArgs, invoked)
+			}
+
+			if result.Command != strings.Join(tc.plan.Args, " ") {
+				t.Errorf("Expected command %q, got %q", strings.Join(tc.plan.Args, " "), result.Command)
+			}
+
+			for _, env := range tc.expectedEnv {
+				if !slices.Contains(cmd.Env, env) {
+					t.Errorf("Expected docker environment to contain %s", env)
+				}
+			}
+
+			if slices.Contains(invoked, "MODE=ci") {
+				t.Errorf("Expected injected values to stay out of the docker arguments")
+			}
+
+			if tc.expectedErr != nil && !errors.Is(result.Error, tc.expectedErr) {
+				t.Errorf("Expected error wrapping %v, got %v", tc.expectedErr, result.Error)
+			}
+
+			if tc.expectedErr == nil && (errors.Is(result.Error, ErrContainerImageNotFound) || errors.Is(result.Error, ErrDockerDaemon)) {
+				t.Errorf("Expected a command error, got %v", result.Error)
+			}
+
+			if tc.errorMessage != "" && (result.Error == nil || result.Error.Error() != tc.errorMessage) {
+				t.Errorf("Expected error %q, got %v", tc.errorMessage, result.Error)
+			}
+		})
+	}
+}
+
+func TestNewDockerRunner(t *testing.T) {
+	tests := []struct {
+		name   string
+		docker DockerConfig
+		errMsg string
+	}{
+		{
+			name:   "Passing",
+			docker: DockerConfig{Image: "alpine"},
+		},
+		{
+			name:   "Failing-NoImage",
+			docker: DockerConfig{},
+			errMsg: ErrContainerImageRequired.Error(),
+		},
+		{
+			name:   "Failing-BlankImage",
+			docker: DockerConfig{Image: "  "},
+			errMsg: ErrContainerImageRequired.Error(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDockerRunner(tc.docker, DefaultSecureConfig())
+
+			checkErrors(tc.errMsg, err, t)
+		})
+	}
+}
+
+func TestDockerRunner_Interfaces(t *testing.T) {
+	runner, err := NewDockerRunner(DockerConfig{Image: "alpine"}, DefaultSecureConfig())
+	if err != nil {
+		t.Fatalf("Failed to create docker runner: %v", err)
+	}
+
+	var _ RunnerContext = runner
+	var _ BufferingRunner = runner
+	var _ DryRunner = runner
+
+	called := false
+	factory := func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		called = true
+
+		return exec.CommandContext(ctx, "true")
+	}
+
+	result := runner.WithCommandFactory(factory).DryRun().Run(CommandPlan{Shell: "bash", Args: []string{"echo", "hi"}})
+
+	if result.Status != COMPLETED || !result.DryRun {
+		t.Errorf("Expected a completed dry run, got %v (dry run %v)", result.Status, result.DryRun)
+	}
+
+	if called {
+		t.Errorf("Expected docker not to run during a dry run")
+	}
+}
+
+func TestDockerRunner_Confirm(t *testing.T) {
+	confirmer := &scriptedConfirmer{answers: []bool{false}}
+	config := DefaultSecureConfig()
+	config.Logger = nil
+	config.Confirmer = confirmer
+	config.RedactPatterns = []*regexp.Regexp{regexp.MustCompile("ghp_[A-Za-z0-9]+")}
+
+	runner, err := NewDockerRunner(DockerConfig{Image: "alpine"}, config)
+	if err != nil {
+		t.Fatalf("Failed to create docker runner: %v", err)
+	}
+
+	called := false
+	factory := func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		called = true
+
+		return exec.CommandContext(ctx, "true")
+	}
+
+	result := runner.WithCommandFactory(factory).Confirming(ConfirmBeforeRun).Run(CommandPlan{Shell: "bash", Args: []string{"echo", "ghp_abc123"}})
+
+	if result.Status != SKIPPED || !errors.Is(result.Error, ErrCommandDeclined) {
+		t.Errorf("Expected a declined command, got %v (error: %v)", result.Status, result.Error)
+	}
+
+	if !reflect.DeepEqual(confirmer.asked, []string{"echo ***"}) {
+		t.Errorf("Expected to be asked about the redacted command as written, got %v", confirmer.asked)
+	}
+
+	if called {
+		t.Errorf("Expected docker not to run for a declined command")
+	}
+}
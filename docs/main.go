@@ -42,5 +42,7 @@ func main() {
 	}
 	app.Register(prTemplate)
 
-	app.Run(os.Args)
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -5,16 +5,20 @@ import "github.com/MoonMoon1919/doyoucompute"
 func BugReport() (doyoucompute.Document, error) {
 	document, err := doyoucompute.NewDocument("Bug Report")
 	if err != nil {
-		return doyoucompute.Document{}, nil
+		return doyoucompute.Document{}, err
 	}
 
-	document.AddFrontmatter(*doyoucompute.NewFrontmatter(map[string]interface{}{
-		"name":      "Bug report",
-		"about":     "Report a bug",
-		"title":     "",
-		"labels":    "",
-		"assignees": "",
-	}))
+	frontmatter := doyoucompute.Frontmatter{}
+	frontmatter.
+		SetString("name", "Bug report").
+		SetString("about", "Report a bug").
+		SetString("title", "").
+		SetString("labels", "").
+		SetString("assignees", "")
+
+	if err := document.AddFrontmatter(frontmatter, doyoucompute.GitHubIssueTemplateSchema()); err != nil {
+		return doyoucompute.Document{}, err
+	}
 
 	expectedBehavior := document.CreateSection("Expected behavior")
 	expectedBehavior.WriteComment("What should happen?")
@@ -4,8 +4,8 @@ import (
 	"github.com/MoonMoon1919/doyoucompute"
 )
 
-func gettingStarted() doyoucompute.Section {
-	gettingStartedSection := doyoucompute.NewSection("Getting started")
+func gettingStarted() *doyoucompute.Section {
+	gettingStartedSection := doyoucompute.MustNewSection("Getting started")
 
 	// Get familiar
 	getFamiliar := gettingStartedSection.CreateSection("Get familiar with the project")
@@ -44,11 +44,11 @@ func gettingStarted() doyoucompute.Section {
 	suggestionsList.Append("Propose improvements")
 	suggestionsList.Append("Ask questions about implementation details")
 
-	return gettingStartedSection
+	return &gettingStartedSection
 }
 
-func codeContributions() doyoucompute.Section {
-	codeContributions := doyoucompute.NewSection("Code contributions")
+func codeContributions() *doyoucompute.Section {
+	codeContributions := doyoucompute.MustNewSection("Code contributions")
 
 	// Guidelines
 	setupSection := codeContributions.CreateSection("Setting Up Your Development Environment")
@@ -108,11 +108,11 @@ func codeContributions() doyoucompute.Section {
 	submissionSteps.Append("Reference any relevant issues using #issue-number")
 	submissionSteps.Append("Wait for review and address any feedback")
 
-	return codeContributions
+	return &codeContributions
 }
 
-func reportingBugs() doyoucompute.Section {
-	bugsSections := doyoucompute.NewSection("Reporting bugs")
+func reportingBugs() *doyoucompute.Section {
+	bugsSections := doyoucompute.MustNewSection("Reporting bugs")
 
 	checkingSection := bugsSections.CreateSection("Checking for Existing Reports")
 	checkingSection.WriteParagraph().
@@ -126,11 +126,11 @@ func reportingBugs() doyoucompute.Section {
 	creatingSection.WriteParagraph().
 		Text("If you can't find an existing report, create a new issue and fill out the bug report form.")
 
-	return bugsSections
+	return &bugsSections
 }
 
-func writingDocs() doyoucompute.Section {
-	docsSection := doyoucompute.NewSection("Writing documentation")
+func writingDocs() *doyoucompute.Section {
+	docsSection := doyoucompute.MustNewSection("Writing documentation")
 
 	// Review existing documentation
 	docsSection.WriteParagraph().
@@ -148,7 +148,7 @@ func writingDocs() doyoucompute.Section {
 		Link("docs folder", "./docs").
 		Text("since we're using doyoucompute to generate it's own documents")
 
-	return docsSection
+	return &docsSection
 }
 
 func Contributing() (doyoucompute.Document, error) {
@@ -19,5 +19,11 @@ func PullRequest() (doyoucompute.Document, error) {
 	testing := document.CreateSection("How I tested")
 	testing.WriteComment("How did you test these changes?")
 
+	checklist := document.CreateSection("Checklist")
+	checklistItems := checklist.CreateList(doyoucompute.TASK)
+	checklistItems.AppendTask("I have added or updated tests for my changes", false)
+	checklistItems.AppendTask("I have updated the documentation, if needed", false)
+	checklistItems.AppendTask("I have regenerated the docs and 'make validate' passes", false)
+
 	return document, nil
 }
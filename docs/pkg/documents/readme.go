@@ -6,24 +6,65 @@ import (
 	"github.com/MoonMoon1919/doyoucompute"
 )
 
-func recommendations() doyoucompute.Section {
-	recommendationsSection := doyoucompute.NewSection("Recommendations")
+func recommendations() *doyoucompute.Section {
+	recommendationsSection := doyoucompute.MustNewSection("Recommendations")
 	practicesList := recommendationsSection.CreateList(doyoucompute.BULLET)
 	practicesList.Append("🔄 Run 'compare' in CI to ensure docs stay current")
 	practicesList.Append("🧪 Use 'plan' to preview commands before execution")
 	practicesList.Append("📂 Organize related commands into logical sections")
 
-	return recommendationsSection
+	return &recommendationsSection
 }
 
-func environmentVariables() (doyoucompute.Section, error) {
-	envSection := doyoucompute.NewSection("Environment Variables")
+func upgrading() *doyoucompute.Section {
+	upgradingSection := doyoucompute.MustNewSection("Upgrading")
+	upgradingSection.WriteIntro().
+		Text("Sections, paragraphs, lists, and tables are now held by pointer, so content written after adding them is kept.").
+		Text("Code written against earlier versions needs these changes:")
+
+	changesList := upgradingSection.CreateList(doyoucompute.BULLET)
+	changesList.AppendParagraph().
+		Code("Document.AddSection").
+		Text("and").
+		Code("Section.AddSection").
+		Text("take a").
+		Code("*Section").
+		Text("instead of a").
+		Code("Section").
+		Text("value")
+	changesList.AppendParagraph().
+		Code("Section.AddParagraph").
+		Text("takes the").
+		Code("*Paragraph").
+		Text("returned by").
+		Code("NewParagraph")
+	changesList.AppendParagraph().
+		Code("ReplaceSection").
+		Text("and").
+		Code("InsertSectionAt").
+		Text("take a").
+		Code("*Section")
+	changesList.AppendParagraph().
+		Text("Type assertions on children added with").
+		Code("AddSection").
+		Text("or").
+		Code("AddList").
+		Text("should assert").
+		Code("*Section").
+		Text("and").
+		Code("*List")
+
+	return &upgradingSection
+}
+
+func environmentVariables() (*doyoucompute.Section, error) {
+	envSection := doyoucompute.MustNewSection("Environment Variables")
 	envSection.WriteIntro().
 		Text("Commands can specify required environment variables:")
 
 	sample, err := os.ReadFile("./docs/pkg/documents/samples/envvars.go")
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	envSection.WriteCodeBlock("go", []string{string(sample)}, doyoucompute.Static)
@@ -31,50 +72,50 @@ func environmentVariables() (doyoucompute.Section, error) {
 	envSection.WriteParagraph().
 		Text("The command will fail to run if the required environment variables are not set and report which are missing.")
 
-	return envSection, nil
+	return &envSection, nil
 }
 
-func configurationSecurity() (doyoucompute.Section, error) {
-	configSection := doyoucompute.NewSection("Configuration")
+func configurationSecurity() (*doyoucompute.Section, error) {
+	configSection := doyoucompute.MustNewSection("Configuration")
 
 	configSection.WriteIntro().
 		Text("Customize execution behavior with security configurations:")
 
 	sample, err := os.ReadFile("./docs/pkg/documents/samples/securityconfig.go")
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	configSection.WriteCodeBlock("go", []string{string(sample)}, doyoucompute.Static)
 
-	return configSection, nil
+	return &configSection, nil
 }
 
-func securitySection() doyoucompute.Section {
-	securitySection := doyoucompute.NewSection("Security Features")
+func securitySection() *doyoucompute.Section {
+	securitySection := doyoucompute.MustNewSection("Security Features")
 
 	securitySection.WriteIntro().
 		Text("DOYOUCOMPUTE includes built-in security features to prevent dangerous command execution:")
 
 	securityList := securitySection.CreateList(doyoucompute.BULLET)
-	securityList.Append("🛡️ Dangerous command blocking (rm -rf, sudo, etc.)")
+	securityList.Append("🛡️ Configurable dangerous command blocking (rm -rf, sudo, etc.)")
 	securityList.Append("⏱️ Configurable execution timeouts")
 	securityList.Append("🐚 Shell allow-listing")
 	securityList.Append("🔒 Command validation and sanitization")
 	securityList.Append("🌍 Environment variable validation")
 
-	return securitySection
+	return &securitySection
 }
 
-func cliSection() (doyoucompute.Section, error) {
-	cliSection := doyoucompute.NewSection("CLI Usage")
+func cliSection() (*doyoucompute.Section, error) {
+	cliSection := doyoucompute.MustNewSection("CLI Usage")
 
 	cliSection.WriteIntro().
 		Text("Create a CLI wrapper for your documents:")
 
 	sample, err := os.ReadFile("./docs/pkg/documents/samples/app.go")
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	cliSection.WriteCodeBlock("go", []string{string(sample)}, doyoucompute.Static)
@@ -102,51 +143,56 @@ func cliSection() (doyoucompute.Section, error) {
 		"Show execution plan without running",
 		"./cli plan --doc-name=setup --section=\"Database Setup\"",
 	)
+	commandsTable.AddRow(
+		"pin-remotes",
+		"Print digests for unpinned remote content",
+		"./cli pin-remotes --doc-name=readme",
+	)
 	commandsTable.AddRow(
 		"list",
 		"List all available documents",
 		"./cli list",
 	)
 
-	return cliSection, nil
+	return &cliSection, nil
 }
 
-func basicUsageSection() (doyoucompute.Section, error) {
-	basicUsageSection := doyoucompute.NewSection("Basic Usage")
+func basicUsageSection() (*doyoucompute.Section, error) {
+	basicUsageSection := doyoucompute.MustNewSection("Basic Usage")
 	basicUsageSection.WriteIntro().
 		Text("Create a simple document with executable commands:")
 
 	sample, err := os.ReadFile("./docs/pkg/documents/samples/basics.go")
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	basicUsageSection.WriteCodeBlock("go", []string{string(sample)}, doyoucompute.Static)
 
-	return basicUsageSection, nil
+	return &basicUsageSection, nil
 }
 
-func quickstartSection() (doyoucompute.Section, error) {
-	quickStartSection := doyoucompute.NewSection("Quick Start")
+func quickstartSection() (*doyoucompute.Section, error) {
+	quickStartSection := doyoucompute.MustNewSection("Quick Start")
 
 	installationSection := quickStartSection.CreateSection("Installation")
 	installationSection.WriteCodeBlock("bash", []string{"go get github.com/MoonMoon1919/doyoucompute"}, doyoucompute.Static)
 
 	basicUsage, err := basicUsageSection()
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	quickStartSection.AddSection(basicUsage)
 
 	cliSection, err := cliSection()
 	if err != nil {
-		return doyoucompute.Section{}, err
+		return nil, err
 	}
 
 	quickStartSection.AddSection(cliSection)
 
-	return quickStartSection, nil
+	return &quickStartSection, nil
 }
 
 func Readme() (doyoucompute.Document, error) {
@@ -198,6 +244,9 @@ func Readme() (doyoucompute.Document, error) {
 	// Recs
 	document.AddSection(recommendations())
 
+	// Upgrading
+	document.AddSection(upgrading())
+
 	// Contributing
 	contributing := document.CreateSection("Contributing")
 	contributing.WriteIntro().
@@ -3,7 +3,7 @@ package samples
 import "github.com/MoonMoon1919/doyoucompute"
 
 func envvars() {
-	setup := doyoucompute.NewSection("Setup")
+	setup := doyoucompute.MustNewSection("Setup")
 
 	setup.WriteExecutable(
 		"bash",
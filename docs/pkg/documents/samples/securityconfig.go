@@ -11,6 +11,10 @@ func securityconfig() {
 	// Default secure configuration
 	config := doyoucompute.DefaultSecureConfig()
 
+	// adjust which commands are blocked
+	config.BlockCommand("kubectl delete", "terraform destroy")
+	config.AllowCommand("dd")
+
 	// or, a custom configuration!
 	config = doyoucompute.ExecutionConfig{
 		Timeout:                30 * time.Second,
@@ -18,8 +22,13 @@ func securityconfig() {
 		BlockDangerousCommands: true,
 	}
 
+	runner, err := doyoucompute.NewTaskRunner(config)
+	if err != nil {
+		panic(err)
+	}
+
 	service, err := doyoucompute.DefaultService(
-		doyoucompute.WithTaskRunner(doyoucompute.NewTaskRunner(config)),
+		doyoucompute.WithTaskRunner(runner),
 	)
 	if err != nil {
 		panic(err)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"os"
 
 	"github.com/MoonMoon1919/doyoucompute"
@@ -54,7 +55,7 @@ func manualRoute() doyoucompute.Document {
 					},
 					&doyoucompute.List{
 						TypeOfList: doyoucompute.NUMBERED,
-						Items: []doyoucompute.Text{
+						Items: []doyoucompute.Node{
 							doyoucompute.Text("first item"),
 							doyoucompute.Text("second item"),
 						},
@@ -73,7 +74,7 @@ func builderRoute() doyoucompute.Document {
 		panic(err)
 	}
 
-	document, _ := doyoucompute.NewDocument("MY DOC")
+	document := doyoucompute.MustNewDocument("MY DOC")
 	document.WriteIntro().Text("I am an introduction paragraph")
 
 	// Build the section
@@ -96,8 +97,10 @@ func builderRoute() doyoucompute.Document {
 		[]string{"my", "cool", "table"},
 	)
 	table.AddRow("some", "cool", "content")
-	table.AddRow("more", "nice", "stuff")
-	table.AddRow("very", "great", "table")
+	table.AddTableRow(doyoucompute.TableRow{Values: []string{"more", "nice", "stuff"}})
+	table.AddRows([][]string{
+		{"very", "great", "table"},
+	})
 
 	// List
 	list := section.CreateList(doyoucompute.NUMBERED)
@@ -109,9 +112,15 @@ func builderRoute() doyoucompute.Document {
 
 func main() {
 	repo := doyoucompute.NewFileRepository()
-	fileRenderer := doyoucompute.NewMarkdownRenderer()
+	fileRenderer, err := doyoucompute.NewMarkdownRenderer()
+	if err != nil {
+		panic(err)
+	}
 	execRenderer := doyoucompute.NewExecutionRenderer()
-	runner := doyoucompute.NewTaskRunner(doyoucompute.DefaultSecureConfig())
+	runner, err := doyoucompute.NewTaskRunner(doyoucompute.DefaultSecureConfig())
+	if err != nil {
+		panic(err)
+	}
 	svc := doyoucompute.NewService(repo, runner, fileRenderer, execRenderer)
 
 	// manualDoc := manualRoute()
@@ -119,5 +128,7 @@ func main() {
 	app := app.New(&svc)
 	app.Register(builderDoc)
 
-	app.Run(os.Args)
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
 }
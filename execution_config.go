@@ -1,25 +1,315 @@
 package doyoucompute
 
-import "time"
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// Logger receives the messages the task runner logs about the commands it runs. *log.Logger
+// implements it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
 
 type ExecutionConfig struct {
 	// Timeout for command execution (0 means no timeout)
 	Timeout time.Duration
 
+	// WorkingDirectory is the directory commands run in (empty means current dir). Relative
+	// working directories set on individual commands are resolved against it
+	WorkingDirectory string
+
+	// ExtraEnv holds environment variables set for every command, on top of those inherited from
+	// the current process. Values set on individual commands take precedence
+	ExtraEnv map[string]string
+
+	// EnvPassthrough chooses which variables of the current process commands inherit: all of
+	// them (the default), those in AllowedEnvVars, or none. Injected values are always set.
+	// Containers of a DockerRunner get the variables named in its DockerConfig instead
+	EnvPassthrough EnvPassthroughMode
+
+	// AllowedEnvVars names the variables of the current process commands inherit under
+	// PassthroughAllowlist, such as "PATH" and "HOME". The variables a command requires are
+	// inherited as well
+	AllowedEnvVars []string
+
+	// Platform is the operating system commands are checked against, named like a GOOS value
+	// (empty means the current one). Commands restricted to other platforms are skipped
+	Platform string
+
+	// RedactEnvVars names environment variables whose values are replaced with RedactedValue in
+	// logged commands, TaskResult.Command, and command output. Variables a command requires or
+	// has injected whose names look like secrets, such as API_TOKEN, are always redacted
+	RedactEnvVars []string
+
+	// RedactPatterns are replaced with RedactedValue wherever RedactEnvVars values are
+	RedactPatterns []*regexp.Regexp
+
+	// Logger receives messages about the commands being run (nil means they are discarded)
+	Logger Logger
+
+	// AuditSink receives an AuditEvent for every command validated, and for those rejected,
+	// declined, or timed out afterwards (nil means no events are recorded)
+	AuditSink AuditSink
+
 	// AllowedShells restricts which shells/interpreters can be used
 	AllowedShells []string
 
 	// AllowedCommands restricts which commands can be executed (nil means allow all)
 	AllowedCommands []string
 
-	// BlockDangerousCommands prevents obviously dangerous operations
+	// AllowedCommandsRegex restricts commands to those whose arguments, joined by spaces, match
+	// at least one of these regular expressions (nil means allow all). It narrows AllowedCommands
+	// rather than replacing it, so a command must pass both. Expressions match anywhere in the
+	// command unless anchored, so use "^go( |$)" rather than "go" to allow only go commands
+	AllowedCommandsRegex []string
+
+	// BlockDangerousCommands prevents the commands in BlockedCommands and the patterns in
+	// BlockedPatterns from running. When both lists are nil, DefaultBlockedCommands and
+	// DefaultBlockedPatterns are blocked instead; set either to an empty slice to block nothing
 	BlockDangerousCommands bool
+
+	// BlockedCommands lists the commands blocked by BlockDangerousCommands. An entry of several
+	// words, such as "kubectl delete", blocks the command only when the words after it include
+	// the rest of the entry, in order
+	BlockedCommands []string
+
+	// BlockedPatterns lists the text blocked by BlockDangerousCommands wherever it appears in a
+	// command line, such as "rm -rf /"
+	BlockedPatterns []string
+
+	// BlockedPatternsRegex blocks commands whose arguments, joined by spaces, match any of these
+	// regular expressions, such as `>\s*/dev/sd`. Pipelines are matched as a whole as well as
+	// stage by stage. They apply whether or not BlockDangerousCommands is set, and take
+	// precedence over AllowedCommandsRegex: a command matching both is blocked
+	BlockedPatternsRegex []string
+
+	// AllowedWorkingDirRoots restricts the working directories commands may run in to
+	// these directories and their descendants, after resolving symlinks (nil means allow any directory)
+	AllowedWorkingDirRoots []string
+
+	// CaptureOutput records command output in TaskResult.Stdout and TaskResult.Stderr
+	// while still streaming it to the terminal
+	CaptureOutput bool
+
+	// MaxOutputBytes caps how much of each stream is captured (0 means no limit). What happens
+	// to the rest of the output is chosen by OutputLimit
+	MaxOutputBytes int
+
+	// OutputLimit chooses what happens once a stream of a command passes MaxOutputBytes: its
+	// captured output is truncated (the default), or the command is stopped
+	OutputLimit OutputLimitMode
+
+	// Nice runs commands at this niceness, from 1 (slightly lower priority) to 19 (lowest), so
+	// they do not starve the rest of the machine (0 means the runner's priority). It is only
+	// supported on Linux and macOS; elsewhere it is ignored with a warning
+	Nice int
+
+	// MaxProcesses caps the number of processes the user running commands may have, applied
+	// to every command as RLIMIT_NPROC (0 means no limit). It is only supported on Linux;
+	// elsewhere it is ignored with a warning
+	MaxProcesses int
+
+	// DryRun performs every validation a command goes through without running it. Commands that
+	// pass are reported as COMPLETED with TaskResult.DryRun set
+	DryRun bool
+
+	// OutputHandler is called with every line a command writes, without its newline, as the
+	// line arrives, such as to show live progress. isStderr reports which stream the line
+	// came from. Calls for a single command never overlap
+	OutputHandler func(plan CommandPlan, line string, isStderr bool)
+
+	// SuppressPassthrough stops command output from being written to the terminal. It is still
+	// captured and handed to the OutputHandler
+	SuppressPassthrough bool
+
+	// Stdout and Stderr receive the output commands pass through to the terminal (nil means
+	// os.Stdout and os.Stderr), such as to keep stdout free for a report
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// BufferOutput holds back each command's output until it finishes and then writes it in
+	// one piece, so the output of commands running concurrently does not interleave
+	BufferOutput bool
+
+	// Confirm selects the commands the Confirmer is asked about before they run, once they have
+	// passed validation (zero means none). Declined commands are reported as SKIPPED with an
+	// error wrapping ErrCommandDeclined. Dry runs do not ask
+	Confirm ConfirmMode
+
+	// Confirmer decides whether the commands selected by Confirm run (nil means a PromptConfirmer
+	// asking on the terminal). Questions about commands running concurrently never overlap
+	Confirmer Confirmer
 }
 
+// EnvPassthroughMode chooses which environment variables of the current process commands
+// inherit.
+type EnvPassthroughMode int
+
+const (
+	// PassthroughAll lets commands inherit the whole environment. It is the default
+	PassthroughAll EnvPassthroughMode = iota + 1
+	// PassthroughAllowlist lets commands inherit only the variables in AllowedEnvVars and those
+	// they require
+	PassthroughAllowlist
+	// PassthroughNone lets commands inherit nothing, so they only see the values injected
+	// through ExtraEnv and their own environment values. Required variables must be injected,
+	// and PATH is unset unless it is injected too
+	PassthroughNone
+)
+
+// String returns the name of the mode: "all", "allowlist", or "none".
+func (e EnvPassthroughMode) String() string {
+	switch e {
+	case PassthroughAll:
+		return "all"
+	case PassthroughAllowlist:
+		return "allowlist"
+	case PassthroughNone:
+		return "none"
+	}
+
+	return fmt.Sprintf("EnvPassthroughMode(%d)", int(e))
+}
+
+// OutputLimitMode chooses what happens to a command whose output passes MaxOutputBytes.
+type OutputLimitMode int
+
+const (
+	// TruncateOutput keeps the command running and stops capturing its output at the limit,
+	// ending the captured output with OutputTruncatedMarker. It is the default
+	TruncateOutput OutputLimitMode = iota + 1
+	// KillOnOutputLimit stops the command once it writes more than the limit to either stream,
+	// failing it with an error wrapping ErrOutputLimitExceeded. Its output is cut off at the
+	// limit whether or not it is captured
+	KillOnOutputLimit
+)
+
+// String returns the name of the mode: "truncate" or "kill".
+func (o OutputLimitMode) String() string {
+	switch o {
+	case TruncateOutput:
+		return "truncate"
+	case KillOnOutputLimit:
+		return "kill"
+	}
+
+	return fmt.Sprintf("OutputLimitMode(%d)", int(o))
+}
+
+// WindowsShells returns the Windows shells commands can be run with: Windows PowerShell,
+// PowerShell, and the command prompt. Add them to AllowedShells to opt in to them.
+func WindowsShells() []string {
+	return []string{"powershell", "pwsh", "cmd"}
+}
+
+// defaultAllowedShells returns the shells and interpreters allowed by default on goos.
+func defaultAllowedShells(goos string) []string {
+	interpreters := []string{"python3", "python", "node", "go"}
+
+	if goos == "windows" {
+		return append(WindowsShells(), interpreters...)
+	}
+
+	return append([]string{"bash", "sh"}, interpreters...)
+}
+
+// DefaultBlockedCommands returns the commands DefaultSecureConfig blocks, such as sudo,
+// shutdown, and mkfs.
+func DefaultBlockedCommands() []string {
+	return slices.Clone(dangerousCommands)
+}
+
+// DefaultBlockedPatterns returns the patterns DefaultSecureConfig blocks, such as "rm -rf /".
+func DefaultBlockedPatterns() []string {
+	return slices.Clone(dangerousPatterns)
+}
+
+// DefaultSecureConfig returns a configuration with a timeout, blocking of the default dangerous
+// commands and patterns, the shells suited to the current platform (bash and sh, or the
+// Windows shells on Windows), and logging through the standard logger.
 func DefaultSecureConfig() ExecutionConfig {
 	return ExecutionConfig{
 		Timeout:                30 * time.Second,
-		AllowedShells:          []string{"bash", "sh", "python3", "python", "node", "go"},
+		Logger:                 log.Default(),
+		AllowedShells:          defaultAllowedShells(runtime.GOOS),
 		BlockDangerousCommands: true,
+		BlockedCommands:        DefaultBlockedCommands(),
+		BlockedPatterns:        DefaultBlockedPatterns(),
+	}
+}
+
+// blockedLists returns the commands and patterns the config blocks: BlockedCommands and
+// BlockedPatterns, or the defaults when neither is set.
+func (c ExecutionConfig) blockedLists() ([]string, []string) {
+	if c.BlockedCommands == nil && c.BlockedPatterns == nil {
+		return DefaultBlockedCommands(), DefaultBlockedPatterns()
+	}
+
+	return c.BlockedCommands, c.BlockedPatterns
+}
+
+// BlockCommand adds commands to BlockedCommands, such as "terraform destroy". Commands that
+// are already blocked are not added again. When neither list is set, the defaults are kept
+// and the commands added to them.
+func (c *ExecutionConfig) BlockCommand(commands ...string) {
+	c.BlockedCommands, c.BlockedPatterns = c.blockedLists()
+
+	for _, command := range commands {
+		if !slices.Contains(c.BlockedCommands, command) {
+			c.BlockedCommands = append(c.BlockedCommands, command)
+		}
 	}
 }
+
+// AllowCommand removes commands from BlockedCommands, such as "dd". It does not add them to
+// AllowedCommands. When neither list is set, the commands are removed from the defaults.
+func (c *ExecutionConfig) AllowCommand(commands ...string) {
+	c.BlockedCommands, c.BlockedPatterns = c.blockedLists()
+
+	c.BlockedCommands = slices.DeleteFunc(slices.Clone(c.BlockedCommands), func(blocked string) bool {
+		return slices.Contains(commands, blocked)
+	})
+}
+
+// ConfigOverride replaces parts of a runner's ExecutionConfig for the commands of a section. Zero
+// fields leave the runner's config as it is.
+//
+// Settings of the command itself win over those of its sections, and those of an inner section
+// win over those of the sections enclosing it: an executable's Timeout wins over a section's, and
+// a subsection's AllowedCommands replace the document section's.
+type ConfigOverride struct {
+	// Timeout replaces the runner's timeout for commands without their own
+	Timeout time.Duration
+	// AllowedCommands replaces the runner's list of allowed commands
+	AllowedCommands []string
+	// AllowedShells replaces the runner's list of allowed shells
+	AllowedShells []string
+}
+
+// IsZero reports whether the override changes nothing.
+func (c ConfigOverride) IsZero() bool {
+	return c.Timeout == 0 && c.AllowedCommands == nil && c.AllowedShells == nil
+}
+
+// apply returns config with the fields set in the override replaced.
+func (c ConfigOverride) apply(config ExecutionConfig) ExecutionConfig {
+	if c.Timeout != 0 {
+		config.Timeout = c.Timeout
+	}
+
+	if c.AllowedCommands != nil {
+		config.AllowedCommands = c.AllowedCommands
+	}
+
+	if c.AllowedShells != nil {
+		config.AllowedShells = c.AllowedShells
+	}
+
+	return config
+}
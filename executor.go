@@ -1,13 +1,19 @@
 package doyoucompute
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // TaskStatus represents the outcome of executing a command or task.
@@ -18,8 +24,34 @@ const (
 	COMPLETED TaskStatus = iota + 1
 	// FAILED indicates the task execution failed or encountered an error
 	FAILED
+	// SKIPPED indicates the task was intentionally not executed
+	SKIPPED
+	// TIMEOUT indicates the task was stopped because it exceeded its deadline
+	TIMEOUT
 )
 
+// String returns the name of the task status.
+func (s TaskStatus) String() string {
+	switch s {
+	case COMPLETED:
+		return "COMPLETED"
+	case FAILED:
+		return "FAILED"
+	case SKIPPED:
+		return "SKIPPED"
+	case TIMEOUT:
+		return "TIMEOUT"
+	}
+
+	return fmt.Sprintf("TaskStatus(%d)", int(s))
+}
+
+// Failed reports whether the status represents a genuine failure, meaning the
+// task ran and did not complete. Skipped tasks are not failures.
+func (s TaskStatus) Failed() bool {
+	return s == FAILED || s == TIMEOUT
+}
+
 // TaskResult contains the outcome and details of executing a single command,
 // including context information and any errors that occurred.
 type TaskResult struct {
@@ -27,10 +59,64 @@ type TaskResult struct {
 	SectionName string
 	// Command contains the full command string that was executed
 	Command string
-	// Status indicates whether the task completed successfully or failed
+	// Status indicates whether the task completed, failed, was skipped, or timed out
 	Status TaskStatus
 	// Error holds any error that occurred during task execution (nil if successful)
 	Error error
+	// Stdout holds what the command wrote to stdout, when output capture is enabled
+	Stdout string
+	// Stderr holds what the command wrote to stderr, when output capture is enabled
+	Stderr string
+	// OutputTruncated reports whether Stdout or Stderr was cut off at the configured limit
+	OutputTruncated bool
+	// Duration is how long the command ran, zero if it never started
+	Duration time.Duration
+	// ExitCode is the command's exit status: ExitCodeNotStarted if it never started,
+	// or ExitCodeTimeout if it was stopped at its deadline
+	ExitCode int
+	// DryRun reports that the command passed validation but was not run because the
+	// runner is in dry-run mode
+	DryRun bool
+}
+
+const (
+	// ExitCodeNotStarted is the exit code of commands that were never started, such as those
+	// rejected by validation or whose executable could not be found
+	ExitCodeNotStarted = -1
+	// ExitCodeTimeout is the exit code of commands stopped for exceeding their deadline,
+	// matching the convention of coreutils timeout
+	ExitCodeTimeout = 124
+)
+
+// TimeoutError is the error of a task stopped for exceeding its timeout. It wraps
+// context.DeadlineExceeded, so it can be matched with errors.Is as well as errors.As.
+type TimeoutError struct {
+	// Timeout is the deadline the command exceeded
+	Timeout time.Duration
+	// Err is the context error that stopped the command
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("command timed out after %s: %s", e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode extracts the exit status of a finished command from the error it returned.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return ExitCodeNotStarted
 }
 
 // Runner defines the interface for executing command plans and returning results.
@@ -40,24 +126,194 @@ type Runner interface {
 	Run(plan CommandPlan) TaskResult
 }
 
+// RunnerContext is the context-aware counterpart of Runner. Runners that start long-running
+// work should implement it so commands are stopped when ctx is cancelled. Plans are run
+// through it whenever it is available.
+type RunnerContext interface {
+	// RunContext executes the command plan, stopping it if ctx is cancelled
+	RunContext(ctx context.Context, plan CommandPlan) TaskResult
+}
+
+// ErrRunCancelled is the error of commands skipped because the context of the run was
+// cancelled or its deadline passed.
+var ErrRunCancelled = errors.New("run cancelled")
+
+// ErrPlatformNotSupported is the error of commands skipped because they are restricted to
+// other platforms than the one they would run on.
+var ErrPlatformNotSupported = errors.New("command does not support this platform")
+
+// ErrConditionNotMet is the error of commands skipped because of their SkipIfEnv or
+// OnlyIfEnv conditions.
+var ErrConditionNotMet = errors.New("run condition not met")
+
+// ErrMissingTools is the error of commands whose required tools cannot be found on the PATH.
+var ErrMissingTools = errors.New("missing tools")
+
+// ErrOutputLimitExceeded is the error of commands stopped for writing more than MaxOutputBytes
+// under KillOnOutputLimit.
+var ErrOutputLimitExceeded = errors.New("output limit exceeded")
+
+// ErrLimitsNotSupported is the error logged as a warning when the Nice or MaxProcesses limits
+// of an ExecutionConfig cannot be applied on the current platform.
+var ErrLimitsNotSupported = errors.New("process limits not supported")
+
+// OutputTruncatedMarker ends captured output that was truncated at MaxOutputBytes.
+const OutputTruncatedMarker = "\n[output truncated]\n"
+
+// CheckTools verifies that every tool can be found on the PATH, returning a single error
+// naming all the missing ones, such as "missing tools: docker, terraform".
+func CheckTools(tools []string) error {
+	var missing []string
+
+	for _, tool := range tools {
+		if slices.Contains(missing, tool) {
+			continue
+		}
+
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingTools, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// runnerAdapter lets a Runner without context support be used as a RunnerContext.
+type runnerAdapter struct {
+	runner Runner
+}
+
+// AdaptRunner wraps a Runner so it satisfies RunnerContext. Because the wrapped runner cannot
+// be interrupted, cancellation is only checked before each command starts. Runners that
+// already implement RunnerContext are returned as-is.
+func AdaptRunner(runner Runner) RunnerContext {
+	if runnerCtx, ok := runner.(RunnerContext); ok {
+		return runnerCtx
+	}
+
+	return runnerAdapter{runner: runner}
+}
+
+// executionConfig returns the config of the wrapped runner.
+func (r runnerAdapter) executionConfig() ExecutionConfig {
+	return runnerConfig(r.runner)
+}
+
+// RunContext runs the plan with the wrapped runner unless ctx is already done, in which
+// case the plan is reported as SKIPPED.
+func (r runnerAdapter) RunContext(ctx context.Context, plan CommandPlan) TaskResult {
+	if err := ctx.Err(); err != nil {
+		return skippedResult(plan, fmt.Errorf("%w: %w", ErrRunCancelled, err), runnerConfig(r.runner))
+	}
+
+	return r.runner.Run(plan)
+}
+
+// BufferingRunner is implemented by runners that can hold back a command's output until it
+// finishes. Runners executing plans concurrently use it to keep output from interleaving.
+type BufferingRunner interface {
+	Runner
+	// Buffered returns a copy of the runner that writes each command's output in one piece
+	// once the command finishes
+	Buffered() Runner
+}
+
+// RedirectingRunner is implemented by runners that can write the output of commands somewhere
+// other than the terminal.
+type RedirectingRunner interface {
+	Runner
+	// WithOutput returns a copy of the runner that writes the output of commands to stdout and
+	// stderr
+	WithOutput(stdout, stderr io.Writer) Runner
+}
+
+// DryRunner is implemented by runners that can validate commands without running them.
+type DryRunner interface {
+	Runner
+	// DryRun returns a copy of the runner that validates commands without running them
+	DryRun() Runner
+}
+
+// CommandFactory creates the exec.Cmd that runs a program with the given arguments. It has
+// the signature of exec.CommandContext, which is the default.
+type CommandFactory func(ctx context.Context, name string, args ...string) *exec.Cmd
+
 // TaskRunner implements the Runner interface for executing commands locally
 // using the operating system's command execution facilities.
 type TaskRunner struct {
-	config ExecutionConfig
+	config     ExecutionConfig
+	rules      commandRules
+	newCommand CommandFactory
 }
 
-// NewTaskRunner creates a new TaskRunner instance for local command execution.
-func NewTaskRunner(config ExecutionConfig) TaskRunner {
-	return TaskRunner{
-		config: config,
+// NewTaskRunner creates a new TaskRunner instance for local command execution. The regular
+// expressions of config are compiled once here; an error wrapping ErrInvalidCommandPattern is
+// returned if any of them is invalid.
+func NewTaskRunner(config ExecutionConfig) (TaskRunner, error) {
+	rules, err := compileCommandRules(config)
+	if err != nil {
+		return TaskRunner{}, err
 	}
+
+	return TaskRunner{
+		config:     config,
+		rules:      rules,
+		newCommand: exec.CommandContext,
+	}, nil
+}
+
+// WithCommandFactory returns a copy of the runner that creates its commands with factory,
+// such as to inspect how commands are invoked without running them.
+func (t TaskRunner) WithCommandFactory(factory CommandFactory) TaskRunner {
+	t.newCommand = factory
+
+	return t
+}
+
+// Buffered returns a copy of the runner with BufferOutput enabled.
+func (t TaskRunner) Buffered() Runner {
+	t.config.BufferOutput = true
+
+	return t
+}
+
+// executionConfig returns the config the runner was created with.
+func (t TaskRunner) executionConfig() ExecutionConfig {
+	return t.config
 }
 
-func validateEnvironment(requiredEnvVars []string) error {
+// WithOutput returns a copy of the runner with Stdout and Stderr set.
+func (t TaskRunner) WithOutput(stdout, stderr io.Writer) Runner {
+	t.config.Stdout, t.config.Stderr = stdout, stderr
+
+	return t
+}
+
+// DryRun returns a copy of the runner with DryRun enabled.
+func (t TaskRunner) DryRun() Runner {
+	t.config.DryRun = true
+
+	return t
+}
+
+// Confirming returns a copy of the runner with Confirm set to mode.
+func (t TaskRunner) Confirming(mode ConfirmMode) Runner {
+	t.config.Confirm = mode
+
+	return t
+}
+
+// validateEnvironment checks that every required variable is injected into the command or,
+// when the command inherits them, set in the current process.
+func validateEnvironment(requiredEnvVars []string, injected map[string]string, inherited bool) error {
 	var missing []string
 
 	for _, envVar := range requiredEnvVars {
-		if os.Getenv(envVar) == "" {
+		if (!inherited || os.Getenv(envVar) == "") && injected[envVar] == "" {
 			missing = append(missing, envVar)
 		}
 	}
@@ -69,24 +325,438 @@ func validateEnvironment(requiredEnvVars []string) error {
 	return nil
 }
 
+// injectedEnv merges the configured ExtraEnv with the values set on a command, letting the
+// command's values win.
+func (t TaskRunner) injectedEnv(values map[string]string) map[string]string {
+	injected := make(map[string]string, len(t.config.ExtraEnv)+len(values))
+	for name, value := range t.config.ExtraEnv {
+		injected[name] = value
+	}
+	for name, value := range values {
+		injected[name] = value
+	}
+
+	return injected
+}
+
+// planEnv returns the values injected into a plan, including those of its pipeline stages.
+func (t TaskRunner) planEnv(plan CommandPlan) map[string]string {
+	injected := t.injectedEnv(plan.EnvValues)
+	for _, stage := range plan.Stages {
+		for name, value := range stage.EnvValues {
+			injected[name] = value
+		}
+	}
+
+	return injected
+}
+
+// envConditionHolds reports whether a NAME or NAME=value condition holds. Variables are looked
+// up in the injected values first and then in the current process; like required variables,
+// a variable counts as set when it is not empty.
+func envConditionHolds(condition string, injected map[string]string) bool {
+	name, expected, hasValue := strings.Cut(condition, "=")
+
+	value, ok := injected[name]
+	if !ok {
+		value = os.Getenv(name)
+	}
+
+	if !hasValue {
+		return value != ""
+	}
+
+	return value == expected
+}
+
+// checkConditions returns why the plan should be skipped according to its environment
+// conditions, or nil if it should run.
+func checkConditions(plan CommandPlan, injected map[string]string) error {
+	for _, condition := range plan.SkipIfEnv {
+		if envConditionHolds(condition, injected) {
+			return fmt.Errorf("%w: SkipIfEnv %s holds", ErrConditionNotMet, condition)
+		}
+	}
+
+	for _, condition := range plan.OnlyIfEnv {
+		if !envConditionHolds(condition, injected) {
+			return fmt.Errorf("%w: OnlyIfEnv %s does not hold", ErrConditionNotMet, condition)
+		}
+	}
+
+	return nil
+}
+
+// environ builds the environment of a command: the variables of the current process it
+// inherits under the EnvPassthrough mode, with the injected values appended so they override
+// inherited ones. Required variables are inherited under PassthroughAllowlist. Returns nil when
+// the command inherits everything and there is nothing to inject, letting it inherit the
+// environment unchanged.
+func (t TaskRunner) environ(values map[string]string, required []string) []string {
+	injected := t.injectedEnv(values)
+
+	var env []string
+	switch t.config.EnvPassthrough {
+	case PassthroughAllowlist:
+		env = []string{}
+		for _, entry := range os.Environ() {
+			name, _, _ := strings.Cut(entry, "=")
+			if slices.Contains(t.config.AllowedEnvVars, name) || slices.Contains(required, name) {
+				env = append(env, entry)
+			}
+		}
+	case PassthroughNone:
+		env = []string{}
+	default:
+		if len(injected) == 0 {
+			return nil
+		}
+		env = os.Environ()
+	}
+
+	for _, name := range envNames(injected) {
+		env = append(env, name+"="+injected[name])
+	}
+
+	return env
+}
+
+// cappedBuffer keeps up to limit bytes of what is written to it, dropping the rest. Writes always
+// report success so it can sit behind an io.MultiWriter without cutting off the other writers.
+// Pipeline stages share one buffer for stderr, so writes are serialized.
+type cappedBuffer struct {
+	mu        sync.Mutex
+	limit     int
+	buffer    []byte
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remaining := len(p)
+	if c.limit > 0 {
+		remaining = min(remaining, c.limit-len(c.buffer))
+	}
+
+	if remaining < len(p) {
+		c.truncated = true
+	}
+	c.buffer = append(c.buffer, p[:remaining]...)
+
+	return len(p), nil
+}
+
+func (c *cappedBuffer) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return string(c.buffer)
+}
+
+// captured returns what was kept, ending with OutputTruncatedMarker if anything was dropped.
+func (c *cappedBuffer) captured() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.truncated {
+		return string(c.buffer) + OutputTruncatedMarker
+	}
+
+	return string(c.buffer)
+}
+
+// limitWriter passes up to limit bytes through to w and drops the rest, calling exceeded the
+// first time a write goes past the limit. Writes always report success so the command keeps
+// running until exceeded stops it.
+type limitWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	limit    int
+	written  int
+	exceeded func()
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	remaining := l.limit - l.written
+	if len(p) <= remaining {
+		l.written += len(p)
+		l.w.Write(p)
+		return len(p), nil
+	}
+
+	if remaining > 0 {
+		l.written = l.limit
+		l.w.Write(p[:remaining])
+	}
+	l.exceeded()
+
+	return len(p), nil
+}
+
+// lineWriter splits what is written to it into lines and calls handle with each of them,
+// without its newline. Flush handles the final line if it did not end with a newline. Writers
+// sharing mu never call handle at the same time.
+type lineWriter struct {
+	mu      *sync.Mutex
+	handle  func(line string)
+	pending []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending = append(l.pending, p...)
+
+	for {
+		end := bytes.IndexByte(l.pending, '\n')
+		if end == -1 {
+			break
+		}
+
+		l.handle(string(l.pending[:end]))
+		l.pending = l.pending[end+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush handles the remainder that did not end with a newline.
+func (l *lineWriter) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		l.handle(string(l.pending))
+		l.pending = nil
+	}
+}
+
+// outputMu serializes the writes of buffered command output, so the output of one command
+// is written in full before the next.
+var outputMu sync.Mutex
+
+// flushOutput writes the buffered output of a finished command to stdout and stderr.
+func flushOutput(stdout, stderr io.Writer, bufferedStdout, bufferedStderr *cappedBuffer) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	io.WriteString(stdout, bufferedStdout.String())
+	io.WriteString(stderr, bufferedStderr.String())
+}
+
+// outputWriters returns the writers the output of commands passes through to.
+func outputWriters(config ExecutionConfig) (io.Writer, io.Writer) {
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if config.Stdout != nil {
+		stdout = config.Stdout
+	}
+	if config.Stderr != nil {
+		stderr = config.Stderr
+	}
+
+	return stdout, stderr
+}
+
+// shellCommandFlags maps the shells that take a whole command line as a single argument to the
+// flag that introduces it.
+var shellCommandFlags = map[string]string{
+	"sh":         "-c",
+	"bash":       "-c",
+	"powershell": "-Command",
+	"pwsh":       "-Command",
+	"cmd":        "/C",
+}
+
+// buildCommand creates the exec.Cmd for a single command. Commands for shells (sh, bash,
+// powershell, pwsh, and cmd) are handed to the shell as one command line so variables are
+// expanded; other interpreters receive the arguments directly, except for multi-line scripts,
+// which are passed inline the way the ShellScript renderer exports them, such as python3 -c.
+func (t TaskRunner) buildCommand(ctx context.Context, shell string, args []string) *exec.Cmd {
+	newCommand := t.newCommand
+	if newCommand == nil {
+		newCommand = exec.CommandContext
+	}
+
+	var cmd *exec.Cmd
+
+	if flag, ok := shellCommandFlags[shell]; ok {
+		cmd = newCommand(ctx, shell, flag, strings.Join(args, " "))
+	} else if invocation, ok := multiLineScript(shell, args); ok {
+		cmd = newCommand(ctx, invocation[0], invocation[1:]...)
+	} else {
+		cmd = newCommand(ctx, args[0], args[1:]...)
+	}
+
+	configureProcess(cmd)
+
+	return cmd
+}
+
+// multiLineScript returns the arguments that run args as an inline script when they hold a
+// single multi-line script for an interpreter, falling back to its -c flag for interpreters
+// without a known invocation.
+func multiLineScript(shell string, args []string) ([]string, bool) {
+	if len(args) != 1 || !strings.Contains(args[0], "\n") {
+		return nil, false
+	}
+
+	if invocation, ok := scriptInvocation(shell, args[0]); ok {
+		return invocation, true
+	}
+
+	return []string{shell, "-c", args[0]}, true
+}
+
+// startCommand starts cmd and applies the Nice and MaxProcesses limits of the config to it.
+// Limits that cannot be applied are logged as warnings rather than failing the command.
+func (t TaskRunner) startCommand(cmd *exec.Cmd, section string) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if err := applyProcessLimits(cmd.Process.Pid, t.config); err != nil {
+		logf(t.config.Logger, "[Section: %s] - Warning: process limits not applied: %v", section, err)
+	}
+
+	return nil
+}
+
+// runPipeline starts every stage of a pipeline with the stdout of each stage wired to the stdin
+// of the next one. Like `set -o pipefail`, the pipeline fails if any of its stages fails.
+func (t TaskRunner) runPipeline(ctx context.Context, plan CommandPlan, stdout, stderr io.Writer) error {
+	section, stages := plan.Context.Name, plan.Stages
+
+	cmds := make([]*exec.Cmd, len(stages))
+	for idx, stage := range stages {
+		cmds[idx] = t.buildCommand(ctx, stage.Shell, stage.Args)
+		cmds[idx].Dir = stage.WorkingDir
+		cmds[idx].Env = t.environ(stage.EnvValues, plan.Environment)
+		cmds[idx].Stderr = stderr
+	}
+	cmds[len(cmds)-1].Stdout = stdout
+
+	var pipes []*os.File
+	closePipes := func() {
+		for _, pipe := range pipes {
+			pipe.Close()
+		}
+	}
+
+	for idx := 0; idx < len(cmds)-1; idx++ {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			closePipes()
+			return err
+		}
+
+		cmds[idx].Stdout = writer
+		cmds[idx+1].Stdin = reader
+		pipes = append(pipes, reader, writer)
+	}
+
+	var pipelineErr error
+	started := 0
+
+	for _, cmd := range cmds {
+		if err := t.startCommand(cmd, section); err != nil {
+			pipelineErr = err
+			break
+		}
+		started++
+	}
+
+	// The children hold their own copies of the pipe ends, close ours so
+	// every stage sees EOF once the stage before it exits
+	closePipes()
+
+	for idx := 0; idx < started; idx++ {
+		if err := cmds[idx].Wait(); err != nil && pipelineErr == nil {
+			pipelineErr = fmt.Errorf("pipeline stage %d (%s): %w", idx+1, strings.Join(stages[idx].Args, " "), err)
+		}
+	}
+
+	return pipelineErr
+}
+
+// withWorkingDir returns the plan with its working directory, and those of its pipeline stages,
+// resolved against the configured WorkingDirectory.
+func (t TaskRunner) withWorkingDir(plan CommandPlan) CommandPlan {
+	switch {
+	case plan.WorkingDir == "":
+		plan.WorkingDir = t.config.WorkingDirectory
+	case t.config.WorkingDirectory != "" && !filepath.IsAbs(plan.WorkingDir):
+		plan.WorkingDir = filepath.Join(t.config.WorkingDirectory, plan.WorkingDir)
+	}
+
+	if len(plan.Stages) > 0 {
+		stages := make([]CommandPlan, len(plan.Stages))
+		for idx, stage := range plan.Stages {
+			stages[idx] = t.withWorkingDir(stage)
+		}
+		plan.Stages = stages
+	}
+
+	return plan
+}
+
 // Run executes a command plan locally using exec.Command, streaming output to
-// stdout/stderr in real-time. Returns a TaskResult with execution status and any errors.
+// stdout/stderr in real-time and capturing it in the result when configured. Returns a TaskResult with execution status and any errors.
 func (t TaskRunner) Run(plan CommandPlan) TaskResult {
+	return t.RunContext(context.Background(), plan)
+}
+
+// RunContext executes a command plan like Run, killing the command if ctx is done. Commands
+// stopped by cancellation are reported as FAILED with an error wrapping ErrRunCancelled, and
+// those stopped by the deadline of ctx as TIMEOUT, like commands exceeding their own timeout.
+func (t TaskRunner) RunContext(parent context.Context, plan CommandPlan) TaskResult {
+	injected := t.planEnv(plan)
+	redactor := newRedactor(t.config, plan.Environment, injected)
+	command := redactor.redact(strings.Join(plan.Args, " "))
+
 	result := TaskResult{
 		SectionName: plan.Context.Name,
-		Command:     strings.Join(plan.Args, " "),
+		Command:     command,
+		ExitCode:    ExitCodeNotStarted,
+	}
+
+	platform := t.config.Platform
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+
+	if !plan.RunsOn(platform) {
+		result.Status = SKIPPED
+		result.Error = fmt.Errorf("%w: runs on %s, current platform is %s", ErrPlatformNotSupported, strings.Join(plan.Platforms, ", "), platform)
+		return result
+	}
+
+	if err := checkConditions(plan, injected); err != nil {
+		result.Status = SKIPPED
+		result.Error = err
+		return result
 	}
 
-	if err := ValidateCommandPlan(plan, t.config); err != nil {
-		result.Error = fmt.Errorf("security validation failed: %w", err)
+	plan = t.withWorkingDir(plan)
+
+	validationErr := validateCommandPlan(plan, t.config, t.rules)
+	auditValidation(t.config, plan, command, validationErr)
+	if validationErr != nil {
+		result.Error = fmt.Errorf("security validation failed: %w", validationErr)
 		result.Status = FAILED
 		return result
 	}
 
-	// Check required environment variables
-	if err := validateEnvironment(plan.Environment); err != nil {
+	// Check required environment variables, counting those injected into any pipeline stage
+	if err := validateEnvironment(plan.Environment, injected, t.config.EnvPassthrough != PassthroughNone); err != nil {
 		result.Error = fmt.Errorf("environment validation failed: %w", err)
 		result.Status = FAILED
+		recordAudit(t.config, plan, command, AuditRejected, "Environment", result.Error)
 		return result
 	}
 
@@ -96,27 +766,149 @@ func (t TaskRunner) Run(plan CommandPlan) TaskResult {
 		return result
 	}
 
-	ctx := context.Background()
-	if t.config.Timeout > 0 {
+	if err := CheckTools(plan.Requires); err != nil {
+		result.Error = fmt.Errorf("prerequisite check failed: %w", err)
+		result.Status = FAILED
+		recordAudit(t.config, plan, command, AuditRejected, "Requires", result.Error)
+		return result
+	}
+
+	if t.config.DryRun {
+		logf(t.config.Logger, "[Section: %s] - Dry run, not running command: '%s'", plan.Context.Name, command)
+		result.Status = COMPLETED
+		result.DryRun = true
+		return result
+	}
+
+	if confirmed, err := confirmCommand(plan, command, t.config); err != nil {
+		result.Error = fmt.Errorf("confirmation failed: %w", err)
+		result.Status = FAILED
+		return result
+	} else if !confirmed {
+		logf(t.config.Logger, "[Section: %s] - Command declined, not running: '%s'", plan.Context.Name, command)
+		result.Error = ErrCommandDeclined
+		result.Status = SKIPPED
+		recordAudit(t.config, plan, command, AuditDeclined, "Confirm", result.Error)
+		return result
+	}
+
+	// A timeout set on the command itself takes precedence over the configured one
+	timeout := t.config.Timeout
+	if plan.Timeout > 0 {
+		timeout = plan.Timeout
+	}
+
+	// An earlier deadline of the parent, such as one for the whole run, stops the command first
+	if deadline, ok := parent.Deadline(); ok && (timeout <= 0 || time.Until(deadline) < timeout) {
+		timeout = time.Until(deadline).Round(time.Millisecond)
+	}
+
+	ctx := parent
+	if timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 		defer cancel()
 	}
 
-	var cmd *exec.Cmd
+	// Commands stopped for their output are told apart from timeouts by the cause of the context
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	logf(t.config.Logger, "[Section: %s] - Running command: '%s'", plan.Context.Name, command)
+
+	passthroughStdout, passthroughStderr := outputWriters(t.config)
+	stdout, stderr := passthroughStdout, passthroughStderr
+	bufferedStdout, bufferedStderr := &cappedBuffer{}, &cappedBuffer{}
+	if t.config.SuppressPassthrough {
+		stdout, stderr = io.Discard, io.Discard
+	} else if t.config.BufferOutput {
+		stdout, stderr = bufferedStdout, bufferedStderr
+	}
+
+	capturedStdout := &cappedBuffer{limit: t.config.MaxOutputBytes}
+	capturedStderr := &cappedBuffer{limit: t.config.MaxOutputBytes}
+	if t.config.CaptureOutput {
+		stdout = io.MultiWriter(stdout, capturedStdout)
+		stderr = io.MultiWriter(stderr, capturedStderr)
+	}
+
+	var handlerStdout, handlerStderr *lineWriter
+	if handler := t.config.OutputHandler; handler != nil {
+		mu := &sync.Mutex{}
+		handlerStdout = &lineWriter{mu: mu, handle: func(line string) { handler(plan, line, false) }}
+		handlerStderr = &lineWriter{mu: mu, handle: func(line string) { handler(plan, line, true) }}
+		stdout = io.MultiWriter(stdout, handlerStdout)
+		stderr = io.MultiWriter(stderr, handlerStderr)
+	}
+
+	// Output is redacted before it is streamed, captured, or handled, so secrets never reach them
+	var redactingStdout, redactingStderr *redactingWriter
+	if redactor.enabled() {
+		redactingStdout = &redactingWriter{w: stdout, redactor: redactor}
+		redactingStderr = &redactingWriter{w: stderr, redactor: redactor}
+		stdout, stderr = redactingStdout, redactingStderr
+	}
+
+	limit := t.config.MaxOutputBytes
+	if t.config.OutputLimit == KillOnOutputLimit && limit > 0 {
+		exceeded := func() { stop(fmt.Errorf("%w: wrote more than %d bytes", ErrOutputLimitExceeded, limit)) }
+		stdout = &limitWriter{w: stdout, limit: limit, exceeded: exceeded}
+		stderr = &limitWriter{w: stderr, limit: limit, exceeded: exceeded}
+	}
+
+	start := time.Now()
 
-	if plan.Shell == "sh" || plan.Shell == "bash" {
-		// We currently only support variable expansion for bash/sh
-		cmd = exec.CommandContext(ctx, plan.Shell, "-c", strings.Join(plan.Args, " "))
+	var err error
+	if len(plan.Stages) > 0 {
+		err = t.runPipeline(ctx, plan, stdout, stderr)
 	} else {
-		cmd = exec.CommandContext(ctx, plan.Args[0], plan.Args[1:]...)
+		cmd := t.buildCommand(ctx, plan.Shell, plan.Args)
+		cmd.Dir = plan.WorkingDir
+		cmd.Env = t.environ(plan.EnvValues, plan.Environment)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		if err = t.startCommand(cmd, plan.Context.Name); err == nil {
+			err = cmd.Wait()
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.ExitCode = exitCode(err)
+
+	if redactor.enabled() {
+		redactingStdout.Flush()
+		redactingStderr.Flush()
+	}
+
+	if t.config.OutputHandler != nil {
+		handlerStdout.Flush()
+		handlerStderr.Flush()
+	}
+
+	if t.config.BufferOutput {
+		flushOutput(passthroughStdout, passthroughStderr, bufferedStdout, bufferedStderr)
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	if t.config.CaptureOutput {
+		result.Stdout = capturedStdout.captured()
+		result.Stderr = capturedStderr.captured()
+		result.OutputTruncated = capturedStdout.truncated || capturedStderr.truncated
+	}
 
-	log.Printf("[Section: %s] - Running command: '%s'", plan.Context.Name, strings.Join(plan.Args, " "))
-	if err := cmd.Run(); err != nil {
+	if err != nil && errors.Is(parent.Err(), context.Canceled) {
+		result.Error = fmt.Errorf("%w: %w", ErrRunCancelled, parent.Err())
+		result.Status = FAILED
+	} else if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrOutputLimitExceeded) {
+		result.Error = cause
+		result.Status = FAILED
+		result.OutputTruncated = true
+	} else if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Error = &TimeoutError{Timeout: timeout, Err: ctx.Err()}
+		result.Status = TIMEOUT
+		result.ExitCode = ExitCodeTimeout
+		recordAudit(t.config, plan, command, AuditTimedOut, "Timeout", result.Error)
+	} else if err != nil {
 		result.Error = err
 		result.Status = FAILED
 	} else {
@@ -126,15 +918,341 @@ func (t TaskRunner) Run(plan CommandPlan) TaskResult {
 	return result
 }
 
+// ExecutionMode controls what happens to the rest of an execution plan once a command fails.
+type ExecutionMode int
+
+const (
+	// ContinueOnError runs every command regardless of earlier failures. It is the default.
+	ContinueOnError ExecutionMode = iota + 1
+	// FailFast stops at the first failed or timed out command and skips the rest of the plan.
+	FailFast
+)
+
+// ErrEarlierCommandFailed is the error of commands skipped because an earlier command failed
+// while running in FailFast mode.
+var ErrEarlierCommandFailed = errors.New("an earlier command failed")
+
+// ErrEarlierStepFailed is the error of the steps of an executable skipped because one of its
+// earlier steps failed.
+var ErrEarlierStepFailed = errors.New("an earlier step failed")
+
+// ErrSetupFailed is the error of commands skipped because a setup command of their section
+// failed.
+var ErrSetupFailed = errors.New("section setup failed")
+
+// Hooks are callbacks invoked around every command of a plan, including the ones that fail
+// or are skipped. A panicking hook is logged as a warning and does not stop the run. When a
+// plan runs in parallel, hooks may be called from several goroutines at once.
+type Hooks struct {
+	// BeforeCommand is called before the command runs, or before it is reported as skipped
+	BeforeCommand func(plan CommandPlan)
+	// AfterCommand is called with the result of the command once it finished or was skipped
+	AfterCommand func(plan CommandPlan, result TaskResult)
+}
+
+// logf logs a message through logger, doing nothing when it is nil.
+func logf(logger Logger, format string, v ...any) {
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
+
+// callHook runs a hook, recovering from panics so a broken hook cannot abort the run.
+func callHook(logger Logger, name string, plan CommandPlan, hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logf(logger, "[Section: %s] - Warning: %s hook panicked: %v", plan.Context.Name, name, r)
+		}
+	}()
+
+	hook()
+}
+
+func (s runSettings) before(plan CommandPlan) {
+	if s.hooks.BeforeCommand != nil {
+		callHook(s.logger, "BeforeCommand", plan, func() { s.hooks.BeforeCommand(plan) })
+	}
+}
+
+func (s runSettings) after(plan CommandPlan, result TaskResult) {
+	if s.hooks.AfterCommand != nil {
+		callHook(s.logger, "AfterCommand", plan, func() { s.hooks.AfterCommand(plan, result) })
+	}
+}
+
+// runSettings holds the options RunExecutionPlan was called with.
+type runSettings struct {
+	mode   ExecutionMode
+	hooks  Hooks
+	logger Logger
+}
+
+// RunOption configures how RunExecutionPlan and RunExecutionPlanParallel run a plan.
+type RunOption func(s *runSettings)
+
+// WithMode sets the execution mode of the plan. The zero value means ContinueOnError.
+func WithMode(mode ExecutionMode) RunOption {
+	return func(s *runSettings) {
+		s.mode = mode
+	}
+}
+
+// WithHooks sets callbacks invoked before and after every command of the plan.
+func WithHooks(hooks Hooks) RunOption {
+	return func(s *runSettings) {
+		s.hooks = hooks
+	}
+}
+
+// WithLogger sets the logger warnings about the run, such as panicking hooks, are logged to.
+// By default they are discarded.
+func WithLogger(logger Logger) RunOption {
+	return func(s *runSettings) {
+		s.logger = logger
+	}
+}
+
+func newRunSettings(opts []RunOption) runSettings {
+	settings := runSettings{mode: ContinueOnError}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return settings
+}
+
+// failureTracker remembers the first failed command of a plan running in FailFast mode,
+// so the commands after it can be skipped. It also remembers which sections failed their
+// setup, whose remaining commands are skipped in every mode, and which sections started
+// running, whose teardown commands still run after a failure. It is safe for concurrent use.
+type failureTracker struct {
+	enabled bool
+
+	mu           sync.Mutex
+	failed       *TaskResult
+	failedSetups map[string]TaskResult
+	started      map[string]bool
+}
+
+// planSections returns a key for the section the plan is in and for each of its parent
+// sections, outermost first.
+func planSections(plan CommandPlan) []string {
+	path := plan.Path
+	if len(path) == 0 {
+		path = []string{plan.Context.Name}
+	}
+
+	keys := make([]string, len(path))
+	for idx := range path {
+		keys[idx] = strings.Join(path[:idx+1], " > ")
+	}
+
+	return keys
+}
+
+func (f *failureTracker) record(plan CommandPlan, result TaskResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sections := planSections(plan)
+	if f.started == nil {
+		f.started = map[string]bool{}
+	}
+	for _, section := range sections {
+		f.started[section] = true
+	}
+
+	if !result.Status.Failed() {
+		return
+	}
+
+	if plan.Phase == PhaseSetup {
+		if f.failedSetups == nil {
+			f.failedSetups = map[string]TaskResult{}
+		}
+		f.failedSetups[sections[len(sections)-1]] = result
+	}
+
+	if f.enabled && f.failed == nil {
+		f.failed = &result
+	}
+}
+
+// skipReason returns why the plan should be skipped, or nil if it should run. Teardown
+// commands are only skipped after a failure when their section never started running.
+func (f *failureTracker) skipReason(plan CommandPlan) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sections := planSections(plan)
+	for _, section := range sections {
+		if setup, ok := f.failedSetups[section]; ok {
+			return fmt.Errorf("%w: '%s' in section '%s'", ErrSetupFailed, setup.Command, setup.SectionName)
+		}
+	}
+
+	if f.failed == nil || (plan.Phase == PhaseTeardown && f.started[sections[len(sections)-1]]) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: '%s' in section '%s'", ErrEarlierCommandFailed, f.failed.Command, f.failed.SectionName)
+}
+
+// skippedResult builds the result of a command that was never run. The command is redacted
+// the way config would redact it had it run.
+func skippedResult(plan CommandPlan, reason error, config ExecutionConfig) TaskResult {
+	redactor := newRedactor(config, plan.Environment, plan.EnvValues)
+
+	return TaskResult{
+		SectionName: plan.Context.Name,
+		Command:     redactor.redact(strings.Join(plan.Args, " ")),
+		Status:      SKIPPED,
+		Error:       reason,
+		ExitCode:    ExitCodeNotStarted,
+	}
+}
+
+// configuredRunner is implemented by runners that run commands according to an ExecutionConfig,
+// including those embedding or wrapping such a runner.
+type configuredRunner interface {
+	executionConfig() ExecutionConfig
+}
+
+// runnerConfig returns the config of runner, so the commands it never runs are redacted the same
+// way as those it does. Runners without one get the zero config, which still redacts the values
+// of secret variables.
+func runnerConfig(runner any) ExecutionConfig {
+	if configured, ok := runner.(configuredRunner); ok {
+		return configured.executionConfig()
+	}
+
+	return ExecutionConfig{}
+}
+
+// runSequence runs the plans at the given indices one after another, storing each result at
+// its index. Once ctx is done, the remaining plans are skipped, as are the steps of an
+// executable after a failed one.
+func runSequence(ctx context.Context, plans []CommandPlan, indices []int, runner RunnerContext, settings runSettings, tracker *failureTracker, results []TaskResult) {
+	var failedStep *TaskResult
+	config := runnerConfig(runner)
+
+	for _, idx := range indices {
+		plan := plans[idx]
+		settings.before(plan)
+
+		if plan.Step <= 1 {
+			failedStep = nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			results[idx] = skippedResult(plan, fmt.Errorf("%w: %w", ErrRunCancelled, err), config)
+		} else if failedStep != nil {
+			results[idx] = skippedResult(plan, fmt.Errorf("%w: '%s' in section '%s'", ErrEarlierStepFailed, failedStep.Command, failedStep.SectionName), config)
+		} else if reason := tracker.skipReason(plan); reason != nil {
+			results[idx] = skippedResult(plan, reason, config)
+		} else {
+			results[idx] = runner.RunContext(ctx, plan)
+			tracker.record(plan, results[idx])
+
+			if plan.Step > 0 && results[idx].Status.Failed() {
+				failedStep = &results[idx]
+			}
+		}
+
+		settings.after(plan, results[idx])
+	}
+}
+
 // RunExecutionPlan executes a sequence of command plans using the provided runner,
-// logging each command before execution and returning results for all commands.
-// Commands are executed sequentially in the order they appear in the plan.
-func RunExecutionPlan(plans []CommandPlan, runner Runner) []TaskResult {
+// returning results for all commands. Commands are executed sequentially in the order
+// they appear in the plan. In FailFast mode, the commands after the first failure are
+// not run and are reported as SKIPPED, except the teardown commands of sections that
+// already started. When a setup command fails, the rest of its section, including its
+// teardown, is skipped in every mode, as are the steps of an executable after a failed one.
+func RunExecutionPlan(plans []CommandPlan, runner Runner, opts ...RunOption) []TaskResult {
+	return RunExecutionPlanContext(context.Background(), plans, runner, opts...)
+}
+
+// RunExecutionPlanContext executes command plans like RunExecutionPlan, stopping the running
+// command when ctx is cancelled and reporting the commands after it as SKIPPED.
+func RunExecutionPlanContext(ctx context.Context, plans []CommandPlan, runner Runner, opts ...RunOption) []TaskResult {
+	settings := newRunSettings(opts)
+	tracker := &failureTracker{enabled: settings.mode == FailFast}
+
+	indices := make([]int, len(plans))
+	for idx := range plans {
+		indices[idx] = idx
+	}
+
+	results := make([]TaskResult, len(plans))
+	runSequence(ctx, plans, indices, AdaptRunner(runner), settings, tracker, results)
+
+	return results
+}
+
+// sectionGroups splits plans into groups by the top-level section they come from, returning
+// the indices of each group's plans in order. Commands written directly in the document
+// form a group of their own.
+func sectionGroups(plans []CommandPlan) [][]int {
+	var groups [][]int
+	positions := map[string]int{}
+
+	for idx, plan := range plans {
+		key := plan.Context.Name
+		if len(plan.Path) > 0 {
+			key = strings.Join(plan.Path[:min(len(plan.Path), 2)], " > ")
+		}
+
+		position, ok := positions[key]
+		if !ok {
+			position = len(groups)
+			positions[key] = position
+			groups = append(groups, nil)
+		}
+		groups[position] = append(groups[position], idx)
+	}
+
+	return groups
+}
+
+// RunExecutionPlanParallel executes command plans grouped by top-level section, running up to
+// concurrency groups at once. Commands within a group still run one after another in plan
+// order, and results are returned in the original plan order. When the runner implements
+// BufferingRunner, its buffered form is used so the output of concurrent commands does not
+// interleave. In FailFast mode, no new commands start once any command has failed.
+func RunExecutionPlanParallel(plans []CommandPlan, runner Runner, concurrency int, opts ...RunOption) []TaskResult {
+	return RunExecutionPlanParallelContext(context.Background(), plans, runner, concurrency, opts...)
+}
+
+// RunExecutionPlanParallelContext executes command plans like RunExecutionPlanParallel,
+// stopping running commands when ctx is cancelled and reporting the rest as SKIPPED.
+func RunExecutionPlanParallelContext(ctx context.Context, plans []CommandPlan, runner Runner, concurrency int, opts ...RunOption) []TaskResult {
+	settings := newRunSettings(opts)
+	tracker := &failureTracker{enabled: settings.mode == FailFast}
+
+	concurrency = max(concurrency, 1)
+	if buffering, ok := runner.(BufferingRunner); ok && concurrency > 1 {
+		runner = buffering.Buffered()
+	}
+
+	runnerCtx := AdaptRunner(runner)
 	results := make([]TaskResult, len(plans))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
 
-	for idx, commandPlan := range plans {
-		results[idx] = runner.Run(commandPlan)
+	for _, group := range sectionGroups(plans) {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(indices []int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			runSequence(ctx, plans, indices, runnerCtx, settings, tracker, results)
+		}(group)
 	}
 
+	wg.Wait()
+
 	return results
 }
@@ -1,10 +1,21 @@
 package doyoucompute
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // Generic test harness for task runner operations
@@ -100,6 +111,57 @@ func TestTaskRunner_Run(t *testing.T) {
 			expectedError:  true,
 			errorMessage:   "",
 		},
+		{
+			name: "Successful pipeline",
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"printf", "'a\\nb\\n'", "|", "grep", "b"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"printf", "'a\\nb\\n'"}},
+					{Shell: "sh", Args: []string{"grep", "b"}},
+				},
+				Context: SectionInfo{
+					Name: "PipeSection",
+				},
+			},
+			expectedStatus: COMPLETED,
+			expectedError:  false,
+			errorMessage:   "",
+		},
+		{
+			name: "Pipeline fails when an earlier stage fails",
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"false", "|", "cat"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"false"}},
+					{Shell: "sh", Args: []string{"cat"}},
+				},
+				Context: SectionInfo{
+					Name: "PipeSection",
+				},
+			},
+			expectedStatus: FAILED,
+			expectedError:  true,
+			errorMessage:   "",
+		},
+		{
+			name: "Pipeline fails when the last stage fails",
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"echo", "hello", "|", "grep", "goodbye"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"echo", "hello"}},
+					{Shell: "sh", Args: []string{"grep", "goodbye"}},
+				},
+				Context: SectionInfo{
+					Name: "PipeSection",
+				},
+			},
+			expectedStatus: FAILED,
+			expectedError:  true,
+			errorMessage:   "",
+		},
 		{
 			name: "Command that returns non-zero exit code",
 			plan: CommandPlan{
@@ -117,7 +179,7 @@ func TestTaskRunner_Run(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			runner := NewTaskRunner(DefaultSecureConfig())
+			runner := newTestTaskRunner(t, DefaultSecureConfig())
 
 			testTaskRunnerOperation(
 				t,
@@ -153,205 +215,2422 @@ func TestTaskRunner_Run(t *testing.T) {
 	}
 }
 
-func TestRunExecutionPlan(t *testing.T) {
+func TestTaskRunner_RunTimeout(t *testing.T) {
 	tests := []struct {
-		name         string
-		plans        []CommandPlan
-		mockResults  []TaskResult
-		errorMessage string
+		name           string
+		timeout        time.Duration
+		plan           CommandPlan
+		expectedStatus TaskStatus
 	}{
 		{
-			name:         "Empty execution plan",
-			plans:        []CommandPlan{},
-			mockResults:  []TaskResult{},
-			errorMessage: "",
+			name:    "Command exceeding deadline times out",
+			timeout: 50 * time.Millisecond,
+			plan: CommandPlan{
+				Shell:   "sh",
+				Args:    []string{"sleep", "5"},
+				Context: SectionInfo{Name: "SlowSection"},
+			},
+			expectedStatus: TIMEOUT,
 		},
 		{
-			name: "Single command plan",
-			plans: []CommandPlan{
-				{
-					Args:    []string{"echo", "hello"},
-					Context: SectionInfo{Name: "Section1"},
-				},
-			},
-			mockResults: []TaskResult{
-				{
-					SectionName: "Section1",
-					Command:     "echo hello",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
+			name:    "Command within deadline completes",
+			timeout: 5 * time.Second,
+			plan: CommandPlan{
+				Shell:   "sh",
+				Args:    []string{"echo", "quick"},
+				Context: SectionInfo{Name: "FastSection"},
 			},
-			errorMessage: "",
+			expectedStatus: COMPLETED,
 		},
 		{
-			name: "Multiple command plans",
-			plans: []CommandPlan{
-				{
-					Args:    []string{"echo", "hello"},
-					Context: SectionInfo{Name: "Section1"},
-				},
-				{
-					Args:    []string{"echo", "world"},
-					Context: SectionInfo{Name: "Section2"},
-				},
-			},
-			mockResults: []TaskResult{
-				{
-					SectionName: "Section1",
-					Command:     "echo hello",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-				{
-					SectionName: "Section2",
-					Command:     "echo world",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
+			name:    "Failing command within deadline fails",
+			timeout: 5 * time.Second,
+			plan: CommandPlan{
+				Shell:   "sh",
+				Args:    []string{"exit 1"},
+				Context: SectionInfo{Name: "FailSection"},
 			},
-			errorMessage: "",
+			expectedStatus: FAILED,
 		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.Timeout = tc.timeout
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.expectedStatus == TIMEOUT && !errors.Is(result.Error, context.DeadlineExceeded) {
+				t.Errorf("Expected error to wrap context.DeadlineExceeded, got %v", result.Error)
+			}
+
+			var timeoutErr *TimeoutError
+			if isTimeout := errors.As(result.Error, &timeoutErr); isTimeout != (tc.expectedStatus == TIMEOUT) {
+				t.Errorf("Expected TimeoutError only for timed out commands, got %v", result.Error)
+			} else if isTimeout && timeoutErr.Timeout != tc.timeout {
+				t.Errorf("Expected timeout %s, got %s", tc.timeout, timeoutErr.Timeout)
+			}
+
+			if expected := "command timed out after " + tc.timeout.String() + ": context deadline exceeded"; tc.expectedStatus == TIMEOUT && result.Error.Error() != expected {
+				t.Errorf("Expected error %q, got %q", expected, result.Error.Error())
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunCaptureOutput(t *testing.T) {
+	tests := []struct {
+		name              string
+		capture           bool
+		maxOutputBytes    int
+		plan              CommandPlan
+		expectedStatus    TaskStatus
+		expectedStdout    string
+		expectedStderr    string
+		expectedTruncated bool
+	}{
 		{
-			name: "Mixed success and failure results",
-			plans: []CommandPlan{
-				{
-					Args:    []string{"echo", "hello"},
-					Context: SectionInfo{Name: "Section1"},
-				},
-				{
-					Args:    []string{"false"},
-					Context: SectionInfo{Name: "Section2"},
-				},
-			},
-			mockResults: []TaskResult{
-				{
-					SectionName: "Section1",
-					Command:     "echo hello",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-				{
-					SectionName: "Section2",
-					Command:     "false",
-					Status:      FAILED,
-					Error:       errors.New("exit status 1"),
-				},
-			},
-			errorMessage: "",
+			name:           "Captures stdout",
+			capture:        true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "hello"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "hello\n",
 		},
 		{
-			name: "Plans with same section name",
-			plans: []CommandPlan{
-				{
-					Args:    []string{"echo", "first"},
-					Context: SectionInfo{Name: "SameSection"},
-				},
-				{
-					Args:    []string{"echo", "second"},
-					Context: SectionInfo{Name: "SameSection"},
-				},
-			},
-			mockResults: []TaskResult{
-				{
-					SectionName: "SameSection",
-					Command:     "echo first",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-				{
-					SectionName: "SameSection",
-					Command:     "echo second",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-			},
-			errorMessage: "",
+			name:           "Captures stderr of a failing command",
+			capture:        true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "partial;", "echo", "broken", ">&2;", "exit", "3"}},
+			expectedStatus: FAILED,
+			expectedStdout: "partial\n",
+			expectedStderr: "broken\n",
+		},
+		{
+			name:           "Captures the last pipeline stage",
+			capture:        true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printf 'a\\nb\\n' | grep b"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"printf 'a\\nb\\n'"}}, {Shell: "sh", Args: []string{"grep", "b"}}}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "b\n",
+		},
+		{
+			name:              "Truncates at the limit",
+			capture:           true,
+			maxOutputBytes:    4,
+			plan:              CommandPlan{Shell: "sh", Args: []string{"echo", "hello", "world"}},
+			expectedStatus:    COMPLETED,
+			expectedStdout:    "hell" + OutputTruncatedMarker,
+			expectedTruncated: true,
+		},
+		{
+			name:           "Output within the limit is not truncated",
+			capture:        true,
+			maxOutputBytes: 6,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "hello"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "hello\n",
+		},
+		{
+			name:           "Nothing captured when disabled",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "hello"}},
+			expectedStatus: COMPLETED,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRunner := &MockRunner{
-				results: make([]TaskResult, len(tc.mockResults)),
-			}
-			copy(mockRunner.results, tc.mockResults)
+			config := DefaultSecureConfig()
+			config.CaptureOutput = tc.capture
+			config.MaxOutputBytes = tc.maxOutputBytes
 
-			testTaskRunnerOperation(
-				t,
-				func() ([]TaskResult, error) {
-					return RunExecutionPlan(tc.plans, mockRunner), nil
-				},
-				tc.errorMessage,
-				func(results []TaskResult, t *testing.T) {
-					// Validate result count
-					if len(results) != len(tc.plans) {
-						t.Errorf("Expected %d results, got %d", len(tc.plans), len(results))
-						return
-					}
+			result := newTestTaskRunner(t, config).Run(tc.plan)
 
-					// Validate results match expected
-					for i, result := range results {
-						expected := tc.mockResults[i]
-						if result.SectionName != expected.SectionName {
-							t.Errorf("Result %d: Expected section name %s, got %s", i, expected.SectionName, result.SectionName)
-						}
-						if result.Command != expected.Command {
-							t.Errorf("Result %d: Expected command %s, got %s", i, expected.Command, result.Command)
-						}
-						if result.Status != expected.Status {
-							t.Errorf("Result %d: Expected status %v, got %v", i, expected.Status, result.Status)
-						}
-						if (result.Error == nil) != (expected.Error == nil) {
-							t.Errorf("Result %d: Expected error presence %v, got %v", i, expected.Error != nil, result.Error != nil)
-						}
-						if result.Error != nil && expected.Error != nil && result.Error.Error() != expected.Error.Error() {
-							t.Errorf("Result %d: Expected error %s, got %s", i, expected.Error.Error(), result.Error.Error())
-						}
-					}
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
 
-					// Validate all plans were called
-					if len(mockRunner.calls) != len(tc.plans) {
-						t.Errorf("Expected %d runner calls, got %d", len(tc.plans), len(mockRunner.calls))
-						return
-					}
+			if result.Stdout != tc.expectedStdout {
+				t.Errorf("Expected stdout %q, got %q", tc.expectedStdout, result.Stdout)
+			}
 
-					// Validate plans were called in order
-					for i, expectedPlan := range tc.plans {
-						if !reflect.DeepEqual(mockRunner.calls[i], expectedPlan) {
-							t.Errorf("Expected plan %d to be %v, got %v", i, expectedPlan, mockRunner.calls[i])
-						}
-					}
-				},
-			)
+			if result.Stderr != tc.expectedStderr {
+				t.Errorf("Expected stderr %q, got %q", tc.expectedStderr, result.Stderr)
+			}
+
+			if result.OutputTruncated != tc.expectedTruncated {
+				t.Errorf("Expected truncated %t, got %t", tc.expectedTruncated, result.OutputTruncated)
+			}
 		})
 	}
 }
 
-func TestNewTaskRunner(t *testing.T) {
+func TestTaskRunner_RunOutputLimit(t *testing.T) {
 	tests := []struct {
-		name         string
-		errorMessage string
+		name              string
+		mode              OutputLimitMode
+		capture           bool
+		plan              CommandPlan
+		expectedStatus    TaskStatus
+		expectedStdout    string
+		expectedError     error
+		expectedTruncated bool
 	}{
 		{
-			name:         "Creates new TaskRunner instance",
-			errorMessage: "",
+			name:              "Truncates a large output and keeps running",
+			capture:           true,
+			plan:              CommandPlan{Shell: "sh", Args: []string{"yes | head -c 10000000; echo done >&2"}},
+			expectedStatus:    COMPLETED,
+			expectedStdout:    strings.Repeat("y\n", 512) + OutputTruncatedMarker,
+			expectedTruncated: true,
+		},
+		{
+			name:              "Kills an endless output",
+			mode:              KillOnOutputLimit,
+			capture:           true,
+			plan:              CommandPlan{Shell: "sh", Args: []string{"yes"}},
+			expectedStatus:    FAILED,
+			expectedStdout:    strings.Repeat("y\n", 512),
+			expectedError:     ErrOutputLimitExceeded,
+			expectedTruncated: true,
+		},
+		{
+			name:              "Kills without capturing",
+			mode:              KillOnOutputLimit,
+			plan:              CommandPlan{Shell: "sh", Args: []string{"yes | head -c 10000000"}},
+			expectedStatus:    FAILED,
+			expectedError:     ErrOutputLimitExceeded,
+			expectedTruncated: true,
+		},
+		{
+			name:           "Output within the limit is not killed",
+			mode:           KillOnOutputLimit,
+			capture:        true,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"head -c 1024 /dev/zero | tr '\\0' y"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: strings.Repeat("y", 1024),
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			testTaskRunnerOperation(
-				t,
-				func() (TaskRunner, error) {
-					return NewTaskRunner(DefaultSecureConfig()), nil
-				},
-				tc.errorMessage,
-				func(result TaskRunner, t *testing.T) {
-					// Validate it's a valid TaskRunner (interface check)
-					var _ Runner = result
-				},
-			)
+			config := DefaultSecureConfig()
+			config.Timeout = 10 * time.Second
+			config.CaptureOutput = tc.capture
+			config.MaxOutputBytes = 1024
+			config.OutputLimit = tc.mode
+			config.SuppressPassthrough = true
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Fatalf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if !errors.Is(result.Error, tc.expectedError) {
+				t.Errorf("Expected error %v, got %v", tc.expectedError, result.Error)
+			}
+
+			if result.Stdout != tc.expectedStdout {
+				t.Errorf("Expected %d bytes of stdout, got %d", len(tc.expectedStdout), len(result.Stdout))
+			}
+
+			if result.OutputTruncated != tc.expectedTruncated {
+				t.Errorf("Expected truncated %t, got %t", tc.expectedTruncated, result.OutputTruncated)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunProcessLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process limits are only fully supported on Linux")
+	}
+
+	logger := &recordingLogger{}
+	config := DefaultSecureConfig()
+	config.CaptureOutput = true
+	config.SuppressPassthrough = true
+	config.Logger = logger
+	config.Nice = 5
+	config.MaxProcesses = 4096
+
+	// The limits apply once the command has started, so the shell waits before reading them
+	plan := CommandPlan{Shell: "sh", Args: []string{"sleep 0.2; cut -d ' ' -f 19 /proc/self/stat; grep 'Max processes' /proc/self/limits"}}
+
+	result := newTestTaskRunner(t, config).Run(plan)
+	if result.Status != COMPLETED {
+		t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+	}
+
+	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines of output, got %q", result.Stdout)
+	}
+
+	if lines[0] != "5" {
+		t.Errorf("Expected niceness 5, got %q", lines[0])
+	}
+
+	if fields := strings.Fields(lines[1]); len(fields) < 4 || fields[2] != "4096" || fields[3] != "4096" {
+		t.Errorf("Expected a process limit of 4096, got %q", lines[1])
+	}
+
+	for _, message := range logger.messages {
+		if strings.Contains(message, "Warning") {
+			t.Errorf("Expected no warnings, got %q", message)
+		}
+	}
+}
+
+func TestTaskRunner_RunExitCodeAndDuration(t *testing.T) {
+	tests := []struct {
+		name             string
+		timeout          time.Duration
+		plan             CommandPlan
+		expectedStatus   TaskStatus
+		expectedExitCode int
+		minDuration      time.Duration
+	}{
+		{
+			name:             "Successful command exits zero",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"sleep", "0.1"}},
+			expectedStatus:   COMPLETED,
+			expectedExitCode: 0,
+			minDuration:      100 * time.Millisecond,
+		},
+		{
+			name:             "Failing command reports its exit code",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"exit 3"}},
+			expectedStatus:   FAILED,
+			expectedExitCode: 3,
+		},
+		{
+			name:             "Failing pipeline stage reports its exit code",
+			plan:             CommandPlan{Shell: "sh", Args: []string{"echo hi | cat; exit 5"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"echo", "hi"}}, {Shell: "sh", Args: []string{"cat; exit 5"}}}},
+			expectedStatus:   FAILED,
+			expectedExitCode: 5,
+		},
+		{
+			name:             "Command that never starts",
+			plan:             CommandPlan{Shell: "python3", Args: []string{"definitely-not-a-real-binary"}},
+			expectedStatus:   FAILED,
+			expectedExitCode: ExitCodeNotStarted,
+		},
+		{
+			name:             "Command that times out",
+			timeout:          50 * time.Millisecond,
+			plan:             CommandPlan{Shell: "sh", Args: []string{"sleep", "5"}},
+			expectedStatus:   TIMEOUT,
+			expectedExitCode: ExitCodeTimeout,
+			minDuration:      50 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			if tc.timeout > 0 {
+				config.Timeout = tc.timeout
+			}
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if result.ExitCode != tc.expectedExitCode {
+				t.Errorf("Expected exit code %d, got %d", tc.expectedExitCode, result.ExitCode)
+			}
+
+			if result.Duration < tc.minDuration {
+				t.Errorf("Expected duration of at least %s, got %s", tc.minDuration, result.Duration)
+			}
+
+			if tc.expectedExitCode != ExitCodeNotStarted && result.Duration <= 0 {
+				t.Errorf("Expected a nonzero duration, got %s", result.Duration)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunWorkingDirAndTimeout(t *testing.T) {
+	// Resolve symlinks so the expected paths match what pwd prints, e.g. on macOS
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error resolving temp dir: %s", err)
+	}
+	workDir := filepath.Join(root, "work")
+	if err := os.Mkdir(workDir, 0o755); err != nil {
+		t.Fatalf("unexpected error creating directory: %s", err)
+	}
+	filePath := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(filePath, []byte("not a directory"), 0o644); err != nil {
+		t.Fatalf("unexpected error creating file: %s", err)
+	}
+
+	tests := []struct {
+		name            string
+		configTimeout   time.Duration
+		allowedRoots    []string
+		plan            CommandPlan
+		expectedStatus  TaskStatus
+		expectedStdout  string
+		expectedTimeout time.Duration
+		expectedErr     error
+	}{
+		{
+			name:           "Runs in the working directory",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd"}, WorkingDir: workDir},
+			expectedStatus: COMPLETED,
+			expectedStdout: workDir + "\n",
+		},
+		{
+			name:           "Runs pipeline stages in their working directories",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd", "|", "cat"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"pwd"}, WorkingDir: workDir}, {Shell: "sh", Args: []string{"cat"}}}},
+			expectedStatus: COMPLETED,
+			expectedStdout: workDir + "\n",
+		},
+		{
+			name:           "Working directory within an allowed root",
+			allowedRoots:   []string{root},
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd"}, WorkingDir: workDir},
+			expectedStatus: COMPLETED,
+			expectedStdout: workDir + "\n",
+		},
+		{
+			name:           "Working directory outside the allowed roots",
+			allowedRoots:   []string{workDir},
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd"}, WorkingDir: root},
+			expectedStatus: FAILED,
+			expectedErr:    ErrInvalidWorkingDir,
+		},
+		{
+			name:           "Nonexistent working directory",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd"}, WorkingDir: filepath.Join(root, "missing")},
+			expectedStatus: FAILED,
+			expectedErr:    ErrInvalidWorkingDir,
+		},
+		{
+			name:           "Working directory that is a file",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"pwd"}, WorkingDir: filePath},
+			expectedStatus: FAILED,
+			expectedErr:    ErrInvalidWorkingDir,
+		},
+		{
+			name:            "Command timeout overrides a longer configured timeout",
+			configTimeout:   5 * time.Second,
+			plan:            CommandPlan{Shell: "sh", Args: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond},
+			expectedStatus:  TIMEOUT,
+			expectedTimeout: 50 * time.Millisecond,
+			expectedErr:     context.DeadlineExceeded,
+		},
+		{
+			name:           "Command timeout overrides a shorter configured timeout",
+			configTimeout:  50 * time.Millisecond,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"sleep", "0.2"}, Timeout: 5 * time.Second},
+			expectedStatus: COMPLETED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.Timeout = tc.configTimeout
+			config.AllowedWorkingDirRoots = tc.allowedRoots
+			config.CaptureOutput = true
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(result.Error, tc.expectedErr) {
+				t.Errorf("Expected error wrapping %v, got %v", tc.expectedErr, result.Error)
+			}
+
+			if result.Stdout != tc.expectedStdout {
+				t.Errorf("Expected stdout %q, got %q", tc.expectedStdout, result.Stdout)
+			}
+
+			var timeoutErr *TimeoutError
+			if errors.As(result.Error, &timeoutErr) && timeoutErr.Timeout != tc.expectedTimeout {
+				t.Errorf("Expected timeout %s, got %s", tc.expectedTimeout, timeoutErr.Timeout)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunConfiguredWorkingDirectory(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error resolving temp dir: %s", err)
+	}
+
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, "sub"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("unexpected error creating directory: %s", err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("unexpected error creating symlink: %s", err)
+	}
+
+	tests := []struct {
+		name             string
+		workingDirectory string
+		allowedRoots     []string
+		planDir          string
+		expectedStatus   TaskStatus
+		expectedStdout   string
+		expectedErr      error
+	}{
+		{
+			name:             "Runs in the configured directory",
+			workingDirectory: root,
+			expectedStatus:   COMPLETED,
+			expectedStdout:   root + "\n",
+		},
+		{
+			name:             "Resolves relative command directories against the configured directory",
+			workingDirectory: root,
+			planDir:          "sub",
+			expectedStatus:   COMPLETED,
+			expectedStdout:   filepath.Join(root, "sub") + "\n",
+		},
+		{
+			name:             "Absolute command directories ignore the configured directory",
+			workingDirectory: root,
+			planDir:          outside,
+			expectedStatus:   COMPLETED,
+			expectedStdout:   outside + "\n",
+		},
+		{
+			name:             "Nonexistent configured directory",
+			workingDirectory: filepath.Join(base, "missing"),
+			expectedStatus:   FAILED,
+			expectedErr:      ErrInvalidWorkingDir,
+		},
+		{
+			name:             "Symlink escaping the allowed root",
+			workingDirectory: root,
+			allowedRoots:     []string{root},
+			planDir:          "escape",
+			expectedStatus:   FAILED,
+			expectedErr:      ErrInvalidWorkingDir,
+		},
+		{
+			name:             "Configured directory outside the allowed roots",
+			workingDirectory: outside,
+			allowedRoots:     []string{root},
+			expectedStatus:   FAILED,
+			expectedErr:      ErrInvalidWorkingDir,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.WorkingDirectory = tc.workingDirectory
+			config.AllowedWorkingDirRoots = tc.allowedRoots
+			config.CaptureOutput = true
+
+			result := newTestTaskRunner(t, config).Run(CommandPlan{Shell: "sh", Args: []string{"pwd", "-P"}, WorkingDir: tc.planDir})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(result.Error, tc.expectedErr) {
+				t.Errorf("Expected error wrapping %v, got %v", tc.expectedErr, result.Error)
+			}
+
+			if result.Stdout != tc.expectedStdout {
+				t.Errorf("Expected stdout %q, got %q", tc.expectedStdout, result.Stdout)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunEnvValues(t *testing.T) {
+	t.Setenv("FOO", "inherited")
+
+	tests := []struct {
+		name           string
+		extraEnv       map[string]string
+		plan           CommandPlan
+		expectedStatus TaskStatus
+		expectedStdout string
+	}{
+		{
+			name:           "Inherits the environment when nothing is injected",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$FOO"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "inherited\n",
+		},
+		{
+			name:           "Injects command values",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$FOO"}, EnvValues: map[string]string{"FOO": "injected"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "injected\n",
+		},
+		{
+			name:           "Injects configured values",
+			extraEnv:       map[string]string{"FOO": "configured"},
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$FOO"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "configured\n",
+		},
+		{
+			name:           "Command values win over configured values",
+			extraEnv:       map[string]string{"FOO": "configured", "BAR": "shared"},
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$FOO", "$BAR"}, EnvValues: map[string]string{"FOO": "injected"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "injected shared\n",
+		},
+		{
+			name:           "Injected values satisfy required variables",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$DOYOUCOMPUTE_TEST_REQUIRED"}, Environment: []string{"DOYOUCOMPUTE_TEST_REQUIRED"}, EnvValues: map[string]string{"DOYOUCOMPUTE_TEST_REQUIRED": "set"}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "set\n",
+		},
+		{
+			name:           "Pipeline stages get their own values",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo $FOO | cat"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"echo", "$FOO"}, EnvValues: map[string]string{"FOO": "stage"}}, {Shell: "sh", Args: []string{"cat"}}}},
+			expectedStatus: COMPLETED,
+			expectedStdout: "stage\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.ExtraEnv = tc.extraEnv
+			config.CaptureOutput = true
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if result.Stdout != tc.expectedStdout {
+				t.Errorf("Expected stdout %q, got %q", tc.expectedStdout, result.Stdout)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunDryRun(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "created")
+
+	tests := []struct {
+		name           string
+		plan           CommandPlan
+		expectedStatus TaskStatus
+		expectedDryRun bool
+		errorMessage   string
+	}{
+		{
+			name:           "Validates without running",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"touch", marker}},
+			expectedStatus: COMPLETED,
+			expectedDryRun: true,
+		},
+		{
+			name:           "Validates pipelines without running",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "hi", "|", "tee", marker}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"echo", "hi"}}, {Shell: "sh", Args: []string{"tee", marker}}}},
+			expectedStatus: COMPLETED,
+			expectedDryRun: true,
+		},
+		{
+			name:           "Missing environment variables still fail",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"touch", marker}, Environment: []string{"DOYOUCOMPUTE_TEST_UNSET"}},
+			expectedStatus: FAILED,
+			errorMessage:   "environment validation failed: required environment variables not set: [DOYOUCOMPUTE_TEST_UNSET]",
+		},
+		{
+			name:           "Security validation still fails",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"sudo", "touch", marker}},
+			expectedStatus: FAILED,
+			errorMessage:   "security validation failed: dangerous command blocked: sudo",
+		},
+		{
+			name:           "Nonexistent working directory still fails",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"touch", "created"}, WorkingDir: filepath.Join(dir, "missing")},
+			expectedStatus: FAILED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.DryRun = true
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if result.DryRun != tc.expectedDryRun {
+				t.Errorf("Expected DryRun %v, got %v", tc.expectedDryRun, result.DryRun)
+			}
+
+			if tc.errorMessage != "" && (result.Error == nil || result.Error.Error() != tc.errorMessage) {
+				t.Errorf("Expected error %q, got %v", tc.errorMessage, result.Error)
+			}
+
+			if result.ExitCode != ExitCodeNotStarted {
+				t.Errorf("Expected exit code %d, got %d", ExitCodeNotStarted, result.ExitCode)
+			}
+
+			if _, err := os.Stat(marker); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Expected the command not to run, but %s exists", marker)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunPlatforms(t *testing.T) {
+	tests := []struct {
+		name           string
+		platform       string
+		platforms      []string
+		expectedStatus TaskStatus
+		errorMessage   string
+	}{
+		{
+			name:           "Unrestricted commands run everywhere",
+			platform:       "windows",
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Runs on a matching platform",
+			platform:       "darwin",
+			platforms:      []string{"darwin"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Runs when any platform matches",
+			platform:       "linux",
+			platforms:      []string{"darwin", "linux"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Skips other platforms",
+			platform:       "linux",
+			platforms:      []string{"darwin"},
+			expectedStatus: SKIPPED,
+			errorMessage:   "command does not support this platform: runs on darwin, current platform is linux",
+		},
+		{
+			name:           "Defaults to the current platform",
+			platforms:      []string{runtime.GOOS},
+			expectedStatus: COMPLETED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.Platform = tc.platform
+
+			result := newTestTaskRunner(t, config).Run(CommandPlan{Shell: "sh", Args: []string{"true"}, Platforms: tc.platforms})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.errorMessage == "" {
+				return
+			}
+
+			if !errors.Is(result.Error, ErrPlatformNotSupported) || result.Error.Error() != tc.errorMessage {
+				t.Errorf("Expected error %q, got %v", tc.errorMessage, result.Error)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunEnvConditions(t *testing.T) {
+	t.Setenv("RUN_MIGRATIONS", "1")
+	t.Setenv("CI", "true")
+	t.Setenv("DOYOUCOMPUTE_TEST_EMPTY", "")
+
+	tests := []struct {
+		name           string
+		plan           CommandPlan
+		expectedStatus TaskStatus
+		errorMessage   string
+	}{
+		{
+			name:           "OnlyIfEnv name is set",
+			plan:           CommandPlan{OnlyIfEnv: []string{"RUN_MIGRATIONS"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "OnlyIfEnv name is not set",
+			plan:           CommandPlan{OnlyIfEnv: []string{"DOYOUCOMPUTE_TEST_UNSET"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: OnlyIfEnv DOYOUCOMPUTE_TEST_UNSET does not hold",
+		},
+		{
+			name:           "OnlyIfEnv empty variables count as unset",
+			plan:           CommandPlan{OnlyIfEnv: []string{"DOYOUCOMPUTE_TEST_EMPTY"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: OnlyIfEnv DOYOUCOMPUTE_TEST_EMPTY does not hold",
+		},
+		{
+			name:           "OnlyIfEnv value matches",
+			plan:           CommandPlan{OnlyIfEnv: []string{"RUN_MIGRATIONS=1"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "OnlyIfEnv value differs",
+			plan:           CommandPlan{OnlyIfEnv: []string{"RUN_MIGRATIONS=0"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: OnlyIfEnv RUN_MIGRATIONS=0 does not hold",
+		},
+		{
+			name:           "OnlyIfEnv requires every condition",
+			plan:           CommandPlan{OnlyIfEnv: []string{"RUN_MIGRATIONS=1", "CI=false"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: OnlyIfEnv CI=false does not hold",
+		},
+		{
+			name:           "OnlyIfEnv sees injected values",
+			plan:           CommandPlan{OnlyIfEnv: []string{"STAGE=prod"}, EnvValues: map[string]string{"STAGE": "prod"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "SkipIfEnv name is set",
+			plan:           CommandPlan{SkipIfEnv: []string{"CI"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: SkipIfEnv CI holds",
+		},
+		{
+			name:           "SkipIfEnv name is not set",
+			plan:           CommandPlan{SkipIfEnv: []string{"DOYOUCOMPUTE_TEST_UNSET"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "SkipIfEnv value matches",
+			plan:           CommandPlan{SkipIfEnv: []string{"DOYOUCOMPUTE_TEST_UNSET", "CI=true"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: SkipIfEnv CI=true holds",
+		},
+		{
+			name:           "SkipIfEnv value differs",
+			plan:           CommandPlan{SkipIfEnv: []string{"CI=false"}},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Conditions are checked before validation",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"sudo", "reboot"}, Environment: []string{"DOYOUCOMPUTE_TEST_UNSET"}, SkipIfEnv: []string{"CI"}},
+			expectedStatus: SKIPPED,
+			errorMessage:   "run condition not met: SkipIfEnv CI holds",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := tc.plan
+			if plan.Shell == "" {
+				plan.Shell = "sh"
+				plan.Args = []string{"true"}
+			}
+
+			result := newTestTaskRunner(t, DefaultSecureConfig()).Run(plan)
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.errorMessage == "" {
+				return
+			}
+
+			if !errors.Is(result.Error, ErrConditionNotMet) || result.Error.Error() != tc.errorMessage {
+				t.Errorf("Expected error %q, got %v", tc.errorMessage, result.Error)
+			}
+		})
+	}
+}
+
+func TestCheckTools(t *testing.T) {
+	tests := []struct {
+		name         string
+		tools        []string
+		errorMessage string
+	}{
+		{
+			name:         "Passing-NoTools",
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-Installed",
+			tools:        []string{"sh"},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-Missing",
+			tools:        []string{"sh", "doyoucompute-missing-tool", "doyoucompute-other-tool", "doyoucompute-missing-tool"},
+			errorMessage: "missing tools: doyoucompute-missing-tool, doyoucompute-other-tool",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckTools(tc.tools)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" && !errors.Is(err, ErrMissingTools) {
+				t.Errorf("Expected error wrapping %v, got %v", ErrMissingTools, err)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunRequires(t *testing.T) {
+	tests := []struct {
+		name           string
+		requires       []string
+		expectedStatus TaskStatus
+		errorMessage   string
+	}{
+		{
+			name:           "Runs when the tools are installed",
+			requires:       []string{"sh"},
+			expectedStatus: COMPLETED,
+		},
+		{
+			name:           "Fails before running when a tool is missing",
+			requires:       []string{"sh", "doyoucompute-missing-tool"},
+			expectedStatus: FAILED,
+			errorMessage:   "prerequisite check failed: missing tools: doyoucompute-missing-tool",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := newTestTaskRunner(t, DefaultSecureConfig()).Run(CommandPlan{Shell: "sh", Args: []string{"true"}, Requires: tc.requires})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			if tc.errorMessage != "" && (result.Error == nil || result.Error.Error() != tc.errorMessage) {
+				t.Errorf("Expected error %q, got %v", tc.errorMessage, result.Error)
+			}
+
+			if tc.errorMessage != "" && result.ExitCode != ExitCodeNotStarted {
+				t.Errorf("Expected exit code %d, got %d", ExitCodeNotStarted, result.ExitCode)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunShellInvocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         CommandPlan
+		expectedArgs []string
+	}{
+		{
+			name:         "Bash",
+			plan:         CommandPlan{Shell: "bash", Args: []string{"echo", "$HOME"}},
+			expectedArgs: []string{"bash", "-c", "echo $HOME"},
+		},
+		{
+			name:         "Sh",
+			plan:         CommandPlan{Shell: "sh", Args: []string{"ls", "-la"}},
+			expectedArgs: []string{"sh", "-c", "ls -la"},
+		},
+		{
+			name:         "Powershell",
+			plan:         CommandPlan{Shell: "powershell", Args: []string{"Get-ChildItem", "-Path", "$env:USERPROFILE"}},
+			expectedArgs: []string{"powershell", "-Command", "Get-ChildItem -Path $env:USERPROFILE"},
+		},
+		{
+			name:         "Pwsh",
+			plan:         CommandPlan{Shell: "pwsh", Args: []string{"Write-Output", "hello"}},
+			expectedArgs: []string{"pwsh", "-Command", "Write-Output hello"},
+		},
+		{
+			name:         "Cmd",
+			plan:         CommandPlan{Shell: "cmd", Args: []string{"echo", "%PATH%"}},
+			expectedArgs: []string{"cmd", "/C", "echo %PATH%"},
+		},
+		{
+			name:         "Interpreter receives arguments directly",
+			plan:         CommandPlan{Shell: "python3", Args: []string{"python3", "-c", "print(1)"}},
+			expectedArgs: []string{"python3", "-c", "print(1)"},
+		},
+		{
+			name:         "Multi-line script runs inline",
+			plan:         CommandPlan{Shell: "python3", Args: []string{"x = 1\nprint(x + 1)"}},
+			expectedArgs: []string{"python3", "-c", "x = 1\nprint(x + 1)"},
+		},
+		{
+			name:         "Multi-line script for an unknown interpreter uses -c",
+			plan:         CommandPlan{Shell: "zsh", Args: []string{"echo one\necho two"}},
+			expectedArgs: []string{"zsh", "-c", "echo one\necho two"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.AllowedShells = append(config.AllowedShells, WindowsShells()...)
+			config.AllowedShells = append(config.AllowedShells, "python3", "zsh")
+
+			var invoked []string
+			factory := func(ctx context.Context, name string, args ...string) *exec.Cmd {
+				invoked = append([]string{name}, args...)
+
+				return exec.CommandContext(ctx, "true")
+			}
+
+			runner := newTestTaskRunner(t, config).WithCommandFactory(factory)
+			result := runner.Run(tc.plan)
+
+			if result.Status != COMPLETED {
+				t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+			}
+
+			if !reflect.DeepEqual(invoked, tc.expectedArgs) {
+				t.Errorf("Expected command %q, got %q", tc.expectedArgs, invoked)
+			}
+		})
+	}
+}
+
+func TestDefaultAllowedShells(t *testing.T) {
+	tests := []struct {
+		name           string
+		goos           string
+		expectedShells []string
+	}{
+		{
+			name:           "Linux",
+			goos:           "linux",
+			expectedShells: []string{"bash", "sh", "python3", "python", "node", "go"},
+		},
+		{
+			name:           "Darwin",
+			goos:           "darwin",
+			expectedShells: []string{"bash", "sh", "python3", "python", "node", "go"},
+		},
+		{
+			name:           "Windows",
+			goos:           "windows",
+			expectedShells: []string{"powershell", "pwsh", "cmd", "python3", "python", "node", "go"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shells := defaultAllowedShells(tc.goos)
+
+			if !reflect.DeepEqual(shells, tc.expectedShells) {
+				t.Errorf("Expected shells %v, got %v", tc.expectedShells, shells)
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunRedaction(t *testing.T) {
+	t.Setenv("DEPLOY_KEY", "s3cr3t-deploy")
+	t.Setenv("API_TOKEN", "s3cr3t-token")
+
+	tests := []struct {
+		name           string
+		plan           CommandPlan
+		redactEnvVars  []string
+		redactPatterns []*regexp.Regexp
+		secret         string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Configured variable resolved from the environment",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "key=$DEPLOY_KEY"}},
+			redactEnvVars:  []string{"DEPLOY_KEY"},
+			secret:         "s3cr3t-deploy",
+			expectedStdout: "key=***\n",
+		},
+		{
+			name:           "Required variable with a secret name",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$API_TOKEN", ">&2"}, Environment: []string{"API_TOKEN"}},
+			secret:         "s3cr3t-token",
+			expectedStderr: "***\n",
+		},
+		{
+			name:           "Injected value with a secret name",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "$DB_PASSWORD"}, EnvValues: map[string]string{"DB_PASSWORD": "hunter2-pw"}},
+			secret:         "hunter2-pw",
+			expectedStdout: "***\n",
+		},
+		{
+			name:           "Secret split across writes",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printf", "s3cr3t-;", "printf", "'deploy\\n'"}},
+			redactEnvVars:  []string{"DEPLOY_KEY"},
+			secret:         "s3cr3t-deploy",
+			expectedStdout: "***\n",
+		},
+		{
+			name:           "Pattern in the command",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "ghp_abc123"}},
+			redactPatterns: []*regexp.Regexp{regexp.MustCompile("ghp_[A-Za-z0-9]+")},
+			secret:         "ghp_abc123",
+			expectedStdout: "***\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs strings.Builder
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			terminal, err := os.CreateTemp(t.TempDir(), "stdout")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			stdout := os.Stdout
+			os.Stdout = terminal
+			defer func() { os.Stdout = stdout }()
+
+			config := DefaultSecureConfig()
+			config.CaptureOutput = true
+			config.RedactEnvVars = tc.redactEnvVars
+			config.RedactPatterns = tc.redactPatterns
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+			os.Stdout = stdout
+
+			if result.Status != COMPLETED {
+				t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+			}
+
+			if result.Stdout != tc.expectedStdout || result.Stderr != tc.expectedStderr {
+				t.Errorf("Expected output %q and %q, got %q and %q", tc.expectedStdout, tc.expectedStderr, result.Stdout, result.Stderr)
+			}
+
+			streamed, err := os.ReadFile(terminal.Name())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for source, text := range map[string]string{"command": result.Command, "log": logs.String(), "streamed output": string(streamed)} {
+				if strings.Contains(text, tc.secret) {
+					t.Errorf("Expected the secret to be redacted from the %s, got %q", source, text)
+				}
+			}
+
+			if !strings.Contains(logs.String(), "Running command") {
+				t.Errorf("Expected the command to be logged, got %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunOutputHandler(t *testing.T) {
+	type line struct {
+		text     string
+		isStderr bool
+	}
+
+	tests := []struct {
+		name                string
+		plan                CommandPlan
+		suppressPassthrough bool
+		expectedStdout      []string
+		expectedStderr      []string
+	}{
+		{
+			name:           "Lines from both streams",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"echo", "one;", "echo", "two", ">&2;", "echo", "three;", "echo", "four", ">&2"}},
+			expectedStdout: []string{"one", "three"},
+			expectedStderr: []string{"two", "four"},
+		},
+		{
+			name:           "Final line without a newline",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printf", "'first\\nsecond\\n\\nlast'"}},
+			expectedStdout: []string{"first", "second", "", "last"},
+		},
+		{
+			name:                "Suppressed passthrough",
+			plan:                CommandPlan{Shell: "sh", Args: []string{"echo", "quiet;", "echo", "loud", ">&2"}},
+			suppressPassthrough: true,
+			expectedStdout:      []string{"quiet"},
+			expectedStderr:      []string{"loud"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			terminal, err := os.CreateTemp(t.TempDir(), "stdout")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			stdout := os.Stdout
+			os.Stdout = terminal
+			defer func() { os.Stdout = stdout }()
+
+			var lines []line
+			config := DefaultSecureConfig()
+			config.SuppressPassthrough = tc.suppressPassthrough
+			config.OutputHandler = func(plan CommandPlan, text string, isStderr bool) {
+				if !reflect.DeepEqual(plan.Args, tc.plan.Args) {
+					t.Errorf("Expected the handler to receive the plan, got %v", plan.Args)
+				}
+				lines = append(lines, line{text: text, isStderr: isStderr})
+			}
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+			os.Stdout = stdout
+
+			if result.Status != COMPLETED {
+				t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+			}
+
+			var stdoutLines, stderrLines []string
+			for _, l := range lines {
+				if l.isStderr {
+					stderrLines = append(stderrLines, l.text)
+				} else {
+					stdoutLines = append(stdoutLines, l.text)
+				}
+			}
+
+			if !reflect.DeepEqual(stdoutLines, tc.expectedStdout) || !reflect.DeepEqual(stderrLines, tc.expectedStderr) {
+				t.Errorf("Expected stdout lines %q and stderr lines %q, got %q and %q", tc.expectedStdout, tc.expectedStderr, stdoutLines, stderrLines)
+			}
+
+			streamed, err := os.ReadFile(terminal.Name())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tc.suppressPassthrough && len(streamed) > 0 {
+				t.Errorf("Expected no output on the terminal, got %q", streamed)
+			}
+
+			if !tc.suppressPassthrough && len(streamed) == 0 {
+				t.Errorf("Expected output to still reach the terminal")
+			}
+		})
+	}
+}
+
+// recordingLogger keeps the messages logged to it.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (r *recordingLogger) Printf(format string, v ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, fmt.Sprintf(format, v...))
+}
+
+func TestTaskRunner_RunEnvPassthrough(t *testing.T) {
+	t.Setenv("DOYOUCOMPUTE_TEST_SECRET", "leaked")
+	t.Setenv("DOYOUCOMPUTE_TEST_ALLOWED", "allowed")
+	t.Setenv("DOYOUCOMPUTE_TEST_REQUIRED", "required")
+
+	tests := []struct {
+		name           string
+		mode           EnvPassthroughMode
+		plan           CommandPlan
+		expectedStatus TaskStatus
+		visible        []string
+		hidden         []string
+	}{
+		{
+			name:           "All variables are inherited by default",
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printenv"}},
+			expectedStatus: COMPLETED,
+			visible:        []string{"DOYOUCOMPUTE_TEST_SECRET", "DOYOUCOMPUTE_TEST_ALLOWED", "DOYOUCOMPUTE_TEST_REQUIRED", "DOYOUCOMPUTE_TEST_INJECTED"},
+		},
+		{
+			name:           "Allowlist inherits allowed and required variables",
+			mode:           PassthroughAllowlist,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printenv"}, Environment: []string{"DOYOUCOMPUTE_TEST_REQUIRED"}},
+			expectedStatus: COMPLETED,
+			visible:        []string{"DOYOUCOMPUTE_TEST_ALLOWED", "DOYOUCOMPUTE_TEST_REQUIRED", "DOYOUCOMPUTE_TEST_INJECTED"},
+			hidden:         []string{"DOYOUCOMPUTE_TEST_SECRET"},
+		},
+		{
+			name:           "Allowlist applies to every pipeline stage",
+			mode:           PassthroughAllowlist,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printenv | cat"}, Stages: []CommandPlan{{Shell: "sh", Args: []string{"printenv"}}, {Shell: "sh", Args: []string{"cat"}}}},
+			expectedStatus: COMPLETED,
+			visible:        []string{"DOYOUCOMPUTE_TEST_ALLOWED", "DOYOUCOMPUTE_TEST_INJECTED"},
+			hidden:         []string{"DOYOUCOMPUTE_TEST_SECRET", "DOYOUCOMPUTE_TEST_REQUIRED"},
+		},
+		{
+			name:           "None only sees injected variables",
+			mode:           PassthroughNone,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printenv"}},
+			expectedStatus: COMPLETED,
+			visible:        []string{"DOYOUCOMPUTE_TEST_INJECTED"},
+			hidden:         []string{"DOYOUCOMPUTE_TEST_SECRET", "DOYOUCOMPUTE_TEST_ALLOWED", "DOYOUCOMPUTE_TEST_REQUIRED"},
+		},
+		{
+			name:           "None fails commands requiring variables that are not injected",
+			mode:           PassthroughNone,
+			plan:           CommandPlan{Shell: "sh", Args: []string{"printenv"}, Environment: []string{"DOYOUCOMPUTE_TEST_REQUIRED"}},
+			expectedStatus: FAILED,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			config.CaptureOutput = true
+			config.SuppressPassthrough = true
+			config.EnvPassthrough = tc.mode
+			config.AllowedEnvVars = []string{"PATH", "DOYOUCOMPUTE_TEST_ALLOWED"}
+			config.ExtraEnv = map[string]string{"DOYOUCOMPUTE_TEST_INJECTED": "injected", "PATH": os.Getenv("PATH")}
+
+			result := newTestTaskRunner(t, config).Run(tc.plan)
+			if result.Status != tc.expectedStatus {
+				t.Fatalf("Expected status %v, got %v (error: %v)", tc.expectedStatus, result.Status, result.Error)
+			}
+
+			names := map[string]bool{}
+			for _, line := range strings.Split(result.Stdout, "\n") {
+				name, _, _ := strings.Cut(line, "=")
+				names[name] = true
+			}
+
+			for _, name := range tc.visible {
+				if !names[name] {
+					t.Errorf("Expected %s to be visible", name)
+				}
+			}
+
+			for _, name := range tc.hidden {
+				if names[name] {
+					t.Errorf("Expected %s to be hidden", name)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunMultiLineScript(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not installed")
+	}
+
+	document := Document{Name: "MyDoc"}
+	section := document.CreateSection("Script")
+	section.Content = append(section.Content, Executable{Shell: "python3", Cmd: []string{"x = 1", "print(x + 1)"}, JoinWith: "\n"})
+
+	plans, err := NewExecutionRenderer().Render(document)
+	if err != nil {
+		t.Fatalf("Failed to render plans: %v", err)
+	}
+
+	config := DefaultSecureConfig()
+	config.AllowedShells = append(config.AllowedShells, "python3")
+	config.CaptureOutput = true
+	config.SuppressPassthrough = true
+
+	result := newTestTaskRunner(t, config).Run(plans[0])
+	if result.Status != COMPLETED {
+		t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+	}
+
+	if result.Stdout != "2\n" {
+		t.Errorf("Expected stdout %q, got %q", "2\n", result.Stdout)
+	}
+}
+
+func TestTaskRunner_RunSectionOverrides(t *testing.T) {
+	document := Document{Name: "MyDoc"}
+	document.CreateSection("Build").WriteExecutable("sh", []string{"sleep", "5"}, []string{})
+	danger := document.CreateSection("Danger Zone")
+	danger.Override(ConfigOverride{Timeout: 100 * time.Millisecond, AllowedCommands: []string{"sleep"}})
+	danger.WriteExecutable("sh", []string{"sleep", "5"}, []string{})
+	danger.WriteExecutable("sh", []string{"echo", "hello"}, []string{})
+
+	plans, err := NewExecutionRenderer().Render(document)
+	if err != nil {
+		t.Fatalf("Failed to render plans: %v", err)
+	}
+
+	config := DefaultSecureConfig()
+	config.AllowedCommands = []string{"echo"}
+	config.SuppressPassthrough = true
+	runner := newTestTaskRunner(t, config)
+
+	tests := []struct {
+		section        string
+		expectedStatus TaskStatus
+		expectedError  error
+	}{
+		// The base config does not allow sleep outside the overriding section
+		{section: "Build", expectedStatus: FAILED, expectedError: ErrCommandNotAllowed},
+		// The section allows sleep but stops it at its own timeout rather than the base config's
+		{section: "Danger Zone", expectedStatus: TIMEOUT},
+		// The section's allowed commands replace those of the base config
+		{section: "Danger Zone", expectedStatus: FAILED, expectedError: ErrCommandNotAllowed},
+	}
+
+	if len(plans) != len(tests) {
+		t.Fatalf("Expected %d plans, got %d", len(tests), len(plans))
+	}
+
+	for idx, tc := range tests {
+		result := runner.Run(plans[idx])
+		if result.SectionName != tc.section {
+			t.Fatalf("Expected command %d in section '%s', got '%s'", idx, tc.section, result.SectionName)
+		}
+
+		if result.Status != tc.expectedStatus {
+			t.Errorf("Command %d: expected status %s, got %s (%v)", idx, tc.expectedStatus, result.Status, result.Error)
+		}
+
+		if tc.expectedError != nil && !errors.Is(result.Error, tc.expectedError) {
+			t.Errorf("Command %d: expected error %v, got %v", idx, tc.expectedError, result.Error)
+		}
+
+		var timeoutErr *TimeoutError
+		if tc.expectedStatus == TIMEOUT && (!errors.As(result.Error, &timeoutErr) || timeoutErr.Timeout != 100*time.Millisecond) {
+			t.Errorf("Command %d: expected the section timeout of 100ms, got %v", idx, result.Error)
+		}
+	}
+}
+
+func TestTaskRunner_RunLogger(t *testing.T) {
+	tests := []struct {
+		name             string
+		dryRun           bool
+		noLogger         bool
+		expectedMessages []string
+	}{
+		{
+			name:             "Running command",
+			expectedMessages: []string{"[Section: Build] - Running command: 'echo hello'"},
+		},
+		{
+			name:             "Dry run",
+			dryRun:           true,
+			expectedMessages: []string{"[Section: Build] - Dry run, not running command: 'echo hello'"},
+		},
+		{
+			name:     "No logger",
+			noLogger: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var standard strings.Builder
+			log.SetOutput(&standard)
+			defer log.SetOutput(os.Stderr)
+
+			logger := &recordingLogger{}
+			config := DefaultSecureConfig()
+			config.DryRun = tc.dryRun
+			config.Logger = logger
+			if tc.noLogger {
+				config.Logger = nil
+			}
+
+			result := newTestTaskRunner(t, config).Run(CommandPlan{Shell: "sh", Args: []string{"echo", "hello"}, Context: SectionInfo{Name: "Build"}})
+
+			if result.Status != COMPLETED {
+				t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+			}
+
+			if !reflect.DeepEqual(logger.messages, tc.expectedMessages) {
+				t.Errorf("Expected messages %q, got %q", tc.expectedMessages, logger.messages)
+			}
+
+			if standard.Len() > 0 {
+				t.Errorf("Expected nothing to be logged to the standard logger, got %q", standard.String())
+			}
+		})
+	}
+}
+
+func TestTaskStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         TaskStatus
+		expectedString string
+		expectedFailed bool
+	}{
+		{name: "Completed", status: COMPLETED, expectedString: "COMPLETED", expectedFailed: false},
+		{name: "Failed", status: FAILED, expectedString: "FAILED", expectedFailed: true},
+		{name: "Skipped", status: SKIPPED, expectedString: "SKIPPED", expectedFailed: false},
+		{name: "Timeout", status: TIMEOUT, expectedString: "TIMEOUT", expectedFailed: true},
+		{name: "Unknown", status: TaskStatus(42), expectedString: "TaskStatus(42)", expectedFailed: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.status.String() != tc.expectedString {
+				t.Errorf("Expected string %s, got %s", tc.expectedString, tc.status.String())
+			}
+
+			if tc.status.Failed() != tc.expectedFailed {
+				t.Errorf("Expected Failed() to be %v, got %v", tc.expectedFailed, tc.status.Failed())
+			}
+		})
+	}
+}
+
+func TestRunExecutionPlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		plans        []CommandPlan
+		mockResults  []TaskResult
+		errorMessage string
+	}{
+		{
+			name:         "Empty execution plan",
+			plans:        []CommandPlan{},
+			mockResults:  []TaskResult{},
+			errorMessage: "",
+		},
+		{
+			name: "Single command plan",
+			plans: []CommandPlan{
+				{
+					Args:    []string{"echo", "hello"},
+					Context: SectionInfo{Name: "Section1"},
+				},
+			},
+			mockResults: []TaskResult{
+				{
+					SectionName: "Section1",
+					Command:     "echo hello",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Multiple command plans",
+			plans: []CommandPlan{
+				{
+					Args:    []string{"echo", "hello"},
+					Context: SectionInfo{Name: "Section1"},
+				},
+				{
+					Args:    []string{"echo", "world"},
+					Context: SectionInfo{Name: "Section2"},
+				},
+			},
+			mockResults: []TaskResult{
+				{
+					SectionName: "Section1",
+					Command:     "echo hello",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+				{
+					SectionName: "Section2",
+					Command:     "echo world",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Mixed success and failure results",
+			plans: []CommandPlan{
+				{
+					Args:    []string{"echo", "hello"},
+					Context: SectionInfo{Name: "Section1"},
+				},
+				{
+					Args:    []string{"false"},
+					Context: SectionInfo{Name: "Section2"},
+				},
+			},
+			mockResults: []TaskResult{
+				{
+					SectionName: "Section1",
+					Command:     "echo hello",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+				{
+					SectionName: "Section2",
+					Command:     "false",
+					Status:      FAILED,
+					Error:       errors.New("exit status 1"),
+				},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Plans with same section name",
+			plans: []CommandPlan{
+				{
+					Args:    []string{"echo", "first"},
+					Context: SectionInfo{Name: "SameSection"},
+				},
+				{
+					Args:    []string{"echo", "second"},
+					Context: SectionInfo{Name: "SameSection"},
+				},
+			},
+			mockResults: []TaskResult{
+				{
+					SectionName: "SameSection",
+					Command:     "echo first",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+				{
+					SectionName: "SameSection",
+					Command:     "echo second",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+			},
+			errorMessage: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRunner := &MockRunner{
+				results: make([]TaskResult, len(tc.mockResults)),
+			}
+			copy(mockRunner.results, tc.mockResults)
+
+			testTaskRunnerOperation(
+				t,
+				func() ([]TaskResult, error) {
+					return RunExecutionPlan(tc.plans, mockRunner), nil
+				},
+				tc.errorMessage,
+				func(results []TaskResult, t *testing.T) {
+					// Validate result count
+					if len(results) != len(tc.plans) {
+						t.Errorf("Expected %d results, got %d", len(tc.plans), len(results))
+						return
+					}
+
+					// Validate results match expected
+					for i, result := range results {
+						expected := tc.mockResults[i]
+						if result.SectionName != expected.SectionName {
+							t.Errorf("Result %d: Expected section name %s, got %s", i, expected.SectionName, result.SectionName)
+						}
+						if result.Command != expected.Command {
+							t.Errorf("Result %d: Expected command %s, got %s", i, expected.Command, result.Command)
+						}
+						if result.Status != expected.Status {
+							t.Errorf("Result %d: Expected status %v, got %v", i, expected.Status, result.Status)
+						}
+						if (result.Error == nil) != (expected.Error == nil) {
+							t.Errorf("Result %d: Expected error presence %v, got %v", i, expected.Error != nil, result.Error != nil)
+						}
+						if result.Error != nil && expected.Error != nil && result.Error.Error() != expected.Error.Error() {
+							t.Errorf("Result %d: Expected error %s, got %s", i, expected.Error.Error(), result.Error.Error())
+						}
+					}
+
+					// Validate all plans were called
+					if len(mockRunner.calls) != len(tc.plans) {
+						t.Errorf("Expected %d runner calls, got %d", len(tc.plans), len(mockRunner.calls))
+						return
+					}
+
+					// Validate plans were called in order
+					for i, expectedPlan := range tc.plans {
+						if !reflect.DeepEqual(mockRunner.calls[i], expectedPlan) {
+							t.Errorf("Expected plan %d to be %v, got %v", i, expectedPlan, mockRunner.calls[i])
+						}
+					}
+				},
+			)
+		})
+	}
+}
+
+// newTestTaskRunner creates a TaskRunner, failing the test if config is invalid.
+func newTestTaskRunner(t *testing.T, config ExecutionConfig) TaskRunner {
+	t.Helper()
+
+	runner, err := NewTaskRunner(config)
+	if err != nil {
+		t.Fatalf("Failed to create task runner: %v", err)
+	}
+
+	return runner
+}
+
+func TestNewTaskRunner(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       ExecutionConfig
+		errorMessage string
+	}{
+		{
+			name:         "Creates new TaskRunner instance",
+			config:       DefaultSecureConfig(),
+			errorMessage: "",
+		},
+		{
+			name: "Compiles regular expressions",
+			config: ExecutionConfig{
+				BlockedPatternsRegex: []string{`>\s*/dev/sd[a-z]`},
+				AllowedCommandsRegex: []string{`^go( |$)`},
+			},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-InvalidBlockedPattern",
+			config:       ExecutionConfig{BlockedPatternsRegex: []string{"go (run"}},
+			errorMessage: "invalid command pattern in BlockedPatternsRegex: error parsing regexp: missing closing ): `go (run`",
+		},
+		{
+			name:         "Fail-InvalidAllowedPattern",
+			config:       ExecutionConfig{AllowedCommandsRegex: []string{"^go", "[a-"}},
+			errorMessage: "invalid command pattern in AllowedCommandsRegex: error parsing regexp: missing closing ]: `[a-`",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testTaskRunnerOperation(
+				t,
+				func() (TaskRunner, error) {
+					return NewTaskRunner(tc.config)
+				},
+				tc.errorMessage,
+				func(result TaskRunner, t *testing.T) {
+					// Validate it's a valid TaskRunner (interface check)
+					var _ Runner = result
+				},
+			)
+		})
+	}
+}
+
+func TestRunExecutionPlanModes(t *testing.T) {
+	plans := []CommandPlan{
+		{Args: []string{"echo", "setup"}, Context: SectionInfo{Name: "Setup"}},
+		{Args: []string{"false"}, Context: SectionInfo{Name: "Migrate"}},
+		{Args: []string{"echo", "deploy"}, Context: SectionInfo{Name: "Deploy"}},
+		{Args: []string{"echo", "verify"}, Context: SectionInfo{Name: "Verify"}},
+	}
+
+	mockResults := []TaskResult{
+		{SectionName: "Setup", Command: "echo setup", Status: COMPLETED},
+		{SectionName: "Migrate", Command: "false", Status: FAILED, Error: errors.New("exit status 1")},
+		{SectionName: "Deploy", Command: "echo deploy", Status: COMPLETED},
+		{SectionName: "Verify", Command: "echo verify", Status: COMPLETED},
+	}
+
+	tests := []struct {
+		name             string
+		opts             []RunOption
+		expectedCalls    int
+		expectedStatuses []TaskStatus
+	}{
+		{
+			name:             "Defaults to continuing on error",
+			expectedCalls:    4,
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, COMPLETED, COMPLETED},
+		},
+		{
+			name:             "Continue on error",
+			opts:             []RunOption{WithMode(ContinueOnError)},
+			expectedCalls:    4,
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, COMPLETED, COMPLETED},
+		},
+		{
+			name:             "Fail fast",
+			opts:             []RunOption{WithMode(FailFast)},
+			expectedCalls:    2,
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, SKIPPED, SKIPPED},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRunner := &MockRunner{results: append([]TaskResult{}, mockResults...)}
+
+			results := RunExecutionPlan(plans, mockRunner, tc.opts...)
+
+			if len(mockRunner.calls) != tc.expectedCalls {
+				t.Errorf("Expected %d commands to run, got %d", tc.expectedCalls, len(mockRunner.calls))
+			}
+
+			if len(results) != len(tc.expectedStatuses) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expectedStatuses), len(results))
+			}
+
+			for idx, result := range results {
+				if result.Status != tc.expectedStatuses[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v", idx, tc.expectedStatuses[idx], result.Status)
+				}
+
+				if result.Status != SKIPPED {
+					continue
+				}
+
+				if result.SectionName != plans[idx].Context.Name || result.Command != strings.Join(plans[idx].Args, " ") {
+					t.Errorf("Result %d: Expected skipped result for %q in %q, got %q in %q", idx, strings.Join(plans[idx].Args, " "), plans[idx].Context.Name, result.Command, result.SectionName)
+				}
+
+				if !errors.Is(result.Error, ErrEarlierCommandFailed) {
+					t.Errorf("Result %d: Expected error wrapping %v, got %v", idx, ErrEarlierCommandFailed, result.Error)
+				}
+
+				if result.Error.Error() != "an earlier command failed: 'false' in section 'Migrate'" {
+					t.Errorf("Result %d: Unexpected skip reason %q", idx, result.Error)
+				}
+			}
+		})
+	}
+}
+
+// loggingRunner wraps a TaskRunner the way a user of the package might, to do something around
+// every command.
+type loggingRunner struct {
+	TaskRunner
+}
+
+func (l loggingRunner) Run(plan CommandPlan) TaskResult {
+	return l.TaskRunner.Run(plan)
+}
+
+func TestRunExecutionPlanSkippedRedaction(t *testing.T) {
+	t.Setenv("DEPLOY_KEY", "hunter2")
+	t.Setenv("DEPLOY_PASSWORD", "letmein")
+
+	tests := []struct {
+		name            string
+		runner          func(t *testing.T) Runner
+		plan            CommandPlan
+		expectedCommand string
+	}{
+		{
+			name:            "Injected secret",
+			runner:          func(t *testing.T) Runner { return &MockRunner{results: []TaskResult{{Status: FAILED}}} },
+			plan:            CommandPlan{Args: []string{"deploy", "--token", "s3cr3t"}, EnvValues: map[string]string{"API_TOKEN": "s3cr3t"}},
+			expectedCommand: "deploy --token " + RedactedValue,
+		},
+		{
+			name:            "Required secret",
+			runner:          func(t *testing.T) Runner { return &MockRunner{results: []TaskResult{{Status: FAILED}}} },
+			plan:            CommandPlan{Args: []string{"deploy", "--password", "letmein"}, Environment: []string{"DEPLOY_PASSWORD"}},
+			expectedCommand: "deploy --password " + RedactedValue,
+		},
+		{
+			name: "Variable redacted by the runner's config",
+			runner: func(t *testing.T) Runner {
+				config := DefaultSecureConfig()
+				config.RedactEnvVars = []string{"DEPLOY_KEY"}
+				config.SuppressPassthrough = true
+
+				return newTestTaskRunner(t, config)
+			},
+			plan:            CommandPlan{Shell: "sh", Args: []string{"deploy", "--key", "hunter2"}, Environment: []string{"DEPLOY_KEY"}},
+			expectedCommand: "deploy --key " + RedactedValue,
+		},
+		{
+			name: "Variable redacted by the config of a wrapped runner",
+			runner: func(t *testing.T) Runner {
+				config := DefaultSecureConfig()
+				config.RedactEnvVars = []string{"DEPLOY_KEY"}
+				config.SuppressPassthrough = true
+
+				return loggingRunner{TaskRunner: newTestTaskRunner(t, config)}
+			},
+			plan:            CommandPlan{Shell: "sh", Args: []string{"deploy", "--key", "hunter2"}, Environment: []string{"DEPLOY_KEY"}},
+			expectedCommand: "deploy --key " + RedactedValue,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plans := []CommandPlan{
+				{Shell: "sh", Args: []string{"false"}, Context: SectionInfo{Name: "Build"}},
+				tc.plan,
+			}
+
+			results := RunExecutionPlan(plans, tc.runner(t), WithMode(FailFast))
+
+			if results[1].Status != SKIPPED {
+				t.Fatalf("Expected status %v, got %v (error: %v)", SKIPPED, results[1].Status, results[1].Error)
+			}
+
+			if results[1].Command != tc.expectedCommand {
+				t.Errorf("Expected command %q, got %q", tc.expectedCommand, results[1].Command)
+			}
+		})
+	}
+}
+
+func TestRunExecutionPlanSetupTeardown(t *testing.T) {
+	plans := []CommandPlan{
+		{Args: []string{"db", "start"}, Context: SectionInfo{Name: "Database"}, Path: []string{"Doc", "Database"}, Phase: PhaseSetup},
+		{Args: []string{"db", "migrate"}, Context: SectionInfo{Name: "Database"}, Path: []string{"Doc", "Database"}},
+		{Args: []string{"db", "seed"}, Context: SectionInfo{Name: "Database"}, Path: []string{"Doc", "Database"}},
+		{Args: []string{"db", "stop"}, Context: SectionInfo{Name: "Database"}, Path: []string{"Doc", "Database"}, Phase: PhaseTeardown},
+		{Args: []string{"deploy"}, Context: SectionInfo{Name: "Deploy"}, Path: []string{"Doc", "Deploy"}},
+		{Args: []string{"cleanup"}, Context: SectionInfo{Name: "Deploy"}, Path: []string{"Doc", "Deploy"}, Phase: PhaseTeardown},
+	}
+
+	tests := []struct {
+		name             string
+		fail             map[string]bool
+		opts             []RunOption
+		expectedCalls    []string
+		expectedStatuses []TaskStatus
+		expectedReasons  []error
+	}{
+		{
+			name:             "Teardown runs after a failing middle command",
+			fail:             map[string]bool{"db migrate": true},
+			opts:             []RunOption{WithMode(FailFast)},
+			expectedCalls:    []string{"db start", "db migrate", "db stop"},
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, SKIPPED, COMPLETED, SKIPPED, SKIPPED},
+			expectedReasons:  []error{nil, nil, ErrEarlierCommandFailed, nil, ErrEarlierCommandFailed, ErrEarlierCommandFailed},
+		},
+		{
+			name:             "Teardown runs when continuing on error",
+			fail:             map[string]bool{"db migrate": true},
+			expectedCalls:    []string{"db start", "db migrate", "db seed", "db stop", "deploy", "cleanup"},
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, COMPLETED, COMPLETED, COMPLETED, COMPLETED},
+			expectedReasons:  []error{nil, nil, nil, nil, nil, nil},
+		},
+		{
+			name:             "Failed setup skips the rest of its section",
+			fail:             map[string]bool{"db start": true},
+			expectedCalls:    []string{"db start", "deploy", "cleanup"},
+			expectedStatuses: []TaskStatus{FAILED, SKIPPED, SKIPPED, SKIPPED, COMPLETED, COMPLETED},
+			expectedReasons:  []error{nil, ErrSetupFailed, ErrSetupFailed, ErrSetupFailed, nil, nil},
+		},
+		{
+			name:             "Failed setup in fail fast mode",
+			fail:             map[string]bool{"db start": true},
+			opts:             []RunOption{WithMode(FailFast)},
+			expectedCalls:    []string{"db start"},
+			expectedStatuses: []TaskStatus{FAILED, SKIPPED, SKIPPED, SKIPPED, SKIPPED, SKIPPED},
+			expectedReasons:  []error{nil, ErrSetupFailed, ErrSetupFailed, ErrSetupFailed, ErrEarlierCommandFailed, ErrEarlierCommandFailed},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := newTimedRunner(0)
+			runner.fail = tc.fail
+
+			var calls []string
+			hooks := Hooks{AfterCommand: func(plan CommandPlan, result TaskResult) {
+				if result.Status != SKIPPED {
+					calls = append(calls, result.Command)
+				}
+			}}
+
+			results := RunExecutionPlan(plans, runner, append(tc.opts, WithHooks(hooks))...)
+
+			if !reflect.DeepEqual(calls, tc.expectedCalls) {
+				t.Errorf("Expected commands %v to run, got %v", tc.expectedCalls, calls)
+			}
+
+			for idx, result := range results {
+				if result.Status != tc.expectedStatuses[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v", idx, tc.expectedStatuses[idx], result.Status)
+				}
+
+				if tc.expectedReasons[idx] != nil && !errors.Is(result.Error, tc.expectedReasons[idx]) {
+					t.Errorf("Result %d: Expected error wrapping %v, got %v", idx, tc.expectedReasons[idx], result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestRunExecutionPlanSteps(t *testing.T) {
+	plans := []CommandPlan{
+		{Args: []string{"mkdir", "build"}, Context: SectionInfo{Name: "Build"}, Step: 1},
+		{Args: []string{"cmake", ".."}, Context: SectionInfo{Name: "Build"}, Step: 2},
+		{Args: []string{"make"}, Context: SectionInfo{Name: "Build"}, Step: 3},
+		{Args: []string{"mkdir", "docs"}, Context: SectionInfo{Name: "Docs"}, Step: 1},
+		{Args: []string{"make", "docs"}, Context: SectionInfo{Name: "Docs"}, Step: 2},
+		{Args: []string{"echo", "done"}, Context: SectionInfo{Name: "Docs"}},
+	}
+
+	tests := []struct {
+		name             string
+		opts             []RunOption
+		expectedStatuses []TaskStatus
+		expectedReasons  []error
+	}{
+		{
+			name:             "Continue on error skips the rest of the failed block",
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, SKIPPED, COMPLETED, COMPLETED, COMPLETED},
+			expectedReasons:  []error{nil, nil, ErrEarlierStepFailed, nil, nil, nil},
+		},
+		{
+			name:             "Fail fast",
+			opts:             []RunOption{WithMode(FailFast)},
+			expectedStatuses: []TaskStatus{COMPLETED, FAILED, SKIPPED, SKIPPED, SKIPPED, SKIPPED},
+			expectedReasons:  []error{nil, nil, ErrEarlierStepFailed, ErrEarlierCommandFailed, ErrEarlierCommandFailed, ErrEarlierCommandFailed},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := newTimedRunner(0)
+			runner.fail = map[string]bool{"cmake ..": true}
+
+			results := RunExecutionPlan(plans, runner, tc.opts...)
+
+			for idx, result := range results {
+				if result.Status != tc.expectedStatuses[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v", idx, tc.expectedStatuses[idx], result.Status)
+				}
+
+				if tc.expectedReasons[idx] != nil && !errors.Is(result.Error, tc.expectedReasons[idx]) {
+					t.Errorf("Result %d: Expected error wrapping %v, got %v", idx, tc.expectedReasons[idx], result.Error)
+				}
+			}
+
+			if reason := results[2].Error.Error(); reason != "an earlier step failed: 'cmake ..' in section 'Build'" {
+				t.Errorf("Unexpected skip reason %q", reason)
+			}
+		})
+	}
+}
+
+// timedRunner records when each command starts and finishes, sleeping for a delay so that
+// commands running concurrently overlap.
+type timedRunner struct {
+	delay  time.Duration
+	delays map[string]time.Duration
+	fail   map[string]bool
+
+	mu       sync.Mutex
+	active   int
+	peak     int
+	started  map[string]time.Time
+	finished map[string]time.Time
+}
+
+func newTimedRunner(delay time.Duration) *timedRunner {
+	return &timedRunner{delay: delay, started: map[string]time.Time{}, finished: map[string]time.Time{}}
+}
+
+func (r *timedRunner) Run(plan CommandPlan) TaskResult {
+	command := strings.Join(plan.Args, " ")
+
+	r.mu.Lock()
+	r.started[command] = time.Now()
+	r.active++
+	r.peak = max(r.peak, r.active)
+	r.mu.Unlock()
+
+	delay, ok := r.delays[command]
+	if !ok {
+		delay = r.delay
+	}
+	time.Sleep(delay)
+
+	r.mu.Lock()
+	r.finished[command] = time.Now()
+	r.active--
+	r.mu.Unlock()
+
+	status := COMPLETED
+	if r.fail[command] {
+		status = FAILED
+	}
+
+	return TaskResult{SectionName: plan.Context.Name, Command: command, Status: status}
+}
+
+func TestRunExecutionPlanParallel(t *testing.T) {
+	plans := []CommandPlan{
+		{Args: []string{"lint", "1"}, Context: SectionInfo{Name: "Lint"}, Path: []string{"Doc", "Lint"}},
+		{Args: []string{"test", "1"}, Context: SectionInfo{Name: "Test"}, Path: []string{"Doc", "Test"}},
+		{Args: []string{"lint", "2"}, Context: SectionInfo{Name: "Lint"}, Path: []string{"Doc", "Lint"}},
+		{Args: []string{"test", "2"}, Context: SectionInfo{Name: "Unit"}, Path: []string{"Doc", "Test", "Unit"}},
+		{Args: []string{"build", "1"}, Context: SectionInfo{Name: "Build"}, Path: []string{"Doc", "Build"}},
+	}
+
+	tests := []struct {
+		name           string
+		concurrency    int
+		fail           map[string]bool
+		delays         map[string]time.Duration
+		opts           []RunOption
+		expectedPeak   int
+		expectedStatus []TaskStatus
+	}{
+		{
+			name:           "Runs sections concurrently",
+			concurrency:    3,
+			expectedPeak:   3,
+			expectedStatus: []TaskStatus{COMPLETED, COMPLETED, COMPLETED, COMPLETED, COMPLETED},
+		},
+		{
+			name:           "Respects the concurrency limit",
+			concurrency:    2,
+			expectedPeak:   2,
+			expectedStatus: []TaskStatus{COMPLETED, COMPLETED, COMPLETED, COMPLETED, COMPLETED},
+		},
+		{
+			name:           "Runs sequentially with a concurrency of one",
+			concurrency:    1,
+			expectedPeak:   1,
+			expectedStatus: []TaskStatus{COMPLETED, COMPLETED, COMPLETED, COMPLETED, COMPLETED},
+		},
+		{
+			name:           "Treats invalid concurrency as one",
+			concurrency:    0,
+			expectedPeak:   1,
+			expectedStatus: []TaskStatus{COMPLETED, COMPLETED, COMPLETED, COMPLETED, COMPLETED},
+		},
+		{
+			name:           "Fail fast skips the rest of a failing section",
+			concurrency:    3,
+			fail:           map[string]bool{"lint 1": true},
+			delays:         map[string]time.Duration{"lint 1": time.Millisecond},
+			opts:           []RunOption{WithMode(FailFast)},
+			expectedPeak:   3,
+			expectedStatus: []TaskStatus{FAILED, COMPLETED, SKIPPED, SKIPPED, COMPLETED},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := newTimedRunner(20 * time.Millisecond)
+			runner.fail = tc.fail
+			runner.delays = tc.delays
+
+			results := RunExecutionPlanParallel(plans, runner, tc.concurrency, tc.opts...)
+
+			if runner.peak != tc.expectedPeak {
+				t.Errorf("Expected at most %d commands running at once, got %d", tc.expectedPeak, runner.peak)
+			}
+
+			if len(results) != len(plans) {
+				t.Fatalf("Expected %d results, got %d", len(plans), len(results))
+			}
+
+			for idx, result := range results {
+				if expected := strings.Join(plans[idx].Args, " "); result.Command != expected {
+					t.Errorf("Result %d: Expected command %q, got %q", idx, expected, result.Command)
+				}
+
+				if result.Status != tc.expectedStatus[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v", idx, tc.expectedStatus[idx], result.Status)
+				}
+			}
+
+			// Commands within a top-level section run in plan order, one after another
+			for _, pair := range [][2]string{{"lint 1", "lint 2"}, {"test 1", "test 2"}} {
+				first, firstRan := runner.finished[pair[0]]
+				second, secondRan := runner.started[pair[1]]
+				if firstRan && secondRan && second.Before(first) {
+					t.Errorf("Expected %q to start after %q finished", pair[1], pair[0])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRunner_Buffered(t *testing.T) {
+	config := DefaultSecureConfig()
+	config.CaptureOutput = true
+
+	runner, ok := newTestTaskRunner(t, config).Buffered().(TaskRunner)
+	if !ok {
+		t.Fatalf("Expected Buffered to return a TaskRunner")
+	}
+
+	if !runner.config.BufferOutput {
+		t.Errorf("Expected buffered runner to have BufferOutput enabled")
+	}
+
+	result := runner.Run(CommandPlan{Shell: "sh", Args: []string{"echo", "out;", "echo", "err", ">&2"}})
+
+	if result.Status != COMPLETED {
+		t.Fatalf("Expected status %v, got %v (error: %v)", COMPLETED, result.Status, result.Error)
+	}
+
+	if result.Stdout != "out\n" || result.Stderr != "err\n" {
+		t.Errorf("Expected captured output %q and %q, got %q and %q", "out\n", "err\n", result.Stdout, result.Stderr)
+	}
+}
+
+func TestRunExecutionPlanContextCancellation(t *testing.T) {
+	plans := []CommandPlan{
+		{Shell: "sh", Args: []string{"echo", "one"}, Context: SectionInfo{Name: "First"}},
+		{Shell: "sh", Args: []string{"sleep", "5"}, Context: SectionInfo{Name: "Second"}},
+		{Shell: "sh", Args: []string{"echo", "three"}, Context: SectionInfo{Name: "Third"}},
+	}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context) []TaskResult
+	}{
+		{
+			name: "Sequential",
+			run: func(ctx context.Context) []TaskResult {
+				return RunExecutionPlanContext(ctx, plans, newTestTaskRunner(t, DefaultSecureConfig()))
+			},
+		},
+		{
+			name: "Parallel",
+			run: func(ctx context.Context) []TaskResult {
+				grouped := []CommandPlan{plans[0], plans[1], plans[2]}
+				for idx := range grouped {
+					grouped[idx].Path = []string{"Doc", "Runbook"}
+				}
+
+				return RunExecutionPlanParallelContext(ctx, grouped, newTestTaskRunner(t, DefaultSecureConfig()), 2)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			time.AfterFunc(300*time.Millisecond, cancel)
+
+			start := time.Now()
+			results := tc.run(ctx)
+
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Expected cancellation to stop the run early, took %s", elapsed)
+			}
+
+			expected := []TaskStatus{COMPLETED, FAILED, SKIPPED}
+			for idx, result := range results {
+				if result.Status != expected[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v (error: %v)", idx, expected[idx], result.Status, result.Error)
+				}
+
+				if idx > 0 && !errors.Is(result.Error, ErrRunCancelled) {
+					t.Errorf("Result %d: Expected error wrapping %v, got %v", idx, ErrRunCancelled, result.Error)
+				}
+
+				if idx > 0 && !errors.Is(result.Error, context.Canceled) {
+					t.Errorf("Result %d: Expected error wrapping %v, got %v", idx, context.Canceled, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskRunner_RunContextDeadline(t *testing.T) {
+	sink := &recordingSink{}
+
+	config := DefaultSecureConfig()
+	config.SuppressPassthrough = true
+	config.AuditSink = sink
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := newTestTaskRunner(t, config).RunContext(ctx, CommandPlan{Shell: "sh", Args: []string{"sleep", "5"}, Context: SectionInfo{Name: "Slow"}})
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the deadline to stop the command early, took %s", elapsed)
+	}
+
+	if result.Status != TIMEOUT {
+		t.Errorf("Expected status %v, got %v (error: %v)", TIMEOUT, result.Status, result.Error)
+	}
+
+	if result.ExitCode != ExitCodeTimeout {
+		t.Errorf("Expected exit code %d, got %d", ExitCodeTimeout, result.ExitCode)
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(result.Error, &timeoutErr) || !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Errorf("Expected a timeout error wrapping %v, got %v", context.DeadlineExceeded, result.Error)
+	} else if timeoutErr.Timeout <= 0 || timeoutErr.Timeout > 200*time.Millisecond {
+		t.Errorf("Expected the timeout of the deadline, got %s", timeoutErr.Timeout)
+	}
+
+	if errors.Is(result.Error, ErrRunCancelled) {
+		t.Errorf("Expected a deadline not to be reported as a cancellation, got %v", result.Error)
+	}
+
+	decisions := make([]AuditDecision, len(sink.events))
+	for idx, event := range sink.events {
+		decisions[idx] = event.Decision
+	}
+
+	if !slices.Contains(decisions, AuditTimedOut) {
+		t.Errorf("Expected a %v audit event, got %v", AuditTimedOut, decisions)
+	}
+}
+
+func TestAdaptRunner(t *testing.T) {
+	tests := []struct {
+		name           string
+		cancelled      bool
+		expectedStatus TaskStatus
+		expectedCalls  int
+	}{
+		{
+			name:           "Runs while the context is active",
+			expectedStatus: COMPLETED,
+			expectedCalls:  1,
+		},
+		{
+			name:           "Skips once the context is cancelled",
+			cancelled:      true,
+			expectedStatus: SKIPPED,
+			expectedCalls:  0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancelled {
+				cancel()
+			}
+			defer cancel()
+
+			mockRunner := &MockRunner{}
+			result := AdaptRunner(mockRunner).RunContext(ctx, CommandPlan{Args: []string{"echo", "hi"}, Context: SectionInfo{Name: "Greet"}})
+
+			if result.Status != tc.expectedStatus {
+				t.Errorf("Expected status %v, got %v", tc.expectedStatus, result.Status)
+			}
+
+			if len(mockRunner.calls) != tc.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tc.expectedCalls, len(mockRunner.calls))
+			}
+		})
+	}
+
+	taskRunner := newTestTaskRunner(t, DefaultSecureConfig())
+	if _, ok := AdaptRunner(taskRunner).(TaskRunner); !ok {
+		t.Errorf("Expected AdaptRunner to return runners implementing RunnerContext as-is")
+	}
+}
+
+func TestRunExecutionPlanHooks(t *testing.T) {
+	plans := []CommandPlan{
+		{Args: []string{"echo", "one"}, Context: SectionInfo{Name: "First"}},
+		{Args: []string{"false"}, Context: SectionInfo{Name: "Second"}},
+		{Args: []string{"echo", "three"}, Context: SectionInfo{Name: "Third"}},
+	}
+
+	mockResults := []TaskResult{
+		{SectionName: "First", Command: "echo one", Status: COMPLETED},
+		{SectionName: "Second", Command: "false", Status: FAILED, Error: errors.New("exit status 1")},
+		{SectionName: "Third", Command: "echo three", Status: COMPLETED},
+	}
+
+	tests := []struct {
+		name          string
+		opts          []RunOption
+		panicking     bool
+		expectedCalls []string
+	}{
+		{
+			name: "Called around every command",
+			expectedCalls: []string{
+				"before echo one", "after echo one COMPLETED",
+				"before false", "after false FAILED",
+				"before echo three", "after echo three COMPLETED",
+			},
+		},
+		{
+			name: "Called for skipped commands",
+			opts: []RunOption{WithMode(FailFast)},
+			expectedCalls: []string{
+				"before echo one", "after echo one COMPLETED",
+				"before false", "after false FAILED",
+				"before echo three", "after echo three SKIPPED",
+			},
+		},
+		{
+			name:      "Panics do not abort the run",
+			panicking: true,
+			expectedCalls: []string{
+				"before echo one", "after echo one COMPLETED",
+				"before false", "after false FAILED",
+				"before echo three", "after echo three COMPLETED",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+
+			hooks := Hooks{
+				BeforeCommand: func(plan CommandPlan) {
+					calls = append(calls, "before "+strings.Join(plan.Args, " "))
+					if tc.panicking {
+						panic("before hook failed")
+					}
+				},
+				AfterCommand: func(plan CommandPlan, result TaskResult) {
+					calls = append(calls, fmt.Sprintf("after %s %s", strings.Join(plan.Args, " "), result.Status))
+					if result.Command != strings.Join(plan.Args, " ") {
+						t.Errorf("Expected result for %q, got %q", strings.Join(plan.Args, " "), result.Command)
+					}
+					if tc.panicking {
+						panic("after hook failed")
+					}
+				},
+			}
+
+			logger := &recordingLogger{}
+			mockRunner := &MockRunner{results: append([]TaskResult{}, mockResults...)}
+			results := RunExecutionPlan(plans, mockRunner, append(tc.opts, WithHooks(hooks), WithLogger(logger))...)
+
+			if len(results) != len(plans) {
+				t.Errorf("Expected %d results, got %d", len(plans), len(results))
+			}
+
+			if !reflect.DeepEqual(calls, tc.expectedCalls) {
+				t.Errorf("Expected hook calls %v, got %v", tc.expectedCalls, calls)
+			}
+
+			expectedWarnings := 0
+			if tc.panicking {
+				expectedWarnings = 2 * len(plans)
+			}
+
+			if len(logger.messages) != expectedWarnings {
+				t.Errorf("Expected %d warnings, got %q", expectedWarnings, logger.messages)
+			}
+
+			if tc.panicking && !strings.Contains(logger.messages[0], "Warning: BeforeCommand hook panicked: before hook failed") {
+				t.Errorf("Unexpected warning %q", logger.messages[0])
+			}
 		})
 	}
 }
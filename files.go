@@ -1,6 +1,12 @@
 package doyoucompute
 
-import "io"
+import (
+	"context"
+	"io"
+)
+
+// writeChunkSize is the number of bytes WriteFileContext writes between cancellation checks.
+const writeChunkSize = 32 * 1024
 
 // LoadFile reads all content from the provided io.Reader and returns it as a string.
 // This utility function abstracts file reading operations and can work with any
@@ -21,3 +27,24 @@ func WriteFile(writer io.Writer, content string) error {
 	_, err := writer.Write([]byte(content))
 	return err
 }
+
+// WriteFileContext writes the provided content string to the given io.Writer in chunks,
+// checking the context between chunks so that large writes can be cancelled part way.
+func WriteFileContext(ctx context.Context, writer io.Writer, content string) error {
+	data := []byte(content)
+
+	for len(data) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunk := data[:min(len(data), writeChunkSize)]
+		if _, err := writer.Write(chunk); err != nil {
+			return err
+		}
+
+		data = data[len(chunk):]
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidFrontmatter is returned when frontmatter does not match a FrontmatterSchema.
+var ErrInvalidFrontmatter = errors.New("invalid frontmatter")
+
+// SetString sets key to a string value and returns the frontmatter for chaining.
+func (f *Frontmatter) SetString(key, value string) *Frontmatter {
+	return f.set(key, value)
+}
+
+// SetStringList sets key to a list of strings and returns the frontmatter for chaining.
+func (f *Frontmatter) SetStringList(key string, values ...string) *Frontmatter {
+	return f.set(key, slices.Clone(values))
+}
+
+// SetBool sets key to a boolean value and returns the frontmatter for chaining.
+func (f *Frontmatter) SetBool(key string, value bool) *Frontmatter {
+	return f.set(key, value)
+}
+
+// set stores value under key, recording the key's position the first time it is set.
+func (f *Frontmatter) set(key string, value interface{}) *Frontmatter {
+	if f.Data == nil {
+		f.Data = make(map[string]interface{})
+	}
+
+	if !slices.Contains(f.order, key) {
+		f.order = append(f.order, key)
+	}
+
+	f.Data[key] = value
+
+	return f
+}
+
+// MarshalYAML encodes the keys set through the Set methods first, in the order they were first
+// set, followed by any other keys of Data in sorted order.
+func (f Frontmatter) MarshalYAML() (interface{}, error) {
+	if len(f.order) == 0 {
+		return f.Data, nil
+	}
+
+	keys := make([]string, 0, len(f.Data))
+	for _, key := range f.order {
+		if _, ok := f.Data[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	rest := make([]string, 0, len(f.Data)-len(keys))
+	for key := range f.Data {
+		if !slices.Contains(keys, key) {
+			rest = append(rest, key)
+		}
+	}
+	slices.Sort(rest)
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range append(keys, rest...) {
+		var value yaml.Node
+		if err := value.Encode(f.Data[key]); err != nil {
+			return nil, fmt.Errorf("encoding frontmatter key '%s': %w", key, err)
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+	}
+
+	return node, nil
+}
+
+// FrontmatterKind is the type of value a frontmatter key holds.
+type FrontmatterKind int
+
+const (
+	// FrontmatterString is a single string
+	FrontmatterString FrontmatterKind = iota + 1
+	// FrontmatterStringList is a list of strings
+	FrontmatterStringList
+	// FrontmatterStringOrList is a single string or a list of strings, such as GitHub's labels
+	FrontmatterStringOrList
+	// FrontmatterBool is a boolean
+	FrontmatterBool
+)
+
+// String returns the name of the kind.
+func (f FrontmatterKind) String() string {
+	switch f {
+	case FrontmatterString:
+		return "string"
+	case FrontmatterStringList:
+		return "string list"
+	case FrontmatterStringOrList:
+		return "string or string list"
+	case FrontmatterBool:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// matches reports whether value is of the kind.
+func (f FrontmatterKind) matches(value interface{}) bool {
+	switch f {
+	case FrontmatterString:
+		_, ok := value.(string)
+		return ok
+	case FrontmatterStringList:
+		return isStringList(value)
+	case FrontmatterStringOrList:
+		_, ok := value.(string)
+		return ok || isStringList(value)
+	case FrontmatterBool:
+		_, ok := value.(bool)
+		return ok
+	default:
+		return false
+	}
+}
+
+// isStringList reports whether value is a list holding only strings, either built with
+// SetStringList or decoded from YAML.
+func isStringList(value interface{}) bool {
+	switch list := value.(type) {
+	case []string:
+		return true
+	case []interface{}:
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// FrontmatterField describes a key of a FrontmatterSchema.
+type FrontmatterField struct {
+	// Kind is the type of value the key must hold
+	Kind FrontmatterKind
+	// Required marks keys that must be set
+	Required bool
+}
+
+// FrontmatterSchema lists the keys frontmatter may hold, checked by Frontmatter.Validate.
+type FrontmatterSchema struct {
+	// Name describes what the frontmatter is for in errors, such as "GitHub issue template"
+	Name string
+	// Fields maps each known key to its description
+	Fields map[string]FrontmatterField
+	// AllowUnknown accepts keys missing from Fields. When false they are reported, which
+	// catches typos such as "assignes"
+	AllowUnknown bool
+}
+
+// GitHubIssueTemplateSchema returns the schema of the frontmatter of a GitHub markdown issue
+// template, which requires a name and an about text.
+func GitHubIssueTemplateSchema() FrontmatterSchema {
+	return FrontmatterSchema{
+		Name: "GitHub issue template",
+		Fields: map[string]FrontmatterField{
+			"name":      {Kind: FrontmatterString, Required: true},
+			"about":     {Kind: FrontmatterString, Required: true},
+			"title":     {Kind: FrontmatterString},
+			"labels":    {Kind: FrontmatterStringOrList},
+			"assignees": {Kind: FrontmatterStringOrList},
+			"projects":  {Kind: FrontmatterStringOrList},
+			"type":      {Kind: FrontmatterString},
+		},
+	}
+}
+
+// Validate checks the frontmatter against schema. Returns an error wrapping
+// ErrInvalidFrontmatter that lists every missing required key, key holding the wrong type of
+// value, and unknown key, or nil if there are none.
+func (f Frontmatter) Validate(schema FrontmatterSchema) error {
+	problems := []string{}
+
+	for _, key := range slices.Sorted(maps.Keys(schema.Fields)) {
+		field := schema.Fields[key]
+
+		value, ok := f.Data[key]
+		if !ok {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("missing required key '%s'", key))
+			}
+			continue
+		}
+
+		if !field.Kind.matches(value) {
+			problems = append(problems, fmt.Sprintf("key '%s' must be a %s, got %T", key, field.Kind, value))
+		}
+	}
+
+	if !schema.AllowUnknown {
+		for _, key := range slices.Sorted(maps.Keys(f.Data)) {
+			if _, ok := schema.Fields[key]; !ok {
+				problems = append(problems, fmt.Sprintf("unknown key '%s'", key))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	name := schema.Name
+	if name == "" {
+		name = "schema"
+	}
+
+	return fmt.Errorf("%w for %s: %s", ErrInvalidFrontmatter, name, strings.Join(problems, "; "))
+}
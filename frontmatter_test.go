@@ -0,0 +1,160 @@
+package doyoucompute
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrontmatterSetters(t *testing.T) {
+	frontmatter := Frontmatter{}
+	frontmatter.
+		SetString("name", "Bug report").
+		SetStringList("labels", "bug", "triage").
+		SetBool("draft", false).
+		SetString("name", "Bug")
+
+	expected := map[string]interface{}{"name": "Bug", "labels": []string{"bug", "triage"}, "draft": false}
+	if !reflect.DeepEqual(frontmatter.Data, expected) {
+		t.Errorf("Expected data %v, got %v", expected, frontmatter.Data)
+	}
+
+	// Keys set directly on Data follow the ordered keys, sorted
+	frontmatter.Data["about"] = "Report a bug"
+
+	document := MustNewDocument("MyDoc")
+	if err := document.AddFrontmatter(frontmatter); err != nil {
+		t.Fatalf("unexpected error adding frontmatter: %s", err.Error())
+	}
+
+	content := renderMarkdown(t, &document)
+	expectedFrontmatter := "---\nname: Bug\nlabels:\n    - bug\n    - triage\ndraft: false\nabout: Report a bug\n\n---\n"
+	if !strings.HasPrefix(content, expectedFrontmatter) {
+		t.Errorf("Expected content to start with %q, got %q", expectedFrontmatter, content)
+	}
+}
+
+func TestFrontmatterValidate(t *testing.T) {
+	schema := FrontmatterSchema{
+		Name: "test",
+		Fields: map[string]FrontmatterField{
+			"name":   {Kind: FrontmatterString, Required: true},
+			"tags":   {Kind: FrontmatterStringList},
+			"labels": {Kind: FrontmatterStringOrList},
+			"draft":  {Kind: FrontmatterBool},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		data         map[string]interface{}
+		schema       FrontmatterSchema
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			data:         map[string]interface{}{"name": "x", "tags": []string{"a"}, "labels": "bug", "draft": true},
+			schema:       schema,
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-DecodedList",
+			data:         map[string]interface{}{"name": "x", "tags": []interface{}{"a", "b"}, "labels": []interface{}{"bug"}},
+			schema:       schema,
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-AllowUnknown",
+			data:         map[string]interface{}{"name": "x", "extra": 1},
+			schema:       FrontmatterSchema{Fields: schema.Fields, AllowUnknown: true},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-MissingRequired",
+			data:         map[string]interface{}{"draft": false},
+			schema:       schema,
+			errorMessage: "invalid frontmatter for test: missing required key 'name'",
+		},
+		{
+			name:         "Fail-WrongTypes",
+			data:         map[string]interface{}{"name": 3, "tags": []interface{}{"a", 1}, "labels": true, "draft": "yes"},
+			schema:       schema,
+			errorMessage: "invalid frontmatter for test: key 'draft' must be a bool, got string; key 'labels' must be a string or string list, got bool; key 'name' must be a string, got int; key 'tags' must be a string list, got []interface {}",
+		},
+		{
+			name:         "Fail-UnknownKey",
+			data:         map[string]interface{}{"name": "x", "draf": true},
+			schema:       schema,
+			errorMessage: "invalid frontmatter for test: unknown key 'draf'",
+		},
+		{
+			name:         "Fail-UnnamedSchema",
+			data:         map[string]interface{}{},
+			schema:       FrontmatterSchema{Fields: schema.Fields},
+			errorMessage: "invalid frontmatter for schema: missing required key 'name'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Frontmatter{Data: tc.data}.Validate(tc.schema)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" && !errors.Is(err, ErrInvalidFrontmatter) {
+				t.Errorf("Expected error to wrap ErrInvalidFrontmatter, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGitHubIssueTemplateSchema(t *testing.T) {
+	tests := []struct {
+		name         string
+		frontmatter  func(f *Frontmatter)
+		errorMessage string
+	}{
+		{
+			name: "Passing",
+			frontmatter: func(f *Frontmatter) {
+				f.SetString("name", "Bug report").
+					SetString("about", "Report a bug").
+					SetString("title", "").
+					SetStringList("labels", "bug").
+					SetString("assignees", "")
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Fail-MissingAbout",
+			frontmatter: func(f *Frontmatter) {
+				f.SetString("name", "Bug report")
+			},
+			errorMessage: "invalid frontmatter for GitHub issue template: missing required key 'about'",
+		},
+		{
+			name: "Fail-Typo",
+			frontmatter: func(f *Frontmatter) {
+				f.SetString("name", "Bug report").
+					SetString("about", "Report a bug").
+					SetString("assignes", "octocat")
+			},
+			errorMessage: "invalid frontmatter for GitHub issue template: unknown key 'assignes'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			frontmatter := Frontmatter{}
+			tc.frontmatter(&frontmatter)
+
+			document := MustNewDocument("MyDoc")
+			err := document.AddFrontmatter(frontmatter, GitHubIssueTemplateSchema())
+
+			checkErrors(tc.errorMessage, err, t)
+			if hasFrontmatter := document.HasFrontmatter(); hasFrontmatter != (tc.errorMessage == "") {
+				t.Errorf("Expected frontmatter to be set only without an error, set: %t", hasFrontmatter)
+			}
+		})
+	}
+}
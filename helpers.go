@@ -56,16 +56,17 @@ type SectionBuilder func(s *Section) error
 // Example:
 //
 //	func AddIntroSection(d *Document) error {
-//	    intro := NewSection("Introduction")
+//	    intro := MustNewSection("Introduction")
 //	    intro.NewParagraph().Text("Welcome to the project")
-//	    d.AddSection(intro)
+//	    d.AddSection(&intro)
 //	    return nil
 //	}
 type DocumentApplier func(d *Document) error
 
 // SectionFactory creates a new Section with the given name and applies all provided
 // builders to populate it with content. Builders are applied in order, and if any
-// builder returns an error, the factory stops and returns that error.
+// builder returns an error, the factory stops and returns that error. Returns an error if
+// the name is invalid, as NewSection does.
 //
 // This is useful for creating reusable section templates with default content.
 //
@@ -82,7 +83,10 @@ type DocumentApplier func(d *Document) error
 //	    },
 //	)
 func SectionFactory(name string, contentFuncs ...SectionBuilder) (Section, error) {
-	s := NewSection(name)
+	s, err := NewSection(name)
+	if err != nil {
+		return Section{}, err
+	}
 
 	for _, cFunc := range contentFuncs {
 		if err := cFunc(&s); err != nil {
@@ -107,9 +111,9 @@ func SectionFactory(name string, contentFuncs ...SectionBuilder) (Section, error
 //	        return nil
 //	    },
 //	    func(d *Document) error {
-//	        section := NewSection("Setup")
+//	        section := MustNewSection("Setup")
 //	        section.WriteCodeBlock("bash", []string{"make install"}, true)
-//	        d.AddSection(section)
+//	        d.AddSection(&section)
 //	        return nil
 //	    },
 //	)
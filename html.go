@@ -0,0 +1,508 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTML implements the Renderer interface to convert document nodes into an HTML fragment.
+// Sections become headings with anchor ids matching the markdown slugs, and all text and
+// attribute values are escaped. Raw HTML blocks are passed through unchanged.
+type HTML struct {
+	unknownNodes UnknownNodePolicy
+
+	// anchors holds the headings of the tree being rendered, and nextAnchor indexes the
+	// next heading to be written, so heading ids follow render order
+	anchors    []sectionAnchor
+	nextAnchor *int
+}
+
+// NewHTMLRenderer creates a new HTML renderer instance configured by the provided options.
+// Returns an error if any option is invalid.
+func NewHTMLRenderer(opts ...OptionBuilder[HTML]) (HTML, error) {
+	renderer := HTML{
+		unknownNodes: UnknownNodesError,
+	}
+
+	if err := ApplyOptions(&renderer, opts...); err != nil {
+		return HTML{}, err
+	}
+
+	return renderer, nil
+}
+
+// WithHTMLUnknownNodes sets how the HTML renderer handles node types it has no handler for.
+// Defaults to UnknownNodesError. Text rendered for unknown nodes is escaped.
+func WithHTMLUnknownNodes(policy UnknownNodePolicy) OptionBuilder[HTML] {
+	return func(h *HTML) (Finalizer[HTML], error) {
+		if policy < UnknownNodesError || policy > UnknownNodesText {
+			return nil, fmt.Errorf("invalid unknown node policy: %d", policy)
+		}
+
+		h.unknownNodes = policy
+
+		return nil, nil
+	}
+}
+
+// headingID returns the anchor for the next heading written, keeping ids in step with
+// the slugs computed for cross references.
+func (h HTML) headingID() string {
+	if h.nextAnchor == nil || *h.nextAnchor >= len(h.anchors) {
+		return ""
+	}
+
+	anchor := h.anchors[*h.nextAnchor]
+	*h.nextAnchor++
+
+	return anchor.Slug
+}
+
+func (h HTML) writeHeader(builder *strings.Builder, content string, level int) {
+	// Don't exceed an H6
+	level = min(max(level, 1), 6)
+
+	if id := h.headingID(); id != "" {
+		fmt.Fprintf(builder, "<h%d id=\"%s\">%s</h%d>", level, html.EscapeString(id), html.EscapeString(content), level)
+		return
+	}
+
+	fmt.Fprintf(builder, "<h%d>%s</h%d>", level, html.EscapeString(content), level)
+}
+
+func (h HTML) renderChildren(children []Node, contextPath *ContextPath) ([]string, error) {
+	results := make([]string, 0, len(children))
+
+	for _, leaf := range children {
+		leafContent, err := h.renderWithTracking(leaf, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, leafContent)
+	}
+
+	return results, nil
+}
+
+func (h HTML) renderHeadedStructure(s Structurer, contextPath *ContextPath) (string, error) {
+	var builder strings.Builder
+
+	ctxPath := contextPath.Push(s.Identifier())
+	contextPath = &ctxPath
+
+	h.writeHeader(&builder, s.Identifier(), ctxPath.CurrentLevel())
+
+	childContent, err := h.renderChildren(s.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, child := range childContent {
+		builder.WriteString("\n")
+		builder.WriteString(child)
+	}
+
+	return builder.String(), nil
+}
+
+// renderParagraph joins the paragraph's items with single spaces, replacing the space
+// with a <br> where the paragraph has a line break.
+func (h HTML) renderParagraph(p Structurer, contextPath *ContextPath) (string, error) {
+	var builder strings.Builder
+	pendingBreak := false
+
+	for _, item := range p.Children() {
+		if item.Type() == LineBreakType {
+			pendingBreak = builder.Len() > 0
+			continue
+		}
+
+		content, err := h.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if builder.Len() > 0 {
+			if pendingBreak {
+				builder.WriteString("<br>\n")
+			} else {
+				builder.WriteString(" ")
+			}
+		}
+		pendingBreak = false
+
+		builder.WriteString(content)
+	}
+
+	if builder.Len() == 0 {
+		return "", errSkipNode
+	}
+
+	return "<p>" + builder.String() + "</p>", nil
+}
+
+// renderList writes each item as an <li>. Nested lists are placed inside the preceding item,
+// as HTML requires.
+func (h HTML) renderList(l *List, contextPath *ContextPath) (string, error) {
+	tag := "ul"
+	if l.TypeOfList == NUMBERED {
+		tag = "ol"
+	}
+
+	items := make([]string, 0, len(l.Items))
+
+	for idx, item := range l.Items {
+		content, err := h.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if item.Type() == ListType && len(items) > 0 {
+			items[len(items)-1] += "\n" + content
+			continue
+		}
+
+		if l.TypeOfList == TASK {
+			checkbox := `<input type="checkbox" disabled>`
+			if l.IsChecked(idx) {
+				checkbox = `<input type="checkbox" checked disabled>`
+			}
+			content = checkbox + " " + content
+		}
+
+		items = append(items, content)
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("<" + tag + ">\n")
+	for _, item := range items {
+		builder.WriteString("<li>" + item + "</li>\n")
+	}
+	builder.WriteString("</" + tag + ">")
+
+	return builder.String(), nil
+}
+
+func (h HTML) renderTable(t *Table, contextPath *ContextPath) (string, error) {
+	var builder strings.Builder
+
+	builder.WriteString("<table>\n<thead>\n<tr>")
+	for _, header := range t.Headers {
+		builder.WriteString("<th>" + html.EscapeString(header) + "</th>")
+	}
+	builder.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	for _, row := range t.Items {
+		cells := make([]string, 0, max(len(row.Values), len(row.Cells), len(t.Headers)))
+
+		if row.Rich() {
+			for _, cell := range row.Cells {
+				content, err := h.renderWithTracking(cell, contextPath)
+				if errors.Is(err, errSkipNode) {
+					content, err = "", nil
+				}
+				if err != nil {
+					return "", err
+				}
+
+				cells = append(cells, content)
+			}
+		} else {
+			for _, value := range row.Values {
+				cells = append(cells, html.EscapeString(value))
+			}
+		}
+
+		for len(cells) < len(t.Headers) {
+			cells = append(cells, "")
+		}
+
+		builder.WriteString("<tr>")
+		for _, cell := range cells {
+			builder.WriteString("<td>" + cell + "</td>")
+		}
+		builder.WriteString("</tr>\n")
+	}
+
+	builder.WriteString("</tbody>\n</table>")
+
+	return builder.String(), nil
+}
+
+func (h HTML) renderCollapsible(c Structurer, contextPath *ContextPath) (string, error) {
+	childContent, err := h.renderChildren(c.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("<details>\n<summary>" + html.EscapeString(c.Identifier()) + "</summary>\n")
+	for _, child := range childContent {
+		builder.WriteString(child)
+		builder.WriteString("\n")
+	}
+	builder.WriteString("</details>")
+
+	return builder.String(), nil
+}
+
+func (h HTML) renderStructureNode(structureNode Structurer, contextPath *ContextPath) (string, error) {
+	switch structureNode.Type() {
+	case DocumentType, SectionType:
+		return h.renderHeadedStructure(structureNode, contextPath)
+	case ParagraphType:
+		return h.renderParagraph(structureNode, contextPath)
+	case ListType:
+		if list, ok := structureNode.(List); ok {
+			return h.renderList(&list, contextPath)
+		}
+		return h.renderList(structureNode.(*List), contextPath)
+	case TableType:
+		if table, ok := structureNode.(Table); ok {
+			return h.renderTable(&table, contextPath)
+		}
+		return h.renderTable(structureNode.(*Table), contextPath)
+	case CollapsibleType:
+		return h.renderCollapsible(structureNode, contextPath)
+	case FrontmatterType: // metadata for markdown tooling, with no HTML equivalent
+		return "", errSkipNode
+	}
+
+	return h.renderUnknown(structureNode, contextPath, errors.New("unhandled structure node type"))
+}
+
+// renderUnknown is the shared fallback for content and structure nodes without a handler.
+func (h HTML) renderUnknown(node Node, contextPath *ContextPath, unhandled error) (string, error) {
+	structure, ok := node.(Structurer)
+	if !ok || h.unknownNodes != UnknownNodesText {
+		content, err := h.unknownNodes.resolve(node, unhandled)
+		return html.EscapeString(content), err
+	}
+
+	childContent, err := h.renderChildren(structure.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(childContent) == 0 {
+		return "", errSkipNode
+	}
+
+	return strings.Join(childContent, "\n"), nil
+}
+
+// renderBlockofCode writes content as preformatted code, tagging it with the language
+// so syntax highlighters can pick it up.
+func (h HTML) renderBlockofCode(typeHint string, content string) string {
+	if typeHint == "" {
+		return "<pre><code>" + html.EscapeString(content) + "</code></pre>"
+	}
+
+	return fmt.Sprintf("<pre><code class=\"language-%s\">%s</code></pre>", html.EscapeString(typeHint), html.EscapeString(content))
+}
+
+// renderParagraphs splits text on blank lines into <p> elements.
+func (h HTML) renderParagraphs(content string) string {
+	blocks := strings.Split(strings.Trim(content, "\n"), "\n\n")
+
+	paragraphs := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		paragraphs = append(paragraphs, "<p>"+html.EscapeString(block)+"</p>")
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+func (h HTML) renderTableOfContents(content MaterializedContent, contextPath *ContextPath) (string, error) {
+	maxDepth, ok := content.Metadata["MaxDepth"].(int)
+	if !ok {
+		return "", errors.New("metadata key 'MaxDepth' not found or not an int")
+	}
+
+	root := NewList(BULLET)
+	lists := []*List{root}
+
+	for _, anchor := range h.anchors {
+		depth := anchor.Level - 1
+		if anchor.Standalone || depth < 1 || (maxDepth > 0 && depth > maxDepth) {
+			continue
+		}
+
+		for len(lists) > depth {
+			lists = lists[:len(lists)-1]
+		}
+		for len(lists) < depth {
+			lists = append(lists, lists[len(lists)-1].CreateList(BULLET))
+		}
+
+		lists[len(lists)-1].AppendNode(Link{Text: anchor.Heading, Url: "#" + anchor.Slug})
+	}
+
+	if len(root.Items) == 0 {
+		return "", errSkipNode
+	}
+
+	return h.renderList(root, contextPath)
+}
+
+func (h HTML) renderContent(contentNode Contenter, contextPath *ContextPath) (string, error) {
+	content, err := contentNode.Materialize()
+	if err != nil {
+		return "", err
+	}
+
+	text := html.EscapeString(content.Content)
+
+	switch contentNode.Type() {
+	case HeaderType:
+		var builder strings.Builder
+		h.writeHeader(&builder, content.Content, contextPath.CurrentLevel())
+		return builder.String(), nil
+	case TextType:
+		return text, nil
+	case BoldType:
+		return "<strong>" + text + "</strong>", nil
+	case ItalicType:
+		return "<em>" + text + "</em>", nil
+	case StrikethroughType:
+		return "<del>" + text + "</del>", nil
+	case CodeType:
+		return "<code>" + text + "</code>", nil
+	case MathInlineType:
+		return `<code class="language-math">` + text + "</code>", nil
+	case LinkType:
+		url, err := getStringFromMetadata(content.Metadata, "Url")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(url), text), nil
+	case CrossRefType:
+		target, err := getStringFromMetadata(content.Metadata, "Target")
+		if err != nil {
+			return "", err
+		}
+		for _, anchor := range h.anchors {
+			if anchor.Name == target {
+				return fmt.Sprintf("<a href=\"#%s\">%s</a>", html.EscapeString(anchor.Slug), text), nil
+			}
+		}
+		return "", fmt.Errorf("cross reference to section '%s': section not found in document", target)
+	case ImageType:
+		url, err := getStringFromMetadata(content.Metadata, "Url")
+		if err != nil {
+			return "", err
+		}
+		title, _ := getStringFromMetadata(content.Metadata, "Title")
+		if title != "" {
+			return fmt.Sprintf("<img src=\"%s\" alt=\"%s\" title=\"%s\">", html.EscapeString(url), text, html.EscapeString(title)), nil
+		}
+		return fmt.Sprintf("<img src=\"%s\" alt=\"%s\">", html.EscapeString(url), text), nil
+	case BadgeType:
+		url, err := getStringFromMetadata(content.Metadata, "Url")
+		if err != nil {
+			return "", err
+		}
+		imageUrl, err := getStringFromMetadata(content.Metadata, "ImageUrl")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("<a href=\"%s\"><img src=\"%s\" alt=\"%s\"></a>", html.EscapeString(url), html.EscapeString(imageUrl), text), nil
+	case CodeBlockType, MermaidType, MathBlockType:
+		blockType, err := getStringFromMetadata(content.Metadata, "BlockType")
+		if err != nil {
+			return "", err
+		}
+		return h.renderBlockofCode(blockType, content.Content), nil
+	case ExecutableType, PipelineType:
+		shell, err := getStringFromMetadata(content.Metadata, "Shell")
+		if err != nil {
+			return "", err
+		}
+		return h.renderBlockofCode(shell, content.Content), nil
+	case BlockQuoteType:
+		return "<blockquote>\n" + h.renderParagraphs(content.Content) + "\n</blockquote>", nil
+	case AdmonitionType:
+		kind, err := getStringFromMetadata(content.Metadata, "Kind")
+		if err != nil {
+			return "", err
+		}
+		label := strings.ToUpper(kind[:1]) + strings.ToLower(kind[1:])
+		return fmt.Sprintf("<blockquote class=\"admonition %s\">\n<p><strong>%s</strong></p>\n%s\n</blockquote>", strings.ToLower(kind), label, h.renderParagraphs(content.Content)), nil
+	case HTMLBlockType:
+		return strings.Trim(content.Content, "\n"), nil
+	case RemoteType:
+		return h.renderParagraphs(content.Content), nil
+	case TableRowType:
+		items, err := getStringsFromMetadata(content.Metadata, "Items")
+		if err != nil {
+			return "", err
+		}
+		cells := make([]string, len(items))
+		for idx, item := range items {
+			cells[idx] = "<td>" + html.EscapeString(item) + "</td>"
+		}
+		return "<tr>" + strings.Join(cells, "") + "</tr>", nil
+	case CommentType:
+		// "--" cannot appear inside an HTML comment
+		return fmt.Sprintf("<!-- %s -->", strings.ReplaceAll(content.Content, "--", "- -")), nil
+	case TableOfContentsType:
+		return h.renderTableOfContents(content, contextPath)
+	case LineBreakType: // only meaningful inside paragraphs, which handle breaks themselves
+		return "", errSkipNode
+	}
+
+	return h.renderUnknown(contentNode, contextPath, errors.New("unknown content node type"))
+}
+
+func (h HTML) renderWithTracking(node Node, contextPath *ContextPath) (string, error) {
+	switch node.Type() {
+	case DocumentType, SectionType, ParagraphType, ListType, TableType, FrontmatterType, CollapsibleType:
+		return h.renderStructureNode(node.(Structurer), contextPath)
+	}
+
+	if contentNode, ok := node.(Contenter); ok {
+		return h.renderContent(contentNode, contextPath)
+	}
+
+	if structureNode, ok := node.(Structurer); ok {
+		return h.renderStructureNode(structureNode, contextPath)
+	}
+
+	return h.renderUnknown(node, contextPath, errors.New("unknown content node type"))
+}
+
+// Stamp appends the GeneratedMarker to rendered HTML as a comment.
+func (h HTML) Stamp(content string) string {
+	return fmt.Sprintf("%s<!-- %s -->\n", content, GeneratedMarker)
+}
+
+// Render converts a node into an HTML fragment, starting with an empty context path.
+// This is the main entry point for the Renderer interface implementation.
+func (h HTML) Render(node Node) (string, error) {
+	h.anchors = collectAnchors(node, false)
+	h.nextAnchor = new(int)
+
+	content, err := h.renderWithTracking(node, &ContextPath{})
+	if errors.Is(err, errSkipNode) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return content + "\n", nil
+}
@@ -0,0 +1,179 @@
+package doyoucompute
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLRender(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     func() Document
+		errorMessage string
+		expected     string
+	}{
+		{
+			name: "Passing",
+			document: func() Document {
+				document := Document{Name: "Guide"}
+				document.WriteIntro().Text("Welcome to").Bold("Guide").LineBreak().Link("home", "https://example.com")
+				document.WriteTableOfContents(0)
+
+				install := document.CreateSection("Install")
+				install.WriteCodeBlock("sh", []string{"go", "install"}, Exec)
+				tasks := install.CreateList(TASK)
+				tasks.AppendTask("done", true)
+				tasks.AppendTask("todo", false)
+				steps := install.CreateList(BULLET)
+				steps.Append("a")
+				steps.CreateList(NUMBERED).Append("b")
+				install.WriteBlockQuoteLines("q1", "", "q2")
+				install.WriteComment("hidden")
+				table := install.CreateTable([]string{"Name", "Value"})
+				table.AddRow("x")
+
+				return document
+			},
+			expected: strings.Join([]string{
+				`<h1 id="guide">Guide</h1>`,
+				`<p>Welcome to <strong>Guide</strong><br>`,
+				`<a href="https://example.com">home</a></p>`,
+				`<ul>`,
+				`<li><a href="#install">Install</a></li>`,
+				`</ul>`,
+				`<h2 id="install">Install</h2>`,
+				`<pre><code class="language-sh">go install</code></pre>`,
+				`<ul>`,
+				`<li><input type="checkbox" checked disabled> done</li>`,
+				`<li><input type="checkbox" disabled> todo</li>`,
+				`</ul>`,
+				`<ul>`,
+				`<li>a`,
+				`<ol>`,
+				`<li>b</li>`,
+				`</ol></li>`,
+				`</ul>`,
+				`<blockquote>`,
+				`<p>q1</p>`,
+				`<p>q2</p>`,
+				`</blockquote>`,
+				`<!-- hidden -->`,
+				`<table>`,
+				`<thead>`,
+				`<tr><th>Name</th><th>Value</th></tr>`,
+				`</thead>`,
+				`<tbody>`,
+				`<tr><td>x</td><td></td></tr>`,
+				`</tbody>`,
+				`</table>`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "Passing-DeepHeadingsAndCollapsible",
+			document: func() Document {
+				document := Document{Name: "Deep"}
+				section := document.CreateSection("Two")
+				for _, name := range []string{"Three", "Four", "Five", "Six", "Seven"} {
+					section = section.CreateSection(name)
+				}
+				section.CreateCollapsible("More").WriteParagraph().Text("Hidden.")
+
+				return document
+			},
+			expected: strings.Join([]string{
+				`<h1 id="deep">Deep</h1>`,
+				`<h2 id="two">Two</h2>`,
+				`<h3 id="three">Three</h3>`,
+				`<h4 id="four">Four</h4>`,
+				`<h5 id="five">Five</h5>`,
+				`<h6 id="six">Six</h6>`,
+				`<h6 id="seven">Seven</h6>`,
+				`<details>`,
+				`<summary>More</summary>`,
+				`<p>Hidden.</p>`,
+				`</details>`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "Failing-UnknownNode",
+			document: func() Document {
+				return Document{Name: "Widgets", Content: []Node{widget("gear")}}
+			},
+			errorMessage: "unknown content node type",
+		},
+	}
+
+	renderer, err := NewHTMLRenderer()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+			content, err := renderer.Render(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestHTMLRenderEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     Node
+		expected string
+	}{
+		{
+			name:     "Passing-Text",
+			node:     NewParagraph().Text(`1 < 2 & "quoted" 'single'`),
+			expected: "<p>1 &lt; 2 &amp; &#34;quoted&#34; &#39;single&#39;</p>\n",
+		},
+		{
+			name:     "Passing-LinkAttributes",
+			node:     NewParagraph().Link("<b>", `https://example.com/?a=1&b="2"`),
+			expected: "<p><a href=\"https://example.com/?a=1&amp;b=&#34;2&#34;\">&lt;b&gt;</a></p>\n",
+		},
+		{
+			name:     "Passing-ImageAttributes",
+			node:     &Paragraph{Items: []Node{Image{AltText: `a "quote"`, Url: "https://example.com/x.png?s=1&t=2", Title: "<title>"}}},
+			expected: "<p><img src=\"https://example.com/x.png?s=1&amp;t=2\" alt=\"a &#34;quote&#34;\" title=\"&lt;title&gt;\"></p>\n",
+		},
+		{
+			name:     "Passing-CodeBlock",
+			node:     CodeBlock{BlockType: `sh"`, Cmd: []string{"echo", "<tag>", "&&", "true"}},
+			expected: "<pre><code class=\"language-sh&#34;\">echo &lt;tag&gt; &amp;&amp; true</code></pre>\n",
+		},
+		{
+			name:     "Passing-SectionHeading",
+			node:     &Section{Name: "Q&A <FAQ>", Content: []Node{}},
+			expected: "<h1 id=\"qa-faq\">Q&amp;A &lt;FAQ&gt;</h1>\n",
+		},
+		{
+			name:     "Passing-TableCells",
+			node:     &Table{Headers: []string{"<k>"}, Items: []TableRow{{Values: []string{"a & b"}}}},
+			expected: "<table>\n<thead>\n<tr><th>&lt;k&gt;</th></tr>\n</thead>\n<tbody>\n<tr><td>a &amp; b</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "Passing-RawHTMLBlock",
+			node:     HTMLBlock("<div class=\"note\">kept</div>"),
+			expected: "<div class=\"note\">kept</div>\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := HTML{}.Render(tc.node)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormFieldType is the kind of input a section becomes in a GitHub issue form.
+type FormFieldType int
+
+const (
+	// FormTextarea is a multi-line text field
+	FormTextarea FormFieldType = iota + 1
+	// FormInput is a single-line text field
+	FormInput
+	// FormDropdown lets the reporter pick from the items of the section's list
+	FormDropdown
+	// FormCheckboxes shows a checkbox for each item of the section's list
+	FormCheckboxes
+)
+
+// String returns the name GitHub uses for the field type.
+func (f FormFieldType) String() string {
+	switch f {
+	case FormTextarea:
+		return "textarea"
+	case FormInput:
+		return "input"
+	case FormDropdown:
+		return "dropdown"
+	case FormCheckboxes:
+		return "checkboxes"
+	default:
+		return "unknown"
+	}
+}
+
+// FormField configures the input a section becomes in a GitHub issue form. The section name
+// is the field's label, and its comments and other content are the field's description.
+type FormField struct {
+	// Type is the kind of input
+	Type FormFieldType
+	// Required makes the field mandatory. For checkboxes every box must be ticked
+	Required bool
+	// ID identifies the field in the issue form (empty means a slug of the section name)
+	ID string
+	// Placeholder is shown in empty text fields
+	Placeholder string
+	// Value pre-fills text fields
+	Value string
+	// Render formats a textarea's content as a code block of this language, such as "shell"
+	Render string
+	// Multiple lets the reporter pick more than one option of a dropdown
+	Multiple bool
+}
+
+// AsFormField makes the section an input of a GitHub issue form and returns the field so it
+// can be configured further, such as by setting a placeholder.
+func (s *Section) AsFormField(fieldType FormFieldType, required bool) *FormField {
+	s.Field = &FormField{Type: fieldType, Required: required}
+
+	return s.Field
+}
+
+// IssueForm implements the Renderer interface to convert a document into a GitHub issue form,
+// the YAML format of files such as .github/ISSUE_TEMPLATE/bug.yml. Sections marked with
+// AsFormField become form fields, while other content becomes markdown shown between them.
+// Comments are left out, except in form fields where they describe the field. The form's
+// name, description, title, labels, assignees, projects, and type are read from the
+// document's frontmatter; "about" is used when "description" is missing, and the document
+// name when "name" is missing.
+type IssueForm struct{}
+
+// NewIssueFormRenderer creates a new IssueForm renderer instance.
+func NewIssueFormRenderer() IssueForm {
+	return IssueForm{}
+}
+
+// issueFormFile is the top level of a GitHub issue form.
+type issueFormFile struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Title       string             `yaml:"title,omitempty"`
+	Labels      interface{}        `yaml:"labels,omitempty"`
+	Assignees   interface{}        `yaml:"assignees,omitempty"`
+	Projects    interface{}        `yaml:"projects,omitempty"`
+	Type        string             `yaml:"type,omitempty"`
+	Body        []issueFormElement `yaml:"body"`
+}
+
+// issueFormElement is an element of the body of a GitHub issue form.
+type issueFormElement struct {
+	Type        string                `yaml:"type"`
+	ID          string                `yaml:"id,omitempty"`
+	Attributes  issueFormAttributes   `yaml:"attributes"`
+	Validations *issueFormValidations `yaml:"validations,omitempty"`
+}
+
+type issueFormAttributes struct {
+	Label       string      `yaml:"label,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Placeholder string      `yaml:"placeholder,omitempty"`
+	Value       string      `yaml:"value,omitempty"`
+	Render      string      `yaml:"render,omitempty"`
+	Multiple    bool        `yaml:"multiple,omitempty"`
+	Options     interface{} `yaml:"options,omitempty"`
+}
+
+type issueFormCheckbox struct {
+	Label    string `yaml:"label"`
+	Required bool   `yaml:"required,omitempty"`
+}
+
+type issueFormValidations struct {
+	Required bool `yaml:"required"`
+}
+
+// issueFormBuilder collects the elements of a form, buffering markdown until the next field.
+type issueFormBuilder struct {
+	markdown Markdown
+	elements []issueFormElement
+	pending  []string
+	ids      slugger
+	fields   int
+}
+
+func (b *issueFormBuilder) addMarkdown(content string) {
+	b.pending = append(b.pending, content)
+}
+
+func (b *issueFormBuilder) flush() {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	b.elements = append(b.elements, issueFormElement{
+		Type:       "markdown",
+		Attributes: issueFormAttributes{Value: strings.Join(b.pending, "\n\n")},
+	})
+	b.pending = nil
+}
+
+func (i IssueForm) renderChildren(children []Node, contextPath *ContextPath, builder *issueFormBuilder) error {
+	for _, child := range children {
+		if err := i.renderNode(child, contextPath, builder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i IssueForm) renderNode(node Node, contextPath *ContextPath, builder *issueFormBuilder) error {
+	switch node.Type() {
+	case SectionType:
+		section, ok := nodeAs[Section](node)
+		if !ok {
+			return fmt.Errorf("unsupported section node %T", node)
+		}
+
+		ctxPath := contextPath.Push(section.Name)
+		if section.Field != nil {
+			return wrapRenderError(i.renderField(section, &ctxPath, builder), ctxPath)
+		}
+
+		builder.addMarkdown(fmt.Sprintf("%s %s", strings.Repeat("#", min(ctxPath.CurrentLevel(), 6)), section.Name))
+
+		return i.renderChildren(section.Content, &ctxPath, builder)
+	case CommentType:
+		return nil
+	}
+
+	content, err := builder.markdown.renderWithTracking(node, contextPath)
+	if errors.Is(err, errSkipNode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	builder.addMarkdown(strings.TrimRight(content, "\n"))
+
+	return nil
+}
+
+// renderField adds the form field of section, using its lists as the options of dropdowns
+// and checkboxes and the rest of its content as the description.
+func (i IssueForm) renderField(section Section, contextPath *ContextPath, builder *issueFormBuilder) error {
+	field := *section.Field
+	if field.Type < FormTextarea || field.Type > FormCheckboxes {
+		return fmt.Errorf("invalid form field type: %d", field.Type)
+	}
+
+	hasOptions := field.Type == FormDropdown || field.Type == FormCheckboxes
+
+	var description, options []string
+	for _, child := range section.Content {
+		switch {
+		case child.Type() == SectionType:
+			return errors.New("form fields cannot have subsections")
+		case child.Type() == CommentType:
+			content, err := child.(Contenter).Materialize()
+			if err != nil {
+				return err
+			}
+			description = append(description, strings.TrimSpace(content.Content))
+		case child.Type() == ListType && hasOptions:
+			for _, item := range child.(Structurer).Children() {
+				option, err := builder.markdown.renderWithTracking(item, contextPath)
+				if err != nil {
+					return err
+				}
+				options = append(options, strings.TrimSpace(option))
+			}
+		default:
+			content, err := builder.markdown.renderWithTracking(child, contextPath)
+			if errors.Is(err, errSkipNode) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			description = append(description, strings.TrimRight(content, "\n"))
+		}
+	}
+
+	if hasOptions && len(options) == 0 {
+		return fmt.Errorf("%s field needs a list of options", field.Type)
+	}
+
+	id := field.ID
+	if id == "" {
+		id = builder.ids.slug(section.Name)
+	} else if _, taken := builder.ids[id]; taken {
+		return fmt.Errorf("form field id '%s' is used more than once", id)
+	} else {
+		builder.ids[id] = 0
+	}
+
+	element := issueFormElement{
+		Type: field.Type.String(),
+		ID:   id,
+		Attributes: issueFormAttributes{
+			Label:       section.Name,
+			Description: strings.Join(description, "\n\n"),
+		},
+	}
+
+	switch field.Type {
+	case FormTextarea:
+		element.Attributes.Placeholder = field.Placeholder
+		element.Attributes.Value = field.Value
+		element.Attributes.Render = field.Render
+	case FormInput:
+		element.Attributes.Placeholder = field.Placeholder
+		element.Attributes.Value = field.Value
+	case FormDropdown:
+		element.Attributes.Multiple = field.Multiple
+		element.Attributes.Options = options
+	case FormCheckboxes:
+		checkboxes := make([]issueFormCheckbox, len(options))
+		for idx, option := range options {
+			checkboxes[idx] = issueFormCheckbox{Label: option, Required: field.Required}
+		}
+		element.Attributes.Options = checkboxes
+	}
+
+	// Checkboxes are required one by one rather than as a whole
+	if field.Required && field.Type != FormCheckboxes {
+		element.Validations = &issueFormValidations{Required: true}
+	}
+
+	builder.flush()
+	builder.elements = append(builder.elements, element)
+	builder.fields++
+
+	return nil
+}
+
+// frontmatterString returns the string value of the first key set in data.
+func frontmatterString(data map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := data[key].(string); ok && value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// frontmatterList returns the value of key if it is a non-empty string or list, and nil otherwise.
+func frontmatterList(data map[string]interface{}, key string) interface{} {
+	switch value := data[key].(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return value
+	case []string, []interface{}:
+		if isStringList(value) && !isEmptyList(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func isEmptyList(value interface{}) bool {
+	switch list := value.(type) {
+	case []string:
+		return len(list) == 0
+	case []interface{}:
+		return len(list) == 0
+	default:
+		return true
+	}
+}
+
+// Render converts a document into GitHub issue form YAML. Returns an error if the node is not
+// a document, the form has no description or no fields, or a field is invalid.
+// This is the main entry point for the Renderer interface implementation.
+func (i IssueForm) Render(node Node) (string, error) {
+	var document *Document
+	switch doc := node.(type) {
+	case *Document:
+		document = doc
+	case Document:
+		document = &doc
+	default:
+		return "", fmt.Errorf("issue forms can only be rendered from a document, got %s", node.Type())
+	}
+
+	data := document.Frontmatter.Data
+	form := issueFormFile{
+		Name:        frontmatterString(data, "name"),
+		Description: frontmatterString(data, "description", "about"),
+		Title:       frontmatterString(data, "title"),
+		Labels:      frontmatterList(data, "labels"),
+		Assignees:   frontmatterList(data, "assignees"),
+		Projects:    frontmatterList(data, "projects"),
+		Type:        frontmatterString(data, "type"),
+	}
+	if form.Name == "" {
+		form.Name = document.Name
+	}
+	if form.Description == "" {
+		return "", errors.New("issue form needs a description: set 'description' in the document's frontmatter")
+	}
+
+	builder := issueFormBuilder{
+		markdown: Markdown{unknownNodes: UnknownNodesError, anchors: collectAnchors(node, false)},
+		ids:      slugger{},
+	}
+
+	contextPath := ContextPath{}.Push(document.Name)
+	if err := i.renderChildren(document.Content, &contextPath, &builder); err != nil {
+		return "", err
+	}
+	builder.flush()
+
+	if builder.fields == 0 {
+		return "", errors.New("issue form needs at least one form field: mark a section with AsFormField")
+	}
+	form.Body = builder.elements
+
+	var content strings.Builder
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(form); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	return content.String(), nil
+}
+
+// Stamp appends the GeneratedMarker to the form as a YAML comment.
+func (i IssueForm) Stamp(content string) string {
+	return fmt.Sprintf("%s# %s\n", content, GeneratedMarker)
+}
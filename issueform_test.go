@@ -0,0 +1,203 @@
+package doyoucompute
+
+import (
+	"os"
+	"testing"
+)
+
+// newBugReportForm returns a bug report built to be rendered as a GitHub issue form.
+func newBugReportForm() Document {
+	document := MustNewDocument("Bug Report")
+
+	frontmatter := Frontmatter{}
+	frontmatter.
+		SetString("name", "Bug report").
+		SetString("description", "File a bug report").
+		SetString("title", "[Bug]: ").
+		SetStringList("labels", "bug", "triage")
+	document.AddFrontmatter(frontmatter)
+
+	document.WriteIntro().Text("Thanks for taking the time to fill out this bug report!")
+
+	happened := document.CreateSection("What happened?")
+	happened.WriteComment("Also tell us, what did you expect to happen?")
+	happened.AsFormField(FormTextarea, true).Placeholder = "Tell us what you see!"
+
+	version := document.CreateSection("Version")
+	version.WriteComment("What version are you running?")
+	version.AsFormField(FormInput, false).Placeholder = "v1.0.0"
+
+	system := document.CreateSection("Operating system")
+	system.WriteComment("Where did it happen?")
+	systems := system.CreateList(BULLET)
+	systems.Append("Linux")
+	systems.Append("macOS")
+	systems.Append("Windows")
+	system.AsFormField(FormDropdown, true).Multiple = true
+
+	logs := document.CreateSection("Relevant log output")
+	logs.WriteParagraph().Text("Copy and paste any relevant log output.").Code("--verbose").Text("helps.")
+	logs.AsFormField(FormTextarea, false).Render = "shell"
+
+	notes := document.CreateSection("Before you submit")
+	notes.WriteComment("Not shown in the form")
+	notes.WriteParagraph().Text("Search the existing issues first.")
+
+	conduct := document.CreateSection("Code of Conduct")
+	conduct.WriteComment("By submitting this issue, you agree to follow our Code of Conduct.")
+	conduct.CreateList(TASK).AppendTask("I agree to follow this project's Code of Conduct", false)
+	conduct.AsFormField(FormCheckboxes, true).ID = "terms"
+
+	return document
+}
+
+func TestIssueFormGolden(t *testing.T) {
+	expected, err := os.ReadFile("testdocs/bug_report_form.yml")
+	if err != nil {
+		t.Fatalf("unexpected error reading golden file: %s", err.Error())
+	}
+
+	repo := NewFakeFileRepo()
+	svc := NewService(repo, &MockRunner{}, NewIssueFormRenderer(), NewExecutionRenderer())
+
+	document := newBugReportForm()
+	if err := svc.RenderFile(&document, ".github/ISSUE_TEMPLATE/bug.yml"); err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	content := repo.files[".github/ISSUE_TEMPLATE/bug.yml"]
+	if content != string(expected) {
+		t.Errorf("Expected content %q, got %q", string(expected), content)
+	}
+}
+
+func TestIssueFormRender(t *testing.T) {
+	tests := []struct {
+		name         string
+		node         func() Node
+		expected     string
+		errorMessage string
+	}{
+		{
+			name: "Passing-FallsBackToAboutAndDocumentName",
+			node: func() Node {
+				document := MustNewDocument("Feature Request")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"about": "Suggest an idea", "labels": ""}})
+				document.CreateSection("Idea").AsFormField(FormTextarea, false)
+				return &document
+			},
+			expected: "name: Feature Request\ndescription: Suggest an idea\nbody:\n  - type: textarea\n    id: idea\n    attributes:\n      label: Idea\n",
+		},
+		{
+			name: "Passing-FieldInsidePlainSection",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				details := document.CreateSection("Details")
+				details.CreateSection("Steps").AsFormField(FormTextarea, true)
+				details.CreateSection("Steps").AsFormField(FormInput, false)
+				return &document
+			},
+			expected: "name: Form\ndescription: A form\nbody:\n  - type: markdown\n    attributes:\n      value: '## Details'\n  - type: textarea\n    id: steps\n    attributes:\n      label: Steps\n    validations:\n      required: true\n  - type: input\n    id: steps-1\n    attributes:\n      label: Steps\n",
+		},
+		{
+			name: "Failing-NotADocument",
+			node: func() Node {
+				section := MustNewSection("Idea")
+				return &section
+			},
+			errorMessage: "issue forms can only be rendered from a document, got Section",
+		},
+		{
+			name: "Failing-NoFields",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				document.CreateSection("Idea").WriteParagraph().Text("Tell us.")
+				return &document
+			},
+			errorMessage: "issue form needs at least one form field: mark a section with AsFormField",
+		},
+		{
+			name: "Failing-DropdownWithoutOptions",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				document.CreateSection("Browser").AsFormField(FormDropdown, true)
+				return &document
+			},
+			errorMessage: `rendering "Form > Browser": dropdown field needs a list of options`,
+		},
+		{
+			name: "Failing-FieldWithSubsections",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				steps := document.CreateSection("Steps")
+				steps.CreateSection("First")
+				steps.AsFormField(FormTextarea, true)
+				return &document
+			},
+			errorMessage: `rendering "Form > Steps": form fields cannot have subsections`,
+		},
+		{
+			name: "Failing-DuplicateID",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				document.CreateSection("First").AsFormField(FormInput, false).ID = "name"
+				document.CreateSection("Second").AsFormField(FormInput, false).ID = "name"
+				return &document
+			},
+			errorMessage: `rendering "Form > Second": form field id 'name' is used more than once`,
+		},
+		{
+			name: "Failing-InvalidType",
+			node: func() Node {
+				document := MustNewDocument("Form")
+				document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+				document.CreateSection("Idea").AsFormField(FormFieldType(9), false)
+				return &document
+			},
+			errorMessage: `rendering "Form > Idea": invalid form field type: 9`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := NewIssueFormRenderer().Render(tc.node())
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestIssueFormSectionRef(t *testing.T) {
+	shared := MustNewDocument("Shared")
+	version := shared.CreateSection("Version")
+	version.WriteComment("What version are you running?")
+	version.AsFormField(FormInput, true).Placeholder = "v1.0.0"
+
+	base := NewService(NewFakeFileRepo(), &MockRunner{}, NewIssueFormRenderer(), NewExecutionRenderer())
+	svc, err := base.With(WithDocumentResolver(DocumentRegistry{"Shared": shared}))
+	if err != nil {
+		t.Fatalf("unexpected error configuring service: %s", err.Error())
+	}
+
+	document := MustNewDocument("Form")
+	document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"description": "A form"}})
+	document.AddSectionRef("Shared", "Version")
+
+	content, err := svc.RenderString(&document)
+	if err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	expected := "name: Form\ndescription: A form\nbody:\n  - type: input\n    id: version\n    attributes:\n      label: Version\n      description: What version are you running?\n      placeholder: v1.0.0\n    validations:\n      required: true\n# " + GeneratedMarker + "\n"
+	if content != expected {
+		t.Errorf("Expected content %q, got %q", expected, content)
+	}
+}
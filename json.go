@@ -0,0 +1,156 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONNode is the serialized form of a node produced by the JSON renderer. Tools consuming
+// the output can unmarshal it into this type.
+type JSONNode struct {
+	// Type is the node's ContentType name, such as "Section" or "CodeBlock"
+	Type string `json:"type"`
+	// Identifier is the name of sections, documents, and collapsibles
+	Identifier string `json:"identifier,omitempty"`
+	// Content is the materialized content of content nodes
+	Content string `json:"content,omitempty"`
+	// Metadata holds the materialized metadata of content nodes and the settings of structures
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Children holds the nested nodes of structures and the cells of rich table rows
+	Children []JSONNode `json:"children,omitempty"`
+}
+
+// JSON implements the Renderer interface to serialize the node tree for other tools.
+// Every content node is materialized exactly once, and the output is deterministic because
+// metadata keys are written in sorted order.
+type JSON struct{}
+
+// NewJSONRenderer creates a new JSON renderer instance.
+func NewJSONRenderer() JSON {
+	return JSON{}
+}
+
+func (j JSON) renderChildren(children []Node) ([]JSONNode, error) {
+	nodes := make([]JSONNode, 0, len(children))
+
+	for _, child := range children {
+		node, err := j.renderNode(child)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (j JSON) renderContent(contentNode Contenter) (JSONNode, error) {
+	content, err := contentNode.Materialize()
+	if err != nil {
+		return JSONNode{}, err
+	}
+
+	node := JSONNode{
+		Type:    contentNode.Type().String(),
+		Content: content.Content,
+	}
+
+	if len(content.Metadata) > 0 {
+		node.Metadata = make(map[string]interface{}, len(content.Metadata))
+		for key, value := range content.Metadata {
+			node.Metadata[key] = value
+		}
+	}
+
+	// The cells of rich table rows are nodes themselves, so they are serialized as children
+	if cells, ok := node.Metadata["Cells"].([]Node); ok {
+		delete(node.Metadata, "Cells")
+
+		node.Children, err = j.renderChildren(cells)
+		if err != nil {
+			return JSONNode{}, err
+		}
+	}
+
+	return node, nil
+}
+
+func (j JSON) renderStructure(structureNode Structurer) (JSONNode, error) {
+	children, err := j.renderChildren(structureNode.Children())
+	if err != nil {
+		return JSONNode{}, err
+	}
+
+	node := JSONNode{
+		Type:       structureNode.Type().String(),
+		Identifier: structureNode.Identifier(),
+		Children:   children,
+	}
+
+	switch structure := structureNode.(type) {
+	case *Document:
+		if structure.HasFrontmatter() {
+			node.Metadata = map[string]interface{}{"Frontmatter": structure.Frontmatter.Data}
+		}
+	case Document:
+		if structure.HasFrontmatter() {
+			node.Metadata = map[string]interface{}{"Frontmatter": structure.Frontmatter.Data}
+		}
+	case *List:
+		node.Metadata = j.listMetadata(*structure)
+	case List:
+		node.Metadata = j.listMetadata(structure)
+	case *Table:
+		node.Metadata = map[string]interface{}{"Headers": structure.Headers}
+	case Table:
+		node.Metadata = map[string]interface{}{"Headers": structure.Headers}
+	}
+
+	return node, nil
+}
+
+func (j JSON) listMetadata(l List) map[string]interface{} {
+	metadata := map[string]interface{}{"ListType": l.TypeOfList.String()}
+
+	if l.TypeOfList == TASK {
+		checked := make([]bool, len(l.Items))
+		for idx := range l.Items {
+			checked[idx] = l.IsChecked(idx)
+		}
+
+		metadata["Checked"] = checked
+	}
+
+	return metadata
+}
+
+func (j JSON) renderNode(node Node) (JSONNode, error) {
+	// Structures are checked first so that nodes which are both, such as custom containers,
+	// keep their children
+	if structureNode, ok := node.(Structurer); ok {
+		return j.renderStructure(structureNode)
+	}
+
+	if contentNode, ok := node.(Contenter); ok {
+		return j.renderContent(contentNode)
+	}
+
+	return JSONNode{}, fmt.Errorf("unknown node type: %s", node.Type())
+}
+
+// Render serializes a node and its descendants into indented JSON.
+// This is the main entry point for the Renderer interface implementation.
+func (j JSON) Render(node Node) (string, error) {
+	tree, err := j.renderNode(node)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(content) + "\n", nil
+}
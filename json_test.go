@@ -0,0 +1,169 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newEveryNodeDocument() *Document {
+	document := Document{Name: "Everything"}
+	document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"title": "Everything"}})
+	document.WriteIntro().
+		Text("Plain").Bold("bold").Italic("italic").Strikethrough("struck").Code("code").
+		LineBreak().Math("x^2").Link("link", "https://example.com").Image("alt", "https://example.com/a.png").
+		Badge("build", "https://example.com/ci", "https://img.shields.io/badge/build-passing-green").
+		CrossRef("", "Usage")
+	document.WriteTableOfContents(2)
+
+	usage := document.CreateSection("Usage")
+	usage.Content = append(usage.Content, Header{Content: "Heading"})
+	usage.WriteCodeBlock("json", []string{"{}"}, Static)
+	usage.WriteExecutable("bash", []string{"go", "test"}, []string{"GOFLAGS"})
+	usage.WritePipeline(Executable{Shell: "bash", Cmd: []string{"ls"}}, Executable{Shell: "bash", Cmd: []string{"wc", "-l"}})
+	usage.WriteBlockQuote("quote")
+	usage.WriteAdmonition(NOTE, "note")
+	usage.WriteHTML("<br>")
+	usage.WriteMermaid([]string{"graph TD", "A-->B"})
+	usage.WriteMathBlock([]string{"a = b"})
+	usage.WriteRemoteContent(Remote{Reader: strings.NewReader("remote content")})
+	usage.WriteComment("comment")
+
+	tasks := usage.CreateList(TASK)
+	tasks.AppendTask("done", true)
+	tasks.AppendTask("todo", false)
+
+	table := usage.CreateTable([]string{"Name", "Value"})
+	table.AddRow("plain", "row")
+	table.AddRichRow(Text("rich"), Code("cell"))
+
+	usage.CreateCollapsible("More").WriteParagraph().Text("Hidden.")
+
+	return &document
+}
+
+func TestJSONRender(t *testing.T) {
+	content, err := NewJSONRenderer().Render(newEveryNodeDocument())
+	checkErrors("", err, t)
+
+	var tree JSONNode
+	if err := json.Unmarshal([]byte(content), &tree); err != nil {
+		t.Fatalf("Unexpected error unmarshaling output: %s", err)
+	}
+
+	if tree.Type != "Document" || tree.Identifier != "Everything" {
+		t.Errorf("Expected Document 'Everything', got %s '%s'", tree.Type, tree.Identifier)
+	}
+
+	if !reflect.DeepEqual(tree.Metadata["Frontmatter"], map[string]interface{}{"title": "Everything"}) {
+		t.Errorf("Expected frontmatter metadata, got %v", tree.Metadata)
+	}
+
+	found := map[string]JSONNode{}
+	var walk func(node JSONNode)
+	walk = func(node JSONNode) {
+		if _, seen := found[node.Type]; !seen {
+			found[node.Type] = node
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(tree)
+
+	for contentType := HeaderType; contentType <= BadgeType; contentType++ {
+		switch contentType {
+		case FrontmatterType, SectionRefType: // frontmatter is document metadata, and references must be resolved first
+			continue
+		}
+
+		if _, ok := found[contentType.String()]; !ok {
+			t.Errorf("Expected output to contain a %s node", contentType)
+		}
+	}
+
+	tests := []struct {
+		nodeType string
+		check    func(node JSONNode) bool
+	}{
+		{"Section", func(node JSONNode) bool { return node.Identifier == "Usage" }},
+		{"Text", func(node JSONNode) bool { return node.Content == "Plain" }},
+		{"CodeBlock", func(node JSONNode) bool { return node.Content == "{}" && node.Metadata["BlockType"] == "json" }},
+		{"Executable", func(node JSONNode) bool {
+			return node.Content == "go test" && reflect.DeepEqual(node.Metadata["Environment"], []interface{}{"GOFLAGS"})
+		}},
+		{"Remote", func(node JSONNode) bool {
+			return node.Content == "remote content" && node.Metadata["Digest"] == ContentDigest("remote content")
+		}},
+		{"List", func(node JSONNode) bool {
+			return node.Metadata["ListType"] == "task" && reflect.DeepEqual(node.Metadata["Checked"], []interface{}{true, false})
+		}},
+		{"Table", func(node JSONNode) bool {
+			return reflect.DeepEqual(node.Metadata["Headers"], []interface{}{"Name", "Value"}) && len(node.Children) == 2
+		}},
+		{"Collapsible", func(node JSONNode) bool { return node.Identifier == "More" && len(node.Children) == 1 }},
+		{"TableOfContents", func(node JSONNode) bool { return node.Metadata["MaxDepth"] == float64(2) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.nodeType, func(t *testing.T) {
+			if node := found[tc.nodeType]; !tc.check(node) {
+				t.Errorf("Unexpected %s node: %+v", tc.nodeType, node)
+			}
+		})
+	}
+
+	t.Run("RichTableRow", func(t *testing.T) {
+		rows := found["Table"].Children
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+
+		rich := rows[1]
+		if _, ok := rich.Metadata["Cells"]; ok {
+			t.Errorf("Expected cells to be serialized as children, not metadata")
+		}
+
+		if len(rich.Children) != 2 || rich.Children[0].Type != "Text" || rich.Children[1].Type != "Code" {
+			t.Errorf("Expected Text and Code cell children, got %+v", rich.Children)
+		}
+	})
+}
+
+func TestJSONRenderDeterministic(t *testing.T) {
+	newDocument := func() *Document {
+		document := Document{Name: "Stable"}
+		document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"b": 2, "a": 1, "c": 3}})
+		document.CreateSection("Run").WriteExecutable("bash", []string{"go", "vet"}, []string{"B", "A"})
+
+		return &document
+	}
+
+	first, err := NewJSONRenderer().Render(newDocument())
+	checkErrors("", err, t)
+
+	for range 10 {
+		next, err := NewJSONRenderer().Render(newDocument())
+		checkErrors("", err, t)
+
+		if next != first {
+			t.Fatalf("Expected identical output, got %q and %q", first, next)
+		}
+	}
+
+	keys := []string{`"Command"`, `"Environment"`, `"Shell"`}
+	for idx := 1; idx < len(keys); idx++ {
+		if strings.Index(first, keys[idx-1]) > strings.Index(first, keys[idx]) {
+			t.Errorf("Expected metadata key %s before %s in %s", keys[idx-1], keys[idx], first)
+		}
+	}
+}
+
+func TestJSONRenderErrors(t *testing.T) {
+	document := Document{Name: "Broken"}
+	document.CreateSection("Shared").AddSectionRef("Other", "Section")
+
+	_, err := NewJSONRenderer().Render(&document)
+	checkErrors("unresolved section reference 'Other/Section': configure a document resolver to include sections from other documents", err, t)
+}
@@ -0,0 +1,255 @@
+package doyoucompute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLinkCheckTimeout is how long CheckLinks waits for a response to each request.
+const DefaultLinkCheckTimeout = 10 * time.Second
+
+// DefaultLinkCheckConcurrency is how many requests CheckLinks makes at once.
+const DefaultLinkCheckConcurrency = 4
+
+// ErrBrokenLink is returned for links whose target does not exist.
+var ErrBrokenLink = errors.New("broken link")
+
+// LinkStatus is the outcome of checking a link.
+type LinkStatus int
+
+const (
+	// LinkOK marks a link whose target exists
+	LinkOK LinkStatus = iota + 1
+	// LinkBroken marks a link whose target is missing or could not be reached
+	LinkBroken
+	// LinkSkipped marks a link that was not checked, such as an in-page anchor, a mailto link,
+	// or an http(s) link when remote checks are disabled
+	LinkSkipped
+)
+
+// String returns the name of the status.
+func (l LinkStatus) String() string {
+	switch l {
+	case LinkOK:
+		return "ok"
+	case LinkBroken:
+		return "broken"
+	case LinkSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// LinkCheckOptions configures CheckLinks.
+type LinkCheckOptions struct {
+	// Root is the directory relative links are resolved against, usually the directory the
+	// document is rendered to. Links starting with "/" are resolved against it too, as they are
+	// on GitHub. Empty means the working directory
+	Root string
+	// CheckRemote issues a HEAD request for every http(s) link. When false they are skipped
+	CheckRemote bool
+	// Timeout bounds each request (0 means DefaultLinkCheckTimeout)
+	Timeout time.Duration
+	// Concurrency limits how many requests are made at once (0 means DefaultLinkCheckConcurrency)
+	Concurrency int
+	// Client makes the requests (nil means http.DefaultClient)
+	Client *http.Client
+}
+
+// LinkResult is the outcome of checking a single link of a document.
+type LinkResult struct {
+	// Path is the path of the section holding the link, such as "MyDoc > Quick Start"
+	Path string
+	// Text is the display text of the link
+	Text string
+	// Url is the target of the link
+	Url string
+	// Status is the outcome of the check
+	Status LinkStatus
+	// StatusCode is the HTTP status of the response for http(s) links that were requested
+	StatusCode int
+	// Error is why the link is broken, or nil if it is not
+	Error error
+}
+
+// String returns the result as "status: path: text (url)", followed by the error if the link is broken.
+func (l LinkResult) String() string {
+	result := fmt.Sprintf("%s: %s: %s (%s)", l.Status, l.Path, l.Text, l.Url)
+	if l.Error != nil {
+		result += ": " + l.Error.Error()
+	}
+
+	return result
+}
+
+// remoteLinkText is the Text of the results for the URLs remote content is downloaded from.
+const remoteLinkText = "remote content"
+
+// CheckLinks checks every link of the document, returning a result for each in document order.
+// Relative links must name a file or directory under opts.Root, and http(s) links must answer
+// a HEAD request with a status below 400 when opts.CheckRemote is set. The URLs of remotes
+// created by NewRemoteURL are checked like links. Each distinct URL is requested once.
+// Unresolved section references are not checked.
+func CheckLinks(doc *Document, opts LinkCheckOptions) []LinkResult {
+	return CheckLinksContext(context.Background(), doc, opts)
+}
+
+// CheckLinksContext is like CheckLinks but stops making requests once ctx is cancelled,
+// marking the links that were not requested as broken with the context's error.
+func CheckLinksContext(ctx context.Context, doc *Document, opts LinkCheckOptions) []LinkResult {
+	results := []LinkResult{}
+	Walk(doc, func(node Node, path ContextPath) (bool, error) {
+		if link, ok := nodeAs[Link](node); ok {
+			results = append(results, LinkResult{Path: path.String(), Text: link.Text, Url: link.Url})
+		}
+
+		if remote, ok := nodeAs[Remote](node); ok {
+			if source, ok := remoteURL(remote); ok {
+				results = append(results, LinkResult{Path: path.String(), Text: remoteLinkText, Url: source})
+			}
+		}
+
+		return true, nil
+	})
+
+	remote := map[string][]int{}
+	for idx := range results {
+		result := &results[idx]
+
+		target, err := url.Parse(strings.TrimSpace(result.Url))
+		switch {
+		case strings.TrimSpace(result.Url) == "":
+			result.Status, result.Error = LinkBroken, fmt.Errorf("%w: link has no URL", ErrBrokenLink)
+		case err != nil:
+			result.Status, result.Error = LinkBroken, fmt.Errorf("%w: %v", ErrBrokenLink, err)
+		case target.Scheme == "http" || target.Scheme == "https":
+			if !opts.CheckRemote {
+				result.Status = LinkSkipped
+				continue
+			}
+			remote[result.Url] = append(remote[result.Url], idx)
+		case target.Scheme != "" || target.Host != "" || target.Path == "":
+			// Other schemes such as mailto, and in-page anchors, have nothing to look up
+			result.Status = LinkSkipped
+		default:
+			result.Status, result.Error = checkLocalLink(opts.Root, target.Path)
+		}
+	}
+
+	checkRemoteLinks(ctx, opts, remote, results)
+
+	return results
+}
+
+// checkLocalLink checks that the target of a relative link exists under root.
+func checkLocalLink(root, target string) (LinkStatus, error) {
+	path, err := url.PathUnescape(target)
+	if err != nil {
+		path = target
+	}
+
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+		return LinkBroken, fmt.Errorf("%w: %v", ErrBrokenLink, err)
+	}
+
+	return LinkOK, nil
+}
+
+// checkRemoteLinks requests every URL of remote, at most opts.Concurrency at once, and records
+// the outcome in the results at its indexes.
+func checkRemoteLinks(ctx context.Context, opts LinkCheckOptions, remote map[string][]int, results []LinkResult) {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLinkCheckTimeout
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultLinkCheckConcurrency
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for target, indexes := range remote {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			statusCode, err := requestLink(ctx, client, timeout, target)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, idx := range indexes {
+				results[idx].StatusCode = statusCode
+				results[idx].Status = LinkOK
+				results[idx].Error = err
+				if err != nil {
+					results[idx].Status = LinkBroken
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// requestLink sends a HEAD request for target, falling back to GET for servers that don't
+// support HEAD, and returns the status of the response. Statuses of 400 and above are errors.
+func requestLink(ctx context.Context, client *http.Client, timeout time.Duration, target string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	statusCode, err := sendRequest(ctx, client, http.MethodHead, target)
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented) {
+		statusCode, err = sendRequest(ctx, client, http.MethodGet, target)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrBrokenLink, err)
+	}
+
+	if statusCode >= http.StatusBadRequest {
+		return statusCode, fmt.Errorf("%w: %s", ErrBrokenLink, http.StatusText(statusCode))
+	}
+
+	return statusCode, nil
+}
+
+// sendRequest sends a request without reading its body and returns the status of the response.
+func sendRequest(ctx context.Context, client *http.Client, method, target string) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	response.Body.Close()
+
+	return response.StatusCode, nil
+}
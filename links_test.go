@@ -0,0 +1,217 @@
+package doyoucompute
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "CONTRIBUTING.md"), []byte("# Contributing\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %s", err.Error())
+	}
+
+	type expectedResult struct {
+		text       string
+		url        string
+		status     LinkStatus
+		statusCode int
+	}
+
+	tests := []struct {
+		name             string
+		opts             LinkCheckOptions
+		expected         []expectedResult
+		expectedRequests int32
+	}{
+		{
+			name: "Passing-RemoteChecks",
+			opts: LinkCheckOptions{Root: root, CheckRemote: true, Concurrency: 1},
+			expected: []expectedResult{
+				{text: "ok", url: server.URL + "/ok", status: LinkOK, statusCode: http.StatusOK},
+				{text: "get only", url: server.URL + "/get-only", status: LinkOK, statusCode: http.StatusOK},
+			},
+			expectedRequests: 3,
+		},
+		{
+			name: "Fail-RemoteNotFound",
+			opts: LinkCheckOptions{Root: root, CheckRemote: true},
+			expected: []expectedResult{
+				{text: "missing", url: server.URL + "/missing", status: LinkBroken, statusCode: http.StatusNotFound},
+			},
+			expectedRequests: 1,
+		},
+		{
+			name: "Passing-RemoteSkippedByDefault",
+			opts: LinkCheckOptions{Root: root},
+			expected: []expectedResult{
+				{text: "missing", url: server.URL + "/missing", status: LinkSkipped},
+			},
+			expectedRequests: 0,
+		},
+		{
+			name: "Passing-RelativeAndSkipped",
+			opts: LinkCheckOptions{Root: root},
+			expected: []expectedResult{
+				{text: "contributing", url: "CONTRIBUTING.md#setup", status: LinkOK},
+				{text: "rooted", url: "/CONTRIBUTING.md", status: LinkOK},
+				{text: "anchor", url: "#installation", status: LinkSkipped},
+				{text: "email", url: "mailto:maintainers@example.com", status: LinkSkipped},
+			},
+			expectedRequests: 0,
+		},
+		{
+			name: "Fail-RelativeMissing",
+			opts: LinkCheckOptions{Root: root},
+			expected: []expectedResult{
+				{text: "license", url: "docs/LICENSE.md", status: LinkBroken},
+			},
+			expectedRequests: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+
+			document, err := NewDocument("MyDoc")
+			if err != nil {
+				t.Fatalf("unexpected error creating document: %s", err.Error())
+			}
+
+			section := document.CreateSection("Links")
+			for _, expected := range tc.expected {
+				section.WriteParagraph().Link(expected.text, expected.url)
+			}
+
+			results := CheckLinks(&document, tc.opts)
+			if len(results) != len(tc.expected) {
+				t.Fatalf("expected %d results, got %d", len(tc.expected), len(results))
+			}
+
+			for idx, result := range results {
+				expected := tc.expected[idx]
+
+				if result.Text != expected.text || result.Url != expected.url || result.Path != "MyDoc > Links" {
+					t.Errorf("expected result %d for %s (%s) in MyDoc > Links, got %s (%s) in %s", idx, expected.text, expected.url, result.Text, result.Url, result.Path)
+				}
+
+				if result.Status != expected.status {
+					t.Errorf("expected %s to be %s, got %s (%v)", result.Url, expected.status, result.Status, result.Error)
+				}
+
+				if result.StatusCode != expected.statusCode {
+					t.Errorf("expected %s status code %d, got %d", result.Url, expected.statusCode, result.StatusCode)
+				}
+
+				if (result.Status == LinkBroken) != errors.Is(result.Error, ErrBrokenLink) {
+					t.Errorf("expected %s to wrap ErrBrokenLink only when broken, got %v", result.Url, result.Error)
+				}
+			}
+
+			if requests.Load() != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, requests.Load())
+			}
+		})
+	}
+}
+
+func TestCheckLinksDeduplicatesRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	document, err := NewDocument("MyDoc")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+
+	document.CreateSection("First").WriteParagraph().Link("home", server.URL)
+	document.CreateSection("Second").WriteParagraph().Link("home again", server.URL)
+
+	results := CheckLinks(&document, LinkCheckOptions{CheckRemote: true})
+	for _, result := range results {
+		if result.Status != LinkOK {
+			t.Errorf("expected %s in %s to be ok, got %s", result.Url, result.Path, result.Status)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestCheckLinksRemoteURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok.md" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	document, err := NewDocument("MyDoc")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+
+	section := document.CreateSection("Included")
+	for _, path := range []string{"/ok.md", "/missing.md"} {
+		remote, err := NewRemoteURL(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error creating remote: %s", err.Error())
+		}
+		section.WriteRemoteContent(remote)
+	}
+	section.WriteRemoteContent(Remote{Reader: strings.NewReader("not downloaded")})
+
+	results := CheckLinks(&document, LinkCheckOptions{CheckRemote: true})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	expected := []struct {
+		url        string
+		status     LinkStatus
+		statusCode int
+	}{
+		{url: server.URL + "/ok.md", status: LinkOK, statusCode: http.StatusOK},
+		{url: server.URL + "/missing.md", status: LinkBroken, statusCode: http.StatusNotFound},
+	}
+
+	for idx, result := range results {
+		if result.Url != expected[idx].url || result.Text != "remote content" || result.Path != "MyDoc > Included" {
+			t.Errorf("expected result %d for remote content (%s) in MyDoc > Included, got %s (%s) in %s", idx, expected[idx].url, result.Text, result.Url, result.Path)
+		}
+
+		if result.Status != expected[idx].status || result.StatusCode != expected[idx].statusCode {
+			t.Errorf("expected %s to be %s with status code %d, got %s with %d", result.Url, expected[idx].status, expected[idx].statusCode, result.Status, result.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package doyoucompute
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintSeverity is how serious a LintIssue is.
+type LintSeverity int
+
+const (
+	// LintWarning marks an issue that renders fine but is likely a mistake
+	LintWarning LintSeverity = iota + 1
+	// LintError marks an issue that produces a broken or ambiguous document
+	LintError
+)
+
+// String returns the name of the severity.
+func (l LintSeverity) String() string {
+	switch l {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// LintIssue describes a structural problem with a document found by Lint.
+type LintIssue struct {
+	// Severity is how serious the issue is
+	Severity LintSeverity
+	// Rule names the check that found the issue, such as "empty-section"
+	Rule string
+	// Path is the path of the section holding the problem, such as "MyDoc > Quick Start"
+	Path string
+	// Message describes the problem
+	Message string
+}
+
+// String returns the issue as "severity: path: message (rule)".
+func (l LintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", l.Severity, l.Path, l.Message, l.Rule)
+}
+
+// Lint checks the structure of a document for mistakes the builder API can't prevent:
+// sections without content or with only comments, section names that are empty or span more
+// than one line, sibling sections sharing a name, tables without rows, lists without items,
+// executables without a command, and links without a URL.
+// Unresolved section references are not checked. Returns no issues if none are found.
+func Lint(doc *Document) []LintIssue {
+	issues := []LintIssue{}
+	lintNode(doc, ContextPath{}, &issues)
+
+	return issues
+}
+
+// lintNode adds the issues of node and its children to issues.
+func lintNode(node Node, path ContextPath, issues *[]LintIssue) {
+	add := func(severity LintSeverity, rule, message string) {
+		*issues = append(*issues, LintIssue{Severity: severity, Rule: rule, Path: path.String(), Message: message})
+	}
+
+	switch node.Type() {
+	case SectionType:
+		section := node.(Structurer)
+		path = path.Push(section.Identifier())
+
+		if _, err := validateName("section", section.Identifier()); err != nil {
+			add(LintError, "invalid-section-name", err.Error())
+		}
+
+		if len(section.Children()) == 0 {
+			add(LintWarning, "empty-section", "section has no content")
+		} else if onlyComments(section.Children()) {
+			add(LintWarning, "comment-only-section", "section has only comments and no visible content")
+		}
+	case DocumentType:
+		path = path.Push(node.(Structurer).Identifier())
+	case TableType:
+		if len(node.(Structurer).Children()) == 0 {
+			add(LintWarning, "empty-table", "table has no rows")
+		}
+	case ListType:
+		if len(node.(Structurer).Children()) == 0 {
+			add(LintWarning, "empty-list", "list has no items")
+		}
+	case ExecutableType:
+		if executable, ok := nodeAs[Executable](node); ok && executableIsEmpty(executable) {
+			add(LintError, "empty-command", "executable has no command")
+		}
+	case PipelineType:
+		if pipeline, ok := nodeAs[Pipeline](node); ok {
+			for idx, stage := range pipeline.Stages {
+				if executableIsEmpty(stage) {
+					add(LintError, "empty-command", fmt.Sprintf("pipeline stage %d has no command", idx+1))
+				}
+			}
+		}
+	case LinkType:
+		if link, ok := nodeAs[Link](node); ok && strings.TrimSpace(link.Url) == "" {
+			add(LintError, "empty-link", fmt.Sprintf("link '%s' has no URL", link.Text))
+		}
+	}
+
+	structurer, ok := node.(Structurer)
+	if !ok {
+		return
+	}
+
+	// Sections are addressed by name, so siblings sharing one can't be told apart
+	seen := map[string]bool{}
+	for _, child := range structurer.Children() {
+		if child.Type() == SectionType {
+			name := child.(Structurer).Identifier()
+			if seen[name] {
+				add(LintError, "duplicate-section", fmt.Sprintf("more than one section is named '%s'", name))
+			}
+			seen[name] = true
+		}
+
+		lintNode(child, path, issues)
+	}
+}
+
+// onlyComments reports whether every node is a comment.
+func onlyComments(nodes []Node) bool {
+	for _, node := range nodes {
+		if node.Type() != CommentType {
+			return false
+		}
+	}
+
+	return true
+}
+
+// executableIsEmpty reports whether an executable has no command to run.
+func executableIsEmpty(executable Executable) bool {
+	if len(executable.Steps) > 0 {
+		return false
+	}
+
+	return strings.TrimSpace(strings.Join(executable.Cmd, "")) == ""
+}
@@ -0,0 +1,127 @@
+package doyoucompute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(doc *Document)
+		expected []LintIssue
+	}{
+		{
+			name: "Passing-CleanDocument",
+			build: func(doc *Document) {
+				section := doc.CreateSection("Install")
+				section.WriteParagraph().Text("Run").Link("the installer", "https://example.com")
+				section.WriteExecutable("bash", []string{"make", "install"}, []string{})
+				section.AddList(BULLET, []Text{"one"})
+				section.AddTable([]string{"Name"}, []TableRow{{Values: []string{"value"}}})
+			},
+			expected: []LintIssue{},
+		},
+		{
+			name: "Fail-EmptySection",
+			build: func(doc *Document) {
+				doc.CreateSection("Empty")
+			},
+			expected: []LintIssue{
+				{Severity: LintWarning, Rule: "empty-section", Path: "MyDoc > Empty", Message: "section has no content"},
+			},
+		},
+		{
+			name: "Fail-CommentOnlySection",
+			build: func(doc *Document) {
+				doc.CreateSection("Notes").WriteComment("todo")
+			},
+			expected: []LintIssue{
+				{Severity: LintWarning, Rule: "comment-only-section", Path: "MyDoc > Notes", Message: "section has only comments and no visible content"},
+			},
+		},
+		{
+			name: "Fail-InvalidSectionName",
+			build: func(doc *Document) {
+				doc.CreateSection("Usage\nMore").WriteParagraph().Text("text")
+			},
+			expected: []LintIssue{
+				{Severity: LintError, Rule: "invalid-section-name", Path: "MyDoc > Usage\nMore", Message: `section name cannot span more than one line: "Usage\nMore"`},
+			},
+		},
+		{
+			name: "Fail-DuplicateSiblingSections",
+			build: func(doc *Document) {
+				section := doc.CreateSection("Usage")
+				section.CreateSection("Example").WriteParagraph().Text("first")
+				section.CreateSection("Example").WriteParagraph().Text("second")
+			},
+			expected: []LintIssue{
+				{Severity: LintError, Rule: "duplicate-section", Path: "MyDoc > Usage", Message: "more than one section is named 'Example'"},
+			},
+		},
+		{
+			name: "Fail-EmptyTable",
+			build: func(doc *Document) {
+				doc.CreateSection("Options").CreateTable([]string{"Flag", "Usage"})
+			},
+			expected: []LintIssue{
+				{Severity: LintWarning, Rule: "empty-table", Path: "MyDoc > Options", Message: "table has no rows"},
+			},
+		},
+		{
+			name: "Fail-EmptyList",
+			build: func(doc *Document) {
+				doc.CreateSection("Steps").CreateList(NUMBERED)
+			},
+			expected: []LintIssue{
+				{Severity: LintWarning, Rule: "empty-list", Path: "MyDoc > Steps", Message: "list has no items"},
+			},
+		},
+		{
+			name: "Fail-EmptyCommand",
+			build: func(doc *Document) {
+				doc.CreateSection("Run").WriteExecutable("bash", []string{}, []string{})
+			},
+			expected: []LintIssue{
+				{Severity: LintError, Rule: "empty-command", Path: "MyDoc > Run", Message: "executable has no command"},
+			},
+		},
+		{
+			name: "Fail-EmptyPipelineStage",
+			build: func(doc *Document) {
+				doc.CreateSection("Run").WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"cat", "file"}},
+					Executable{Shell: "bash", Cmd: []string{" "}},
+				)
+			},
+			expected: []LintIssue{
+				{Severity: LintError, Rule: "empty-command", Path: "MyDoc > Run", Message: "pipeline stage 2 has no command"},
+			},
+		},
+		{
+			name: "Fail-EmptyLinkURL",
+			build: func(doc *Document) {
+				doc.CreateSection("Links").WriteParagraph().Link("docs", "")
+			},
+			expected: []LintIssue{
+				{Severity: LintError, Rule: "empty-link", Path: "MyDoc > Links", Message: "link 'docs' has no URL"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document, err := NewDocument("MyDoc")
+			if err != nil {
+				t.Fatalf("unexpected error creating document: %s", err.Error())
+			}
+			tc.build(&document)
+
+			issues := Lint(&document)
+			if !reflect.DeepEqual(issues, tc.expected) {
+				t.Errorf("expected issues %v, got %v", tc.expected, issues)
+			}
+		})
+	}
+}
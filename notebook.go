@@ -0,0 +1,169 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NotebookCell is a single cell of a Jupyter notebook in nbformat v4.
+type NotebookCell struct {
+	CellType       string                 `json:"cell_type"`
+	ExecutionCount *int                   `json:"execution_count,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	Outputs        []interface{}          `json:"outputs,omitempty"`
+	Source         []string               `json:"source"`
+}
+
+// NotebookFile is a Jupyter notebook in nbformat v4.
+type NotebookFile struct {
+	Cells         []NotebookCell         `json:"cells"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	NBFormat      int                    `json:"nbformat"`
+	NBFormatMinor int                    `json:"nbformat_minor"`
+}
+
+// Notebook implements the Renderer interface to export a document as a Jupyter notebook.
+// Executables and code blocks become code cells, with their shell or language recorded in the
+// cell metadata, and everything between them is rendered as markdown into markdown cells.
+type Notebook struct{}
+
+// NewNotebookRenderer creates a new Notebook renderer instance.
+func NewNotebookRenderer() Notebook {
+	return Notebook{}
+}
+
+// notebookSource splits content into the line-per-entry form nbformat uses, keeping newlines.
+func notebookSource(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// notebookBuilder collects cells, buffering markdown until the next code cell.
+type notebookBuilder struct {
+	markdown Markdown
+	cells    []NotebookCell
+	pending  []string
+}
+
+func (b *notebookBuilder) addMarkdown(content string) {
+	b.pending = append(b.pending, content)
+}
+
+func (b *notebookBuilder) flush() {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	b.cells = append(b.cells, NotebookCell{
+		CellType: "markdown",
+		Metadata: map[string]interface{}{},
+		Source:   notebookSource(strings.Join(b.pending, "\n\n")),
+	})
+	b.pending = nil
+}
+
+func (b *notebookBuilder) addCode(content string, metadata map[string]interface{}) {
+	b.flush()
+
+	b.cells = append(b.cells, NotebookCell{
+		CellType: "code",
+		Metadata: metadata,
+		Outputs:  []interface{}{},
+		Source:   notebookSource(content),
+	})
+}
+
+func (n Notebook) renderWithTracking(node Node, contextPath *ContextPath, builder *notebookBuilder) error {
+	switch node.Type() {
+	case DocumentType, SectionType:
+		structure := node.(Structurer)
+
+		ctxPath := contextPath.Push(structure.Identifier())
+		builder.addMarkdown(fmt.Sprintf("%s %s", strings.Repeat("#", min(ctxPath.CurrentLevel(), 5)), structure.Identifier()))
+
+		for _, child := range structure.Children() {
+			if err := n.renderWithTracking(child, &ctxPath, builder); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case ExecutableType, PipelineType:
+		content, err := node.(Contenter).Materialize()
+		if err != nil {
+			return err
+		}
+
+		shell, err := getStringFromMetadata(content.Metadata, "Shell")
+		if err != nil {
+			return err
+		}
+
+		builder.addCode(content.Content, map[string]interface{}{"shell": shell})
+
+		return nil
+	case CodeBlockType:
+		content, err := node.(Contenter).Materialize()
+		if err != nil {
+			return err
+		}
+
+		language, err := getStringFromMetadata(content.Metadata, "BlockType")
+		if err != nil {
+			return err
+		}
+
+		builder.addCode(content.Content, map[string]interface{}{"language": language})
+
+		return nil
+	}
+
+	// Everything else, including tables and remote content, reads best as markdown
+	content, err := builder.markdown.renderWithTracking(node, contextPath)
+	if errors.Is(err, errSkipNode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	builder.addMarkdown(strings.TrimRight(content, "\n"))
+
+	return nil
+}
+
+// Render converts a node into nbformat v4 notebook JSON.
+// This is the main entry point for the Renderer interface implementation.
+func (n Notebook) Render(node Node) (string, error) {
+	builder := notebookBuilder{
+		markdown: Markdown{unknownNodes: UnknownNodesError, anchors: collectAnchors(node, false)},
+	}
+
+	if err := n.renderWithTracking(node, &ContextPath{}, &builder); err != nil {
+		return "", err
+	}
+	builder.flush()
+
+	notebook := NotebookFile{
+		Cells:         builder.cells,
+		Metadata:      map[string]interface{}{},
+		NBFormat:      4,
+		NBFormatMinor: 4,
+	}
+	if notebook.Cells == nil {
+		notebook.Cells = []NotebookCell{}
+	}
+
+	content, err := json.MarshalIndent(notebook, "", " ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(content) + "\n", nil
+}
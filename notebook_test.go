@@ -0,0 +1,90 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNotebookRender(t *testing.T) {
+	document := Document{Name: "Tutorial"}
+	document.WriteIntro().Text("Follow along.")
+
+	setup := document.CreateSection("Setup")
+	setup.WriteRemoteContent(Remote{Reader: strings.NewReader("Fetched from elsewhere.")})
+	setup.WriteExecutable("bash", []string{"go", "mod", "download"}, []string{})
+	setup.Content = append(setup.Content, CodeBlock{BlockType: "python", Cmd: []string{"import sys", "print(sys.version)"}, JoinWith: "\n"})
+
+	reference := document.CreateSection("Reference")
+	table := reference.CreateTable([]string{"Flag", "Meaning"})
+	table.AddRow("-v", "verbose")
+
+	content, err := NewNotebookRenderer().Render(&document)
+	checkErrors("", err, t)
+
+	var notebook NotebookFile
+	if err := json.Unmarshal([]byte(content), &notebook); err != nil {
+		t.Fatalf("Unexpected error unmarshaling output: %s", err)
+	}
+
+	if notebook.NBFormat != 4 {
+		t.Errorf("Expected nbformat 4, got %d", notebook.NBFormat)
+	}
+
+	types := []string{}
+	for _, cell := range notebook.Cells {
+		types = append(types, cell.CellType)
+	}
+
+	expectedTypes := []string{"markdown", "code", "code", "markdown"}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Fatalf("Expected cell types %v, got %v", expectedTypes, types)
+	}
+
+	tests := []struct {
+		name     string
+		cell     int
+		source   string
+		metadata map[string]interface{}
+	}{
+		{
+			name:     "SectionsAndRemoteContent",
+			cell:     0,
+			source:   "# Tutorial\n\nFollow along.\n\n## Setup\n\nFetched from elsewhere.",
+			metadata: map[string]interface{}{},
+		},
+		{
+			name:     "Executable",
+			cell:     1,
+			source:   "go mod download",
+			metadata: map[string]interface{}{"shell": "bash"},
+		},
+		{
+			name:     "CodeBlock",
+			cell:     2,
+			source:   "import sys\nprint(sys.version)",
+			metadata: map[string]interface{}{"language": "python"},
+		},
+		{
+			name:     "Table",
+			cell:     3,
+			source:   "## Reference\n\n| Flag | Meaning |\n| ---- | ---- |\n| -v | verbose |",
+			metadata: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cell := notebook.Cells[tc.cell]
+
+			if source := strings.Join(cell.Source, ""); source != tc.source {
+				t.Errorf("Expected source %q, got %q", tc.source, source)
+			}
+
+			if !reflect.DeepEqual(cell.Metadata, tc.metadata) {
+				t.Errorf("Expected metadata %v, got %v", tc.metadata, cell.Metadata)
+			}
+		})
+	}
+}
@@ -2,27 +2,259 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/MoonMoon1919/doyoucompute"
 	"github.com/urfave/cli/v3"
 )
 
-func cliBuilder(cliName string, service *doyoucompute.Service, documents map[string]doyoucompute.Document) *cli.Command {
-	// helper function that looks up a document by name from the registered documents map.
-	// returns an error if the document is not found.
-	findDoc := func(documentName string) (doyoucompute.Document, error) {
-		doc, ok := documents[documentName]
+// printExitDetails prints the exit code and duration of a command that ran and failed.
+func printExitDetails(result doyoucompute.TaskResult) {
+	if result.ExitCode == doyoucompute.ExitCodeNotStarted {
+		return
+	}
+
+	fmt.Printf("   Exit code: %d (took %s)\n", result.ExitCode, result.Duration.Round(time.Millisecond))
+}
+
+// ANSI escapes coloring the lines of a diff.
+const (
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiCyan  = "\033[36m"
+	ansiBold  = "\033[1m"
+	ansiReset = "\033[0m"
+)
+
+// formatStats lays out document statistics as a two-column table.
+func formatStats(stats doyoucompute.DocumentStats) string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "Sections\t%d\n", stats.Sections)
+
+	depths := make([]int, 0, len(stats.SectionsByDepth))
+	for depth := range stats.SectionsByDepth {
+		depths = append(depths, depth)
+	}
+	slices.Sort(depths)
+	for _, depth := range depths {
+		fmt.Fprintf(writer, "  Depth %d\t%d\n", depth, stats.SectionsByDepth[depth])
+	}
+
+	fmt.Fprintf(writer, "Paragraphs\t%d\n", stats.Paragraphs)
+	fmt.Fprintf(writer, "Executables\t%d\n", stats.Executables)
+	fmt.Fprintf(writer, "Code blocks\t%d\n", stats.CodeBlocks)
+	fmt.Fprintf(writer, "Tables\t%d\n", stats.Tables)
+	fmt.Fprintf(writer, "Links\t%d\n", stats.Links)
+	fmt.Fprintf(writer, "Remotes\t%d\n", stats.Remotes)
+	fmt.Fprintf(writer, "Words\t%d\n", stats.Words)
+	fmt.Fprintf(writer, "Reading time\t%s\n", stats.ReadingTime)
+
+	writer.Flush()
 
-		if !ok {
-			return doyoucompute.Document{}, errors.New("document not found")
+	return builder.String()
+}
+
+// formatDiff prepares a unified diff for the terminal: colored if color is set, and cut off
+// after maxLines lines with a note on how many were left out (0 means no limit).
+func formatDiff(diff string, color bool, maxLines int) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+
+	omitted := 0
+	if maxLines > 0 && len(lines) > maxLines {
+		omitted = len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+
+	var builder strings.Builder
+	for _, line := range lines {
+		code := ""
+		if color {
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				code = ansiBold
+			case strings.HasPrefix(line, "@@"):
+				code = ansiCyan
+			case strings.HasPrefix(line, "-"):
+				code = ansiRed
+			case strings.HasPrefix(line, "+"):
+				code = ansiGreen
+			}
+		}
+
+		if code != "" {
+			builder.WriteString(code + line + ansiReset + "\n")
+		} else {
+			builder.WriteString(line + "\n")
 		}
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(&builder, "... %d more line(s) not shown, use --max-diff-lines 0 to show the whole diff\n", omitted)
+	}
+
+	return builder.String()
+}
+
+// reportResults prints feedback for every executed command followed by a summary
+// of each status bucket. Only genuine failures (failed or timed out commands) produce an error.
+func reportResults(results []doyoucompute.TaskResult) error {
+	counts := map[doyoucompute.TaskStatus]int{}
+
+	for _, result := range results {
+		counts[result.Status]++
+
+		switch result.Status {
+		case doyoucompute.COMPLETED:
+			if result.DryRun {
+				fmt.Printf("🧪 Validated (dry run): %s (section: %s)\n", result.Command, result.SectionName)
+				continue
+			}
+			fmt.Printf("✅ Completed: %s (section: %s, took %s)\n", result.Command, result.SectionName, result.Duration.Round(time.Millisecond))
+		case doyoucompute.SKIPPED:
+			fmt.Printf("⏭️  Skipped: %s (section: %s)\n", result.Command, result.SectionName)
+			if result.Error != nil {
+				fmt.Printf("   Reason: %v\n", result.Error)
+			}
+		case doyoucompute.TIMEOUT:
+			var timeoutErr *doyoucompute.TimeoutError
+			if errors.As(result.Error, &timeoutErr) {
+				fmt.Printf("⏱️  Command timed out after %s in section '%s': %s\n", timeoutErr.Timeout, result.SectionName, result.Command)
+			} else {
+				fmt.Printf("⏱️  Command timed out in section '%s': %s\n", result.SectionName, result.Command)
+				fmt.Printf("   Error: %v\n", result.Error)
+			}
+			fmt.Printf("   💡 Tip: If the command is just slow, raise the Timeout in its ExecutionConfig\n")
+			fmt.Println()
+		default:
+			// Extract missing env vars from error message if it's an env validation error
+			if strings.Contains(result.Error.Error(), "environment validation failed") {
+				fmt.Printf("❌ Command failed in section '%s': %s\n", result.SectionName, result.Command)
+				fmt.Printf("   Error: %v\n", result.Error)
+
+				// Give helpful suggestion
+				if strings.Contains(result.Error.Error(), "required environment variables not set") {
+					fmt.Printf("   💡 Tip: Set the required environment variables and try again\n")
+				}
+			} else if strings.Contains(result.Error.Error(), "security validation failed") {
+				fmt.Printf("❌ Command blocked for security in section '%s': %s\n", result.SectionName, result.Command)
+				fmt.Printf("   Error: %v\n", result.Error)
+			} else {
+				fmt.Printf("❌ Command failed in section '%s': %s\n", result.SectionName, result.Command)
+				fmt.Printf("   Error: %v\n", result.Error)
+				printExitDetails(result)
+			}
+			fmt.Println()
+		}
+	}
+
+	failedCount := counts[doyoucompute.FAILED] + counts[doyoucompute.TIMEOUT]
+
+	fmt.Printf("📊 Summary: %d completed, %d failed, %d timed out, %d skipped\n",
+		counts[doyoucompute.COMPLETED],
+		counts[doyoucompute.FAILED],
+		counts[doyoucompute.TIMEOUT],
+		counts[doyoucompute.SKIPPED],
+	)
+
+	if failedCount > 0 {
+		fmt.Println("❌ Failed commands:")
+		for _, result := range results {
+			if result.Status.Failed() {
+				fmt.Printf("   - %s (section: %s, exit code %d, took %s)\n", result.Command, result.SectionName, result.ExitCode, result.Duration.Round(time.Millisecond))
+			}
+		}
+
+		return fmt.Errorf("%d out of %d commands failed", failedCount, len(results))
+	}
+
+	if counts[doyoucompute.SKIPPED] > 0 {
+		fmt.Printf("🎉 All %d executed commands completed successfully!\n", counts[doyoucompute.COMPLETED])
+		return nil
+	}
+
+	fmt.Printf("🎉 All %d commands completed successfully!\n", len(results))
+
+	return nil
+}
+
+// reportJSON runs the document and prints a JSON report of the run to stdout. The output of
+// commands goes to stderr to keep stdout parseable. When junitPath is set, a JUnit report is
+// written there too. Like reportResults, only genuine failures produce an error.
+func reportJSON(ctx context.Context, service *doyoucompute.Service, document *doyoucompute.Document, section string, junitPath string) error {
+	service, err := service.With(doyoucompute.WithCommandOutput(os.Stderr, os.Stderr))
+	if err != nil {
+		return err
+	}
+
+	report, err := service.ExecuteScriptWithReportContext(ctx, document, section)
+	if err != nil {
+		return fmt.Errorf("Failed to execute script: %w", err)
+	}
 
-		return doc, nil
+	if err := writeJUnitFile(junitPath, report.Results); err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode report: %w", err)
+	}
+
+	fmt.Println(string(encoded))
+
+	if report.Failed() {
+		counts := report.Counts()
+		return fmt.Errorf("%d out of %d commands failed", counts.Failed+counts.TimedOut, counts.Total)
+	}
+
+	return nil
+}
+
+// writeJUnitFile writes the results as a JUnit XML report to path. An empty path writes nothing.
+func writeJUnitFile(path string, results []doyoucompute.TaskResult) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create JUnit report: %w", err)
+	}
+	defer file.Close()
+
+	if err := doyoucompute.WriteJUnitReport(results, file); err != nil {
+		return fmt.Errorf("Failed to write JUnit report: %w", err)
+	}
+
+	return file.Close()
+}
+
+// findDocument looks up a document by name from the registered documents map.
+// returns an error if the document is not found.
+func findDocument(documents map[string]doyoucompute.Document, documentName string) (doyoucompute.Document, error) {
+	doc, ok := documents[documentName]
+
+	if !ok {
+		return doyoucompute.Document{}, errors.New("document not found")
+	}
+
+	return doc, nil
+}
+
+func cliBuilder(cliName string, service *doyoucompute.Service, documents map[string]doyoucompute.Document, extraCommands ...*cli.Command) *cli.Command {
+	findDoc := func(documentName string) (doyoucompute.Document, error) {
+		return findDocument(documents, documentName)
 	}
 
 	cmd := &cli.Command{
@@ -31,7 +263,7 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 		Commands: []*cli.Command{
 			{
 				Name:  "render",
-				Usage: "Render a document as markdown",
+				Usage: "Render a document as markdown, html, json, plain text, or a Jupyter notebook",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:  "path",
@@ -41,10 +273,28 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 						Name:  "doc-name",
 						Usage: "The name of the document",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Overwrite the output file even if it was not generated by doyoucompute",
+					},
+					&cli.StringFlag{
+						Name:  "unknown-nodes",
+						Usage: "How to handle unsupported node types: error, skip, or text",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "The output format: markdown, html, json, text, notebook, or issue-form",
+						Value: doyoucompute.FormatMarkdown,
+					},
+					&cli.StringFlag{
+						Name:  "section",
+						Usage: "Render only this section, using '/' to separate nested sections (e.g., 'Quick Start/Installation')",
+					},
 				},
 				Action: func(ctx context.Context, c *cli.Command) error {
 					outpath := c.String("path")
 					name := c.String("doc-name")
+					section := c.String("section")
 
 					document, err := findDoc(name)
 					if err != nil {
@@ -54,7 +304,41 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 					fmt.Printf("📄 Rendering document: %s\n", name)
 					fmt.Printf("📁 Output path: %s\n", outpath)
 
-					if err := service.RenderFile(&document, outpath); err != nil {
+					opts := []doyoucompute.OptionsServiceFunc{doyoucompute.WithOverwriteProtection(!c.Bool("force"))}
+
+					if c.IsSet("unknown-nodes") || c.IsSet("format") {
+						var policy doyoucompute.UnknownNodePolicy
+
+						if c.IsSet("unknown-nodes") {
+							policy, err = doyoucompute.ParseUnknownNodePolicy(c.String("unknown-nodes"))
+							if err != nil {
+								return fmt.Errorf("❌ %w", err)
+							}
+						}
+
+						renderer, err := doyoucompute.NewFileRenderer(c.String("format"), policy)
+						if err != nil {
+							return fmt.Errorf("❌ Failed to configure renderer: %w", err)
+						}
+
+						opts = append(opts, doyoucompute.WithFileRenderer(renderer))
+					}
+
+					svc, err := service.With(opts...)
+					if err != nil {
+						return fmt.Errorf("❌ Failed to configure service: %w", err)
+					}
+
+					if section != "" {
+						fmt.Printf("📑 Section: %s\n", section)
+						err = svc.RenderSectionContext(ctx, &document, section, outpath)
+					} else {
+						err = svc.RenderFileContext(ctx, &document, outpath)
+					}
+					if err != nil {
+						if errors.Is(err, doyoucompute.ErrHandWrittenFile) {
+							return fmt.Errorf("❌ %w\n💡 Tip: Run 'render --doc-name %s --path %s --force' to overwrite it anyway.", err, name, outpath)
+						}
 						return fmt.Errorf("❌ Failed to render document: %w", err)
 					}
 
@@ -74,6 +358,46 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 						Name:  "doc-name",
 						Usage: "The name of the document",
 					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "The format the file was rendered in: markdown, html, json, text, notebook, or issue-form",
+						Value: doyoucompute.FormatMarkdown,
+					},
+					&cli.IntFlag{
+						Name:  "context",
+						Value: doyoucompute.DefaultDiffContext,
+						Usage: "How many unchanged lines to show around each change of the diff",
+					},
+					&cli.IntFlag{
+						Name:  "max-diff-lines",
+						Value: 200,
+						Usage: "How many lines of the diff to show (0 shows the whole diff)",
+					},
+					&cli.BoolFlag{
+						Name:  "color",
+						Usage: "Color the lines of the diff",
+					},
+					&cli.BoolFlag{
+						Name:  "normalize-line-endings",
+						Usage: "Treat CRLF and LF line endings as the same",
+					},
+					&cli.BoolFlag{
+						Name:  "trim-whitespace",
+						Usage: "Ignore spaces and tabs at the end of lines",
+					},
+					&cli.BoolFlag{
+						Name:  "collapse-blank-lines",
+						Usage: "Treat runs of blank lines as a single one",
+					},
+					&cli.BoolFlag{
+						Name:  "ignore-frontmatter",
+						Usage: "Ignore the frontmatter block at the start of the file",
+					},
+					&cli.StringFlag{
+						Name:  "hash",
+						Usage: "The hash algorithm the contents are reported by: sha256 or md5",
+						Value: doyoucompute.HashSHA256.String(),
+					},
 				},
 				Action: func(ctx context.Context, c *cli.Command) error {
 					outpath := c.String("path")
@@ -87,7 +411,35 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 					fmt.Printf("🔍 Comparing document: %s\n", name)
 					fmt.Printf("📁 Against file: %s\n", outpath)
 
-					result, err := service.CompareFile(&document, outpath)
+					algorithm, err := doyoucompute.ParseHashAlgorithm(c.String("hash"))
+					if err != nil {
+						return fmt.Errorf("❌ %w", err)
+					}
+
+					opts := []doyoucompute.OptionsServiceFunc{doyoucompute.WithDiffContext(c.Int("context")), doyoucompute.WithHashAlgorithm(algorithm)}
+					if c.IsSet("normalize-line-endings") || c.IsSet("trim-whitespace") || c.IsSet("collapse-blank-lines") || c.IsSet("ignore-frontmatter") {
+						opts = append(opts, doyoucompute.WithCompareOptions(doyoucompute.CompareOptions{
+							NormalizeLineEndings:   c.Bool("normalize-line-endings"),
+							TrimTrailingWhitespace: c.Bool("trim-whitespace"),
+							CollapseBlankLines:     c.Bool("collapse-blank-lines"),
+							IgnoreFrontmatter:      c.Bool("ignore-frontmatter"),
+						}))
+					}
+					if c.IsSet("format") {
+						renderer, err := doyoucompute.NewFileRenderer(c.String("format"), 0)
+						if err != nil {
+							return fmt.Errorf("❌ Failed to configure renderer: %w", err)
+						}
+
+						opts = append(opts, doyoucompute.WithFileRenderer(renderer))
+					}
+
+					svc, err := service.With(opts...)
+					if err != nil {
+						return fmt.Errorf("❌ Failed to configure service: %w", err)
+					}
+
+					result, err := svc.CompareFileContext(ctx, &document, outpath)
 					if err != nil {
 						if os.IsNotExist(err) {
 							return fmt.Errorf("❌ File '%s' does not exist.\n💡 Tip: Run 'render --doc-name %s --path %s' to create it.", outpath, name, outpath)
@@ -97,8 +449,11 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 
 					if !result.Matches {
 						fmt.Printf("❌ Content mismatch detected:\n")
-						fmt.Printf("   📄 Document hash: %s\n", result.DocumentHash)
-						fmt.Printf("   📁 File hash:     %s\n", result.FileHash)
+						fmt.Printf("   📄 Document hash (%s): %s\n", result.Algorithm, result.DocumentHash)
+						fmt.Printf("   📁 File hash (%s):     %s\n", result.Algorithm, result.FileHash)
+						fmt.Println()
+						fmt.Print(formatDiff(result.Diff, c.Bool("color"), c.Int("max-diff-lines")))
+						fmt.Println()
 						fmt.Printf("💡 Tip: Run 'render --doc-name %s --path %s' to update the file\n", name, outpath)
 						return fmt.Errorf("Files don't match")
 					}
@@ -114,12 +469,46 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 					&cli.StringFlag{
 						Name:  "section",
 						Value: doyoucompute.ALL_SECTIONS,
-						Usage: "The specific section in a document you'd like to run",
+						Usage: "The specific section in a document you'd like to run, using '/' to separate nested sections and '#N' to select its Nth command (e.g., 'Quick Start/Installation#2')",
 					},
 					&cli.StringFlag{
 						Name:  "doc-name",
 						Usage: "The name of the document",
 					},
+					&cli.BoolFlag{
+						Name:  "fail-fast",
+						Usage: "Stop at the first failed command and skip the rest",
+					},
+					&cli.IntFlag{
+						Name:  "parallel",
+						Value: 1,
+						Usage: "How many top-level sections to run at once",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Validate every command without running it",
+					},
+					&cli.BoolFlag{
+						Name:  "confirm",
+						Usage: "Ask before running each command",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Value: "text",
+						Usage: "The output format of the results (text or json)",
+					},
+					&cli.StringFlag{
+						Name:  "junit",
+						Usage: "Also write the results as a JUnit XML report to this path",
+					},
+					&cli.StringSliceFlag{
+						Name:  "tags",
+						Usage: "Only include commands with any of these tags, including those of their sections",
+					},
+					&cli.StringSliceFlag{
+						Name:  "skip-tags",
+						Usage: "Leave out commands with any of these tags, even if --tags includes them",
+					},
 				},
 				Action: func(ctx context.Context, c *cli.Command) error {
 					section := c.String("section")
@@ -130,45 +519,252 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 						return err
 					}
 
-					results, err := service.ExecuteScript(&document, section)
+					opts := []doyoucompute.OptionsServiceFunc{
+						doyoucompute.WithConcurrency(c.Int("parallel")),
+						doyoucompute.WithDryRun(c.Bool("dry-run")),
+						doyoucompute.WithTags(c.StringSlice("tags")...),
+						doyoucompute.WithSkipTags(c.StringSlice("skip-tags")...),
+					}
+					if c.Bool("fail-fast") {
+						opts = append(opts, doyoucompute.WithExecutionMode(doyoucompute.FailFast))
+					}
+					if c.Bool("confirm") {
+						opts = append(opts, doyoucompute.WithConfirmation(doyoucompute.ConfirmBeforeRun))
+					}
+
+					runService, err := service.With(opts...)
+					if err != nil {
+						return err
+					}
+
+					switch output := c.String("output"); output {
+					case "text":
+						results, err := runService.ExecuteScriptContext(ctx, &document, section)
+						if err != nil {
+							return fmt.Errorf("Failed to execute script: %w", err)
+						}
+
+						if err := writeJUnitFile(c.String("junit"), results); err != nil {
+							return err
+						}
+
+						return reportResults(results)
+					case "json":
+						return reportJSON(ctx, runService, &document, section, c.String("junit"))
+					default:
+						return fmt.Errorf("unsupported output format: %s (expected text or json)", output)
+					}
+				},
+			},
+			{
+				Name:  "validate",
+				Usage: "Checks the document's commands for mistakes and blocked commands without running them",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					issues := document.Validate(doyoucompute.DefaultSecureConfig())
+					if len(issues) == 0 {
+						fmt.Printf("✅ All commands are valid\n")
+						return nil
+					}
+
+					for _, issue := range issues {
+						fmt.Printf("❌ %s\n", issue)
+					}
+
+					return fmt.Errorf("❌ Found %d issue(s) in document '%s'", len(issues), name)
+				},
+			},
+			{
+				Name:  "lint",
+				Usage: "Checks the document's structure for mistakes such as empty sections, lists, and tables",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+					&cli.BoolFlag{
+						Name:  "fail-on-warnings",
+						Usage: "Exit with an error when only warnings are found",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
 					if err != nil {
-						return fmt.Errorf("Failed to execute script: %w", err)
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					issues := doyoucompute.Lint(&document)
+					if len(issues) == 0 {
+						fmt.Printf("✅ No issues found\n")
+						return nil
+					}
+
+					errorCount := 0
+					for _, issue := range issues {
+						if issue.Severity == doyoucompute.LintError {
+							errorCount++
+							fmt.Printf("❌ %s\n", issue)
+							continue
+						}
+
+						fmt.Printf("⚠️  %s\n", issue)
 					}
 
-					// Provide feedback on results
-					var failedCount int
+					if errorCount > 0 || c.Bool("fail-on-warnings") {
+						return fmt.Errorf("❌ Found %d issue(s) in document '%s' (%d error(s))", len(issues), name, errorCount)
+					}
+
+					fmt.Printf("✅ Found %d warning(s) and no errors\n", len(issues))
 
+					return nil
+				},
+			},
+			{
+				Name:  "check-links",
+				Usage: "Checks that the document's links point to files or pages that exist",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+					&cli.StringFlag{
+						Name:  "root",
+						Value: ".",
+						Usage: "The directory relative links are resolved against, usually where the document is rendered to",
+					},
+					&cli.BoolFlag{
+						Name:  "remote",
+						Usage: "Request http(s) links to check they exist, instead of skipping them",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Value: doyoucompute.DefaultLinkCheckTimeout,
+						Usage: "How long to wait for each request",
+					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Value: doyoucompute.DefaultLinkCheckConcurrency,
+						Usage: "How many requests to make at once",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					results := doyoucompute.CheckLinksContext(ctx, &document, doyoucompute.LinkCheckOptions{
+						Root:        c.String("root"),
+						CheckRemote: c.Bool("remote"),
+						Timeout:     c.Duration("timeout"),
+						Concurrency: c.Int("concurrency"),
+					})
+
+					broken, skipped := 0, 0
 					for _, result := range results {
-						if result.Status == doyoucompute.FAILED {
-							failedCount++
-
-							// Extract missing env vars from error message if it's an env validation error
-							if strings.Contains(result.Error.Error(), "environment validation failed") {
-								fmt.Printf("❌ Command failed in section '%s': %s\n", result.SectionName, result.Command)
-								fmt.Printf("   Error: %v\n", result.Error)
-
-								// Give helpful suggestion
-								if strings.Contains(result.Error.Error(), "required environment variables not set") {
-									fmt.Printf("   💡 Tip: Set the required environment variables and try again\n")
-								}
-							} else if strings.Contains(result.Error.Error(), "security validation failed") {
-								fmt.Printf("❌ Command blocked for security in section '%s': %s\n", result.SectionName, result.Command)
-								fmt.Printf("   Error: %v\n", result.Error)
-							} else {
-								fmt.Printf("❌ Command failed in section '%s': %s\n", result.SectionName, result.Command)
-								fmt.Printf("   Error: %v\n", result.Error)
-							}
-							fmt.Println()
-						} else {
-							fmt.Printf("✅ Completed: %s (section: %s)\n", result.Command, result.SectionName)
+						switch result.Status {
+						case doyoucompute.LinkBroken:
+							broken++
+							fmt.Printf("❌ %s\n", result)
+						case doyoucompute.LinkSkipped:
+							skipped++
 						}
 					}
 
-					if failedCount > 0 {
-						return fmt.Errorf("%d out of %d commands failed", failedCount, len(results))
+					if broken > 0 {
+						return fmt.Errorf("❌ Found %d broken link(s) in document '%s'", broken, name)
+					}
+
+					fmt.Printf("✅ All %d checked link(s) are valid (%d skipped)\n", len(results)-skipped, skipped)
+
+					return nil
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "Checks that the tools required by the document's commands are installed",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "section",
+						Value: doyoucompute.ALL_SECTIONS,
+						Usage: "The specific section in a document you'd like to check, using '/' to separate nested sections and '#N' to select its Nth command (e.g., 'Quick Start/Installation#2')",
+					},
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					section := c.String("section")
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					if err := service.CheckPrerequisites(&document, section); err != nil {
+						return fmt.Errorf("❌ Prerequisite check failed: %w", err)
+					}
+
+					fmt.Printf("✅ All required tools are installed\n")
+
+					return nil
+				},
+			},
+			{
+				Name:  "stats",
+				Usage: "Shows counts of the document's sections, commands, links, and words",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the statistics as JSON",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					stats, err := document.Stats()
+					if err != nil {
+						return fmt.Errorf("❌ Failed to compute statistics: %w", err)
+					}
+
+					if c.Bool("json") {
+						encoded, err := json.MarshalIndent(stats, "", "  ")
+						if err != nil {
+							return fmt.Errorf("❌ Failed to encode statistics: %w", err)
+						}
+
+						fmt.Println(string(encoded))
+						return nil
 					}
 
-					fmt.Printf("🎉 All %d commands completed successfully!\n", len(results))
+					fmt.Print(formatStats(stats))
 
 					return nil
 				},
@@ -180,29 +776,50 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 					&cli.StringFlag{
 						Name:  "section",
 						Value: doyoucompute.ALL_SECTIONS,
-						Usage: "The specific section in a document you'd like to run",
+						Usage: "The specific section in a document you'd like to run, using '/' to separate nested sections and '#N' to select its Nth command (e.g., 'Quick Start/Installation#2')",
 					},
 					&cli.StringFlag{
 						Name:  "doc-name",
 						Usage: "The name of the document",
 					},
+					&cli.StringSliceFlag{
+						Name:  "tags",
+						Usage: "Only include commands with any of these tags, including those of their sections",
+					},
+					&cli.StringSliceFlag{
+						Name:  "skip-tags",
+						Usage: "Leave out commands with any of these tags, even if --tags includes them",
+					},
 				},
 				Action: func(ctx context.Context, c *cli.Command) error {
 					section := c.String("section")
 					name := c.String("doc-name")
+					tags := c.StringSlice("tags")
+					skipTags := c.StringSlice("skip-tags")
 
 					document, err := findDoc(name)
 					if err != nil {
 						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
 					}
 
+					planService, err := service.With(doyoucompute.WithTags(tags...), doyoucompute.WithSkipTags(skipTags...))
+					if err != nil {
+						return err
+					}
+
 					fmt.Printf("📋 Creating execution plan for: %s\n", name)
 					if section != doyoucompute.ALL_SECTIONS {
 						fmt.Printf("🎯 Section filter: %s\n", section)
 					}
+					if len(tags) > 0 {
+						fmt.Printf("🏷️  Tags: %s\n", strings.Join(tags, ", "))
+					}
+					if len(skipTags) > 0 {
+						fmt.Printf("🚫 Skipping tags: %s\n", strings.Join(skipTags, ", "))
+					}
 					fmt.Println()
 
-					results, err := service.PlanScriptExecution(&document, section)
+					results, err := planService.PlanScriptExecution(&document, section)
 					if err != nil {
 						return fmt.Errorf("❌ Failed to create execution plan: %w", err)
 					}
@@ -220,11 +837,59 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 
 					for i, result := range results {
 						fmt.Printf("%d. 📍 Section: %s\n", i+1, result.Context.Name)
+						if result.Context.Origin != "" {
+							fmt.Printf("   📎 Included from: %s\n", result.Context.Origin)
+						}
+						switch result.Phase {
+						case doyoucompute.PhaseSetup:
+							fmt.Printf("   🏗️  Setup: runs before the other commands of the section\n")
+						case doyoucompute.PhaseTeardown:
+							fmt.Printf("   🧹 Teardown: runs after the other commands of the section, even if they fail\n")
+						}
+						if result.Step > 0 {
+							fmt.Printf("   🪜 Step %d: skipped if an earlier step of the block fails\n", result.Step)
+						}
 						fmt.Printf("   🐚 Shell: %s\n", result.Shell)
 						fmt.Printf("   ⚡ Command: %s\n", strings.Join(result.Args, " "))
+						for stageIdx, stage := range result.Stages {
+							fmt.Printf("      %d. 🔗 Stage (%s): %s\n", stageIdx+1, stage.Shell, strings.Join(stage.Args, " "))
+							if env := stage.DisplayEnv(); len(env) > 0 {
+								fmt.Printf("         🔧 Injected env vars: %s\n", strings.Join(env, " "))
+							}
+						}
 						if len(result.Environment) > 0 {
 							fmt.Printf("   🌍 Required env vars: %v\n", result.Environment)
 						}
+						if env := result.DisplayEnv(); len(env) > 0 {
+							fmt.Printf("   🔧 Injected env vars: %s\n", strings.Join(env, " "))
+						}
+						if result.WorkingDir != "" {
+							fmt.Printf("   📂 Working directory: %s\n", result.WorkingDir)
+						}
+						if result.Timeout > 0 {
+							fmt.Printf("   ⏱️  Timeout: %s\n", result.Timeout)
+						}
+						if len(result.Platforms) > 0 {
+							fmt.Printf("   💻 Platforms: %s\n", strings.Join(result.Platforms, ", "))
+						}
+						if len(result.SkipIfEnv) > 0 {
+							fmt.Printf("   🚫 Skipped if: %s\n", strings.Join(result.SkipIfEnv, ", "))
+						}
+						if len(result.OnlyIfEnv) > 0 {
+							fmt.Printf("   ✔️  Only if: %s\n", strings.Join(result.OnlyIfEnv, ", "))
+						}
+						if len(result.Requires) > 0 {
+							fmt.Printf("   🧰 Requires: %s\n", strings.Join(result.Requires, ", "))
+						}
+						if len(result.Tags) > 0 {
+							fmt.Printf("   🏷️  Tags: %s\n", strings.Join(result.Tags, ", "))
+						}
+						if result.AllowedCommands != nil {
+							fmt.Printf("   🛡️  Allowed commands: %s\n", strings.Join(result.AllowedCommands, ", "))
+						}
+						if result.AllowedShells != nil {
+							fmt.Printf("   🛡️  Allowed shells: %s\n", strings.Join(result.AllowedShells, ", "))
+						}
 						fmt.Println()
 					}
 
@@ -237,6 +902,95 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 					return nil
 				},
 			},
+			{
+				Name:  "export-script",
+				Usage: "Exports the executable commands of a document as a bash script",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "The path to which you want to write the script",
+					},
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Overwrite the output file even if it was not generated by doyoucompute",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					outpath := c.String("path")
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					fmt.Printf("📜 Exporting script for document: %s\n", name)
+					fmt.Printf("📁 Output path: %s\n", outpath)
+
+					svc, err := service.With(
+						doyoucompute.WithFileRenderer(doyoucompute.NewShellScriptRenderer()),
+						doyoucompute.WithOverwriteProtection(!c.Bool("force")),
+					)
+					if err != nil {
+						return fmt.Errorf("❌ Failed to configure service: %w", err)
+					}
+
+					if err := svc.RenderFileContext(ctx, &document, outpath); err != nil {
+						if errors.Is(err, doyoucompute.ErrHandWrittenFile) {
+							return fmt.Errorf("❌ %w\n💡 Tip: Run 'export-script --doc-name %s --path %s --force' to overwrite it anyway.", err, name, outpath)
+						}
+						return fmt.Errorf("❌ Failed to export script: %w", err)
+					}
+
+					fmt.Printf("✅ Successfully exported '%s' to '%s'\n", name, outpath)
+					fmt.Printf("💡 Tip: Run 'bash %s' to execute it\n", outpath)
+					return nil
+				},
+			},
+			{
+				Name:  "pin-remotes",
+				Usage: "Prints the current digests of all unpinned remote content in a document",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-name",
+						Usage: "The name of the document",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					name := c.String("doc-name")
+
+					document, err := findDoc(name)
+					if err != nil {
+						return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", name)
+					}
+
+					digests, err := service.RemoteDigests(&document)
+					if err != nil {
+						return fmt.Errorf("❌ Failed to compute remote digests: %w", err)
+					}
+
+					if len(digests) == 0 {
+						fmt.Printf("✅ All remote content in '%s' is pinned\n", name)
+						return nil
+					}
+
+					fmt.Printf("📌 Found %d unpinned remote(s) in '%s':\n\n", len(digests), name)
+
+					for _, digest := range digests {
+						fmt.Printf("%d. 📍 Section: %s\n", digest.Index, digest.SectionName)
+						fmt.Printf("   🔒 Pin: %s\n", digest.Digest)
+						fmt.Println()
+					}
+
+					fmt.Printf("💡 Tip: Set the Pin field on each Remote to keep renders reproducible\n")
+
+					return nil
+				},
+			},
 			{
 				Name:  "list",
 				Usage: "List all available docs",
@@ -260,12 +1014,32 @@ func cliBuilder(cliName string, service *doyoucompute.Service, documents map[str
 		},
 	}
 
+	cmd.Commands = append(cmd.Commands, extraCommands...)
+
 	return cmd
 }
 
+// builtinCommandNames returns the names of the commands every CLI provides,
+// which custom commands are not allowed to shadow.
+func builtinCommandNames() []string {
+	builtins := cliBuilder("", nil, nil).Commands
+	names := make([]string, len(builtins))
+
+	for idx, command := range builtins {
+		names[idx] = command.Name
+	}
+
+	return names
+}
+
+// DocumentCommandFunc is the action for a custom command that operates on a single
+// registered document. The document has already been resolved from the --doc-name flag.
+type DocumentCommandFunc func(ctx context.Context, service *doyoucompute.Service, document doyoucompute.Document, c *cli.Command) error
+
 type app struct {
 	documents map[string]doyoucompute.Document
 	service   *doyoucompute.Service
+	commands  []*cli.Command
 }
 
 // New creates a new CLI application instance with the provided service and
@@ -310,14 +1084,72 @@ func (a *app) Register(document doyoucompute.Document) {
 	a.documents[document.Name] = document
 }
 
+// AddCommand registers a custom command alongside the built-in ones.
+// Returns an error if the name collides with a built-in or previously added command.
+func (a *app) AddCommand(cmd *cli.Command) error {
+	if cmd == nil || strings.TrimSpace(cmd.Name) == "" {
+		return errors.New("command name cannot be empty")
+	}
+
+	for _, name := range builtinCommandNames() {
+		if cmd.Name == name {
+			return fmt.Errorf("command '%s' collides with a built-in command", cmd.Name)
+		}
+	}
+
+	for _, existing := range a.commands {
+		if cmd.Name == existing.Name {
+			return fmt.Errorf("command '%s' has already been added", cmd.Name)
+		}
+	}
+
+	a.commands = append(a.commands, cmd)
+
+	return nil
+}
+
+// AddDocumentCommand registers a custom command that receives a registered document.
+// The command gets a --doc-name flag and reports unknown documents the same way the
+// built-in commands do before fn is invoked.
+func (a *app) AddDocumentCommand(name, usage string, fn DocumentCommandFunc) error {
+	return a.AddCommand(&cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "doc-name",
+				Usage: "The name of the document",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			docName := c.String("doc-name")
+
+			document, err := findDocument(a.documents, docName)
+			if err != nil {
+				return fmt.Errorf("❌ Document '%s' not found. Use 'list' command to see available documents.", docName)
+			}
+
+			return fn(ctx, a.service, document, c)
+		},
+	})
+}
+
 // Run executes the CLI application with the provided command-line arguments.
 // This is the main entry point for the CLI functionality.
+// Section references in documents are resolved against the other registered documents.
+// Interrupting the process cancels the running command and skips the rest.
+// Errors are returned rather than logged, so the caller decides how to report them.
 func (a *app) Run(args []string) error {
-	cli := cliBuilder("dycoctl", a.service, a.documents)
-
-	if err := cli.Run(context.Background(), args); err != nil {
-		log.Fatal(err)
+	service, err := a.service.With(doyoucompute.WithDocumentResolver(doyoucompute.DocumentRegistry(a.documents)))
+	if err != nil {
+		return err
 	}
+	a.service = service
 
-	return nil
+	cli := cliBuilder("dycoctl", a.service, a.documents, a.commands...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return cli.Run(ctx, args)
 }
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MoonMoon1919/doyoucompute"
+	"github.com/urfave/cli/v3"
+)
+
+func checkErrors(expectedErrorMsg string, err error, t *testing.T) {
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	if errMsg != expectedErrorMsg {
+		t.Errorf("expected error %s, got %s", expectedErrorMsg, errMsg)
+	}
+}
+
+func newTestApp(t *testing.T) *app {
+	svc, err := doyoucompute.DefaultService()
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %s", err.Error())
+	}
+
+	a := New(svc)
+
+	document, err := doyoucompute.NewDocument("MyDoc")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+	a.Register(document)
+
+	return a
+}
+
+func TestAddCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     []string
+		command      *cli.Command
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			command:      &cli.Command{Name: "publish"},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-BuiltinCollision",
+			command:      &cli.Command{Name: "render"},
+			errorMessage: "command 'render' collides with a built-in command",
+		},
+		{
+			name:         "Fail-DuplicateCustomCommand",
+			existing:     []string{"publish"},
+			command:      &cli.Command{Name: "publish"},
+			errorMessage: "command 'publish' has already been added",
+		},
+		{
+			name:         "Fail-EmptyName",
+			command:      &cli.Command{Name: " "},
+			errorMessage: "command name cannot be empty",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t)
+
+			for _, name := range tc.existing {
+				if err := a.AddCommand(&cli.Command{Name: name}); err != nil {
+					t.Fatalf("unexpected error adding command %s: %s", name, err.Error())
+				}
+			}
+
+			err := a.AddCommand(tc.command)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if a.commands[len(a.commands)-1] != tc.command {
+				t.Errorf("expected command %s to be registered", tc.command.Name)
+			}
+		})
+	}
+}
+
+func TestAddDocumentCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		commandName  string
+		args         []string
+		errorMessage string
+		expectedDoc  string
+	}{
+		{
+			name:         "Passing",
+			commandName:  "publish",
+			args:         []string{"dycoctl", "publish", "--doc-name", "MyDoc"},
+			errorMessage: "",
+			expectedDoc:  "MyDoc",
+		},
+		{
+			name:         "Fail-DocumentNotFound",
+			commandName:  "publish",
+			args:         []string{"dycoctl", "publish", "--doc-name", "Nope"},
+			errorMessage: "❌ Document 'Nope' not found. Use 'list' command to see available documents.",
+		},
+		{
+			name:         "Fail-BuiltinCollision",
+			commandName:  "list",
+			errorMessage: "command 'list' collides with a built-in command",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t)
+
+			var invokedWith string
+			err := a.AddDocumentCommand(tc.commandName, "Renders then uploads a document", func(ctx context.Context, service *doyoucompute.Service, document doyoucompute.Document, c *cli.Command) error {
+				if service != a.service {
+					t.Errorf("expected the app's service to be passed to the command")
+				}
+
+				invokedWith = document.Name
+				return nil
+			})
+			if err != nil {
+				checkErrors(tc.errorMessage, err, t)
+				return
+			}
+
+			if tc.errorMessage == "" {
+				err = a.Run(tc.args)
+			} else {
+				// Run exits the process on failure, so drive the CLI directly for error paths
+				err = cliBuilder("dycoctl", a.service, a.documents, a.commands...).Run(context.Background(), tc.args)
+			}
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if invokedWith != tc.expectedDoc {
+				t.Errorf("expected command to be invoked with document %s, got %s", tc.expectedDoc, invokedWith)
+			}
+		})
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	diff := "--- a.md\n+++ MyDoc (rendered)\n@@ -1 +1 @@\n-old\n+new\n"
+
+	tests := []struct {
+		name     string
+		color    bool
+		maxLines int
+		expected string
+	}{
+		{
+			name:     "Passing-Plain",
+			expected: diff,
+		},
+		{
+			name:     "Passing-Truncated",
+			maxLines: 3,
+			expected: "--- a.md\n+++ MyDoc (rendered)\n@@ -1 +1 @@\n... 2 more line(s) not shown, use --max-diff-lines 0 to show the whole diff\n",
+		},
+		{
+			name:     "Passing-Colored",
+			color:    true,
+			expected: ansiBold + "--- a.md" + ansiReset + "\n" + ansiBold + "+++ MyDoc (rendered)" + ansiReset + "\n" + ansiCyan + "@@ -1 +1 @@" + ansiReset + "\n" + ansiRed + "-old" + ansiReset + "\n" + ansiGreen + "+new" + ansiReset + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if formatted := formatDiff(diff, tc.color, tc.maxLines); formatted != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestFormatStats(t *testing.T) {
+	stats := doyoucompute.DocumentStats{
+		Sections:        3,
+		SectionsByDepth: map[int]int{2: 1, 1: 2},
+		Paragraphs:      4,
+		Executables:     2,
+		Links:           1,
+		Words:           250,
+		ReadingTime:     75 * time.Second,
+	}
+
+	expected := "Sections      3\n" +
+		"  Depth 1     2\n" +
+		"  Depth 2     1\n" +
+		"Paragraphs    4\n" +
+		"Executables   2\n" +
+		"Code blocks   0\n" +
+		"Tables        0\n" +
+		"Links         1\n" +
+		"Remotes       0\n" +
+		"Words         250\n" +
+		"Reading time  1m15s\n"
+
+	if formatted := formatStats(stats); formatted != expected {
+		t.Errorf("expected %q, got %q", expected, formatted)
+	}
+}
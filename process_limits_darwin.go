@@ -0,0 +1,24 @@
+//go:build darwin
+
+package doyoucompute
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// applyProcessLimits applies the niceness of config to the process group of a started command.
+// Processes the command starts afterwards inherit it. Process limits are not supported.
+func applyProcessLimits(pid int, config ExecutionConfig) error {
+	if config.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PGRP, pid, config.Nice); err != nil {
+			return fmt.Errorf("setting niceness: %w", err)
+		}
+	}
+
+	if config.MaxProcesses > 0 {
+		return fmt.Errorf("%w: MaxProcesses", ErrLimitsNotSupported)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build linux && !(mips || mipsle || mips64 || mips64le)
+
+package doyoucompute
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// rlimitNproc is RLIMIT_NPROC, which the syscall package does not define. Its value differs
+// on MIPS, which is excluded by the build constraint.
+const rlimitNproc = 6
+
+// applyProcessLimits applies the niceness and process limit of config to the process group of
+// a started command. Processes the command starts afterwards inherit them.
+func applyProcessLimits(pid int, config ExecutionConfig) error {
+	if config.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PGRP, pid, config.Nice); err != nil {
+			return fmt.Errorf("setting niceness: %w", err)
+		}
+	}
+
+	if config.MaxProcesses > 0 {
+		limit := syscall.Rlimit{Cur: uint64(config.MaxProcesses), Max: uint64(config.MaxProcesses)}
+		_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), rlimitNproc, uintptr(unsafe.Pointer(&limit)), 0, 0, 0)
+		if errno != 0 {
+			return fmt.Errorf("setting process limit: %w", errno)
+		}
+	}
+
+	return nil
+}
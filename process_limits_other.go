@@ -0,0 +1,14 @@
+//go:build !darwin && !(linux && !(mips || mipsle || mips64 || mips64le))
+
+package doyoucompute
+
+import "fmt"
+
+// applyProcessLimits reports that process limits are not supported on this platform.
+func applyProcessLimits(pid int, config ExecutionConfig) error {
+	if config.Nice != 0 || config.MaxProcesses > 0 {
+		return fmt.Errorf("%w on this platform", ErrLimitsNotSupported)
+	}
+
+	return nil
+}
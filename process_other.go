@@ -0,0 +1,9 @@
+//go:build !unix
+
+package doyoucompute
+
+import "os/exec"
+
+// configureProcess is a no-op on platforms without process groups; cancelling
+// the command only stops the process itself.
+func configureProcess(cmd *exec.Cmd) {}
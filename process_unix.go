@@ -0,0 +1,17 @@
+//go:build unix
+
+package doyoucompute
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess starts the command in its own process group so that cancelling
+// it (e.g. on timeout) also stops any children spawned by the shell.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,305 @@
+package doyoucompute
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultRemoteTimeout is how long remote content created by NewRemoteURL waits for a response.
+const DefaultRemoteTimeout = 10 * time.Second
+
+// ErrRemoteStatus is returned when a server answers a request for remote content with a
+// status that was not expected.
+var ErrRemoteStatus = errors.New("unexpected status")
+
+// RemoteCacheEntry is the content of a URL stored in a RemoteCache, with the ETag it was served with.
+type RemoteCacheEntry struct {
+	// ETag is the entity tag the server sent with the content
+	ETag string `json:"etag"`
+	// Content is the body of the response
+	Content []byte `json:"content"`
+}
+
+// RemoteCache stores remote content by URL, so it is only downloaded again when the server
+// reports a different ETag.
+type RemoteCache interface {
+	// Get returns the entry stored for url, or false if there is none.
+	Get(url string) (RemoteCacheEntry, bool)
+	// Put stores the entry for url, replacing any entry stored before.
+	Put(url string, entry RemoteCacheEntry) error
+}
+
+// MemoryRemoteCache is a RemoteCache that keeps entries in memory for the life of the process.
+// It is safe for concurrent use.
+type MemoryRemoteCache struct {
+	mu      sync.Mutex
+	entries map[string]RemoteCacheEntry
+}
+
+// NewMemoryRemoteCache creates an empty in-memory cache.
+func NewMemoryRemoteCache() *MemoryRemoteCache {
+	return &MemoryRemoteCache{entries: map[string]RemoteCacheEntry{}}
+}
+
+// Get returns the entry stored for url, or false if there is none.
+func (m *MemoryRemoteCache) Get(url string) (RemoteCacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[url]
+
+	return entry, ok
+}
+
+// Put stores the entry for url.
+func (m *MemoryRemoteCache) Put(url string, entry RemoteCacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[url] = entry
+
+	return nil
+}
+
+// DiskRemoteCache is a RemoteCache that keeps each entry in a file of a directory, so entries
+// are reused by later runs, such as CI jobs restoring the directory from their cache.
+type DiskRemoteCache struct {
+	dir string
+}
+
+// NewDiskRemoteCache creates a cache storing its entries in dir, which is created when the
+// first entry is stored.
+func NewDiskRemoteCache(dir string) *DiskRemoteCache {
+	return &DiskRemoteCache{dir: dir}
+}
+
+// path returns the file the entry for url is stored in.
+func (d *DiskRemoteCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry stored for url, or false if there is none or it cannot be read.
+func (d *DiskRemoteCache) Get(url string) (RemoteCacheEntry, bool) {
+	data, err := os.ReadFile(d.path(url))
+	if err != nil {
+		return RemoteCacheEntry{}, false
+	}
+
+	var entry RemoteCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return RemoteCacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// Put stores the entry for url in its own file.
+func (d *DiskRemoteCache) Put(url string, entry RemoteCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create remote cache directory '%s': %w", d.dir, err)
+	}
+
+	return os.WriteFile(d.path(url), data, 0644)
+}
+
+// RemoteOption configures remote content created by NewRemoteURL.
+type RemoteOption func(f *urlFetcher) error
+
+// WithRemoteClient sets the client requests are made with. Defaults to http.DefaultClient.
+func WithRemoteClient(client *http.Client) RemoteOption {
+	return func(f *urlFetcher) error {
+		if client == nil {
+			return errors.New("remote client cannot be nil")
+		}
+
+		f.client = client
+
+		return nil
+	}
+}
+
+// WithRemoteTimeout sets how long to wait for a response. Defaults to DefaultRemoteTimeout.
+func WithRemoteTimeout(timeout time.Duration) RemoteOption {
+	return func(f *urlFetcher) error {
+		if timeout <= 0 {
+			return fmt.Errorf("remote timeout must be positive, got %s", timeout)
+		}
+
+		f.timeout = timeout
+
+		return nil
+	}
+}
+
+// WithExpectedStatus sets the response statuses that are accepted. Defaults to 200 OK.
+func WithExpectedStatus(statuses ...int) RemoteOption {
+	return func(f *urlFetcher) error {
+		if len(statuses) == 0 {
+			return errors.New("expected statuses cannot be empty")
+		}
+
+		f.expected = statuses
+
+		return nil
+	}
+}
+
+// WithRemoteCache stores the content in cache and revalidates it with the server's ETag on
+// later fetches, so unchanged content is not downloaded again. Responses without an ETag are
+// not cached.
+func WithRemoteCache(cache RemoteCache) RemoteOption {
+	return func(f *urlFetcher) error {
+		if cache == nil {
+			return errors.New("remote cache cannot be nil")
+		}
+
+		f.cache = cache
+
+		return nil
+	}
+}
+
+// NewRemoteURL creates remote content downloaded from an http(s) URL, such as a shared snippet
+// on raw.githubusercontent.com. Nothing is downloaded until the content is materialized, and
+// it is downloaded again, or revalidated against the cache, each time it is. Errors name the
+// URL. Returns an error if the URL is not an http(s) URL or an option is invalid.
+func NewRemoteURL(rawURL string, opts ...RemoteOption) (Remote, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return Remote{}, fmt.Errorf("invalid remote URL '%s': %w", rawURL, err)
+	}
+
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Remote{}, fmt.Errorf("invalid remote URL '%s': must be an http or https URL", rawURL)
+	}
+
+	fetcher := &urlFetcher{
+		url:      rawURL,
+		client:   http.DefaultClient,
+		timeout:  DefaultRemoteTimeout,
+		expected: []int{http.StatusOK},
+	}
+
+	for _, opt := range opts {
+		if err := opt(fetcher); err != nil {
+			return Remote{}, err
+		}
+	}
+
+	return Remote{Reader: fetcher}, nil
+}
+
+// urlFetcher is the Reader of remote content created by NewRemoteURL. The content is
+// downloaded on the first read, and again on the first read after the previous content was
+// read to the end.
+type urlFetcher struct {
+	url      string
+	client   *http.Client
+	timeout  time.Duration
+	expected []int
+	cache    RemoteCache
+
+	mu      sync.Mutex
+	content *bytes.Reader
+}
+
+// remoteURL returns the URL the content of remote is downloaded from, if it was created by
+// NewRemoteURL.
+func remoteURL(remote Remote) (string, bool) {
+	fetcher, ok := remote.Reader.(*urlFetcher)
+	if !ok {
+		return "", false
+	}
+
+	return fetcher.url, true
+}
+
+// Read downloads the content if it hasn't been yet, then reads from it.
+func (f *urlFetcher) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.content == nil {
+		content, err := f.fetch()
+		if err != nil {
+			return 0, fmt.Errorf("fetching remote content from '%s': %w", f.url, err)
+		}
+
+		f.content = bytes.NewReader(content)
+	}
+
+	n, err := f.content.Read(p)
+	if err == io.EOF {
+		f.content = nil
+	}
+
+	return n, err
+}
+
+// fetch downloads the content, sending the cached ETag so the server can answer 304 Not
+// Modified when it has not changed.
+func (f *urlFetcher) fetch() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached RemoteCacheEntry
+	var hasCached bool
+	if f.cache != nil {
+		cached, hasCached = f.cache.Get(f.url)
+		if hasCached && cached.ETag != "" {
+			request.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && hasCached {
+		return cached.Content, nil
+	}
+
+	if !slices.Contains(f.expected, response.StatusCode) {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteStatus, response.Status)
+	}
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if etag := response.Header.Get("ETag"); f.cache != nil && etag != "" {
+		if err := f.cache.Put(f.url, RemoteCacheEntry{ETag: etag, Content: content}); err != nil {
+			return nil, fmt.Errorf("caching content: %w", err)
+		}
+	}
+
+	return content, nil
+}
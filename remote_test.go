@@ -0,0 +1,221 @@
+package doyoucompute
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewRemoteURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/snippet.md":
+			w.Write([]byte("Shared snippet."))
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("Accepted."))
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		url           string
+		options       []RemoteOption
+		expected      string
+		createError   string
+		errorContains string
+		wrapped       error
+	}{
+		{
+			name:     "Passing-OK",
+			url:      server.URL + "/snippet.md",
+			expected: "Shared snippet.",
+		},
+		{
+			name:     "Passing-ExpectedStatus",
+			url:      server.URL + "/accepted",
+			options:  []RemoteOption{WithExpectedStatus(http.StatusOK, http.StatusAccepted)},
+			expected: "Accepted.",
+		},
+		{
+			name:          "Failing-NotFound",
+			url:           server.URL + "/missing.md",
+			errorContains: "fetching remote content from '" + server.URL + "/missing.md': unexpected status: 404 Not Found",
+			wrapped:       ErrRemoteStatus,
+		},
+		{
+			name:          "Failing-UnexpectedStatus",
+			url:           server.URL + "/accepted",
+			errorContains: "unexpected status: 202 Accepted",
+			wrapped:       ErrRemoteStatus,
+		},
+		{
+			name:          "Failing-Timeout",
+			url:           server.URL + "/slow",
+			options:       []RemoteOption{WithRemoteTimeout(50 * time.Millisecond)},
+			errorContains: "fetching remote content from '" + server.URL + "/slow'",
+		},
+		{
+			name:        "Failing-NotHTTP",
+			url:         "file:///etc/passwd",
+			createError: "invalid remote URL 'file:///etc/passwd': must be an http or https URL",
+		},
+		{
+			name:        "Failing-InvalidTimeout",
+			url:         server.URL,
+			options:     []RemoteOption{WithRemoteTimeout(0)},
+			createError: "remote timeout must be positive, got 0s",
+		},
+		{
+			name:        "Failing-NoExpectedStatus",
+			url:         server.URL,
+			options:     []RemoteOption{WithExpectedStatus()},
+			createError: "expected statuses cannot be empty",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			remote, err := NewRemoteURL(tc.url, tc.options...)
+
+			checkErrors(tc.createError, err, t)
+			if tc.createError != "" {
+				return
+			}
+
+			content, err := remote.Materialize()
+			if tc.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tc.errorContains, err)
+				}
+				if tc.wrapped != nil && !errors.Is(err, tc.wrapped) {
+					t.Errorf("Expected error to wrap %v, got %v", tc.wrapped, err)
+				}
+				return
+			}
+
+			checkErrors("", err, t)
+			if content.Content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content.Content)
+			}
+		})
+	}
+}
+
+func TestNewRemoteURLRendersLazily(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("Shared snippet."))
+	}))
+	defer server.Close()
+
+	remote, err := NewRemoteURL(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error creating remote: %s", err.Error())
+	}
+
+	document := MustNewDocument("MyDoc")
+	document.CreateSection("Snippet").WriteRemoteContent(remote)
+
+	if requests.Load() != 0 {
+		t.Fatalf("Expected no request before rendering, got %d", requests.Load())
+	}
+
+	for range 2 {
+		if content := renderMarkdown(t, &document); !strings.Contains(content, "Shared snippet.") {
+			t.Errorf("Expected rendered content to hold the snippet, got %q", content)
+		}
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected a request per render, got %d", requests.Load())
+	}
+}
+
+func TestNewRemoteURLCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache func(t *testing.T) RemoteCache
+	}{
+		{
+			name:  "Memory",
+			cache: func(t *testing.T) RemoteCache { return NewMemoryRemoteCache() },
+		},
+		{
+			name:  "Disk",
+			cache: func(t *testing.T) RemoteCache { return NewDiskRemoteCache(t.TempDir() + "/cache") },
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var downloads, hits atomic.Int32
+			etag := `"v1"`
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == etag {
+					hits.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				downloads.Add(1)
+				w.Header().Set("ETag", etag)
+				w.Write([]byte("Content " + etag))
+			}))
+			defer server.Close()
+
+			cache := tc.cache(t)
+			materialize := func() string {
+				t.Helper()
+
+				// A new remote each time, as a later run would create
+				remote, err := NewRemoteURL(server.URL+"/snippet.md", WithRemoteCache(cache))
+				if err != nil {
+					t.Fatalf("unexpected error creating remote: %s", err.Error())
+				}
+
+				content, err := remote.Materialize()
+				if err != nil {
+					t.Fatalf("unexpected error materializing: %s", err.Error())
+				}
+
+				return content.Content
+			}
+
+			if content := materialize(); content != `Content "v1"` {
+				t.Errorf("Expected downloaded content, got %q", content)
+			}
+
+			if content := materialize(); content != `Content "v1"` {
+				t.Errorf("Expected cached content, got %q", content)
+			}
+
+			if downloads.Load() != 1 || hits.Load() != 1 {
+				t.Errorf("Expected 1 download and 1 cache hit, got %d and %d", downloads.Load(), hits.Load())
+			}
+
+			// A changed ETag downloads the new content
+			etag = `"v2"`
+			if content := materialize(); content != `Content "v2"` {
+				t.Errorf("Expected new content after the ETag changed, got %q", content)
+			}
+
+			if downloads.Load() != 2 {
+				t.Errorf("Expected 2 downloads, got %d", downloads.Load())
+			}
+		})
+	}
+}
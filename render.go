@@ -3,7 +3,12 @@ package doyoucompute
 import (
 	"errors"
 	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +22,117 @@ type Renderer[T any] interface {
 	Render(node Node) (T, error)
 }
 
+// NodeRenderer is implemented by renderers that can render part of a document, such as a
+// single section, as a standalone file.
+type NodeRenderer interface {
+	// RenderNode renders node as if it were the top of a document.
+	RenderNode(node Node) (string, error)
+}
+
+// StreamRenderer is implemented by renderers that can write their output incrementally,
+// avoiding holding the whole rendered document in memory.
+type StreamRenderer interface {
+	// RenderTo processes a node and writes the output to w as the tree is walked.
+	// Returns an error if rendering fails or w cannot be written to.
+	RenderTo(node Node, w io.Writer) error
+}
+
+// UnknownNodePolicy controls how a renderer treats node types it has no handler for,
+// such as custom nodes or node types newer than the renderer.
+type UnknownNodePolicy int
+
+const (
+	// UnknownNodesError fails the whole render when an unknown node is encountered
+	UnknownNodesError UnknownNodePolicy = iota + 1
+	// UnknownNodesSkip leaves unknown nodes out of the output
+	UnknownNodesSkip
+	// UnknownNodesText renders the materialized content of unknown nodes as plain text
+	UnknownNodesText
+)
+
+// ParseUnknownNodePolicy converts "error", "skip", or "text" into an UnknownNodePolicy.
+func ParseUnknownNodePolicy(value string) (UnknownNodePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "error":
+		return UnknownNodesError, nil
+	case "skip":
+		return UnknownNodesSkip, nil
+	case "text":
+		return UnknownNodesText, nil
+	}
+
+	return 0, fmt.Errorf("invalid unknown node policy '%s' (expected error, skip, or text)", value)
+}
+
+// errSkipNode signals that a node produced no output and should be left out entirely,
+// rather than rendered as an empty string that would leave blank lines behind.
+var errSkipNode = errors.New("node skipped")
+
+// RenderError records where in a document a render failed.
+type RenderError struct {
+	// Path is the section path the failing node was rendered under, such as "MyDoc > Quick Start"
+	Path string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("rendering %q: %s", e.Path, e.Err)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// wrapRenderError annotates err with the section path it was raised under. Errors are only
+// wrapped once, at the innermost section, so the path points at the failing node.
+func wrapRenderError(err error, contextPath ContextPath) error {
+	if err == nil || errors.Is(err, errSkipNode) || len(contextPath) == 0 {
+		return err
+	}
+
+	var renderErr *RenderError
+	if errors.As(err, &renderErr) {
+		return err
+	}
+
+	return &RenderError{Path: contextPath.String(), Err: err}
+}
+
+// resolve applies the policy to a content node the renderer has no handler for. Nodes that
+// cannot be materialized are skipped under the text policy. unhandled is returned under the error policy.
+func (p UnknownNodePolicy) resolve(node Node, unhandled error) (string, error) {
+	switch p {
+	case UnknownNodesSkip:
+		return "", errSkipNode
+	case UnknownNodesText:
+		contenter, ok := node.(Contenter)
+		if !ok {
+			return "", errSkipNode
+		}
+
+		content, err := contenter.Materialize()
+		if err != nil {
+			return "", err
+		}
+
+		return content.Content, nil
+	}
+
+	return "", unhandled
+}
+
+// GeneratedMarker is embedded in rendered files so that generated output can be
+// told apart from hand-written files living at the same path.
+const GeneratedMarker = "Code generated by doyoucompute. DO NOT EDIT."
+
+// Stamper is implemented by renderers whose output format can carry the
+// GeneratedMarker without affecting how the output is displayed.
+type Stamper interface {
+	// Stamp appends the GeneratedMarker to already rendered content.
+	Stamp(content string) string
+}
+
 func getStringFromMetadata(metadata map[string]interface{}, key string) (string, error) {
 	val, exists := metadata[key]
 	if !exists {
@@ -56,6 +172,20 @@ func getStringsFromMetadata(metadata map[string]interface{}, key string) ([]stri
 	return nil, fmt.Errorf("metadata key %s expected []string, got %T", key, val)
 }
 
+func getStringSlicesFromMetadata(metadata map[string]interface{}, key string) ([][]string, error) {
+	val, exists := metadata[key]
+	if !exists {
+		return nil, fmt.Errorf("missing metadata key: %s", key)
+	}
+
+	slices, ok := val.([][]string)
+	if !ok {
+		return nil, fmt.Errorf("metadata key %s expected [][]string, got %T", key, val)
+	}
+
+	return slices, nil
+}
+
 // MARK: Tracking
 
 // SectionInfo contains metadata about a section's position within the document hierarchy.
@@ -64,6 +194,15 @@ type SectionInfo struct {
 	Name string
 	// Level indicates the nesting depth of the section (1 for top-level, 2 for subsection, etc.)
 	Level int
+	// Origin is the "document/section path" a section was included from through a SectionRef,
+	// or empty for sections written directly in the document
+	Origin string
+	// Number is the section's dotted position in the document (e.g., "2.3"),
+	// set only when section numbering is enabled
+	Number string
+
+	// sections counts the child sections numbered so far
+	sections int
 }
 
 // ContextPath represents a stack of section information that tracks the current
@@ -72,9 +211,10 @@ type ContextPath []SectionInfo
 
 // Push adds a new section to the context path and returns the updated path.
 // The level is automatically calculated based on the current depth.
+// Sections nested inside an included section inherit its Origin.
 func (c ContextPath) Push(name string) ContextPath {
 	level := len(c) + 1
-	return append(c, SectionInfo{Name: name, Level: level})
+	return append(c, SectionInfo{Name: name, Level: level, Origin: c.Current().Origin})
 }
 
 // Current returns the SectionInfo for the current (most recent) section.
@@ -87,6 +227,24 @@ func (c ContextPath) Current() SectionInfo {
 	return c[len(c)-1]
 }
 
+// nextSectionNumber counts a new child section of the current section and returns its number.
+// Siblings share the parent's entry, so consecutive calls number them 1, 2, 3, and so on.
+func (c ContextPath) nextSectionNumber() string {
+	parent := &c[len(c)-1]
+	parent.sections++
+
+	return childSectionNumber(parent.Number, parent.sections)
+}
+
+// childSectionNumber appends a child's position to its parent's dotted number.
+func childSectionNumber(parent string, position int) string {
+	if parent == "" {
+		return strconv.Itoa(position)
+	}
+
+	return parent + "." + strconv.Itoa(position)
+}
+
 // CurrentSection returns the name of the current section.
 // Returns an empty string if no sections are in the path.
 func (c ContextPath) CurrentSection() string {
@@ -97,6 +255,21 @@ func (c ContextPath) CurrentSection() string {
 	return c.Current().Name
 }
 
+// Names returns the names of the sections in the path, starting with the document.
+func (c ContextPath) Names() []string {
+	names := make([]string, len(c))
+	for idx, info := range c {
+		names[idx] = info.Name
+	}
+
+	return names
+}
+
+// String returns the section names in the path joined with " > ", such as "MyDoc > Quick Start".
+func (c ContextPath) String() string {
+	return strings.Join(c.Names(), " > ")
+}
+
 // CurrentLevel returns the nesting level of the current section.
 // Returns -1 if no sections are in the path.
 func (c ContextPath) CurrentLevel() int {
@@ -111,15 +284,210 @@ func (c ContextPath) CurrentLevel() int {
 
 // Markdown implements the Renderer interface to convert document nodes into markdown format.
 // It handles hierarchical document structures and maintains proper heading levels during traversal.
-type Markdown struct{}
+type Markdown struct {
+	unknownNodes     UnknownNodePolicy
+	htmlLineBreaks   bool
+	sectionNumbering bool
+	headingOffset    int
+	maxHeadingLevel  int  // zero means defaultMaxHeadingLevel
+	bulletMarker     rune // zero means '-'
+	fenceStyle       FenceStyle
+	omitComments     bool
+	lineEnding       LineEnding
+	trailingNewline  bool
+
+	// anchors holds the headings of the tree being rendered, used to resolve cross references
+	anchors []sectionAnchor
+}
+
+// LineEnding selects the line terminator written by the Markdown renderer.
+type LineEnding int
+
+const (
+	// LineEndingLF terminates lines with "\n"
+	LineEndingLF LineEnding = iota + 1
+	// LineEndingCRLF terminates lines with "\r\n", as Windows tools expect
+	LineEndingCRLF
+)
+
+// WithLineEnding sets the line terminator used in the rendered output. Line endings in
+// remote content are converted as well. Defaults to LineEndingLF.
+func WithLineEnding(ending LineEnding) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		if ending < LineEndingLF || ending > LineEndingCRLF {
+			return nil, fmt.Errorf("invalid line ending: %d", ending)
+		}
+
+		m.lineEnding = ending
+
+		return nil, nil
+	}
+}
+
+// WithSingleTrailingNewline guarantees the output ends with exactly one newline,
+// trimming extras left by trailing lists or remote content.
+func WithSingleTrailingNewline() OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		m.trailingNewline = true
+
+		return nil, nil
+	}
+}
+
+// WithComments controls whether Comment nodes are rendered. Disabling comments leaves them
+// out entirely, which suits published docs; templates usually want to keep them. Defaults to true.
+func WithComments(enabled bool) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		m.omitComments = !enabled
+
+		return nil, nil
+	}
+}
+
+// WithHTMLLineBreaks renders line breaks in paragraphs as <br> tags instead of
+// trailing double spaces, which some editors strip.
+func WithHTMLLineBreaks(enabled bool) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		m.htmlLineBreaks = enabled
+
+		return nil, nil
+	}
+}
+
+// WithSectionNumbering prefixes section headings with their dotted position in the document,
+// such as "2.3 Database Setup". The document title is not numbered.
+func WithSectionNumbering() OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		m.sectionNumbering = true
+
+		return nil, nil
+	}
+}
+
+// FenceStyle selects the characters used to fence code blocks in markdown output.
+type FenceStyle int
+
+const (
+	// FenceBackticks fences code blocks with ```
+	FenceBackticks FenceStyle = iota + 1
+	// FenceTildes fences code blocks with ~~~
+	FenceTildes
+)
+
+// WithBulletMarker sets the marker used for bulleted and task list items: '-', '*', or '+'.
+// Defaults to '-'.
+func WithBulletMarker(marker rune) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		switch marker {
+		case '-', '*', '+':
+		default:
+			return nil, fmt.Errorf("invalid bullet marker '%c' (expected '-', '*', or '+')", marker)
+		}
+
+		m.bulletMarker = marker
+
+		return nil, nil
+	}
+}
+
+// WithFenceStyle sets whether code blocks are fenced with backticks or tildes.
+// Defaults to FenceBackticks.
+func WithFenceStyle(style FenceStyle) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		if style < FenceBackticks || style > FenceTildes {
+			return nil, fmt.Errorf("invalid fence style: %d", style)
+		}
+
+		m.fenceStyle = style
+
+		return nil, nil
+	}
+}
+
+// defaultMaxHeadingLevel is the deepest heading the Markdown renderer writes unless configured otherwise.
+const defaultMaxHeadingLevel = 5
+
+// WithHeadingOffset shifts every heading down by n levels, so an offset of 1 starts the
+// document title at H2. Useful when embedding generated content into a larger page.
+func WithHeadingOffset(n int) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		if n < 0 {
+			return nil, fmt.Errorf("heading offset cannot be negative, got %d", n)
+		}
+
+		m.headingOffset = n
+
+		return nil, nil
+	}
+}
+
+// WithMaxHeadingLevel sets the deepest heading level written; deeper headings are clamped to it.
+// Defaults to 5.
+func WithMaxHeadingLevel(level int) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		if level < 1 || level > 6 {
+			return nil, fmt.Errorf("max heading level must be between 1 and 6, got %d", level)
+		}
+
+		m.maxHeadingLevel = level
+
+		return nil, nil
+	}
+}
+
+// NewMarkdownRenderer creates a new Markdown renderer instance configured by the provided options.
+// Returns an error if any option is invalid.
+func NewMarkdownRenderer(opts ...OptionBuilder[Markdown]) (Markdown, error) {
+	renderer := Markdown{
+		unknownNodes: UnknownNodesError,
+	}
+
+	if err := ApplyOptions(&renderer, opts...); err != nil {
+		return Markdown{}, err
+	}
+
+	// The offset and cap are set independently, so they can only be checked against each other here
+	if top := 1 + renderer.headingOffset; top > renderer.maxLevel() {
+		return Markdown{}, fmt.Errorf("heading offset %d puts the top heading at level %d, past the max heading level %d", renderer.headingOffset, top, renderer.maxLevel())
+	}
 
-// NewMarkdownRenderer creates a new Markdown renderer instance.
-func NewMarkdownRenderer() Markdown {
-	return Markdown{}
+	return renderer, nil
+}
+
+func (m Markdown) maxLevel() int {
+	if m.maxHeadingLevel == 0 {
+		return defaultMaxHeadingLevel
+	}
+
+	return m.maxHeadingLevel
+}
+
+// headingLevel applies the configured offset and cap to a level computed from the context path.
+func (m Markdown) headingLevel(level int) int {
+	return min(level+m.headingOffset, m.maxLevel())
+}
+
+// WithUnknownNodes sets how the renderer handles node types it has no handler for.
+// Defaults to UnknownNodesError.
+func WithUnknownNodes(policy UnknownNodePolicy) OptionBuilder[Markdown] {
+	return func(m *Markdown) (Finalizer[Markdown], error) {
+		if policy < UnknownNodesError || policy > UnknownNodesText {
+			return nil, fmt.Errorf("invalid unknown node policy: %d", policy)
+		}
+
+		m.unknownNodes = policy
+
+		return nil, nil
+	}
 }
 
 func (m Markdown) writeHeader(builder *strings.Builder, content string, level int) {
-	fmt.Fprintf(builder, "%s %s\n\n", strings.Repeat("#", level), content)
+	builder.WriteString(m.headerLine(content, level))
+	builder.WriteString("\n\n")
+}
+
+func (m Markdown) headerLine(content string, level int) string {
+	return fmt.Sprintf("%s %s", strings.Repeat("#", level), content)
 }
 
 func (m Markdown) renderChildren(children []Node, contextPath *ContextPath) ([]string, error) {
@@ -127,29 +495,63 @@ func (m Markdown) renderChildren(children []Node, contextPath *ContextPath) ([]s
 		return nil, nil
 	}
 
-	results := make([]string, len(children))
+	results := make([]string, 0, len(children))
 
-	for idx, leaf := range children {
+	for _, leaf := range children {
 		leafContent, err := m.renderWithTracking(leaf, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		results[idx] = leafContent
+		results = append(results, leafContent)
 	}
 
 	return results, nil
 }
 
-func (m Markdown) renderParagraph(p Structurer, contextPath *ContextPath) (string, error) {
-	childContent, err := m.renderChildren(p.Children(), contextPath)
-	if err != nil {
-		return "", err
+func (m Markdown) lineBreak() string {
+	if m.htmlLineBreaks {
+		return "<br>\n"
 	}
 
+	return "  \n"
+}
+
+// renderParagraph joins the paragraph's items with single spaces. Line breaks replace the space
+// between the items around them, so consecutive breaks collapse into one and breaks
+// at the start or end of the paragraph are dropped.
+func (m Markdown) renderParagraph(p Structurer, contextPath *ContextPath) (string, error) {
 	var builder strings.Builder
+	pendingBreak := false
+
+	for _, item := range p.Children() {
+		if item.Type() == LineBreakType {
+			pendingBreak = builder.Len() > 0
+			continue
+		}
+
+		content, err := m.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if builder.Len() > 0 {
+			if pendingBreak {
+				builder.WriteString(m.lineBreak())
+			} else {
+				builder.WriteString(" ")
+			}
+		}
+		pendingBreak = false
 
-	builder.WriteString(strings.Join(childContent, " "))
+		builder.WriteString(content)
+	}
 
 	return builder.String(), nil
 }
@@ -165,11 +567,7 @@ func (m Markdown) renderDocument(d *Document, contextPath *ContextPath) (string,
 
 	var builder strings.Builder
 
-	// Don't exceed an H5
-	level := ctxPath.CurrentLevel()
-	if ctxPath.CurrentLevel() > 5 {
-		level = 5
-	}
+	level := m.headingLevel(ctxPath.CurrentLevel())
 
 	if d.HasFrontmatter() {
 		frontmatter, err := m.renderFrontmatter(d.Frontmatter)
@@ -190,7 +588,14 @@ func (m Markdown) renderDocument(d *Document, contextPath *ContextPath) (string,
 }
 
 func (m Markdown) renderSection(s Structurer, contextPath *ContextPath) (string, error) {
+	heading := s.Identifier()
+
 	ctxPath := contextPath.Push(s.Identifier())
+	if m.sectionNumbering && len(*contextPath) > 0 {
+		number := contextPath.nextSectionNumber()
+		ctxPath[len(ctxPath)-1].Number = number
+		heading = fmt.Sprintf("%s %s", number, heading)
+	}
 	contextPath = &ctxPath // Update the context path so as we walk the tree we correctly track header level
 
 	childContent, err := m.renderChildren(s.Children(), contextPath)
@@ -200,22 +605,20 @@ func (m Markdown) renderSection(s Structurer, contextPath *ContextPath) (string,
 
 	var builder strings.Builder
 
-	// Don't exceed an H5
-	level := ctxPath.CurrentLevel()
-	if ctxPath.CurrentLevel() > 5 {
-		level = 5
+	// A section with no content is just its heading, so it doesn't leave a gap behind
+	builder.WriteString(m.headerLine(heading, m.headingLevel(ctxPath.CurrentLevel())))
+	for _, content := range childContent {
+		builder.WriteString("\n\n")
+		builder.WriteString(content)
 	}
 
-	m.writeHeader(&builder, s.Identifier(), level)
-	builder.WriteString(strings.Join(childContent, "\n\n"))
-
 	return builder.String(), nil
 }
 
 func (m Markdown) renderTable(t *Table, contextPath *ContextPath) (string, error) {
 	var builder strings.Builder
 
-	joiner := strings.Join(t.Headers, " | ")
+	joiner := strings.Join(escapeTableCells(t.Headers), " | ")
 
 	// Header row
 	builder.WriteString("| ")
@@ -241,9 +644,22 @@ func (m Markdown) renderTable(t *Table, contextPath *ContextPath) (string, error
 	builder.WriteString("\n")
 
 	// Children
-	childContent, err := m.renderChildren(t.Children(), contextPath)
-	if err != nil {
-		return "", err
+	childContent := make([]string, 0, len(t.Items))
+
+	for _, row := range t.Items {
+		var rowContent string
+		var err error
+
+		if row.Rich() {
+			rowContent, err = m.renderRichTableRow(row, len(t.Headers), contextPath)
+		} else {
+			rowContent, err = m.renderWithTracking(padTableRow(row, len(t.Headers)), contextPath)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		childContent = append(childContent, rowContent)
 	}
 
 	builder.WriteString(strings.Join(childContent, "\n"))
@@ -251,21 +667,135 @@ func (m Markdown) renderTable(t *Table, contextPath *ContextPath) (string, error
 	return builder.String(), nil
 }
 
+// escapeTableCells escapes pipes so cell values cannot split a column.
+func escapeTableCells(cells []string) []string {
+	escaped := make([]string, len(cells))
+
+	for idx, cell := range cells {
+		escaped[idx] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+
+	return escaped
+}
+
+// padTableRow returns a copy of a plain row padded with empty values out to the column count,
+// so short rows still render with one cell per header.
+func padTableRow(row TableRow, columns int) TableRow {
+	if len(row.Values) >= columns {
+		return row
+	}
+
+	values := make([]string, columns)
+	copy(values, row.Values)
+
+	return TableRow{Values: values}
+}
+
+// renderRichTableRow renders each cell through the content renderer, escaping pipes so
+// cell content cannot split the column and flattening newlines, which tables cannot contain.
+// Short rows are padded with empty cells out to the column count.
+func (m Markdown) renderRichTableRow(row TableRow, columns int, contextPath *ContextPath) (string, error) {
+	cells := make([]string, 0, max(len(row.Cells), columns))
+
+	for _, cell := range row.Cells {
+		content, err := m.renderWithTracking(cell, contextPath)
+		if errors.Is(err, errSkipNode) {
+			content, err = "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content = strings.ReplaceAll(content, "|", `\|`)
+		content = strings.ReplaceAll(content, "\n", " ")
+
+		cells = append(cells, content)
+	}
+
+	for len(cells) < columns {
+		cells = append(cells, "")
+	}
+
+	return "| " + strings.Join(cells, " | ") + " |", nil
+}
+
+// indentLines indents every line after the first so multi-line content stays inside its list item.
+func indentLines(content string, indent string) string {
+	return strings.ReplaceAll(content, "\n", "\n"+indent)
+}
+
+// listPrefix returns the item marker for a list, swapping in the configured bullet marker.
+func (m Markdown) listPrefix(l *List) string {
+	if l.TypeOfList == NUMBERED || m.bulletMarker == 0 {
+		return l.TypeOfList.Prefix()
+	}
+
+	return string(m.bulletMarker)
+}
+
 func (m Markdown) renderList(l *List, contextPath *ContextPath) (string, error) {
 	var builder strings.Builder
 
-	childContent, err := m.renderChildren(l.Children(), contextPath)
+	prefix := m.listPrefix(l)
+
+	// Continuation lines and nested lists align with the text of the parent item
+	indent := strings.Repeat(" ", len(prefix)+1)
+
+	for idx, item := range l.Items {
+		content, err := m.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content = strings.TrimRight(content, "\n")
+
+		if item.Type() == ListType {
+			builder.WriteString(indent)
+			builder.WriteString(indentLines(content, indent))
+			builder.WriteString("\n")
+			continue
+		}
+
+		builder.WriteString(prefix)
+		builder.WriteString(" ")
+		if l.TypeOfList == TASK {
+			if l.IsChecked(idx) {
+				builder.WriteString("[x] ")
+			} else {
+				builder.WriteString("[ ] ")
+			}
+		}
+		builder.WriteString(indentLines(content, indent))
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), nil
+}
+
+// renderCollapsible wraps the children in details/summary HTML. The children are rendered with the
+// enclosing context, and the blank lines around them let markdown inside the details be parsed.
+func (m Markdown) renderCollapsible(c Structurer, contextPath *ContextPath) (string, error) {
+	var builder strings.Builder
+
+	childContent, err := m.renderChildren(c.Children(), contextPath)
 	if err != nil {
 		return "", err
 	}
 
-	for _, item := range childContent {
-		builder.WriteString(l.TypeOfList.Prefix())
-		builder.WriteString(" ")
-		builder.WriteString(item)
+	builder.WriteString("<details>\n")
+	builder.WriteString(fmt.Sprintf("<summary>%s</summary>\n", html.EscapeString(c.Identifier())))
+
+	if len(childContent) > 0 {
 		builder.WriteString("\n")
+		builder.WriteString(strings.TrimRight(strings.Join(childContent, "\n\n"), "\n"))
+		builder.WriteString("\n\n")
 	}
 
+	builder.WriteString("</details>")
+
 	return builder.String(), nil
 }
 
@@ -274,7 +804,7 @@ func (m Markdown) renderFrontmatter(f Frontmatter) (string, error) {
 
 	builder.WriteString("---\n")
 
-	data, err := yaml.Marshal(f.Data)
+	data, err := yaml.Marshal(f)
 	if err != nil {
 		return "", err
 	}
@@ -294,18 +824,46 @@ func (m Markdown) renderStructureNode(structureNode Structurer, contextPath *Con
 	case ParagraphType:
 		return m.renderParagraph(structureNode, contextPath)
 	case ListType:
+		if list, ok := structureNode.(List); ok {
+			return m.renderList(&list, contextPath)
+		}
 		return m.renderList(structureNode.(*List), contextPath)
 	case TableType:
+		if table, ok := structureNode.(Table); ok {
+			return m.renderTable(&table, contextPath)
+		}
 		return m.renderTable(structureNode.(*Table), contextPath)
+	case CollapsibleType:
+		return m.renderCollapsible(structureNode, contextPath)
+	}
+
+	return m.renderUnknown(structureNode, contextPath, errors.New("unhandled structure node type"))
+}
+
+// renderUnknown is the shared fallback for content and structure nodes without a handler.
+// Under the text policy, unknown structures render their children as if they were a section body.
+func (m Markdown) renderUnknown(node Node, contextPath *ContextPath, unhandled error) (string, error) {
+	structure, ok := node.(Structurer)
+	if !ok || m.unknownNodes != UnknownNodesText {
+		return m.unknownNodes.resolve(node, unhandled)
+	}
+
+	childContent, err := m.renderChildren(structure.Children(), contextPath)
+	if err != nil {
+		return "", err
 	}
 
-	return "", errors.New("unhandled structure node type")
+	if len(childContent) == 0 {
+		return "", errSkipNode
+	}
+
+	return strings.Join(childContent, "\n\n"), nil
 }
 
 func (m Markdown) renderHeader(content MaterializedContent, contextPath *ContextPath) (string, error) {
 	var headerContent strings.Builder
 
-	m.writeHeader(&headerContent, content.Content, contextPath.CurrentLevel())
+	m.writeHeader(&headerContent, content.Content, m.headingLevel(contextPath.CurrentLevel()))
 
 	return headerContent.String(), nil
 }
@@ -319,21 +877,145 @@ func (m Markdown) renderLink(content MaterializedContent) (string, error) {
 	return fmt.Sprintf("[%s](%s)", content.Content, url), nil
 }
 
-func (m Markdown) renderText(content MaterializedContent) (string, error) {
-	return content.Content, nil
-}
+func (m Markdown) renderBadge(content MaterializedContent) (string, error) {
+	url, err := getStringFromMetadata(content.Metadata, "Url")
+	if err != nil {
+		return "", err
+	}
 
-func (m Markdown) renderCode(content MaterializedContent) (string, error) {
-	return fmt.Sprintf("`%s`", content.Content), nil
+	imageUrl, err := getStringFromMetadata(content.Metadata, "ImageUrl")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("[![%s](%s)](%s)", content.Content, imageUrl, url), nil
 }
 
-func (m Markdown) renderBlockofCode(typeHint string, content string, builder *strings.Builder) {
-	builder.WriteString("```")
-	builder.WriteString(typeHint)
+func (m Markdown) renderCrossRef(content MaterializedContent) (string, error) {
+	target, err := getStringFromMetadata(content.Metadata, "Target")
+	if err != nil {
+		return "", err
+	}
+
+	for _, anchor := range m.anchors {
+		if anchor.Name == target {
+			return fmt.Sprintf("[%s](#%s)", content.Content, anchor.Slug), nil
+		}
+	}
+
+	return "", fmt.Errorf("cross reference to section '%s': section not found in document", target)
+}
+
+// renderTableOfContents lists the sections below the root heading as nested links, using the
+// same anchors the headings receive.
+func (m Markdown) renderTableOfContents(content MaterializedContent, contextPath *ContextPath) (string, error) {
+	maxDepth, ok := content.Metadata["MaxDepth"].(int)
+	if !ok {
+		return "", errors.New("metadata key 'MaxDepth' not found or not an int")
+	}
+
+	root := NewList(BULLET)
+	lists := []*List{root}
+
+	for _, anchor := range m.anchors {
+		depth := anchor.Level - 1
+		if anchor.Standalone || depth < 1 || (maxDepth > 0 && depth > maxDepth) {
+			continue
+		}
+
+		for len(lists) > depth {
+			lists = lists[:len(lists)-1]
+		}
+		for len(lists) < depth {
+			lists = append(lists, lists[len(lists)-1].CreateList(BULLET))
+		}
+
+		lists[len(lists)-1].AppendNode(Link{Text: anchor.Heading, Url: "#" + anchor.Slug})
+	}
+
+	if len(root.Items) == 0 {
+		return "", errSkipNode
+	}
+
+	toc, err := m.renderList(root, contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(toc, "\n"), nil
+}
+
+func (m Markdown) renderImage(content MaterializedContent) (string, error) {
+	url, err := getStringFromMetadata(content.Metadata, "Url")
+	if err != nil {
+		return "", err
+	}
+
+	title, err := getStringFromMetadata(content.Metadata, "Title")
+	if err != nil || title == "" {
+		return fmt.Sprintf("![%s](%s)", content.Content, url), nil
+	}
+
+	return fmt.Sprintf("![%s](%s \"%s\")", content.Content, url, strings.ReplaceAll(title, `"`, `\"`)), nil
+}
+
+func (m Markdown) renderText(content MaterializedContent) (string, error) {
+	return content.Content, nil
+}
+
+func (m Markdown) renderBold(content MaterializedContent) (string, error) {
+	return fmt.Sprintf("**%s**", content.Content), nil
+}
+
+func (m Markdown) renderItalic(content MaterializedContent) (string, error) {
+	return fmt.Sprintf("*%s*", content.Content), nil
+}
+
+// renderStrikethrough escapes tildes in the content so they cannot close the strikethrough early.
+func (m Markdown) renderStrikethrough(content MaterializedContent) (string, error) {
+	return fmt.Sprintf("~~%s~~", strings.ReplaceAll(content.Content, "~", `\~`)), nil
+}
+
+// renderMathInline escapes dollar signs in the expression so they cannot end the math early.
+func (m Markdown) renderMathInline(content MaterializedContent) (string, error) {
+	return fmt.Sprintf("$%s$", strings.ReplaceAll(content.Content, "$", `\$`)), nil
+}
+
+// renderCode wraps the content in a backtick fence. When the content itself contains backticks,
+// the fence is made longer than any backtick run in the content and padded with spaces (which
+// CommonMark strips) so the content can start or end with a backtick.
+func (m Markdown) renderCode(content MaterializedContent) (string, error) {
+	if !strings.Contains(content.Content, "`") {
+		return fmt.Sprintf("`%s`", content.Content), nil
+	}
+
+	longestRun, run := 0, 0
+	for _, char := range content.Content {
+		if char == '`' {
+			run++
+			longestRun = max(longestRun, run)
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", longestRun+1)
+
+	return fmt.Sprintf("%s %s %s", fence, content.Content, fence), nil
+}
+
+func (m Markdown) renderBlockofCode(typeHint string, content string, builder *strings.Builder) {
+	fence := "```"
+	if m.fenceStyle == FenceTildes {
+		fence = "~~~"
+	}
+
+	builder.WriteString(fence)
+	builder.WriteString(typeHint)
 	builder.WriteString("\n")
 	builder.WriteString(content)
 	builder.WriteString("\n")
-	builder.WriteString("```")
+	builder.WriteString(fence)
 }
 
 func (m Markdown) renderCodeBlock(content MaterializedContent) (string, error) {
@@ -350,7 +1032,37 @@ func (m Markdown) renderCodeBlock(content MaterializedContent) (string, error) {
 }
 
 func (m Markdown) renderBlockQuote(content MaterializedContent) (string, error) {
-	return fmt.Sprintf("> %s", content.Content), nil
+	return quoteLines(content.Content), nil
+}
+
+// quoteLines prefixes every line with the blockquote marker, leaving blank lines as a bare ">".
+func quoteLines(content string) string {
+	lines := strings.Split(content, "\n")
+
+	for idx, line := range lines {
+		if line == "" {
+			lines[idx] = ">"
+		} else {
+			lines[idx] = "> " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m Markdown) renderAdmonition(content MaterializedContent) (string, error) {
+	kind, err := getStringFromMetadata(content.Metadata, "Kind")
+	if err != nil {
+		return "", err
+	}
+
+	return quoteLines(fmt.Sprintf("[!%s]\n%s", kind, content.Content)), nil
+}
+
+// renderHTMLBlock trims surrounding newlines so the block is separated from its
+// neighbours by exactly one blank line, which markdown parsers need to treat it as a block.
+func (m Markdown) renderHTMLBlock(content MaterializedContent) (string, error) {
+	return strings.Trim(content.Content, "\n"), nil
 }
 
 func (m Markdown) renderExecutable(content MaterializedContent) (string, error) {
@@ -374,7 +1086,7 @@ func (m Markdown) renderTableRow(content MaterializedContent) (string, error) {
 		return "", err
 	}
 
-	joiner := strings.Join(items, " | ")
+	joiner := strings.Join(escapeTableCells(items), " | ")
 
 	builder.WriteString("| ")
 	builder.WriteString(joiner)
@@ -391,6 +1103,10 @@ func (m Markdown) renderRemoteContent(content MaterializedContent) (string, erro
 }
 
 func (m Markdown) renderComment(content MaterializedContent) (string, error) {
+	if m.omitComments {
+		return "", errSkipNode
+	}
+
 	return fmt.Sprintf("<!-- %s -->", content.Content), nil
 }
 
@@ -405,15 +1121,37 @@ func (m Markdown) renderContent(contentNode Contenter, contextPath *ContextPath)
 		return m.renderHeader(content, contextPath)
 	case LinkType:
 		return m.renderLink(content)
+	case ImageType:
+		return m.renderImage(content)
+	case CrossRefType:
+		return m.renderCrossRef(content)
+	case BadgeType:
+		return m.renderBadge(content)
+	case TableOfContentsType:
+		return m.renderTableOfContents(content, contextPath)
 	case TextType:
 		return m.renderText(content)
 	case CodeType:
 		return m.renderCode(content)
-	case CodeBlockType:
+	case BoldType:
+		return m.renderBold(content)
+	case ItalicType:
+		return m.renderItalic(content)
+	case StrikethroughType:
+		return m.renderStrikethrough(content)
+	case MathInlineType:
+		return m.renderMathInline(content)
+	case CodeBlockType, MermaidType, MathBlockType:
 		return m.renderCodeBlock(content)
 	case BlockQuoteType:
 		return m.renderBlockQuote(content)
-	case ExecutableType:
+	case AdmonitionType:
+		return m.renderAdmonition(content)
+	case HTMLBlockType:
+		return m.renderHTMLBlock(content)
+	case LineBreakType: // only meaningful inside paragraphs, which handle breaks themselves
+		return "", errSkipNode
+	case ExecutableType, PipelineType:
 		return m.renderExecutable(content)
 	case TableRowType:
 		return m.renderTableRow(content)
@@ -423,22 +1161,303 @@ func (m Markdown) renderContent(contentNode Contenter, contextPath *ContextPath)
 		return m.renderComment(content)
 	}
 
-	return "", errors.New("unknown content node type")
+	return m.renderUnknown(contentNode, contextPath, errors.New("unknown content node type"))
 }
 
 func (m Markdown) renderWithTracking(node Node, contextPath *ContextPath) (string, error) {
+	content, err := m.renderNode(node, contextPath)
+
+	return content, wrapRenderError(err, *contextPath)
+}
+
+func (m Markdown) renderNode(node Node, contextPath *ContextPath) (string, error) {
 	switch node.Type() {
-	case DocumentType, SectionType, ParagraphType, ListType, TableType, FrontmatterType:
+	case DocumentType, SectionType, ParagraphType, ListType, TableType, FrontmatterType, CollapsibleType:
 		return m.renderStructureNode(node.(Structurer), contextPath)
-	default: // let the content renderer check through an error for invalid type
-		return m.renderContent(node.(Contenter), contextPath)
 	}
+
+	// let the content renderer check through an error for invalid type
+	if contentNode, ok := node.(Contenter); ok {
+		return m.renderContent(contentNode, contextPath)
+	}
+
+	if structureNode, ok := node.(Structurer); ok {
+		return m.renderStructureNode(structureNode, contextPath)
+	}
+
+	return m.renderUnknown(node, contextPath, errors.New("unknown content node type"))
+}
+
+// Stamp appends the GeneratedMarker to rendered markdown as an HTML comment,
+// which markdown viewers do not display.
+func (m Markdown) Stamp(content string) string {
+	stamp := fmt.Sprintf("\n<!-- %s -->\n", GeneratedMarker)
+	if m.lineEnding == LineEndingCRLF {
+		stamp = strings.ReplaceAll(stamp, "\n", "\r\n")
+	}
+
+	return content + stamp
+}
+
+// write writes content to w, naming the section being written if it fails.
+func (m Markdown) write(w io.Writer, content string, contextPath ContextPath) error {
+	if _, err := io.WriteString(w, content); err != nil {
+		return fmt.Errorf("writing %q: %w", contextPath.String(), err)
+	}
+
+	return nil
+}
+
+// streamChildren writes each child as it is rendered, separated by blank lines, and writes leading
+// before the first child that produces output. Sections are streamed in turn; any other node is
+// rendered whole before being written.
+func (m Markdown) streamChildren(children []Node, contextPath *ContextPath, w io.Writer, leading string) error {
+	first := true
+
+	for _, child := range children {
+		separator := "\n\n"
+		if first {
+			separator = leading
+		}
+
+		if child.Type() == SectionType {
+			if err := m.write(w, separator, *contextPath); err != nil {
+				return err
+			}
+			if err := m.streamSection(child.(Structurer), contextPath, w); err != nil {
+				return err
+			}
+
+			first = false
+			continue
+		}
+
+		content, err := m.renderWithTracking(child, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := m.write(w, separator+content, *contextPath); err != nil {
+			return err
+		}
+		first = false
+	}
+
+	return nil
+}
+
+// streamDocument mirrors renderDocument, writing the heading before rendering any children.
+func (m Markdown) streamDocument(d *Document, contextPath *ContextPath, w io.Writer) error {
+	ctxPath := contextPath.Push(d.Identifier())
+
+	var builder strings.Builder
+
+	if d.HasFrontmatter() {
+		frontmatter, err := m.renderFrontmatter(d.Frontmatter)
+		if err != nil {
+			return err
+		}
+
+		builder.WriteString(frontmatter)
+	}
+
+	m.writeHeader(&builder, d.Identifier(), m.headingLevel(ctxPath.CurrentLevel()))
+	if err := m.write(w, builder.String(), ctxPath); err != nil {
+		return err
+	}
+
+	if err := m.streamChildren(d.Children(), &ctxPath, w, ""); err != nil {
+		return err
+	}
+
+	// Final newline
+	return m.write(w, "\n", ctxPath)
+}
+
+// streamSection mirrors renderSection, writing the heading before rendering any children.
+func (m Markdown) streamSection(s Structurer, contextPath *ContextPath, w io.Writer) error {
+	heading := s.Identifier()
+
+	ctxPath := contextPath.Push(s.Identifier())
+	if m.sectionNumbering && len(*contextPath) > 0 {
+		number := contextPath.nextSectionNumber()
+		ctxPath[len(ctxPath)-1].Number = number
+		heading = fmt.Sprintf("%s %s", number, heading)
+	}
+
+	if err := m.write(w, m.headerLine(heading, m.headingLevel(ctxPath.CurrentLevel())), ctxPath); err != nil {
+		return err
+	}
+
+	return m.streamChildren(s.Children(), &ctxPath, w, "\n\n")
+}
+
+// eolWriter normalizes line endings on their way to w and, when trim is set, holds back trailing
+// newlines so that finish can end the output with exactly one.
+type eolWriter struct {
+	w       io.Writer
+	crlf    bool
+	trim    bool
+	pending int
+	written bool
+}
+
+func (e *eolWriter) writeLines(content string) error {
+	if e.crlf {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+
+	_, err := io.WriteString(e.w, content)
+
+	return err
+}
+
+func (e *eolWriter) Write(p []byte) (int, error) {
+	content := strings.ReplaceAll(string(p), "\r\n", "\n")
+
+	if !e.trim {
+		return len(p), e.writeLines(content)
+	}
+
+	// Newlines are only written once more content follows them
+	trimmed := strings.TrimRight(content, "\n")
+	if trimmed != "" {
+		if err := e.writeLines(strings.Repeat("\n", e.pending) + trimmed); err != nil {
+			return 0, err
+		}
+
+		e.pending = 0
+		e.written = true
+	}
+	e.pending += len(content) - len(trimmed)
+
+	return len(p), nil
+}
+
+func (e *eolWriter) finish() error {
+	if !e.trim || !e.written {
+		return nil
+	}
+
+	return e.writeLines("\n")
+}
+
+// RenderTo converts a node into markdown format and writes it to w as the tree is walked,
+// so large documents are never held in memory in full.
+// This is the main entry point for the StreamRenderer interface implementation.
+func (m Markdown) RenderTo(node Node, w io.Writer) error {
+	if m.lineEnding != LineEndingCRLF && !m.trailingNewline {
+		return m.renderTo(node, w)
+	}
+
+	normalized := &eolWriter{w: w, crlf: m.lineEnding == LineEndingCRLF, trim: m.trailingNewline}
+	if err := m.renderTo(node, normalized); err != nil {
+		return err
+	}
+
+	return normalized.finish()
+}
+
+func (m Markdown) renderTo(node Node, w io.Writer) error {
+	m.anchors = collectAnchors(node, m.sectionNumbering)
+
+	contextPath := &ContextPath{}
+
+	switch node.Type() {
+	case DocumentType:
+		return m.streamDocument(node.(*Document), contextPath, w)
+	case SectionType:
+		return m.streamSection(node.(Structurer), contextPath, w)
+	}
+
+	content, err := m.renderWithTracking(node, contextPath)
+	if errors.Is(err, errSkipNode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.write(w, content, *contextPath)
+}
+
+// RenderNode renders a single node, such as a section pulled out of a document, as a standalone
+// markdown snippet. Headings start at H1 and the output ends with a newline, like a rendered document.
+func (m Markdown) RenderNode(node Node) (string, error) {
+	content, err := m.Render(node)
+	if err != nil {
+		return "", err
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		newline := "\n"
+		if m.lineEnding == LineEndingCRLF {
+			newline = "\r\n"
+		}
+
+		content += newline
+	}
+
+	return content, nil
 }
 
 // Render converts a document node into markdown format, starting with an empty context path.
 // This is the main entry point for the Renderer interface implementation.
 func (m Markdown) Render(node Node) (string, error) {
-	return m.renderWithTracking(node, &ContextPath{})
+	var builder strings.Builder
+
+	if err := m.RenderTo(node, &builder); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// MARK: Formats
+
+// Output formats accepted by NewFileRenderer.
+const (
+	FormatMarkdown  = "markdown"
+	FormatHTML      = "html"
+	FormatJSON      = "json"
+	FormatText      = "text"
+	FormatNotebook  = "notebook"
+	FormatIssueForm = "issue-form"
+)
+
+// NewFileRenderer creates the renderer for an output format: "markdown", "html", "json", "text",
+// "notebook", or "issue-form".
+// unknownNodes sets how node types without a handler are treated; 0 keeps the default of
+// failing the render. The JSON, notebook, and issue form renderers ignore the policy.
+func NewFileRenderer(format string, unknownNodes UnknownNodePolicy) (Renderer[string], error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatMarkdown, "md":
+		if unknownNodes == 0 {
+			return NewMarkdownRenderer()
+		}
+		return NewMarkdownRenderer(WithUnknownNodes(unknownNodes))
+	case FormatHTML:
+		if unknownNodes == 0 {
+			return NewHTMLRenderer()
+		}
+		return NewHTMLRenderer(WithHTMLUnknownNodes(unknownNodes))
+	case FormatJSON:
+		return NewJSONRenderer(), nil
+	case FormatText, "txt":
+		if unknownNodes == 0 {
+			return NewPlainTextRenderer()
+		}
+		return NewPlainTextRenderer(WithPlainTextUnknownNodes(unknownNodes))
+	case FormatNotebook, "ipynb":
+		return NewNotebookRenderer(), nil
+	case FormatIssueForm:
+		return NewIssueFormRenderer(), nil
+	}
+
+	return nil, fmt.Errorf("invalid format '%s' (expected markdown, html, json, text, notebook, or issue-form)", format)
 }
 
 // MARK: Executor
@@ -452,8 +1471,75 @@ type CommandPlan struct {
 	Args []string
 	// Context provides information about which section this command originated from
 	Context SectionInfo
+	// Path holds the names of the sections enclosing the command, starting with the document
+	// and ending with the Context section
+	Path []string
 	// Environment variables that must be set for the command to be executed
 	Environment []string
+	// EnvValues holds environment variables to set for the command, overriding inherited ones.
+	// For pipelines each stage carries its own values.
+	EnvValues map[string]string
+	// Stages holds the individual commands of a pipeline, in order. It is empty for
+	// plain executables; for pipelines Args holds every stage joined by "|" for display.
+	Stages []CommandPlan
+	// Timeout overrides the runner's configured timeout (0 means use the runner's)
+	Timeout time.Duration
+	// WorkingDir is the directory the command runs in (empty means the runner's working directory)
+	WorkingDir string
+	// Platforms restricts the command to these operating systems, named like GOOS values
+	// (empty means every platform)
+	Platforms []string
+	// SkipIfEnv skips the command when any of these NAME or NAME=value conditions holds
+	SkipIfEnv []string
+	// OnlyIfEnv skips the command unless all of these NAME or NAME=value conditions hold
+	OnlyIfEnv []string
+	// Requires lists the tools the command needs, as executable names looked up on the PATH
+	Requires []string
+	// Phase marks the command as part of the setup or teardown of the section it is in
+	Phase ExecutablePhase
+	// Step is the position of the command among the steps of its executable, starting at 1
+	// (zero means the executable has no steps)
+	Step int
+	// Tags label the command, including the tags of the sections it is in
+	Tags []string
+	// AllowedCommands overrides the runner's allowed commands, as set by the ConfigOverride of
+	// the innermost section that sets them (nil means use the runner's)
+	AllowedCommands []string
+	// AllowedShells overrides the runner's allowed shells, as set by the ConfigOverride of the
+	// innermost section that sets them (nil means use the runner's)
+	AllowedShells []string
+}
+
+// withOverrides returns config with the allowed commands and shells of the plan applied.
+func (p CommandPlan) withOverrides(config ExecutionConfig) ExecutionConfig {
+	return ConfigOverride{AllowedCommands: p.AllowedCommands, AllowedShells: p.AllowedShells}.apply(config)
+}
+
+// HasTag reports whether the command is labelled with tag.
+func (c CommandPlan) HasTag(tag string) bool {
+	return slices.Contains(c.Tags, tag)
+}
+
+// RunsOn reports whether the command can run on the platform, named like a GOOS value.
+func (p CommandPlan) RunsOn(platform string) bool {
+	return len(p.Platforms) == 0 || slices.Contains(p.Platforms, platform)
+}
+
+// DisplayEnv returns the environment values injected into the command as sorted NAME=value
+// pairs for display, with the values of secret variables replaced by RedactedValue.
+func (p CommandPlan) DisplayEnv() []string {
+	names := envNames(p.EnvValues)
+
+	pairs := make([]string, len(names))
+	for idx, name := range names {
+		value := p.EnvValues[name]
+		if IsSecretEnvVar(name) {
+			value = RedactedValue
+		}
+		pairs[idx] = name + "=" + value
+	}
+
+	return pairs
 }
 
 // Executioner implements the Renderer interface to extract executable commands
@@ -469,7 +1555,7 @@ func (e Executioner) renderChildren(node Structurer, contextPath *ContextPath) (
 	var commands []CommandPlan
 
 	for _, leaf := range node.Children() {
-		cmds, err := e.renderWithTracking(leaf, contextPath)
+		cmds, err := e.renderTree(leaf, *contextPath)
 		if err != nil {
 			return make([]CommandPlan, 0), err
 		}
@@ -480,19 +1566,193 @@ func (e Executioner) renderChildren(node Structurer, contextPath *ContextPath) (
 	return commands, nil
 }
 
+// renderTree plans node and the commands within it. Lists (including nested lists) and
+// collapsibles belong to the enclosing section, so their commands are planned under its path.
+// Paragraphs and tables hold no commands and are skipped.
+func (e Executioner) renderTree(node Node, contextPath ContextPath) ([]CommandPlan, error) {
+	var commands []CommandPlan
+
+	err := walk(node, contextPath, func(node Node, path ContextPath) (bool, error) {
+		if node.Type() == ListType || node.Type() == CollapsibleType {
+			return true, nil
+		}
+
+		cmds, err := e.renderNode(node, &path)
+		commands = append(commands, cmds...)
+
+		return false, err
+	})
+	if err != nil {
+		return make([]CommandPlan, 0), err
+	}
+
+	return commands, nil
+}
+
+// sectionTags returns the tags of a section node, or nil for other structures.
+func sectionTags(node Structurer) []string {
+	switch section := node.(type) {
+	case Section:
+		return section.Tags
+	case *Section:
+		return section.Tags
+	}
+
+	return nil
+}
+
+// sectionOverrides returns the config overrides of a section node, or none for other structures.
+func sectionOverrides(node Structurer) ConfigOverride {
+	switch section := node.(type) {
+	case Section:
+		return section.Overrides
+	case *Section:
+		return section.Overrides
+	}
+
+	return ConfigOverride{}
+}
+
+// applyOverrides sets the fields of the command that neither it nor an inner section has set.
+func applyOverrides(command *CommandPlan, overrides ConfigOverride) {
+	if command.Timeout == 0 {
+		command.Timeout = overrides.Timeout
+	}
+
+	if command.AllowedCommands == nil {
+		command.AllowedCommands = overrides.AllowedCommands
+	}
+
+	if command.AllowedShells == nil {
+		command.AllowedShells = overrides.AllowedShells
+	}
+}
+
+// mergeTags returns the inherited tags followed by the command's own, without duplicates.
+func mergeTags(inherited, own []string) []string {
+	merged := make([]string, 0, len(inherited)+len(own))
+	for _, tag := range append(slices.Clone(inherited), own...) {
+		if !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+
+	return merged
+}
+
 func (e Executioner) renderStructureNode(node Structurer, contextPath *ContextPath) ([]CommandPlan, error) {
 	ctxPath := contextPath.Push(node.Identifier())
+	if origin := includedFrom(node); origin != "" {
+		ctxPath[len(ctxPath)-1].Origin = origin
+	}
 
-	return e.renderChildren(node, &ctxPath)
+	commands, err := e.renderChildren(node, &ctxPath)
+	if err != nil {
+		return commands, err
+	}
+
+	// Commands inherit the tags of every section they are in
+	if tags := sectionTags(node); len(tags) > 0 {
+		for idx := range commands {
+			commands[idx].Tags = mergeTags(tags, commands[idx].Tags)
+		}
+	}
+
+	// Inner sections render first, so their overrides are already set when outer ones apply
+	if overrides := sectionOverrides(node); !overrides.IsZero() {
+		for idx := range commands {
+			applyOverrides(&commands[idx], overrides)
+		}
+	}
+
+	// Setup commands of this section go first and teardown commands last, wherever they were
+	// written. Those of nested sections were already placed within their own group.
+	var setup, main, teardown []CommandPlan
+	for _, command := range commands {
+		switch {
+		case len(command.Path) != len(ctxPath):
+			main = append(main, command)
+		case command.Phase == PhaseSetup:
+			setup = append(setup, command)
+		case command.Phase == PhaseTeardown:
+			teardown = append(teardown, command)
+		default:
+			main = append(main, command)
+		}
+	}
+
+	return append(append(setup, main...), teardown...), nil
 }
 
-func (e Executioner) renderExecutable(content MaterializedContent, contextPath *ContextPath) (CommandPlan, error) {
+// renderExecutable plans an executable. An executable with steps becomes a plan per step,
+// each sharing the executable's settings and numbered by Step.
+func (e Executioner) renderExecutable(content MaterializedContent, contextPath *ContextPath) ([]CommandPlan, error) {
 	shell, err := getStringFromMetadata(content.Metadata, "Shell")
 	if err != nil {
-		return CommandPlan{}, nil
+		return []CommandPlan{}, nil
 	}
 
 	args, err := getStringsFromMetadata(content.Metadata, "Command")
+	if err != nil {
+		return []CommandPlan{}, err
+	}
+
+	envvars, err := getStringsFromMetadata(content.Metadata, "Environment")
+	if err != nil {
+		return []CommandPlan{}, err
+	}
+
+	// Timeout, working directory, environment values, platforms, conditions, and required
+	// tools are optional, so executables materialized without them still plan
+	timeout, _ := content.Metadata["Timeout"].(time.Duration)
+	workingDir, _ := content.Metadata["WorkingDir"].(string)
+	envValues, _ := content.Metadata["EnvValues"].(map[string]string)
+	platforms, _ := content.Metadata["Platforms"].([]string)
+	skipIfEnv, _ := content.Metadata["SkipIfEnv"].([]string)
+	onlyIfEnv, _ := content.Metadata["OnlyIfEnv"].([]string)
+	requires, _ := content.Metadata["Requires"].([]string)
+	phase, _ := content.Metadata["Phase"].(ExecutablePhase)
+	steps, _ := content.Metadata["Steps"].([][]string)
+	tags, _ := content.Metadata["Tags"].([]string)
+
+	plan := CommandPlan{
+		Shell:       shell,
+		Args:        args,
+		Context:     contextPath.Current(),
+		Path:        contextPath.Names(),
+		Environment: envvars,
+		EnvValues:   envValues,
+		Timeout:     timeout,
+		WorkingDir:  workingDir,
+		Platforms:   platforms,
+		SkipIfEnv:   skipIfEnv,
+		OnlyIfEnv:   onlyIfEnv,
+		Requires:    requires,
+		Phase:       phase,
+		Tags:        tags,
+	}
+
+	if len(steps) == 0 {
+		return []CommandPlan{plan}, nil
+	}
+
+	plans := make([]CommandPlan, len(steps))
+	for idx, step := range steps {
+		plans[idx] = plan
+		plans[idx].Args = step
+		plans[idx].Step = idx + 1
+	}
+
+	return plans, nil
+}
+
+func (e Executioner) renderPipeline(content MaterializedContent, contextPath *ContextPath) (CommandPlan, error) {
+	shells, err := getStringsFromMetadata(content.Metadata, "StageShells")
+	if err != nil {
+		return CommandPlan{}, err
+	}
+
+	commands, err := getStringSlicesFromMetadata(content.Metadata, "Stages")
 	if err != nil {
 		return CommandPlan{}, err
 	}
@@ -502,24 +1762,62 @@ func (e Executioner) renderExecutable(content MaterializedContent, contextPath *
 		return CommandPlan{}, err
 	}
 
+	timeout, _ := content.Metadata["Timeout"].(time.Duration)
+	workingDirs, _ := content.Metadata["StageWorkingDirs"].([]string)
+	envValues, _ := content.Metadata["StageEnvValues"].([]map[string]string)
+	platforms, _ := content.Metadata["Platforms"].([]string)
+	skipIfEnv, _ := content.Metadata["SkipIfEnv"].([]string)
+	onlyIfEnv, _ := content.Metadata["OnlyIfEnv"].([]string)
+	requires, _ := content.Metadata["Requires"].([]string)
+	tags, _ := content.Metadata["Tags"].([]string)
+
+	stages := make([]CommandPlan, len(commands))
+	var args []string
+
+	for idx, command := range commands {
+		if idx > 0 {
+			args = append(args, "|")
+		}
+		args = append(args, command...)
+
+		stages[idx] = CommandPlan{
+			Shell:   shells[idx],
+			Args:    command,
+			Context: contextPath.Current(),
+		}
+		if idx < len(workingDirs) {
+			stages[idx].WorkingDir = workingDirs[idx]
+		}
+		if idx < len(envValues) {
+			stages[idx].EnvValues = envValues[idx]
+		}
+	}
+
 	return CommandPlan{
-		Shell:       shell,
+		Shell:       shells[0],
 		Args:        args,
 		Context:     contextPath.Current(),
+		Path:        contextPath.Names(),
 		Environment: envvars,
+		Stages:      stages,
+		Timeout:     timeout,
+		Platforms:   platforms,
+		SkipIfEnv:   skipIfEnv,
+		OnlyIfEnv:   onlyIfEnv,
+		Requires:    requires,
+		Tags:        tags,
 	}, nil
 }
 
-func (e Executioner) renderWithTracking(node Node, contextPath *ContextPath) ([]CommandPlan, error) {
+// renderNode plans a document, section, or command node. Other nodes have no plans.
+func (e Executioner) renderNode(node Node, contextPath *ContextPath) ([]CommandPlan, error) {
 	var commands []CommandPlan
 
 	switch node.Type() {
-	// Intentionally skip paragraphs and tables
-	// Lists _could_ have executables as items
-	case DocumentType, SectionType, ListType:
+	case DocumentType, SectionType:
 		cmds, err := e.renderStructureNode(node.(Structurer), contextPath)
 		if err != nil {
-			return []CommandPlan{}, nil
+			return []CommandPlan{}, err
 		}
 
 		commands = append(commands, cmds...)
@@ -531,7 +1829,26 @@ func (e Executioner) renderWithTracking(node Node, contextPath *ContextPath) ([]
 			return []CommandPlan{}, err
 		}
 
-		cmd, err := e.renderExecutable(content, contextPath)
+		cmds, err := e.renderExecutable(content, contextPath)
+		if err != nil {
+			return []CommandPlan{}, err
+		}
+
+		commands = append(commands, cmds...)
+
+	// Unresolved references would silently drop the referenced section's commands
+	case SectionRefType:
+		if _, err := node.(Contenter).Materialize(); err != nil {
+			return []CommandPlan{}, err
+		}
+
+	case PipelineType:
+		content, err := node.(Contenter).Materialize()
+		if err != nil {
+			return []CommandPlan{}, err
+		}
+
+		cmd, err := e.renderPipeline(content, contextPath)
 		if err != nil {
 			return []CommandPlan{}, err
 		}
@@ -546,10 +1863,5 @@ func (e Executioner) renderWithTracking(node Node, contextPath *ContextPath) ([]
 // returning them as a slice of CommandPlan for execution planning.
 // This is the main entry point for the Renderer interface implementation.
 func (e Executioner) Render(node Node) ([]CommandPlan, error) {
-	cmds, err := e.renderWithTracking(node, &ContextPath{})
-	if err != nil {
-		return make([]CommandPlan, 0), err
-	}
-
-	return cmds, nil
+	return e.renderTree(node, ContextPath{})
 }
@@ -1,8 +1,12 @@
 package doyoucompute
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestMarkdownRender(t *testing.T) {
@@ -61,23 +65,1203 @@ func TestMarkdownRender(t *testing.T) {
 			},
 			expected: "# MyDoc\n\n## INTRO\n\nThis is an introduction. And another sentence here.\n\nhey im some remote content\n\n## Quick Start\n\n### Prerequisites\n\nProbably go\n\n## Long version\n\nvery long version\n",
 		},
+		{
+			name: "Passing-Pipeline",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					Section{
+						Name: "Fetch",
+						Content: []Node{
+							Pipeline{
+								Stages: []Executable{
+									{Shell: "bash", Cmd: []string{"curl", "-s", "example.com"}},
+									{Shell: "bash", Cmd: []string{"jq", ".name"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: "# MyDoc\n\n## Fetch\n\n```bash\ncurl -s example.com | jq .name\n```\n",
+		},
+		{
+			name: "Passing-Steps",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Build").WriteSteps("bash", [][]string{{"mkdir", "-p", "build"}, {"cmake", "-S", ".", "-B", "build"}}, []string{})
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Build\n\n```bash\nmkdir -p build\ncmake -S . -B build\n```\n",
+		},
+		{
+			name: "Passing-TaskList",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				list := document.CreateSection("Checklist").CreateList(TASK)
+				list.AppendTask("done item", true)
+				list.Append("write tests")
+				list.Push("read the docs")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Checklist\n\n- [ ] read the docs\n- [x] done item\n- [ ] write tests\n\n",
+		},
+		{
+			name: "Passing-NestedLists",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				list := document.CreateSection("Features").CreateList(BULLET)
+				list.Append("Rendering")
+				formats := list.CreateList(NUMBERED)
+				formats.Append("Markdown")
+				formats.Append("Execution plans\nfor runnable docs")
+				renderers := formats.CreateList(BULLET)
+				renderers.Append("Executioner")
+				list.Append("Execution")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Features\n\n- Rendering\n  1. Markdown\n  1. Execution plans\n     for runnable docs\n     - Executioner\n- Execution\n\n",
+		},
+		{
+			name: "Passing-RichListItems",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				list := document.CreateSection("Install").CreateList(BULLET)
+				list.Append("Plain item")
+				list.AppendParagraph().Text("See the").Link("docs", "https://example.com").Text("for").Code("go get").Text("instructions")
+				list.AppendNode(Executable{Shell: "bash", Cmd: []string{"go", "get"}})
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Install\n\n- Plain item\n- See the [docs](https://example.com) for `go get` instructions\n- ```bash\n  go get\n  ```\n\n",
+		},
+		{
+			name: "Passing-Emphasis",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Usage").WriteParagraph().Text("Run").Bold("only").Code("go test").Italic("before").Text("pushing.")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Usage\n\nRun **only** `go test` *before* pushing.\n",
+		},
+		{
+			name: "Passing-Strikethrough",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Flags").WriteParagraph().Text("Use").Link("--output", "#output").Text("instead of").Strikethrough("--out~file")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Flags\n\nUse [--output](#output) instead of ~~--out\\~file~~\n",
+		},
+		{
+			name: "Passing-Admonition",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				section := document.CreateSection("Install")
+				section.WriteAdmonition(TIP, "Use the installer.")
+				section.WriteAdmonition(WARNING, "This removes the cache.\n\nBack it up first.")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Install\n\n> [!TIP]\n> Use the installer.\n\n> [!WARNING]\n> This removes the cache.\n>\n> Back it up first.\n",
+		},
+		{
+			name: "Passing-Badges",
+			document: func() Document {
+				license, _ := NewLicenseBadge("MoonMoon1919/doyoucompute")
+
+				document := Document{Name: "MyDoc"}
+				document.WriteIntro().
+					Badge("build", "https://github.com/o/r/actions", "https://img.shields.io/badge/build-passing-green").
+					AddBadge(license)
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n[![build](https://img.shields.io/badge/build-passing-green)](https://github.com/o/r/actions) [![license](https://img.shields.io/github/license/MoonMoon1919/doyoucompute)](https://github.com/MoonMoon1919/doyoucompute/blob/HEAD/LICENSE)\n",
+		},
+		{
+			name: "Passing-EmbeddedDocument",
+			document: func() Document {
+				contributing := Document{Name: "Contributing"}
+				contributing.WriteIntro().Text("Thanks for helping out.")
+				contributing.CreateSection("Testing").WriteParagraph().Text("Run the tests.")
+
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Usage").WriteParagraph().Text("Run it.")
+				document.AddDocument(contributing)
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Usage\n\nRun it.\n\n## Contributing\n\nThanks for helping out.\n\n### Testing\n\nRun the tests.\n",
+		},
+		{
+			name: "Passing-MultilineBlockQuote",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				section := document.CreateSection("Quotes")
+				section.WriteBlockQuote("Short and sweet.")
+				section.WriteBlockQuoteLines("First paragraph of the quote.", "", "Second paragraph,", "still quoted.")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Quotes\n\n> Short and sweet.\n\n> First paragraph of the quote.\n>\n> Second paragraph,\n> still quoted.\n",
+		},
+		{
+			name: "Passing-Collapsible",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				section := document.CreateSection("Troubleshooting")
+				details := section.CreateCollapsible("Build failures")
+				details.WriteParagraph().Text("Clear the cache.")
+				details.CreateSection("Linux").WriteParagraph().Text("Check permissions.")
+				details.CreateCollapsible("Still failing?").WriteParagraph().Text("Open an issue.")
+				section.CreateCollapsible("Nothing here yet")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Troubleshooting\n\n<details>\n<summary>Build failures</summary>\n\nClear the cache.\n\n### Linux\n\nCheck permissions.\n\n<details>\n<summary>Still failing?</summary>\n\nOpen an issue.\n\n</details>\n\n</details>\n\n<details>\n<summary>Nothing here yet</summary>\n</details>\n",
+		},
+		{
+			name: "Passing-CollapsibleEscapedSummary",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Comparisons").CreateCollapsible("When a < b & c").WriteParagraph().Text("Swap them.")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Comparisons\n\n<details>\n<summary>When a &lt; b &amp; c</summary>\n\nSwap them.\n\n</details>\n",
+		},
+		{
+			name: "Passing-HTMLBlock",
+			document: func() Document {
+				document := Document{Name: "MyDoc", Content: []Node{HTMLBlock(`<a id="top"></a>`)}}
+				section := document.CreateSection("Logo")
+				section.WriteHTML("\n<p align=\"center\">\n  <img src=\"logo.png\" width=\"400\">\n</p>\n")
+				section.WriteParagraph().Text("Welcome.")
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n<a id=\"top\"></a>\n\n## Logo\n\n<p align=\"center\">\n  <img src=\"logo.png\" width=\"400\">\n</p>\n\nWelcome.\n",
+		},
+		{
+			name: "Passing-Mermaid",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Architecture").WriteMermaid([]string{"graph TD", "    A[Document] --> B[Renderer]", "    B --> C[File]"})
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Architecture\n\n```mermaid\ngraph TD\n    A[Document] --> B[Renderer]\n    B --> C[File]\n```\n",
+		},
+		{
+			name: "Passing-Math",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				section := document.CreateSection("Pricing")
+				section.WriteParagraph().Text("The cost is").Math(`c = $5 \times n`).Text("per run.")
+				section.WriteMathBlock([]string{`\begin{aligned}`, `c &= 5n \\`, `n &= 3`, `\end{aligned}`})
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Pricing\n\nThe cost is $c = \\$5 \\times n$ per run.\n\n```math\n\\begin{aligned}\nc &= 5n \\\\\nn &= 3\n\\end{aligned}\n```\n",
+		},
+		{
+			name: "Passing-RichTableCells",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				table := document.CreateSection("Flags").CreateTable([]string{"Flag", "Description"})
+				table.AddRow("--path", "Output path")
+				table.AddRichRow(Code("--unknown-nodes"), NewParagraph().Text("One of").Code("error|skip|text"))
+				table.AddRichRow(Link{Text: "--force", Url: "#force"}, Text("a | b"))
+
+				return document
+			}(),
+			expected: "# MyDoc\n\n## Flags\n\n| Flag | Description |\n| ---- | ---- |\n| --path | Output path |\n| `--unknown-nodes` | One of `error\\|skip\\|text` |\n| [--force](#force) | a \\| b |\n",
+		},
+		{
+			name: "Passing-Images",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					Section{
+						Name: "Architecture",
+						Content: []Node{
+							Paragraph{
+								Items: []Node{
+									Text("Build status:"),
+									Image{AltText: "build", Url: "https://example.com/badge.svg"},
+								},
+							},
+							Image{AltText: "Diagram", Url: "docs/arch.png", Title: `The "big" picture`},
+						},
+					},
+				},
+			},
+			expected: "# MyDoc\n\n## Architecture\n\nBuild status: ![build](https://example.com/badge.svg)\n\n![Diagram](docs/arch.png \"The \\\"big\\\" picture\")\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := tc.renderer.Render(&tc.document)
+
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+
+			if errMsg != tc.errorMessage {
+				t.Errorf("Expected error %s, got %s", tc.errorMessage, errMsg)
+			}
+
+			if content != tc.expected {
+				t.Errorf("Expected content %s, got %s", tc.expected, content)
+			}
+		})
+	}
+}
+
+// widget is a content node type the built-in renderers know nothing about
+type widget string
+
+func (w widget) Type() ContentType { return ContentType(100) }
+
+func (w widget) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{Type: w.Type(), Content: string(w)}, nil
+}
+
+// panel is a structure node type the built-in renderers know nothing about
+type panel struct {
+	Content []Node
+}
+
+func (p panel) Type() ContentType  { return ContentType(101) }
+func (p panel) Identifier() string { return "panel" }
+func (p panel) Children() []Node   { return p.Content }
+
+func TestMarkdownRenderUnknownNodes(t *testing.T) {
+	document := Document{
+		Name: "MyDoc",
+		Content: []Node{
+			Section{
+				Name: "INTRO",
+				Content: []Node{
+					Text("before"),
+					widget("custom widget"),
+					panel{Content: []Node{Text("inside a panel")}},
+					Text("after"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		policy       UnknownNodePolicy
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:         "Fail-Error",
+			policy:       UnknownNodesError,
+			errorMessage: "rendering \"MyDoc > INTRO\": unknown content node type",
+		},
+		{
+			name:     "Passing-Skip",
+			policy:   UnknownNodesSkip,
+			expected: "# MyDoc\n\n## INTRO\n\nbefore\n\nafter\n",
+		},
+		{
+			name:     "Passing-Text",
+			policy:   UnknownNodesText,
+			expected: "# MyDoc\n\n## INTRO\n\nbefore\n\ncustom widget\n\ninside a panel\n\nafter\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewMarkdownRenderer(WithUnknownNodes(tc.policy))
+			if err != nil {
+				t.Fatalf("unexpected error creating renderer: %s", err.Error())
+			}
+
+			content, err := renderer.Render(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %s, got %s", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestParseUnknownNodePolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		expected     UnknownNodePolicy
+		errorMessage string
+	}{
+		{name: "Passing-Error", value: "error", expected: UnknownNodesError},
+		{name: "Passing-Skip", value: "skip", expected: UnknownNodesSkip},
+		{name: "Passing-Text", value: "Text", expected: UnknownNodesText},
+		{
+			name:         "Fail-Invalid",
+			value:        "ignore",
+			errorMessage: "invalid unknown node policy 'ignore' (expected error, skip, or text)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := ParseUnknownNodePolicy(tc.value)
+
+			checkErrors(tc.errorMessage, err, t)
+			if policy != tc.expected {
+				t.Errorf("Expected policy %d, got %d", tc.expected, policy)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderLineBreaks(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []OptionBuilder[Markdown]
+		paragraph *Paragraph
+		expected  string
+	}{
+		{
+			name:      "Passing",
+			paragraph: NewParagraph().Text("First line").LineBreak().Text("second line"),
+			expected:  "# MyDoc\n\nFirst line  \nsecond line\n",
+		},
+		{
+			name:      "Passing-ConsecutiveBreaksCollapse",
+			paragraph: NewParagraph().Text("First").Code("line").LineBreak().LineBreak().LineBreak().Text("second"),
+			expected:  "# MyDoc\n\nFirst `line`  \nsecond\n",
+		},
+		{
+			name:      "Passing-LeadingAndTrailingBreaksDropped",
+			paragraph: NewParagraph().LineBreak().Text("Only line").LineBreak(),
+			expected:  "# MyDoc\n\nOnly line\n",
+		},
+		{
+			name:      "Passing-HTMLBreaks",
+			options:   []OptionBuilder[Markdown]{WithHTMLLineBreaks(true)},
+			paragraph: NewParagraph().Text("First line").LineBreak().Text("second line"),
+			expected:  "# MyDoc\n\nFirst line<br>\nsecond line\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewMarkdownRenderer(tc.options...)
+			if err != nil {
+				t.Fatalf("unexpected error creating renderer: %s", err.Error())
+			}
+
+			content, err := renderer.Render(&Document{Name: "MyDoc", Content: []Node{tc.paragraph}})
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderShortTableRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		addRow   func(table *Table) error
+		expected string
+	}{
+		{
+			name:     "Passing-OneValue",
+			addRow:   func(table *Table) error { return table.AddRow("only") },
+			expected: "| only |  |  |",
+		},
+		{
+			name:     "Passing-TwoValues",
+			addRow:   func(table *Table) error { return table.AddRow("one", "two") },
+			expected: "| one | two |  |",
+		},
+		{
+			name:     "Passing-OneRichCell",
+			addRow:   func(table *Table) error { return table.AddRichRow(Code("only")) },
+			expected: "| `only` |  |  |",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table := NewTable([]string{"a", "b", "c"}, []TableRow{})
+			if err := tc.addRow(table); err != nil {
+				t.Fatalf("unexpected error adding row: %s", err.Error())
+			}
+
+			content, err := Markdown{}.Render(&Document{Name: "MyDoc", Content: []Node{table}})
+			checkErrors("", err, t)
+
+			lines := strings.Split(strings.TrimSpace(content), "\n")
+			row := lines[len(lines)-1]
+			header := lines[len(lines)-3]
+
+			if row != tc.expected {
+				t.Errorf("Expected row %q, got %q", tc.expected, row)
+			}
+
+			if strings.Count(row, "|") != strings.Count(header, "|") {
+				t.Errorf("Expected row %q to have as many pipes as header %q", row, header)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderCrossRefs(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     func() Document
+		errorMessage string
+		expected     string
+	}{
+		{
+			name: "Passing",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.WriteIntro().Text("See the").CrossRef("", "Installation").Text("section.")
+				document.CreateSection("Installation").WriteParagraph().Text("Run the installer.")
+
+				return document
+			},
+			expected: "# MyDoc\n\nSee the [Installation](#installation) section.\n\n## Installation\n\nRun the installer.\n",
+		},
+		{
+			name: "Passing-Punctuation",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.WriteIntro().CrossRef("FAQ", "What's new in v2.0? (Beta!)")
+				document.CreateSection("What's new in v2.0? (Beta!)").WriteParagraph().Text("Everything.")
+
+				return document
+			},
+			expected: "# MyDoc\n\n[FAQ](#whats-new-in-v20-beta)\n\n## What's new in v2.0? (Beta!)\n\nEverything.\n",
+		},
+		{
+			name: "Passing-DuplicateNames",
+			document: func() Document {
+				document := Document{Name: "Guide"}
+				document.WriteIntro().CrossRef("", "Configuration").Text("then").CrossRef("", "macOS")
+				linux := document.CreateSection("Linux")
+				linux.CreateSection("Configuration").WriteParagraph().Text("Edit the file.")
+				macOS := document.CreateSection("macOS")
+				macOS.CreateSection("Configuration").WriteParagraph().Text("Edit the plist.")
+
+				return document
+			},
+			expected: "# Guide\n\n[Configuration](#configuration) then [macOS](#macos)\n\n## Linux\n\n### Configuration\n\nEdit the file.\n\n## macOS\n\n### Configuration\n\nEdit the plist.\n",
+		},
+		{
+			name: "Failing-MissingSection",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.WriteIntro().CrossRef("", "Installation")
+
+				return document
+			},
+			errorMessage: "rendering \"MyDoc\": cross reference to section 'Installation': section not found in document",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+			content, err := Markdown{}.Render(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderTableOfContents(t *testing.T) {
+	newNestedDocument := func(maxDepth int) Document {
+		document := Document{Name: "Guide"}
+		document.WriteTableOfContents(maxDepth)
+
+		install := document.CreateSection("Install")
+		linux := install.CreateSection("Linux")
+		linux.CreateSection("Debian & Ubuntu").WriteParagraph().Text("Use apt.")
+		install.CreateSection("macOS").WriteParagraph().Text("Use brew.")
+		document.CreateSection("Usage").WriteParagraph().Text("Run it.")
+
+		return document
+	}
+
+	tests := []struct {
+		name         string
+		document     Document
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing-DepthLimit",
+			document: newNestedDocument(2),
+			expected: "# Guide\n\n- [Install](#install)\n  - [Linux](#linux)\n  - [macOS](#macos)\n- [Usage](#usage)\n\n## Install\n\n### Linux\n\n#### Debian & Ubuntu\n\nUse apt.\n\n### macOS\n\nUse brew.\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name:     "Passing-AllLevels",
+			document: newNestedDocument(0),
+			expected: "# Guide\n\n- [Install](#install)\n  - [Linux](#linux)\n    - [Debian & Ubuntu](#debian--ubuntu)\n  - [macOS](#macos)\n- [Usage](#usage)\n\n## Install\n\n### Linux\n\n#### Debian & Ubuntu\n\nUse apt.\n\n### macOS\n\nUse brew.\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name: "Passing-NoSections",
+			document: func() Document {
+				document := Document{Name: "Guide"}
+				document.WriteIntro().Text("Nothing to list.")
+				document.WriteTableOfContents(2)
+
+				return document
+			}(),
+			expected: "# Guide\n\nNothing to list.\n",
+		},
+		{
+			name: "Failing-NegativeDepth",
+			document: func() Document {
+				document := Document{Name: "Guide"}
+				document.WriteTableOfContents(-1)
+
+				return document
+			}(),
+			errorMessage: "rendering \"Guide\": table of contents depth cannot be negative, got -1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := Markdown{}.Render(&tc.document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderSectionNumbering(t *testing.T) {
+	tests := []struct {
+		name     string
+		document func() Document
+		expected string
+	}{
+		{
+			name: "Passing-NestedSiblings",
+			document: func() Document {
+				document := Document{Name: "Runbook"}
+				setup := document.CreateSection("Setup")
+				setup.CreateSection("Prerequisites").WriteParagraph().Text("Install Go.")
+				database := setup.CreateSection("Database Setup")
+				database.CreateSection("Migrations").WriteParagraph().Text("Run them.")
+				database.CreateSection("Seeds").WriteParagraph().Text("Load them.")
+				document.CreateSection("Operations").CreateSection("Backups").WriteParagraph().Text("Nightly.")
+
+				return document
+			},
+			expected: "# Runbook\n\n## 1 Setup\n\n### 1.1 Prerequisites\n\nInstall Go.\n\n### 1.2 Database Setup\n\n#### 1.2.1 Migrations\n\nRun them.\n\n#### 1.2.2 Seeds\n\nLoad them.\n\n## 2 Operations\n\n### 2.1 Backups\n\nNightly.\n",
+		},
+		{
+			name: "Passing-BeyondHeadingCap",
+			document: func() Document {
+				document := Document{Name: "Deep"}
+				section := document.CreateSection("One")
+				for _, name := range []string{"Two", "Three", "Four", "Five"} {
+					section = section.CreateSection(name)
+				}
+				section.WriteParagraph().Text("Bottom.")
+
+				return document
+			},
+			expected: "# Deep\n\n## 1 One\n\n### 1.1 Two\n\n#### 1.1.1 Three\n\n##### 1.1.1.1 Four\n\n##### 1.1.1.1.1 Five\n\nBottom.\n",
+		},
+		{
+			name: "Passing-CollapsibleAndAnchors",
+			document: func() Document {
+				document := Document{Name: "Guide"}
+				document.WriteTableOfContents(0)
+				document.CreateSection("Install").WriteParagraph().Text("See").CrossRef("", "Usage")
+				document.CreateSection("Extras").CreateCollapsible("More").CreateSection("Usage").WriteParagraph().Text("Run it.")
+
+				return document
+			},
+			expected: "# Guide\n\n- [1 Install](#1-install)\n- [2 Extras](#2-extras)\n  - [2.1 Usage](#21-usage)\n\n## 1 Install\n\nSee [Usage](#21-usage)\n\n## 2 Extras\n\n<details>\n<summary>More</summary>\n\n### 2.1 Usage\n\nRun it.\n\n</details>\n",
+		},
+	}
+
+	renderer, err := NewMarkdownRenderer(WithSectionNumbering())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+			content, err := renderer.Render(&document)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderHeadingLevels(t *testing.T) {
+	deepDocument := func() Document {
+		document := Document{Name: "Deep"}
+		section := document.CreateSection("One")
+		for _, name := range []string{"Two", "Three", "Four", "Five"} {
+			section = section.CreateSection(name)
+		}
+		section.WriteParagraph().Text("Bottom.")
+
+		return document
+	}
+
+	tests := []struct {
+		name         string
+		opts         []OptionBuilder[Markdown]
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing-Defaults",
+			expected: "# Deep\n\n## One\n\n### Two\n\n#### Three\n\n##### Four\n\n##### Five\n\nBottom.\n",
+		},
+		{
+			name:     "Passing-OffsetWithinDefaultCap",
+			opts:     []OptionBuilder[Markdown]{WithHeadingOffset(1)},
+			expected: "## Deep\n\n### One\n\n#### Two\n\n##### Three\n\n##### Four\n\n##### Five\n\nBottom.\n",
+		},
+		{
+			name:     "Passing-OffsetWithRaisedCap",
+			opts:     []OptionBuilder[Markdown]{WithHeadingOffset(1), WithMaxHeadingLevel(6)},
+			expected: "## Deep\n\n### One\n\n#### Two\n\n##### Three\n\n###### Four\n\n###### Five\n\nBottom.\n",
+		},
+		{
+			name:     "Passing-LoweredCap",
+			opts:     []OptionBuilder[Markdown]{WithMaxHeadingLevel(3)},
+			expected: "# Deep\n\n## One\n\n### Two\n\n### Three\n\n### Four\n\n### Five\n\nBottom.\n",
+		},
+		{
+			name:     "Passing-OffsetAtCap",
+			opts:     []OptionBuilder[Markdown]{WithMaxHeadingLevel(2), WithHeadingOffset(1)},
+			expected: "## Deep\n\n## One\n\n## Two\n\n## Three\n\n## Four\n\n## Five\n\nBottom.\n",
+		},
+		{
+			name:         "Failing-OffsetPastCap",
+			opts:         []OptionBuilder[Markdown]{WithHeadingOffset(3), WithMaxHeadingLevel(3)},
+			errorMessage: "heading offset 3 puts the top heading at level 4, past the max heading level 3",
+		},
+		{
+			name:         "Failing-OffsetPastDefaultCap",
+			opts:         []OptionBuilder[Markdown]{WithHeadingOffset(5)},
+			errorMessage: "heading offset 5 puts the top heading at level 6, past the max heading level 5",
+		},
+		{
+			name:         "Failing-NegativeOffset",
+			opts:         []OptionBuilder[Markdown]{WithHeadingOffset(-1)},
+			errorMessage: "heading offset cannot be negative, got -1",
+		},
+		{
+			name:         "Failing-CapOutOfRange",
+			opts:         []OptionBuilder[Markdown]{WithMaxHeadingLevel(7)},
+			errorMessage: "max heading level must be between 1 and 6, got 7",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewMarkdownRenderer(tc.opts...)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			document := deepDocument()
+			content, err := renderer.Render(&document)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderListAndFenceStyles(t *testing.T) {
+	newStyledDocument := func() Document {
+		document := Document{Name: "MyDoc"}
+		section := document.CreateSection("Setup")
+		list := section.CreateList(BULLET)
+		list.Append("Install Go")
+		list.CreateList(NUMBERED).Append("Download")
+		tasks := section.CreateList(TASK)
+		tasks.AppendTask("Clone", true)
+		section.WriteExecutable("bash", []string{"go", "build"}, []string{})
+
+		return document
+	}
+
+	tests := []struct {
+		name         string
+		opts         []OptionBuilder[Markdown]
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing-Defaults",
+			expected: "# MyDoc\n\n## Setup\n\n- Install Go\n  1. Download\n\n\n- [x] Clone\n\n\n```bash\ngo build\n```\n",
+		},
+		{
+			name:     "Passing-StarBulletsAndTildes",
+			opts:     []OptionBuilder[Markdown]{WithBulletMarker('*'), WithFenceStyle(FenceTildes)},
+			expected: "# MyDoc\n\n## Setup\n\n* Install Go\n  1. Download\n\n\n* [x] Clone\n\n\n~~~bash\ngo build\n~~~\n",
+		},
+		{
+			name:     "Passing-PlusBulletsAndBackticks",
+			opts:     []OptionBuilder[Markdown]{WithBulletMarker('+'), WithFenceStyle(FenceBackticks)},
+			expected: "# MyDoc\n\n## Setup\n\n+ Install Go\n  1. Download\n\n\n+ [x] Clone\n\n\n```bash\ngo build\n```\n",
+		},
+		{
+			name:         "Failing-InvalidBulletMarker",
+			opts:         []OptionBuilder[Markdown]{WithBulletMarker('#')},
+			errorMessage: "invalid bullet marker '#' (expected '-', '*', or '+')",
+		},
+		{
+			name:         "Failing-InvalidFenceStyle",
+			opts:         []OptionBuilder[Markdown]{WithFenceStyle(FenceStyle(9))},
+			errorMessage: "invalid fence style: 9",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewMarkdownRenderer(tc.opts...)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			document := newStyledDocument()
+			content, err := renderer.Render(&document)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+// failingWriter accepts up to limit bytes and then fails every write.
+type failingWriter struct {
+	limit   int
+	written strings.Builder
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.written.Len()+len(p) > f.limit {
+		return 0, errors.New("disk full")
+	}
+
+	return f.written.Write(p)
+}
+
+func TestMarkdownRenderTo(t *testing.T) {
+	newStreamDocument := func() Document {
+		document := Document{Name: "MyDoc"}
+		document.WriteIntro().Text("Welcome.")
+		install := document.CreateSection("Install")
+		install.WriteParagraph().Text("Run it.")
+		install.CreateSection("Linux").WriteRemoteContent(Remote{Reader: strings.NewReader("remote notes")})
+
+		return document
+	}
+
+	tests := []struct {
+		name         string
+		limit        int
+		errorMessage string
+	}{
+		{
+			name:  "Passing",
+			limit: 1 << 20,
+		},
+		{
+			name:         "Failing-DocumentHeading",
+			limit:        0,
+			errorMessage: "writing \"MyDoc\": disk full",
+		},
+		{
+			name:         "Failing-NestedSection",
+			limit:        len("# MyDoc\n\nWelcome.\n\n## Install\n\nRun it.\n\n"),
+			errorMessage: "writing \"MyDoc > Install > Linux\": disk full",
+		},
+	}
+
+	renderer, err := NewMarkdownRenderer()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newStreamDocument()
+			writer := &failingWriter{limit: tc.limit}
+
+			err := renderer.RenderTo(&document, writer)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			expected := "# MyDoc\n\nWelcome.\n\n## Install\n\nRun it.\n\n### Linux\n\nremote notes\n"
+			if writer.written.String() != expected {
+				t.Errorf("Expected content %q, got %q", expected, writer.written.String())
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderErrorPaths(t *testing.T) {
+	readErr := errors.New("connection reset")
+
+	document := Document{Name: "MyDoc"}
+	document.WriteIntro().Text("Welcome.")
+	install := document.CreateSection("Quick Start").CreateSection("Installation")
+	install.WriteParagraph().Text("Fetching notes.")
+	install.WriteRemoteContent(Remote{Reader: iotest.ErrReader(readErr)})
+
+	_, err := Markdown{}.Render(&document)
+	checkErrors("rendering \"MyDoc > Quick Start > Installation\": connection reset", err, t)
+
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("Expected a RenderError, got %T", err)
+	}
+
+	if renderErr.Path != "MyDoc > Quick Start > Installation" {
+		t.Errorf("Expected path %q, got %q", "MyDoc > Quick Start > Installation", renderErr.Path)
+	}
+
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected error to wrap the reader error, got %s", err)
+	}
+}
+
+func TestMarkdownRenderComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		document func() Document
+		expected string
+	}{
+		{
+			name:    "Passing-Kept",
+			enabled: true,
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				usage := document.CreateSection("Usage")
+				usage.WriteParagraph().Text("First.")
+				usage.WriteComment("reviewer note")
+				usage.WriteParagraph().Text("Second.")
+
+				return document
+			},
+			expected: "# MyDoc\n\n## Usage\n\nFirst.\n\n<!-- reviewer note -->\n\nSecond.\n",
+		},
+		{
+			name: "Passing-StrippedBetweenParagraphs",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				usage := document.CreateSection("Usage")
+				usage.WriteParagraph().Text("First.")
+				usage.WriteComment("reviewer note")
+				usage.WriteComment("another note")
+				usage.WriteParagraph().Text("Second.")
+
+				return document
+			},
+			expected: "# MyDoc\n\n## Usage\n\nFirst.\n\nSecond.\n",
+		},
+		{
+			name: "Passing-StrippedOnlyChild",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Notes").WriteComment("fill this in")
+				document.CreateSection("Usage").WriteParagraph().Text("Run it.")
+
+				return document
+			},
+			expected: "# MyDoc\n\n## Notes\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name: "Passing-StrippedOnlyChildOfLastSection",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Usage").WriteParagraph().Text("Run it.")
+				document.CreateSection("Notes").WriteComment("fill this in")
+
+				return document
+			},
+			expected: "# MyDoc\n\n## Usage\n\nRun it.\n\n## Notes\n",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			content, err := tc.renderer.Render(&tc.document)
-
-			var errMsg string
+			renderer, err := NewMarkdownRenderer(WithComments(tc.enabled))
 			if err != nil {
-				errMsg = err.Error()
+				t.Fatalf("Unexpected error: %s", err)
 			}
 
-			if errMsg != tc.errorMessage {
-				t.Errorf("Expected error %s, got %s", tc.errorMessage, errMsg)
+			document := tc.document()
+			content, err := renderer.Render(&document)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderLineEndings(t *testing.T) {
+	newLineEndingDocument := func() Document {
+		document := Document{Name: "MyDoc"}
+		document.WriteIntro().Text("Welcome.")
+		usage := document.CreateSection("Usage")
+		usage.WriteRemoteContent(Remote{Reader: strings.NewReader("line one\r\nline two")})
+		usage.CreateList(BULLET).Append("last item")
+
+		return document
+	}
+
+	tests := []struct {
+		name         string
+		opts         []OptionBuilder[Markdown]
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing-Defaults",
+			expected: "# MyDoc\n\nWelcome.\n\n## Usage\n\nline one\r\nline two\n\n- last item\n\n",
+		},
+		{
+			name:     "Passing-CRLF",
+			opts:     []OptionBuilder[Markdown]{WithLineEnding(LineEndingCRLF)},
+			expected: "# MyDoc\r\n\r\nWelcome.\r\n\r\n## Usage\r\n\r\nline one\r\nline two\r\n\r\n- last item\r\n\r\n",
+		},
+		{
+			name:     "Passing-SingleTrailingNewline",
+			opts:     []OptionBuilder[Markdown]{WithSingleTrailingNewline()},
+			expected: "# MyDoc\n\nWelcome.\n\n## Usage\n\nline one\nline two\n\n- last item\n",
+		},
+		{
+			name:     "Passing-CRLFSingleTrailingNewline",
+			opts:     []OptionBuilder[Markdown]{WithLineEnding(LineEndingCRLF), WithSingleTrailingNewline()},
+			expected: "# MyDoc\r\n\r\nWelcome.\r\n\r\n## Usage\r\n\r\nline one\r\nline two\r\n\r\n- last item\r\n",
+		},
+		{
+			name:         "Failing-InvalidLineEnding",
+			opts:         []OptionBuilder[Markdown]{WithLineEnding(LineEnding(3))},
+			errorMessage: "invalid line ending: 3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewMarkdownRenderer(tc.opts...)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
 			}
 
+			document := newLineEndingDocument()
+			content, err := renderer.Render(&document)
+
+			checkErrors("", err, t)
 			if content != tc.expected {
-				t.Errorf("Expected content %s, got %s", tc.expected, content)
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderMultilineCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     Node
+		expected string
+	}{
+		{
+			name: "Passing-BashScript",
+			node: Executable{
+				Shell:    "bash",
+				Cmd:      []string{"set -e", "go vet ./...", "go test ./..."},
+				JoinWith: "\n",
+			},
+			expected: "```bash\nset -e\ngo vet ./...\ngo test ./...\n```",
+		},
+		{
+			name: "Passing-JSONPayload",
+			node: CodeBlock{
+				BlockType: "json",
+				Cmd:       []string{"{", `  "name": "doyoucompute",`, `  "private": false`, "}"},
+				JoinWith:  "\n",
+			},
+			expected: "```json\n{\n  \"name\": \"doyoucompute\",\n  \"private\": false\n}\n```",
+		},
+		{
+			name:     "Passing-DefaultJoin",
+			node:     CodeBlock{BlockType: "sh", Cmd: []string{"go", "vet"}},
+			expected: "```sh\ngo vet\n```",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := Markdown{}.Render(tc.node)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     Node
+		expected string
+	}{
+		{
+			name:     "Passing-TableCellPipe",
+			node:     &Table{Headers: []string{"Flag", "a|b"}, Items: []TableRow{{Values: []string{"--mode", "fast|slow"}}}},
+			expected: "| Flag | a\\|b |\n| ---- | ---- |\n| --mode | fast\\|slow |",
+		},
+		{
+			name:     "Passing-CodeWithoutBackticks",
+			node:     NewParagraph().Code("go test"),
+			expected: "`go test`",
+		},
+		{
+			name:     "Passing-CodeWithOneBacktick",
+			node:     NewParagraph().Code("echo `date`"),
+			expected: "`` echo `date` ``",
+		},
+		{
+			name:     "Passing-CodeWithTwoBackticks",
+			node:     NewParagraph().Code("``nested``"),
+			expected: "``` ``nested`` ```",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := Markdown{}.Render(tc.node)
+
+			checkErrors("", err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestNewFileRenderer(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		unknownNodes UnknownNodePolicy
+		withWidget   bool
+		expected     string
+		errorMessage string
+	}{
+		{name: "Passing-Markdown", format: "markdown", expected: "# Doc\n\nHi\n"},
+		{name: "Passing-MarkdownAlias", format: "MD", expected: "# Doc\n\nHi\n"},
+		{name: "Passing-HTML", format: "html", expected: "<h1 id=\"doc\">Doc</h1>\n<p>Hi</p>\n"},
+		{name: "Passing-JSON", format: "json", expected: "{\n  \"type\": \"Document\",\n  \"identifier\": \"Doc\",\n  \"children\": [\n    {\n      \"type\": \"Paragraph\",\n      \"children\": [\n        {\n          \"type\": \"Text\",\n          \"content\": \"Hi\"\n        }\n      ]\n    }\n  ]\n}\n"},
+		{name: "Passing-Text", format: "text", expected: "Doc\n===\n\nHi\n"},
+		{name: "Passing-TextSkipsUnknown", format: "txt", unknownNodes: UnknownNodesSkip, withWidget: true, expected: "Doc\n===\n\nHi\n"},
+		{name: "Failing-TextUnknown", format: "text", withWidget: true, errorMessage: "unknown content node type"},
+		{name: "Failing-IssueFormWithoutDescription", format: "issue-form", errorMessage: "issue form needs a description: set 'description' in the document's frontmatter"},
+		{name: "Failing-InvalidFormat", format: "pdf", errorMessage: "invalid format 'pdf' (expected markdown, html, json, text, notebook, or issue-form)"},
+		{name: "Failing-InvalidPolicy", format: "html", unknownNodes: UnknownNodePolicy(9), errorMessage: "invalid unknown node policy: 9"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := NewFileRenderer(tc.format, tc.unknownNodes)
+			if tc.errorMessage != "" && err != nil {
+				checkErrors(tc.errorMessage, err, t)
+				return
+			}
+			checkErrors("", err, t)
+
+			document := Document{Name: "Doc", Content: []Node{NewParagraph().Text("Hi")}}
+			if tc.withWidget {
+				document.Content = append(document.Content, widget("gear"))
+			}
+			content, err := renderer.Render(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestCommandPlanDisplayEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		envValues map[string]string
+		expected  []string
+	}{
+		{
+			name:     "No values",
+			expected: []string{},
+		},
+		{
+			name:      "Sorted by name",
+			envValues: map[string]string{"ENV": "staging", "CGO_ENABLED": "0"},
+			expected:  []string{"CGO_ENABLED=0", "ENV=staging"},
+		},
+		{
+			name:      "Secrets are redacted",
+			envValues: map[string]string{"GITHUB_TOKEN": "ghp_123", "db_password": "hunter2", "REGION": "eu-west-1"},
+			expected:  []string{"GITHUB_TOKEN=***", "REGION=eu-west-1", "db_password=***"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			found := CommandPlan{EnvValues: tc.envValues}.DisplayEnv()
+
+			if !reflect.DeepEqual(found, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, found)
 			}
 		})
 	}
@@ -116,10 +1300,17 @@ func TestExecutionPlanRender(t *testing.T) {
 								Name: "Quick Start",
 								Content: []Node{
 									Text("Install dependencies"),
+									Image{AltText: "Diagram", Url: "docs/arch.png"},
 									Executable{
 										Shell: "bash",
 										Cmd:   []string{"go", "get"},
 									},
+									Pipeline{
+										Stages: []Executable{
+											{Shell: "bash", Cmd: []string{"go", "list", "./..."}},
+											{Shell: "sh", Cmd: []string{"wc", "-l"}},
+										},
+									},
 								},
 							},
 						},
@@ -143,8 +1334,380 @@ func TestExecutionPlanRender(t *testing.T) {
 						Level: 3,
 					},
 				},
+				{
+					Shell: "bash",
+					Args:  []string{"go", "list", "./...", "|", "wc", "-l"},
+					Context: SectionInfo{
+						Name:  "Quick Start",
+						Level: 3,
+					},
+					Stages: []CommandPlan{
+						{Shell: "bash", Args: []string{"go", "list", "./..."}, Context: SectionInfo{Name: "Quick Start", Level: 3}},
+						{Shell: "sh", Args: []string{"wc", "-l"}, Context: SectionInfo{Name: "Quick Start", Level: 3}},
+					},
+				},
+			},
+		},
+		{
+			name: "Passing-ListItems",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				list := document.CreateSection("Setup").CreateList(NUMBERED)
+				list.Append("Install the tools")
+				nested := list.CreateList(BULLET)
+				nested.AppendNode(Executable{Shell: "bash", Cmd: []string{"go", "install"}})
+				deeper := nested.CreateList(BULLET)
+				deeper.AppendNode(Executable{Shell: "bash", Cmd: []string{"go", "vet"}})
+				list.AppendParagraph().Text("Then run").Code("go test")
+				list.AppendNode(Executable{Shell: "bash", Cmd: []string{"go", "test"}})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "install"},
+					Context: SectionInfo{Name: "Setup", Level: 2},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "vet"},
+					Context: SectionInfo{Name: "Setup", Level: 2},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "test"},
+					Context: SectionInfo{Name: "Setup", Level: 2},
+				},
+			},
+		},
+		{
+			name: "Passing-Collapsible",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				details := document.CreateSection("Troubleshooting").CreateCollapsible("Reset")
+				details.AddNode(HTMLBlock("<br>"))
+				details.AddNode(MathInline("x^2"))
+				details.AddNode(MathBlock{Lines: []string{"x^2"}})
+				details.AddNode(Executable{Shell: "bash", Cmd: []string{"go", "clean", "-cache"}})
+				details.CreateSection("Deep clean").WriteExecutable("bash", []string{"go", "clean", "-modcache"}, []string{})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "clean", "-cache"},
+					Context: SectionInfo{Name: "Troubleshooting", Level: 2},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "clean", "-modcache"},
+					Context: SectionInfo{Name: "Deep clean", Level: 3},
+				},
+			},
+		},
+		{
+			name: "Passing-TimeoutAndWorkingDir",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				build := document.CreateSection("Build")
+				build.Content = append(build.Content,
+					Executable{Shell: "bash", Cmd: []string{"make"}, Timeout: time.Minute, WorkingDir: "web"},
+				)
+				build.WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"cat", "log"}, WorkingDir: "logs", Timeout: time.Second},
+					Executable{Shell: "bash", Cmd: []string{"wc", "-l"}, Timeout: time.Minute},
+				)
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:      "bash",
+					Args:       []string{"make"},
+					Context:    SectionInfo{Name: "Build", Level: 2},
+					Timeout:    time.Minute,
+					WorkingDir: "web",
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"cat", "log", "|", "wc", "-l"},
+					Context: SectionInfo{Name: "Build", Level: 2},
+					Timeout: time.Minute,
+					Stages: []CommandPlan{
+						{Shell: "bash", Args: []string{"cat", "log"}, Context: SectionInfo{Name: "Build", Level: 2}, WorkingDir: "logs"},
+						{Shell: "bash", Args: []string{"wc", "-l"}, Context: SectionInfo{Name: "Build", Level: 2}},
+					},
+				},
+			},
+		},
+		{
+			name: "Passing-EnvValues",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				build := document.CreateSection("Build")
+				build.Content = append(build.Content,
+					Executable{Shell: "bash", Cmd: []string{"go", "build"}, EnvValues: map[string]string{"CGO_ENABLED": "0"}},
+				)
+				build.WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"env"}, EnvValues: map[string]string{"ENV": "staging"}},
+					Executable{Shell: "bash", Cmd: []string{"grep", "ENV"}},
+				)
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:     "bash",
+					Args:      []string{"go", "build"},
+					Context:   SectionInfo{Name: "Build", Level: 2},
+					EnvValues: map[string]string{"CGO_ENABLED": "0"},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"env", "|", "grep", "ENV"},
+					Context: SectionInfo{Name: "Build", Level: 2},
+					Stages: []CommandPlan{
+						{Shell: "bash", Args: []string{"env"}, Context: SectionInfo{Name: "Build", Level: 2}, EnvValues: map[string]string{"ENV": "staging"}},
+						{Shell: "bash", Args: []string{"grep", "ENV"}, Context: SectionInfo{Name: "Build", Level: 2}},
+					},
+				},
+			},
+		},
+		{
+			name: "Passing-Platforms",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				install := document.CreateSection("Install")
+				install.Content = append(install.Content,
+					Executable{Shell: "bash", Cmd: []string{"brew", "install", "jq"}, Platforms: []string{"darwin"}},
+					Executable{Shell: "bash", Cmd: []string{"apt-get", "install", "jq"}, Platforms: []string{"linux"}},
+				)
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:     "bash",
+					Args:      []string{"brew", "install", "jq"},
+					Context:   SectionInfo{Name: "Install", Level: 2},
+					Platforms: []string{"darwin"},
+				},
+				{
+					Shell:     "bash",
+					Args:      []string{"apt-get", "install", "jq"},
+					Context:   SectionInfo{Name: "Install", Level: 2},
+					Platforms: []string{"linux"},
+				},
+			},
+		},
+		{
+			name: "Passing-EnvConditions",
+			document: func() Document {
+				document := Document{Name: "Deploy"}
+				migrate := document.CreateSection("Migrate")
+				migrate.Content = append(migrate.Content,
+					Executable{Shell: "bash", Cmd: []string{"make", "migrate"}, OnlyIfEnv: []string{"RUN_MIGRATIONS=1"}, SkipIfEnv: []string{"DRY"}},
+				)
+				migrate.WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"cat", "plan.sql"}, OnlyIfEnv: []string{"RUN_MIGRATIONS"}},
+					Executable{Shell: "bash", Cmd: []string{"psql"}, SkipIfEnv: []string{"CI=true"}},
+				)
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:     "bash",
+					Args:      []string{"make", "migrate"},
+					Context:   SectionInfo{Name: "Migrate", Level: 2},
+					SkipIfEnv: []string{"DRY"},
+					OnlyIfEnv: []string{"RUN_MIGRATIONS=1"},
+				},
+				{
+					Shell:     "bash",
+					Args:      []string{"cat", "plan.sql", "|", "psql"},
+					Context:   SectionInfo{Name: "Migrate", Level: 2},
+					SkipIfEnv: []string{"CI=true"},
+					OnlyIfEnv: []string{"RUN_MIGRATIONS"},
+					Stages: []CommandPlan{
+						{Shell: "bash", Args: []string{"cat", "plan.sql"}, Context: SectionInfo{Name: "Migrate", Level: 2}},
+						{Shell: "bash", Args: []string{"psql"}, Context: SectionInfo{Name: "Migrate", Level: 2}},
+					},
+				},
+			},
+		},
+		{
+			name: "Passing-SetupTeardown",
+			document: func() Document {
+				document := Document{Name: "Test"}
+				integration := document.CreateSection("Integration")
+				integration.WriteTeardown("bash", []string{"docker", "stop", "db"}, []string{})
+				integration.WriteExecutable("bash", []string{"go", "test", "./..."}, []string{})
+				integration.WriteSetup("bash", []string{"docker", "start", "db"}, []string{})
+				cache := integration.CreateSection("Cache")
+				cache.WriteExecutable("bash", []string{"go", "test", "./cache"}, []string{})
+				cache.WriteSetup("bash", []string{"docker", "start", "redis"}, []string{})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:   "bash",
+					Args:    []string{"docker", "start", "db"},
+					Context: SectionInfo{Name: "Integration", Level: 2},
+					Phase:   PhaseSetup,
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "test", "./..."},
+					Context: SectionInfo{Name: "Integration", Level: 2},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"docker", "start", "redis"},
+					Context: SectionInfo{Name: "Cache", Level: 3},
+					Phase:   PhaseSetup,
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "test", "./cache"},
+					Context: SectionInfo{Name: "Cache", Level: 3},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"docker", "stop", "db"},
+					Context: SectionInfo{Name: "Integration", Level: 2},
+					Phase:   PhaseTeardown,
+				},
+			},
+		},
+		{
+			name: "Passing-Steps",
+			document: func() Document {
+				document := Document{Name: "Build"}
+				configure := document.CreateSection("Configure")
+				configure.Content = append(configure.Content, Executable{
+					Shell:      "bash",
+					Steps:      [][]string{{"mkdir", "-p", "build"}, {"cmake", "-S", ".", "-B", "build"}},
+					WorkingDir: "src",
+				})
+				configure.WriteExecutable("bash", []string{"make"}, []string{})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:      "bash",
+					Args:       []string{"mkdir", "-p", "build"},
+					Context:    SectionInfo{Name: "Configure", Level: 2},
+					WorkingDir: "src",
+					Step:       1,
+				},
+				{
+					Shell:      "bash",
+					Args:       []string{"cmake", "-S", ".", "-B", "build"},
+					Context:    SectionInfo{Name: "Configure", Level: 2},
+					WorkingDir: "src",
+					Step:       2,
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"make"},
+					Context: SectionInfo{Name: "Configure", Level: 2},
+				},
+			},
+		},
+		{
+			name: "Passing-Tags",
+			document: func() Document {
+				document := Document{Name: "Test"}
+				cleanup := document.CreateSection("Cleanup")
+				cleanup.AddTags("destructive")
+				cleanup.Content = append(cleanup.Content, Executable{Shell: "bash", Cmd: []string{"rm", "-rf", "build"}, Tags: []string{"local", "destructive"}})
+				database := cleanup.CreateSection("Database")
+				database.AddTags("ci-only")
+				database.WriteExecutable("bash", []string{"dropdb", "test"}, []string{})
+				document.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:   "bash",
+					Args:    []string{"rm", "-rf", "build"},
+					Context: SectionInfo{Name: "Cleanup", Level: 2},
+					Tags:    []string{"destructive", "local"},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"dropdb", "test"},
+					Context: SectionInfo{Name: "Database", Level: 3},
+					Tags:    []string{"destructive", "ci-only"},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "build"},
+					Context: SectionInfo{Name: "Build", Level: 2},
+				},
+			},
+		},
+		{
+			name: "Passing-SectionOverrides",
+			document: func() Document {
+				document := Document{Name: "Test"}
+				danger := document.CreateSection("Danger Zone")
+				danger.Override(ConfigOverride{Timeout: 5 * time.Minute, AllowedCommands: []string{"terraform"}})
+				danger.WriteExecutable("bash", []string{"terraform", "apply"}, []string{})
+				danger.Content = append(danger.Content, Executable{Shell: "bash", Cmd: []string{"terraform", "plan"}, Timeout: time.Minute})
+				state := danger.CreateSection("State")
+				state.Override(ConfigOverride{AllowedCommands: []string{"terraform", "rm"}})
+				state.WriteExecutable("bash", []string{"rm", "terraform.tfstate"}, []string{})
+				document.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{})
+
+				return document
+			}(),
+			expected: []CommandPlan{
+				{
+					Shell:           "bash",
+					Args:            []string{"terraform", "apply"},
+					Context:         SectionInfo{Name: "Danger Zone", Level: 2},
+					Timeout:         5 * time.Minute,
+					AllowedCommands: []string{"terraform"},
+				},
+				{
+					Shell:           "bash",
+					Args:            []string{"terraform", "plan"},
+					Context:         SectionInfo{Name: "Danger Zone", Level: 2},
+					Timeout:         time.Minute,
+					AllowedCommands: []string{"terraform"},
+				},
+				{
+					Shell:           "bash",
+					Args:            []string{"rm", "terraform.tfstate"},
+					Context:         SectionInfo{Name: "State", Level: 3},
+					Timeout:         5 * time.Minute,
+					AllowedCommands: []string{"terraform", "rm"},
+				},
+				{
+					Shell:   "bash",
+					Args:    []string{"go", "build"},
+					Context: SectionInfo{Name: "Build", Level: 2},
+				},
 			},
 		},
+		{
+			name: "Failing-NestedPipelinePath",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				install := document.CreateSection("Quick Start").CreateSection("Installation")
+				install.CreateList(BULLET).AppendNode(Pipeline{})
+
+				return document
+			}(),
+			errorMessage: "rendering \"MyDoc > Quick Start > Installation\": pipeline has no stages",
+		},
 	}
 
 	for _, tc := range tests {
@@ -178,6 +1741,42 @@ func TestExecutionPlanRender(t *testing.T) {
 						t.Errorf("expected arg %s at index %d, found %s", expectedArg, idx, foundArg)
 					}
 				}
+
+				if found.Timeout != expected.Timeout || found.WorkingDir != expected.WorkingDir {
+					t.Errorf("Expected timeout %s and working dir %q, got %s and %q", expected.Timeout, expected.WorkingDir, found.Timeout, found.WorkingDir)
+				}
+
+				if !reflect.DeepEqual(found.SkipIfEnv, expected.SkipIfEnv) || !reflect.DeepEqual(found.OnlyIfEnv, expected.OnlyIfEnv) {
+					t.Errorf("Expected conditions %v and %v, got %v and %v", expected.SkipIfEnv, expected.OnlyIfEnv, found.SkipIfEnv, found.OnlyIfEnv)
+				}
+
+				if !reflect.DeepEqual(found.Platforms, expected.Platforms) {
+					t.Errorf("Expected platforms %v, got %v", expected.Platforms, found.Platforms)
+				}
+
+				if found.Phase != expected.Phase {
+					t.Errorf("Expected phase %s, got %s", expected.Phase, found.Phase)
+				}
+
+				if found.Step != expected.Step {
+					t.Errorf("Expected step %d, got %d", expected.Step, found.Step)
+				}
+
+				if !reflect.DeepEqual(found.Tags, expected.Tags) {
+					t.Errorf("Expected tags %v, got %v", expected.Tags, found.Tags)
+				}
+
+				if !reflect.DeepEqual(found.AllowedCommands, expected.AllowedCommands) || !reflect.DeepEqual(found.AllowedShells, expected.AllowedShells) {
+					t.Errorf("Expected allowed commands %v and shells %v, got %v and %v", expected.AllowedCommands, expected.AllowedShells, found.AllowedCommands, found.AllowedShells)
+				}
+
+				if !reflect.DeepEqual(found.EnvValues, expected.EnvValues) {
+					t.Errorf("Expected env values %v, got %v", expected.EnvValues, found.EnvValues)
+				}
+
+				if !reflect.DeepEqual(found.Stages, expected.Stages) {
+					t.Errorf("Expected stages %v, got %v", expected.Stages, found.Stages)
+				}
 			}
 		})
 	}
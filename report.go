@@ -0,0 +1,259 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ReportCounts holds how many commands of a run ended in each status.
+type ReportCounts struct {
+	// Total is the number of commands in the run
+	Total int
+	// Completed is the number of commands that completed successfully
+	Completed int
+	// Failed is the number of commands that failed
+	Failed int
+	// TimedOut is the number of commands stopped for exceeding their timeout
+	TimedOut int
+	// Skipped is the number of commands that were not run
+	Skipped int
+}
+
+// Report aggregates the results of running a document as a script, for consumers that
+// need machine-readable output such as CI systems.
+type Report struct {
+	// Document is the name of the document that was run
+	Document string
+	// Section is the section filter the run was started with (ALL_SECTIONS for the whole document)
+	Section string
+	// StartedAt is when the run started
+	StartedAt time.Time
+	// FinishedAt is when the last command of the run finished
+	FinishedAt time.Time
+	// Results holds the result of every command, in plan order
+	Results []TaskResult
+}
+
+// Counts tallies the results of the report by status.
+func (r Report) Counts() ReportCounts {
+	counts := ReportCounts{Total: len(r.Results)}
+
+	for _, result := range r.Results {
+		switch result.Status {
+		case COMPLETED:
+			counts.Completed++
+		case FAILED:
+			counts.Failed++
+		case TIMEOUT:
+			counts.TimedOut++
+		case SKIPPED:
+			counts.Skipped++
+		}
+	}
+
+	return counts
+}
+
+// Failed reports whether any command of the run failed or timed out.
+func (r Report) Failed() bool {
+	counts := r.Counts()
+
+	return counts.Failed+counts.TimedOut > 0
+}
+
+// reportCountsJSON is the JSON representation of ReportCounts.
+type reportCountsJSON struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	TimedOut  int `json:"timed_out"`
+	Skipped   int `json:"skipped"`
+}
+
+// taskResultJSON is the JSON representation of a TaskResult in a report.
+type taskResultJSON struct {
+	Section         string  `json:"section"`
+	Command         string  `json:"command"`
+	Status          string  `json:"status"`
+	Error           *string `json:"error"`
+	ExitCode        int     `json:"exit_code"`
+	DurationMS      int64   `json:"duration_ms"`
+	Stdout          string  `json:"stdout"`
+	Stderr          string  `json:"stderr"`
+	OutputTruncated bool    `json:"output_truncated"`
+	DryRun          bool    `json:"dry_run"`
+}
+
+// reportJSON is the JSON representation of a Report.
+type reportJSON struct {
+	Document   string           `json:"document"`
+	Section    string           `json:"section"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	DurationMS int64            `json:"duration_ms"`
+	Counts     reportCountsJSON `json:"counts"`
+	Results    []taskResultJSON `json:"results"`
+}
+
+// MarshalJSON encodes the report with a stable schema of snake_case keys. Every key is always
+// present: errors are null for commands without one, durations are whole milliseconds, and
+// timestamps use RFC 3339.
+func (r Report) MarshalJSON() ([]byte, error) {
+	counts := r.Counts()
+
+	results := make([]taskResultJSON, len(r.Results))
+	for idx, result := range r.Results {
+		var errMessage *string
+		if result.Error != nil {
+			message := result.Error.Error()
+			errMessage = &message
+		}
+
+		results[idx] = taskResultJSON{
+			Section:         result.SectionName,
+			Command:         result.Command,
+			Status:          result.Status.String(),
+			Error:           errMessage,
+			ExitCode:        result.ExitCode,
+			DurationMS:      result.Duration.Milliseconds(),
+			Stdout:          result.Stdout,
+			Stderr:          result.Stderr,
+			OutputTruncated: result.OutputTruncated,
+			DryRun:          result.DryRun,
+		}
+	}
+
+	return json.Marshal(reportJSON{
+		Document:   r.Document,
+		Section:    r.Section,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		DurationMS: r.FinishedAt.Sub(r.StartedAt).Milliseconds(),
+		Counts: reportCountsJSON{
+			Total:     counts.Total,
+			Completed: counts.Completed,
+			Failed:    counts.Failed,
+			TimedOut:  counts.TimedOut,
+			Skipped:   counts.Skipped,
+		},
+		Results: results,
+	})
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the commands of a single section.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+
+	duration time.Duration
+}
+
+// junitTestCase holds the result of a single command.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+// junitMessage is a failure or skipped element, with the error as its message.
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// junitSeconds formats a duration as the fractional seconds JUnit reports use.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnitReport writes the results as a JUnit XML testsuites document, so CI systems can
+// show them as a test report. Every section becomes a testsuite, in the order the sections
+// first appear, and every command a testcase. Failed and timed out commands carry a failure
+// with the error as its message and the captured output as its body.
+func WriteJUnitReport(results []TaskResult, w io.Writer) error {
+	report := junitTestSuites{Name: "doyoucompute"}
+	positions := map[string]int{}
+	var total time.Duration
+
+	for _, result := range results {
+		position, ok := positions[result.SectionName]
+		if !ok {
+			position = len(report.Suites)
+			positions[result.SectionName] = position
+			report.Suites = append(report.Suites, junitTestSuite{Name: result.SectionName})
+		}
+		suite := &report.Suites[position]
+
+		testCase := junitTestCase{
+			Name:      result.Command,
+			ClassName: result.SectionName,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Stdout,
+			SystemErr: result.Stderr,
+		}
+
+		var message string
+		if result.Error != nil {
+			message = result.Error.Error()
+		}
+
+		switch {
+		case result.Status.Failed():
+			testCase.Failure = &junitMessage{Message: message, Type: result.Status.String(), Body: result.Stdout + result.Stderr}
+			suite.Failures++
+			report.Failures++
+		case result.Status == SKIPPED:
+			testCase.Skipped = &junitMessage{Message: message}
+			suite.Skipped++
+			report.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suite.duration += result.Duration
+		report.Tests++
+		total += result.Duration
+	}
+
+	for idx := range report.Suites {
+		report.Suites[idx].Time = junitSeconds(report.Suites[idx].duration)
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
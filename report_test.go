@@ -0,0 +1,258 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportMarshalJSON(t *testing.T) {
+	startedAt := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		results        []TaskResult
+		expectedCounts map[string]int
+		expectedResult []map[string]interface{}
+		expectedFailed bool
+	}{
+		{
+			name: "Passing-Success",
+			results: []TaskResult{
+				{SectionName: "Build", Command: "go build", Status: COMPLETED, Duration: 1500 * time.Millisecond, Stdout: "ok\n"},
+			},
+			expectedCounts: map[string]int{"total": 1, "completed": 1, "failed": 0, "timed_out": 0, "skipped": 0},
+			expectedResult: []map[string]interface{}{
+				{
+					"section":          "Build",
+					"command":          "go build",
+					"status":           "COMPLETED",
+					"error":            nil,
+					"exit_code":        float64(0),
+					"duration_ms":      float64(1500),
+					"stdout":           "ok\n",
+					"stderr":           "",
+					"output_truncated": false,
+					"dry_run":          false,
+				},
+			},
+		},
+		{
+			name: "Passing-Failure",
+			results: []TaskResult{
+				{SectionName: "Test", Command: "go test", Status: FAILED, Error: errors.New("exit status 1"), ExitCode: 1, Duration: 20 * time.Millisecond, Stderr: "FAIL\n"},
+				{SectionName: "Test", Command: "sleep 60", Status: TIMEOUT, Error: &TimeoutError{Timeout: time.Second, Err: errors.New("context deadline exceeded")}, ExitCode: ExitCodeTimeout, Duration: time.Second},
+				{SectionName: "Release", Command: "make release", Status: SKIPPED, Error: ErrEarlierCommandFailed, ExitCode: ExitCodeNotStarted},
+			},
+			expectedCounts: map[string]int{"total": 3, "completed": 0, "failed": 1, "timed_out": 1, "skipped": 1},
+			expectedResult: []map[string]interface{}{
+				{
+					"section":          "Test",
+					"command":          "go test",
+					"status":           "FAILED",
+					"error":            "exit status 1",
+					"exit_code":        float64(1),
+					"duration_ms":      float64(20),
+					"stdout":           "",
+					"stderr":           "FAIL\n",
+					"output_truncated": false,
+					"dry_run":          false,
+				},
+				{
+					"section":          "Test",
+					"command":          "sleep 60",
+					"status":           "TIMEOUT",
+					"error":            "command timed out after 1s: context deadline exceeded",
+					"exit_code":        float64(ExitCodeTimeout),
+					"duration_ms":      float64(1000),
+					"stdout":           "",
+					"stderr":           "",
+					"output_truncated": false,
+					"dry_run":          false,
+				},
+				{
+					"section":          "Release",
+					"command":          "make release",
+					"status":           "SKIPPED",
+					"error":            "an earlier command failed",
+					"exit_code":        float64(ExitCodeNotStarted),
+					"duration_ms":      float64(0),
+					"stdout":           "",
+					"stderr":           "",
+					"output_truncated": false,
+					"dry_run":          false,
+				},
+			},
+			expectedFailed: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := Report{
+				Document:   "Runbook",
+				Section:    "Deploy",
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(2 * time.Second),
+				Results:    tc.results,
+			}
+
+			encoded, err := json.Marshal(report)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var decoded struct {
+				Document   string                   `json:"document"`
+				Section    string                   `json:"section"`
+				StartedAt  time.Time                `json:"started_at"`
+				FinishedAt time.Time                `json:"finished_at"`
+				DurationMS int64                    `json:"duration_ms"`
+				Counts     map[string]int           `json:"counts"`
+				Results    []map[string]interface{} `json:"results"`
+			}
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("unexpected error decoding %s: %s", encoded, err)
+			}
+
+			if decoded.Document != "Runbook" || decoded.Section != "Deploy" {
+				t.Errorf("Expected document Runbook and section Deploy, got %q and %q", decoded.Document, decoded.Section)
+			}
+
+			if !decoded.StartedAt.Equal(startedAt) || !decoded.FinishedAt.Equal(startedAt.Add(2*time.Second)) {
+				t.Errorf("Unexpected timestamps %s and %s", decoded.StartedAt, decoded.FinishedAt)
+			}
+
+			if decoded.DurationMS != 2000 {
+				t.Errorf("Expected duration 2000ms, got %dms", decoded.DurationMS)
+			}
+
+			if !reflect.DeepEqual(decoded.Counts, tc.expectedCounts) {
+				t.Errorf("Expected counts %v, got %v", tc.expectedCounts, decoded.Counts)
+			}
+
+			if !reflect.DeepEqual(decoded.Results, tc.expectedResult) {
+				t.Errorf("Expected results %v, got %v", tc.expectedResult, decoded.Results)
+			}
+
+			if report.Failed() != tc.expectedFailed {
+				t.Errorf("Expected Failed() to be %v, got %v", tc.expectedFailed, report.Failed())
+			}
+		})
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	results := []TaskResult{
+		{SectionName: "Build", Command: "go build", Status: COMPLETED, Duration: 1500 * time.Millisecond, Stdout: "built\n"},
+		{SectionName: "Test", Command: "echo '<tag>' && go test -run \"A&B\"", Status: FAILED, Error: errors.New("exit status 1: <bad> & \"worse\""), Duration: 250 * time.Millisecond, Stdout: "--- FAIL\n", Stderr: "panic: <nil>\n"},
+		{SectionName: "Build", Command: "go vet", Status: TIMEOUT, Error: &TimeoutError{Timeout: time.Second, Err: errors.New("context deadline exceeded")}, Duration: time.Second},
+		{SectionName: "Release", Command: "make release", Status: SKIPPED, Error: ErrEarlierCommandFailed},
+	}
+
+	var builder strings.Builder
+	if err := WriteJUnitReport(results, &builder); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(builder.String(), xml.Header) {
+		t.Errorf("Expected the report to start with the XML header, got %q", builder.String())
+	}
+
+	if !strings.Contains(builder.String(), "name=\"echo &#39;&lt;tag&gt;&#39; &amp;&amp; go test -run &#34;A&amp;B&#34;\"") {
+		t.Errorf("Expected special characters in the command to be escaped, got %s", builder.String())
+	}
+
+	type message struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Body    string `xml:",chardata"`
+	}
+
+	var parsed struct {
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Skipped  int    `xml:"skipped,attr"`
+		Time     string `xml:"time,attr"`
+		Suites   []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Skipped  int    `xml:"skipped,attr"`
+			Time     string `xml:"time,attr"`
+			Cases    []struct {
+				Name      string   `xml:"name,attr"`
+				ClassName string   `xml:"classname,attr"`
+				Time      string   `xml:"time,attr"`
+				Failure   *message `xml:"failure"`
+				Skipped   *message `xml:"skipped"`
+				SystemOut string   `xml:"system-out"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal([]byte(builder.String()), &parsed); err != nil {
+		t.Fatalf("unexpected error parsing %s: %s", builder.String(), err)
+	}
+
+	if parsed.Tests != 4 || parsed.Failures != 2 || parsed.Skipped != 1 || parsed.Time != "2.750" {
+		t.Errorf("Unexpected totals: %d tests, %d failures, %d skipped, time %s", parsed.Tests, parsed.Failures, parsed.Skipped, parsed.Time)
+	}
+
+	expectedSuites := []struct {
+		name     string
+		tests    int
+		failures int
+		skipped  int
+		time     string
+	}{
+		{name: "Build", tests: 2, failures: 1, skipped: 0, time: "2.500"},
+		{name: "Test", tests: 1, failures: 1, skipped: 0, time: "0.250"},
+		{name: "Release", tests: 1, failures: 0, skipped: 1, time: "0.000"},
+	}
+
+	if len(parsed.Suites) != len(expectedSuites) {
+		t.Fatalf("Expected %d testsuites, got %d", len(expectedSuites), len(parsed.Suites))
+	}
+
+	for idx, expected := range expectedSuites {
+		suite := parsed.Suites[idx]
+		if suite.Name != expected.name || suite.Tests != expected.tests || suite.Failures != expected.failures || suite.Skipped != expected.skipped || suite.Time != expected.time {
+			t.Errorf("Suite %d: Expected %+v, got name %s, %d tests, %d failures, %d skipped, time %s", idx, expected, suite.Name, suite.Tests, suite.Failures, suite.Skipped, suite.Time)
+		}
+	}
+
+	build := parsed.Suites[0].Cases
+	if build[0].Name != "go build" || build[0].ClassName != "Build" || build[0].Failure != nil || build[0].SystemOut != "built\n" {
+		t.Errorf("Unexpected passing testcase: %+v", build[0])
+	}
+
+	if build[1].Failure == nil || build[1].Failure.Type != "TIMEOUT" || build[1].Failure.Message != "command timed out after 1s: context deadline exceeded" {
+		t.Errorf("Expected a TIMEOUT failure, got %+v", build[1].Failure)
+	}
+
+	failing := parsed.Suites[1].Cases[0]
+	if failing.Name != results[1].Command {
+		t.Errorf("Expected testcase name %q, got %q", results[1].Command, failing.Name)
+	}
+
+	if failing.Failure == nil {
+		t.Fatalf("Expected a failure element")
+	}
+
+	if failing.Failure.Type != "FAILED" || failing.Failure.Message != results[1].Error.Error() {
+		t.Errorf("Expected FAILED failure with message %q, got %q of type %s", results[1].Error, failing.Failure.Message, failing.Failure.Type)
+	}
+
+	if failing.Failure.Body != "--- FAIL\npanic: <nil>\n" {
+		t.Errorf("Expected failure body with the captured output, got %q", failing.Failure.Body)
+	}
+
+	skipped := parsed.Suites[2].Cases[0]
+	if skipped.Skipped == nil || skipped.Skipped.Message != ErrEarlierCommandFailed.Error() {
+		t.Errorf("Expected a skipped element with the skip reason, got %+v", skipped.Skipped)
+	}
+}
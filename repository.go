@@ -1,15 +1,46 @@
 package doyoucompute
 
-import "os"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
 
 // FileRepository implements the Repository interface using the local file system
 // for loading and saving content. It provides concrete file operations for the
 // runnable documentation system.
-type FileRepository struct{}
+type FileRepository struct {
+	// writeContent writes the content of a save (nil means WriteFileContext)
+	writeContent func(ctx context.Context, writer io.Writer, content string) error
+	// noMkdirAll disables creating missing parent directories on save
+	noMkdirAll bool
+}
+
+// FileRepositoryOption configures a FileRepository created with NewFileRepository.
+type FileRepositoryOption func(f *FileRepository)
+
+// WithMkdirAll sets whether Save creates missing parent directories of the path it writes to,
+// with 0755 permissions. It is enabled by default, so a document can be rendered straight to
+// a path such as .github/ISSUE_TEMPLATE/bug_report.md in a fresh repository.
+func WithMkdirAll(enabled bool) FileRepositoryOption {
+	return func(f *FileRepository) {
+		f.noMkdirAll = !enabled
+	}
+}
 
 // NewFileRepository creates a new FileRepository instance for file system operations.
-func NewFileRepository() FileRepository {
-	return FileRepository{}
+// It takes in any number of FileRepositoryOption to configure the repository.
+func NewFileRepository(opts ...FileRepositoryOption) FileRepository {
+	repository := FileRepository{}
+	for _, opt := range opts {
+		opt(&repository)
+	}
+
+	return repository
 }
 
 // Load opens and reads the content of a file at the specified path, returning
@@ -25,16 +56,105 @@ func (f FileRepository) Load(path string) (string, error) {
 	return LoadFile(file)
 }
 
-// Save creates a new file at the specified path and writes the provided content to it.
-// If the file already exists, it will be overwritten. Returns an error if the file
-// cannot be created or written to.
+// Save writes the provided content to the file at the specified path, replacing it in one
+// step: the content is written to a temporary file in the same directory, which is then
+// renamed over the target. A failed save leaves an existing file untouched. Existing files
+// keep their permissions and new ones are created with 0644, along with any missing parent
+// directories unless disabled with WithMkdirAll. Returns an error if the file cannot be written.
 func (f FileRepository) Save(path string, content string) error {
-	file, err := os.Create(path)
+	return f.save(context.Background(), path, content)
+}
+
+// LoadContext behaves like Load but returns early with the context's error if it is
+// cancelled before the file has been read.
+func (f FileRepository) LoadContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	content, err := f.Load(path)
 	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return content, nil
+}
+
+// SaveContext behaves like Save but does not touch the file if the context has already
+// been cancelled, and stops writing with the context's error if it is cancelled mid-write,
+// leaving an existing file untouched.
+func (f FileRepository) SaveContext(ctx context.Context, path string, content string) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	defer file.Close()
+	return f.save(ctx, path, content)
+}
+
+// save writes content to a temporary file next to path and renames it over path once it has
+// been written in full. The temporary file is removed if anything fails.
+func (f FileRepository) save(ctx context.Context, path string, content string) error {
+	// Writing through a symlink replaces the file it points to rather than the link itself
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	if !f.noMkdirAll {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create parent directories of '%s': %w", path, err)
+		}
+	}
+
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	defer func() {
+		if !renamed {
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
+
+	writeContent := f.writeContent
+	if writeContent == nil {
+		writeContent = WriteFileContext
+	}
+
+	if err := writeContent(ctx, temp, content); err != nil {
+		return err
+	}
+
+	if err := temp.Chmod(perm); err != nil {
+		return err
+	}
+
+	// The content must reach the disk before the rename makes it visible
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+	renamed = true
 
-	return WriteFile(file, content)
+	return nil
 }
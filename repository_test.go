@@ -0,0 +1,216 @@
+package doyoucompute
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileRepositorySave(t *testing.T) {
+	failingWrite := func(ctx context.Context, writer io.Writer, content string) error {
+		if _, err := io.WriteString(writer, content[:len(content)/2]); err != nil {
+			return err
+		}
+
+		return errors.New("disk full")
+	}
+
+	tests := []struct {
+		name         string
+		existing     string
+		existingPerm fs.FileMode
+		writeContent func(ctx context.Context, writer io.Writer, content string) error
+		content      string
+		expected     string
+		expectedPerm fs.FileMode
+		errorMessage string
+	}{
+		{
+			name:         "Passing-NewFile",
+			content:      "# Hello\n",
+			expected:     "# Hello\n",
+			expectedPerm: 0o644,
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-ReplaceFile",
+			existing:     "# Old\n",
+			existingPerm: 0o644,
+			content:      "# New\n",
+			expected:     "# New\n",
+			expectedPerm: 0o644,
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-PreservesPermissions",
+			existing:     "# Old\n",
+			existingPerm: 0o600,
+			content:      "# New\n",
+			expected:     "# New\n",
+			expectedPerm: 0o600,
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-WriteLeavesOriginal",
+			existing:     "# Old\n",
+			existingPerm: 0o644,
+			writeContent: failingWrite,
+			content:      "# New content that never lands\n",
+			expected:     "# Old\n",
+			expectedPerm: 0o644,
+			errorMessage: "disk full",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "README.md")
+
+			if tc.existing != "" {
+				if err := os.WriteFile(path, []byte(tc.existing), tc.existingPerm); err != nil {
+					t.Fatalf("failed to write existing file: %s", err.Error())
+				}
+			}
+
+			repo := FileRepository{writeContent: tc.writeContent}
+			err := repo.Save(path, tc.content)
+
+			checkErrors(tc.errorMessage, err, t)
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read file: %s", err.Error())
+			}
+
+			if string(content) != tc.expected {
+				t.Errorf("expected content %q, got %q", tc.expected, string(content))
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat file: %s", err.Error())
+			}
+
+			if info.Mode().Perm() != tc.expectedPerm {
+				t.Errorf("expected permissions %v, got %v", tc.expectedPerm, info.Mode().Perm())
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read directory: %s", err.Error())
+			}
+
+			if len(entries) != 1 {
+				t.Errorf("expected temporary files to be cleaned up, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestFileRepositorySaveContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(path, []byte("# Old\n"), 0o644); err != nil {
+		t.Fatalf("failed to write existing file: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewFileRepository().SaveContext(ctx, path, "# New\n")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err.Error())
+	}
+
+	if string(content) != "# Old\n" {
+		t.Errorf("expected file to be left untouched, got %q", string(content))
+	}
+}
+
+func TestFileRepositorySaveParentDirs(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []FileRepositoryOption
+		blocked      bool
+		errorMessage string
+	}{
+		{
+			name:         "Passing-CreatesParentsByDefault",
+			opts:         nil,
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-CreatesParentsWhenEnabled",
+			opts:         []FileRepositoryOption{WithMkdirAll(true)},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-ParentsMissingWhenDisabled",
+			opts:         []FileRepositoryOption{WithMkdirAll(false)},
+			errorMessage: "no such file or directory",
+		},
+		{
+			name:         "Fail-ParentIsFile",
+			opts:         nil,
+			blocked:      true,
+			errorMessage: "failed to create parent directories of",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".github", "ISSUE_TEMPLATE", "bug_report.md")
+
+			if tc.blocked {
+				if err := os.WriteFile(filepath.Join(dir, ".github"), []byte("not a directory"), 0o644); err != nil {
+					t.Fatalf("failed to write blocking file: %s", err.Error())
+				}
+			}
+
+			err := NewFileRepository(tc.opts...).Save(path, "# Bug report\n")
+			if tc.errorMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errorMessage) {
+					t.Fatalf("expected error containing %q, got %v", tc.errorMessage, err)
+				}
+
+				if _, err := os.Stat(path); err == nil {
+					t.Errorf("expected file not to be created")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read file: %s", err.Error())
+			}
+
+			if string(content) != "# Bug report\n" {
+				t.Errorf("expected content %q, got %q", "# Bug report\n", string(content))
+			}
+
+			info, err := os.Stat(filepath.Dir(path))
+			if err != nil {
+				t.Fatalf("failed to stat parent directory: %s", err.Error())
+			}
+
+			if !info.IsDir() {
+				t.Errorf("expected parent to be a directory, got %v", info.Mode())
+			}
+		})
+	}
+}
@@ -3,8 +3,14 @@ package doyoucompute
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 var (
@@ -12,8 +18,11 @@ var (
 	ErrCommandNotAllowed = errors.New("command not allowed")
 	ErrDangerousCommand  = errors.New("dangerous command blocked")
 	ErrInvalidWorkingDir = errors.New("invalid working directory")
+
+	ErrInvalidCommandPattern = errors.New("invalid command pattern")
 )
 
+// dangerousCommands are blocked by DefaultSecureConfig.
 var dangerousCommands = []string{
 	"format", "fdisk", "mkfs", // Disk formatting
 	"shutdown", "reboot", "halt", // System control
@@ -24,6 +33,7 @@ var dangerousCommands = []string{
 	"dd", // prevent people from creating huge files
 }
 
+// dangerousPatterns are blocked by DefaultSecureConfig.
 var dangerousPatterns = []string{
 	"rm -rf /", "rm -fr /", // Root deletion
 	"rm -rf /*", "rm -fr /*", // Root contents
@@ -33,9 +43,186 @@ var dangerousPatterns = []string{
 	"chmod 777 /", "chmod -R 777 /", // Dangerous permissions on root
 }
 
-// ValidateCommandPlan validates that a command plan is safe to execute
+// secretEnvPatterns are fragments of environment variable names whose values are treated as secrets.
+var secretEnvPatterns = []string{
+	"SECRET", "TOKEN", "PASSWORD", "PASSWD", "CREDENTIAL",
+	"API_KEY", "APIKEY", "PRIVATE_KEY", "ACCESS_KEY",
+}
+
+// secretEnvSegments are words of environment variable names, separated by underscores, whose
+// values are treated as secrets. They only match whole words, so AUTH matches NPM_AUTH but not
+// GIT_AUTHOR_NAME or OAUTH_CALLBACK_URL.
+var secretEnvSegments = []string{"AUTH"}
+
+// RedactedValue is shown in place of secret environment values.
+const RedactedValue = "***"
+
+// IsSecretEnvVar reports whether an environment variable name matches a secret pattern,
+// such as GITHUB_TOKEN, DB_PASSWORD, or NPM_AUTH. Matching is case-insensitive.
+func IsSecretEnvVar(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, pattern := range secretEnvPatterns {
+		if strings.Contains(upper, pattern) {
+			return true
+		}
+	}
+
+	for _, segment := range strings.Split(upper, "_") {
+		if slices.Contains(secretEnvSegments, segment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactor replaces secret values and matches of redaction patterns with RedactedValue.
+type redactor struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// newRedactor builds the redactor of a command from the configuration, the variables the command
+// requires, and the values injected into it. It redacts the values of the RedactEnvVars and of
+// the variables whose names look like secrets, looked up in the injected values and then in the
+// current process, as well as matches of the RedactPatterns.
+func newRedactor(config ExecutionConfig, required []string, injected map[string]string) redactor {
+	names := slices.Clone(config.RedactEnvVars)
+	for _, name := range required {
+		if IsSecretEnvVar(name) {
+			names = append(names, name)
+		}
+	}
+	for name := range injected {
+		if IsSecretEnvVar(name) {
+			names = append(names, name)
+		}
+	}
+
+	values := make([]string, len(names))
+	for idx, name := range names {
+		value, ok := injected[name]
+		if !ok {
+			value = os.Getenv(name)
+		}
+		values[idx] = value
+	}
+
+	// Longer values go first, so a secret containing another is replaced in full
+	values = slices.DeleteFunc(values, func(value string) bool { return value == "" })
+	slices.SortFunc(values, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+
+	return redactor{values: slices.Compact(values), patterns: config.RedactPatterns}
+}
+
+// enabled reports whether the redactor has anything to redact.
+func (r redactor) enabled() bool {
+	return len(r.values) > 0 || len(r.patterns) > 0
+}
+
+// redact returns text with every secret replaced by RedactedValue.
+func (r redactor) redact(text string) string {
+	for _, value := range r.values {
+		text = strings.ReplaceAll(text, value, RedactedValue)
+	}
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllString(text, RedactedValue)
+	}
+
+	return text
+}
+
+// redactingWriter redacts what is written to it a line at a time, so secrets split across
+// writes are still caught, and writes the result to w. Flush writes the final partial line.
+// Pipeline stages share one writer for stderr, so writes are serialized.
+type redactingWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	redactor redactor
+	pending  []byte
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = append(r.pending, p...)
+
+	end := strings.LastIndexByte(string(r.pending), '\n')
+	if end == -1 {
+		return len(p), nil
+	}
+
+	lines := string(r.pending[:end+1])
+	r.pending = append(r.pending[:0], r.pending[end+1:]...)
+
+	if _, err := io.WriteString(r.w, r.redactor.redact(lines)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the redacted remainder that did not end with a newline.
+func (r *redactingWriter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(r.w, r.redactor.redact(string(r.pending)))
+	r.pending = r.pending[:0]
+
+	return err
+}
+
+// ValidateCommandPlan validates that a command plan is safe to execute.
+// Pipelines are validated stage by stage so every command in the pipe is checked.
+// Returns an error wrapping ErrInvalidCommandPattern if the regular expressions of config
+// do not compile. The decision is recorded to the AuditSink of config.
 func ValidateCommandPlan(plan CommandPlan, config ExecutionConfig) error {
-	if err := validatePlanArgs(plan, config); err != nil {
+	rules, err := compileCommandRules(config)
+	if err != nil {
+		return err
+	}
+
+	err = validateCommandPlan(plan, config, rules)
+	auditValidation(config, plan, newRedactor(config, plan.Environment, plan.EnvValues).redact(strings.Join(plan.Args, " ")), err)
+
+	return err
+}
+
+// validateCommandPlan validates a command plan with the already compiled rules of config.
+func validateCommandPlan(plan CommandPlan, config ExecutionConfig, rules commandRules) error {
+	config = plan.withOverrides(config)
+
+	if err := validateWorkingDir(plan.WorkingDir, config); err != nil {
+		return err
+	}
+
+	if len(plan.Stages) > 0 {
+		// Blocking expressions also see the whole pipeline, such as to block curl piped to sh
+		if err := rules.checkBlocked(strings.Join(plan.Args, " ")); err != nil {
+			return err
+		}
+
+		for idx, stage := range plan.Stages {
+			if err := validateCommandPlan(stage, config, rules); err != nil {
+				return fmt.Errorf("pipeline stage %d: %w", idx+1, err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := validatePlanArgs(plan, config, rules); err != nil {
 		return err
 	}
 
@@ -46,8 +233,73 @@ func ValidateCommandPlan(plan CommandPlan, config ExecutionConfig) error {
 	return nil
 }
 
+// commandRules holds the compiled BlockedPatternsRegex and AllowedCommandsRegex of a configuration.
+type commandRules struct {
+	blocked []*regexp.Regexp
+	allowed []*regexp.Regexp
+}
+
+// compileCommandRules compiles the regular expressions of config.
+func compileCommandRules(config ExecutionConfig) (commandRules, error) {
+	blocked, err := compilePatterns("BlockedPatternsRegex", config.BlockedPatternsRegex)
+	if err != nil {
+		return commandRules{}, err
+	}
+
+	allowed, err := compilePatterns("AllowedCommandsRegex", config.AllowedCommandsRegex)
+	if err != nil {
+		return commandRules{}, err
+	}
+
+	return commandRules{blocked: blocked, allowed: allowed}, nil
+}
+
+func compilePatterns(field string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for idx, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w in %s: %v", ErrInvalidCommandPattern, field, err)
+		}
+		compiled[idx] = re
+	}
+
+	return compiled, nil
+}
+
+// checkBlocked returns an error if a command matches any blocking expression.
+func (r commandRules) checkBlocked(command string) error {
+	for _, re := range r.blocked {
+		if re.MatchString(command) {
+			return ruleErr("BlockedPatternsRegex", fmt.Errorf("%w: matches '%s'", ErrDangerousCommand, re))
+		}
+	}
+
+	return nil
+}
+
+// check returns an error if a command matches a blocking expression, or if allowing expressions
+// are configured and it matches none of them. Blocking takes precedence.
+func (r commandRules) check(command string) error {
+	if err := r.checkBlocked(command); err != nil {
+		return err
+	}
+
+	if len(r.allowed) == 0 {
+		return nil
+	}
+
+	for _, re := range r.allowed {
+		if re.MatchString(command) {
+			return nil
+		}
+	}
+
+	return ruleErr("AllowedCommandsRegex", fmt.Errorf("%w: %s (allowed patterns: %v)", ErrCommandNotAllowed, command, r.allowed))
+}
+
 // validatePlanArgs checks basic plan structure and all command-related validation
-func validatePlanArgs(plan CommandPlan, config ExecutionConfig) error {
+func validatePlanArgs(plan CommandPlan, config ExecutionConfig, rules commandRules) error {
 	if len(plan.Args) == 0 {
 		return errors.New("command plan has no arguments")
 	}
@@ -68,22 +320,128 @@ func validatePlanArgs(plan CommandPlan, config ExecutionConfig) error {
 			}
 		}
 		if !allowed {
-			return fmt.Errorf("%w: %s (allowed: %v)", ErrCommandNotAllowed, baseCommand, config.AllowedCommands)
+			return ruleErr("AllowedCommands", fmt.Errorf("%w: %s (allowed: %v)", ErrCommandNotAllowed, baseCommand, config.AllowedCommands))
 		}
 	}
 
-	// Check for dangerous commands and patterns if enabled
+	if err := rules.check(strings.Join(plan.Args, " ")); err != nil {
+		return err
+	}
+
 	if config.BlockDangerousCommands {
-		for _, dangerous := range dangerousCommands {
-			if baseCommand == dangerous {
-				return fmt.Errorf("%w: %s", ErrDangerousCommand, dangerous)
+		if err := checkBlockedCommand(plan, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBlockedCommand returns an error if a command, or any stage of a pipeline, runs one of
+// the BlockedCommands or contains one of the BlockedPatterns of config, or of the defaults when
+// config sets neither. Shells run the arguments joined into a command line, so the line is
+// checked as well as the arguments themselves.
+func checkBlockedCommand(plan CommandPlan, config ExecutionConfig) error {
+	config.BlockedCommands, config.BlockedPatterns = config.blockedLists()
+
+	for _, stage := range plan.Stages {
+		if err := checkBlockedCommand(stage, config); err != nil {
+			return err
+		}
+	}
+
+	fullCommand := strings.Join(plan.Args, " ")
+
+	if err := checkDangerousCommands(plan.Args, config, 0); err != nil {
+		return err
+	}
+
+	if err := checkDangerousCommands(shellWords(fullCommand), config, 0); err != nil {
+		return err
+	}
+
+	return checkDangerousPatterns(fullCommand, config)
+}
+
+// maxPayloadDepth bounds how deeply nested shell payloads, such as bash -c "sh -c '...'",
+// are unwrapped.
+const maxPayloadDepth = 8
+
+// privilegeCommands escalate privileges wherever they appear in a command, such as in
+// find -exec sudo chown.
+var privilegeCommands = []string{"sudo", "su"}
+
+// commandWrappers run the command that follows them, after the given number of arguments
+// besides flags, such as the duration of timeout.
+var commandWrappers = map[string]int{
+	"env": 0, "nohup": 0, "exec": 0, "time": 0, "nice": 0, "xargs": 0,
+	"command": 0, "builtin": 0, "timeout": 1,
+}
+
+// shellKeywords are followed by a command, such as in if reboot; then.
+var shellKeywords = []string{"if", "then", "else", "elif", "do", "while", "until", "!"}
+
+// commandSeparators end one command and start another.
+var commandSeparators = []string{";", "&", "|", "&&", "||", "(", ")", "`", "$("}
+
+// shellPayloadFlags are the flags that make a shell run the next argument as a command line.
+var shellPayloadFlags = map[string]string{
+	"sh": "-c", "bash": "-c", "zsh": "-c", "dash": "-c", "ksh": "-c",
+	"powershell": "-Command", "pwsh": "-Command", "cmd": "/C",
+}
+
+// checkDangerousCommands returns an error if any of words runs a command blocked by config.
+// Words in command position are checked: the first, those after separators such as && and |,
+// and those run by wrappers such as env or xargs. Shell payloads, such as the argument of
+// bash -c, are split into words and checked the same way. Arguments that merely contain a
+// blocked command, such as --format, are allowed, except for blocked privilege escalation
+// commands, which are blocked anywhere.
+func checkDangerousCommands(words []string, config ExecutionConfig, depth int) error {
+	commandPosition := true
+
+	for idx := 0; idx < len(words); idx++ {
+		word := words[idx]
+		if slices.Contains(commandSeparators, word) {
+			commandPosition = true
+			continue
+		}
+
+		base := filepath.Base(word)
+		if slices.Contains(privilegeCommands, base) && slices.Contains(config.BlockedCommands, base) {
+			return ruleErr("BlockedCommands", fmt.Errorf("%w: %s", ErrDangerousCommand, base))
+		}
+
+		if !commandPosition {
+			continue
+		}
+
+		if slices.Contains(shellKeywords, word) || isEnvAssignment(word) {
+			continue
+		}
+
+		if skip, ok := commandWrappers[base]; ok {
+			for idx+1 < len(words) && (strings.HasPrefix(words[idx+1], "-") || skip > 0) {
+				if !strings.HasPrefix(words[idx+1], "-") {
+					skip--
+				}
+				idx++
 			}
+			continue
+		}
+
+		if blocked, ok := blockedCommand(base, words[idx+1:], config.BlockedCommands); ok {
+			return ruleErr("BlockedCommands", fmt.Errorf("%w: %s", ErrDangerousCommand, blocked))
 		}
 
-		fullCommand := strings.Join(plan.Args, " ")
-		for _, pattern := range dangerousPatterns {
-			if strings.Contains(fullCommand, pattern) {
-				return fmt.Errorf("%w: contains '%s'", ErrDangerousCommand, pattern)
+		commandPosition = false
+
+		if payload, ok := shellPayload(base, words[idx+1:]); ok && depth < maxPayloadDepth {
+			if err := checkDangerousCommands(shellWords(payload), config, depth+1); err != nil {
+				return err
+			}
+
+			if err := checkDangerousPatterns(payload, config); err != nil {
+				return err
 			}
 		}
 	}
@@ -91,6 +449,190 @@ func validatePlanArgs(plan CommandPlan, config ExecutionConfig) error {
 	return nil
 }
 
+// blockedCommand returns the entry of blocked matching a command and the words after it. An
+// entry of several words matches when the command's arguments, up to the next separator,
+// include the rest of the entry in order.
+func blockedCommand(command string, args []string, blocked []string) (string, bool) {
+	if end := slices.IndexFunc(args, func(arg string) bool { return slices.Contains(commandSeparators, arg) }); end != -1 {
+		args = args[:end]
+	}
+
+	for _, entry := range blocked {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 || fields[0] != command {
+			continue
+		}
+
+		rest := fields[1:]
+		for _, arg := range args {
+			if len(rest) > 0 && arg == rest[0] {
+				rest = rest[1:]
+			}
+		}
+
+		if len(rest) == 0 {
+			return entry, true
+		}
+	}
+
+	return "", false
+}
+
+// shellPayload returns the command line a shell is given to run among its arguments, such as
+// "reboot" in bash -ec reboot.
+func shellPayload(shell string, args []string) (string, bool) {
+	flag, ok := shellPayloadFlags[shell]
+	if !ok {
+		return "", false
+	}
+
+	for idx, arg := range args[:max(len(args)-1, 0)] {
+		if slices.Contains(commandSeparators, arg) {
+			break
+		}
+
+		// POSIX shells accept -c among other single-letter flags, such as -ec
+		posixFlags := flag == "-c" && strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c")
+		if strings.EqualFold(arg, flag) || posixFlags {
+			return args[idx+1], true
+		}
+	}
+
+	return "", false
+}
+
+// checkDangerousPatterns returns an error if a command line contains a pattern blocked by config,
+// either as written or with its words separated by single spaces, so quoting and extra
+// whitespace do not hide it.
+func checkDangerousPatterns(line string, config ExecutionConfig) error {
+	normalized := strings.Join(shellWords(line), " ")
+
+	for _, pattern := range config.BlockedPatterns {
+		if strings.Contains(line, pattern) || strings.Contains(normalized, pattern) {
+			return ruleErr("BlockedPatterns", fmt.Errorf("%w: contains '%s'", ErrDangerousCommand, pattern))
+		}
+	}
+
+	return nil
+}
+
+// isEnvAssignment reports whether a word assigns a variable for the command after it, such as
+// FOO=bar.
+func isEnvAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+
+	return ok && envVarName.MatchString(name)
+}
+
+// shellWords splits a command line into words the way a shell would for the purpose of finding
+// the commands it runs. Quotes are removed and backslashes escape the next character. Command
+// separators, including line breaks, become words of their own.
+func shellWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(line)
+	var quote rune
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && idx+1 < len(runes) {
+				idx++
+				word.WriteRune(runes[idx])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && idx+1 < len(runes):
+			idx++
+			word.WriteRune(runes[idx])
+			inWord = true
+		case r == '$' && idx+1 < len(runes) && runes[idx+1] == '(':
+			flush()
+			words = append(words, "$(")
+			idx++
+		case r == '\n':
+			flush()
+			words = append(words, ";")
+		case strings.ContainsRune(";&|()`", r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+
+	return words
+}
+
+// resolvePath returns the absolute path of path with any symlinks resolved.
+func resolvePath(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Abs(resolved)
+}
+
+// validateWorkingDir checks that a working directory exists and, when roots are configured,
+// that it does not escape them. Symlinks are resolved first, so a link inside a root cannot
+// point outside of it. An empty directory means the current one and is always valid.
+func validateWorkingDir(dir string, config ExecutionConfig) error {
+	if dir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidWorkingDir, dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrInvalidWorkingDir, dir)
+	}
+
+	if len(config.AllowedWorkingDirRoots) == 0 {
+		return nil // No restrictions
+	}
+
+	absDir, err := resolvePath(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidWorkingDir, dir, err)
+	}
+
+	for _, root := range config.AllowedWorkingDirRoots {
+		absRoot, err := resolvePath(root)
+		if err != nil {
+			continue // Roots that don't exist cannot contain anything
+		}
+
+		if rel, err := filepath.Rel(absRoot, absDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+
+	return ruleErr("AllowedWorkingDirRoots", fmt.Errorf("%w: %s is outside the allowed roots %v", ErrInvalidWorkingDir, dir, config.AllowedWorkingDirRoots))
+}
+
 // validateShell checks if the shell is allowed
 func validateShell(shell string, config ExecutionConfig) error {
 	if len(config.AllowedShells) == 0 {
@@ -103,5 +645,151 @@ func validateShell(shell string, config ExecutionConfig) error {
 		}
 	}
 
-	return fmt.Errorf("%w: %s (allowed: %v)", ErrShellNotAllowed, shell, config.AllowedShells)
+	return ruleErr("AllowedShells", fmt.Errorf("%w: %s (allowed: %v)", ErrShellNotAllowed, shell, config.AllowedShells))
+}
+
+// envVarName matches valid environment variable names: letters, digits, and underscores,
+// not starting with a digit.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidationIssue describes a problem with an executable found by Document.Validate.
+type ValidationIssue struct {
+	// Path is the path of the section holding the executable, such as "MyDoc > Quick Start"
+	Path string
+	// Command is the command of the executable, as it would be rendered
+	Command string
+	// Message describes the problem
+	Message string
+}
+
+// String returns the issue as "path: message (command)".
+func (v ValidationIssue) String() string {
+	if v.Command == "" {
+		return fmt.Sprintf("%s: %s", v.Path, v.Message)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", v.Path, v.Message, v.Command)
+}
+
+// Validate checks every executable and pipeline in the document without running them, so
+// mistakes show up when the document is built rather than when it is run. Each command must
+// have a shell the runner knows and a non-empty command, name its environment variables
+// with letters, digits, and underscores, and pass ValidateCommandPlan under config. Commands
+// in unresolved section references are not checked. Returns no issues if the document is valid.
+func (d Document) Validate(config ExecutionConfig) []ValidationIssue {
+	rules, err := compileCommandRules(config)
+	if err != nil {
+		return []ValidationIssue{{Path: d.Name, Message: err.Error()}}
+	}
+
+	issues := []ValidationIssue{}
+	validateNode(d, ContextPath{}, config, rules, &issues)
+
+	return issues
+}
+
+// validateNode adds the issues of the executables in node and its children to issues.
+func validateNode(node Node, path ContextPath, config ExecutionConfig, rules commandRules, issues *[]ValidationIssue) {
+	switch node.Type() {
+	case DocumentType, SectionType:
+		path = path.Push(node.(Structurer).Identifier())
+		config = sectionOverrides(node.(Structurer)).apply(config)
+		fallthrough
+	case ListType, CollapsibleType:
+		for _, child := range node.(Structurer).Children() {
+			validateNode(child, path, config, rules, issues)
+		}
+	case ExecutableType, PipelineType:
+		for _, message := range validateCommandNode(node.(Contenter), path, config, rules) {
+			issue := ValidationIssue{Path: path.String(), Message: message}
+			if content, err := node.(Contenter).Materialize(); err == nil {
+				issue.Command = content.Content
+			}
+
+			*issues = append(*issues, issue)
+		}
+	}
+}
+
+// validateCommandNode returns the problems with an executable or pipeline, without duplicates.
+// Security validation only applies once the command is well formed.
+func validateCommandNode(node Contenter, path ContextPath, config ExecutionConfig, rules commandRules) []string {
+	plans, err := NewExecutionRenderer().renderNode(node, &path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var messages []string
+	add := func(message string) {
+		if !slices.Contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+
+	for _, plan := range plans {
+		commands := []CommandPlan{plan}
+		if len(plan.Stages) > 0 {
+			commands = plan.Stages
+		}
+
+		for _, command := range commands {
+			for _, message := range validateCommandStructure(command, config) {
+				add(message)
+			}
+		}
+
+		for _, name := range planEnvNames(plan) {
+			if !envVarName.MatchString(name) {
+				add(fmt.Sprintf("invalid environment variable name '%s'", name))
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		return messages
+	}
+
+	for _, plan := range plans {
+		if err := validateCommandPlan(plan, config, rules); err != nil {
+			add(err.Error())
+		}
+	}
+
+	return messages
+}
+
+// validateCommandStructure returns the problems with the shell and command of a single command.
+func validateCommandStructure(plan CommandPlan, config ExecutionConfig) []string {
+	var messages []string
+
+	switch _, isShell := shellCommandFlags[plan.Shell]; {
+	case strings.TrimSpace(plan.Shell) == "":
+		messages = append(messages, "executable has no shell")
+	case len(config.AllowedShells) == 0 && !isShell && scriptInterpreters[plan.Shell] == "":
+		// Allowed shells are checked by ValidateCommandPlan
+		messages = append(messages, fmt.Sprintf("unknown shell '%s'", plan.Shell))
+	}
+
+	if len(plan.Args) == 0 || strings.TrimSpace(plan.Args[0]) == "" {
+		messages = append(messages, "executable has no command")
+	}
+
+	return messages
+}
+
+// planEnvNames returns the names of the environment variables a plan requires, injects, or
+// checks in its conditions.
+func planEnvNames(plan CommandPlan) []string {
+	names := slices.Clone(plan.Environment)
+	names = append(names, envNames(plan.EnvValues)...)
+	for _, stage := range plan.Stages {
+		names = append(names, envNames(stage.EnvValues)...)
+	}
+
+	for _, condition := range slices.Concat(plan.SkipIfEnv, plan.OnlyIfEnv) {
+		name, _, _ := strings.Cut(condition, "=")
+		names = append(names, name)
+	}
+
+	return names
 }
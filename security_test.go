@@ -1,6 +1,9 @@
 package doyoucompute
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestValidateCommandPlan(t *testing.T) {
 	tests := []struct {
@@ -90,6 +93,138 @@ func TestValidateCommandPlan(t *testing.T) {
 			},
 			errorMessage: "dangerous command blocked: contains 'chmod 777 /'",
 		},
+		{
+			name: "Fail-WrappedShellPayload",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"bash", "-c", "sudo rm -rf /"},
+			},
+			errorMessage: "dangerous command blocked: sudo",
+		},
+		{
+			name: "Fail-PayloadAfterSeparator",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"sh", "-c", "make install && reboot"},
+			},
+			errorMessage: "dangerous command blocked: reboot",
+		},
+		{
+			name: "Fail-CombinedShellFlags",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"bash", "-ec", "shutdown -h now"},
+			},
+			errorMessage: "dangerous command blocked: shutdown",
+		},
+		{
+			name: "Fail-NestedPayload",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"bash", "-c", "sh -c 'dd if=/dev/zero of=disk.img'"},
+			},
+			errorMessage: "dangerous command blocked: dd",
+		},
+		{
+			name: "Fail-PowerShellPayload",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"pwsh", "-Command", "systemctl stop nginx"},
+			},
+			errorMessage: "dangerous command blocked: systemctl",
+		},
+		{
+			name: "Fail-ChainedCommand",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"echo", "done", ";", "halt"},
+			},
+			errorMessage: "dangerous command blocked: halt",
+		},
+		{
+			name: "Fail-CommandSubstitution",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"echo", "$(crontab -l)"},
+			},
+			errorMessage: "dangerous command blocked: crontab",
+		},
+		{
+			name: "Fail-WrappedByEnv",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"env", "-i", "MODE=ci", "timeout", "10", "fdisk", "-l"},
+			},
+			errorMessage: "dangerous command blocked: fdisk",
+		},
+		{
+			name: "Fail-SudoAsArgument",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"find", ".", "-exec", "sudo", "chown", "root", "{}", ";"},
+			},
+			errorMessage: "dangerous command blocked: sudo",
+		},
+		{
+			name: "Fail-QuotedPatternInPayload",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"bash", "-c", "rm  -rf '/'"},
+			},
+			errorMessage: "dangerous command blocked: contains 'rm -rf /'",
+		},
+		{
+			name: "Passing-SubstringsNotBlocked",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"git", "log", "--format=%H", "&&", "echo", "formatting", "done", "at", "noon"},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Passing-SafeShellPayload",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "bash",
+				Args:  []string{"bash", "-c", "go fmt ./... && echo 'reboot not needed'"},
+			},
+			errorMessage: "",
+		},
 		{
 			name: "Fail-NotInAllowedCommands",
 			config: ExecutionConfig{
@@ -102,6 +237,53 @@ func TestValidateCommandPlan(t *testing.T) {
 			},
 			errorMessage: "command not allowed: chmod (allowed: [echo])",
 		},
+		{
+			name: "Passing-Pipeline",
+			config: ExecutionConfig{
+				AllowedCommands:        []string{"curl", "jq"},
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"curl", "example.com", "|", "jq", ".name"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"curl", "example.com"}},
+					{Shell: "sh", Args: []string{"jq", ".name"}},
+				},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "Fail-PipelineBlockedSecondStage",
+			config: ExecutionConfig{
+				BlockDangerousCommands: true,
+			},
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"echo", "yes", "|", "sudo", "tee", "/etc/hosts"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"echo", "yes"}},
+					{Shell: "sh", Args: []string{"sudo", "tee", "/etc/hosts"}},
+				},
+			},
+			errorMessage: "pipeline stage 2: dangerous command blocked: sudo",
+		},
+		{
+			name: "Fail-PipelineStageShellNotAllowed",
+			config: ExecutionConfig{
+				AllowedShells:          []string{"sh"},
+				BlockDangerousCommands: false,
+			},
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"echo", "print(1)", "|", "python3"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"echo", "print(1)"}},
+					{Shell: "python3", Args: []string{"python3"}},
+				},
+			},
+			errorMessage: "pipeline stage 2: shell not allowed: python3 (allowed: [sh])",
+		},
 	}
 
 	for _, tc := range tests {
@@ -119,3 +301,342 @@ func TestValidateCommandPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockedCommands(t *testing.T) {
+	tests := []struct {
+		name         string
+		configure    func(c *ExecutionConfig)
+		args         []string
+		errorMessage string
+	}{
+		{
+			name:         "Fail-DefaultCommand",
+			configure:    func(c *ExecutionConfig) {},
+			args:         []string{"dd", "if=/dev/zero", "of=disk.img"},
+			errorMessage: "dangerous command blocked: dd",
+		},
+		{
+			name:      "Passing-AllowedCommand",
+			configure: func(c *ExecutionConfig) { c.AllowCommand("dd") },
+			args:      []string{"dd", "if=/dev/zero", "of=disk.img"},
+		},
+		{
+			name:         "Fail-OtherDefaultsStillBlocked",
+			configure:    func(c *ExecutionConfig) { c.AllowCommand("dd") },
+			args:         []string{"reboot"},
+			errorMessage: "dangerous command blocked: reboot",
+		},
+		{
+			name:      "Passing-AllowedPrivilegeCommand",
+			configure: func(c *ExecutionConfig) { c.AllowCommand("sudo") },
+			args:      []string{"find", ".", "-exec", "sudo", "chown", "root", "{}", ";"},
+		},
+		{
+			name:         "Fail-BlockedSubcommand",
+			configure:    func(c *ExecutionConfig) { c.BlockCommand("kubectl delete", "terraform destroy") },
+			args:         []string{"kubectl", "-n", "prod", "delete", "pod", "web"},
+			errorMessage: "dangerous command blocked: kubectl delete",
+		},
+		{
+			name:         "Fail-BlockedSubcommandInPayload",
+			configure:    func(c *ExecutionConfig) { c.BlockCommand("kubectl delete", "terraform destroy") },
+			args:         []string{"bash", "-c", "terraform init && terraform destroy -auto-approve"},
+			errorMessage: "dangerous command blocked: terraform destroy",
+		},
+		{
+			name:      "Passing-OtherSubcommand",
+			configure: func(c *ExecutionConfig) { c.BlockCommand("kubectl delete") },
+			args:      []string{"kubectl", "get", "pods", "&&", "echo", "delete"},
+		},
+		{
+			name:         "Fail-BlockedPattern",
+			configure:    func(c *ExecutionConfig) { c.BlockedPatterns = append(c.BlockedPatterns, "git push --force") },
+			args:         []string{"git", "push", "--force", "origin", "main"},
+			errorMessage: "dangerous command blocked: contains 'git push --force'",
+		},
+		{
+			name: "Passing-EmptyLists",
+			configure: func(c *ExecutionConfig) {
+				c.BlockedCommands = []string{}
+				c.BlockedPatterns = []string{}
+			},
+			args: []string{"sudo", "rm", "-rf", "/"},
+		},
+		{
+			name: "Fail-NilListsUseDefaults",
+			configure: func(c *ExecutionConfig) {
+				c.BlockedCommands = nil
+				c.BlockedPatterns = nil
+			},
+			args:         []string{"sudo", "rm", "-rf", "/"},
+			errorMessage: "dangerous command blocked: sudo",
+		},
+		{
+			name: "Passing-AllowedCommandWithNilLists",
+			configure: func(c *ExecutionConfig) {
+				c.BlockedCommands = nil
+				c.BlockedPatterns = nil
+				c.AllowCommand("dd")
+			},
+			args: []string{"dd", "if=/dev/zero", "of=disk.img"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultSecureConfig()
+			tc.configure(&config)
+
+			err := ValidateCommandPlan(CommandPlan{Shell: "bash", Args: tc.args}, config)
+
+			var errMessage string
+			if err != nil {
+				errMessage = err.Error()
+			}
+
+			if errMessage != tc.errorMessage {
+				t.Errorf("Got error %s, expected %s", errMessage, tc.errorMessage)
+			}
+		})
+	}
+}
+
+func TestCommandPatternsRegex(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       ExecutionConfig
+		plan         CommandPlan
+		errorMessage string
+	}{
+		{
+			name:         "Fail-BlockedWithoutSpace",
+			config:       ExecutionConfig{BlockedPatternsRegex: []string{">\\s*/dev/sd[a-z]"}},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"cat", "disk.img", ">/dev/sda"}},
+			errorMessage: "dangerous command blocked: matches '>\\s*/dev/sd[a-z]'",
+		},
+		{
+			name: "Passing-AllowedSubcommand",
+			config: ExecutionConfig{
+				AllowedCommandsRegex: []string{"^go( |$)"},
+				BlockedPatternsRegex: []string{"^go run\\b"},
+			},
+			plan: CommandPlan{Shell: "sh", Args: []string{"go", "test", "./..."}},
+		},
+		{
+			name: "Fail-BlockWinsOverAllow",
+			config: ExecutionConfig{
+				AllowedCommandsRegex: []string{"^go( |$)"},
+				BlockedPatternsRegex: []string{"^go run\\b"},
+			},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"go", "run", "main.go"}},
+			errorMessage: "dangerous command blocked: matches '^go run\\b'",
+		},
+		{
+			name:         "Fail-NotMatchingAllowed",
+			config:       ExecutionConfig{AllowedCommandsRegex: []string{"^go( |$)"}},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"cargo", "build"}},
+			errorMessage: "command not allowed: cargo build (allowed patterns: [^go( |$)])",
+		},
+		{
+			name:   "Passing-UnanchoredMatchesAnywhere",
+			config: ExecutionConfig{AllowedCommandsRegex: []string{"go"}},
+			plan:   CommandPlan{Shell: "sh", Args: []string{"cargo", "build"}},
+		},
+		{
+			name:   "Passing-AnchoredMatchesWholeCommand",
+			config: ExecutionConfig{AllowedCommandsRegex: []string{"^make (build|test)$"}},
+			plan:   CommandPlan{Shell: "sh", Args: []string{"make", "test"}},
+		},
+		{
+			name:         "Fail-AnchoredRejectsExtraArguments",
+			config:       ExecutionConfig{AllowedCommandsRegex: []string{"^make (build|test)$"}},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"make", "test", "&&", "make", "deploy"}},
+			errorMessage: "command not allowed: make test && make deploy (allowed patterns: [^make (build|test)$])",
+		},
+		{
+			name: "Fail-NarrowsAllowedCommands",
+			config: ExecutionConfig{
+				AllowedCommands:      []string{"go", "make"},
+				AllowedCommandsRegex: []string{"^go( |$)"},
+			},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"make", "build"}},
+			errorMessage: "command not allowed: make build (allowed patterns: [^go( |$)])",
+		},
+		{
+			name:   "Fail-BlockedPipeline",
+			config: ExecutionConfig{BlockedPatternsRegex: []string{"curl .*\\| *(ba)?sh"}},
+			plan: CommandPlan{
+				Shell: "sh",
+				Args:  []string{"curl", "-s", "example.com/install.sh", "|", "sh"},
+				Stages: []CommandPlan{
+					{Shell: "sh", Args: []string{"curl", "-s", "example.com/install.sh"}},
+					{Shell: "sh", Args: []string{"sh"}},
+				},
+			},
+			errorMessage: "dangerous command blocked: matches 'curl .*\\| *(ba)?sh'",
+		},
+		{
+			name:         "Fail-InvalidPattern",
+			config:       ExecutionConfig{BlockedPatternsRegex: []string{"go (run"}},
+			plan:         CommandPlan{Shell: "sh", Args: []string{"go", "build"}},
+			errorMessage: "invalid command pattern in BlockedPatternsRegex: error parsing regexp: missing closing ): `go (run`",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCommandPlan(tc.plan, tc.config)
+
+			var errMessage string
+			if err != nil {
+				errMessage = err.Error()
+			}
+
+			if errMessage != tc.errorMessage {
+				t.Errorf("Got error %s, expected %s", errMessage, tc.errorMessage)
+			}
+		})
+	}
+}
+
+func TestDefaultBlockedLists(t *testing.T) {
+	expectedCommands := []string{
+		"format", "fdisk", "mkfs",
+		"shutdown", "reboot", "halt",
+		"sudo", "su",
+		"iptables", "ufw", "firewall-cmd",
+		"crontab", "at", "batch", "atq", "atrm",
+		"systemctl", "service", "launchctl",
+		"dd",
+	}
+	expectedPatterns := []string{
+		"rm -rf /", "rm -fr /",
+		"rm -rf /*", "rm -fr /*",
+		"> /dev/sd", "> /dev/hd", "> /dev/nvme",
+		"dd of=/dev/",
+		":(){ :|:& };:",
+		"chmod 777 /", "chmod -R 777 /",
+	}
+
+	config := DefaultSecureConfig()
+	if !reflect.DeepEqual(config.BlockedCommands, expectedCommands) {
+		t.Errorf("Expected blocked commands %v, got %v", expectedCommands, config.BlockedCommands)
+	}
+
+	if !reflect.DeepEqual(config.BlockedPatterns, expectedPatterns) {
+		t.Errorf("Expected blocked patterns %v, got %v", expectedPatterns, config.BlockedPatterns)
+	}
+
+	// Changing one configuration must not change the defaults of the next
+	config.AllowCommand("dd")
+	config.BlockedCommands[0] = "changed"
+	config.BlockedPatterns[0] = "changed"
+
+	if next := DefaultSecureConfig(); !reflect.DeepEqual(next.BlockedCommands, expectedCommands) || !reflect.DeepEqual(next.BlockedPatterns, expectedPatterns) {
+		t.Errorf("Expected defaults to be unchanged, got %v and %v", next.BlockedCommands, next.BlockedPatterns)
+	}
+}
+
+func TestIsSecretEnvVar(t *testing.T) {
+	tests := []struct {
+		name     string
+		envVar   string
+		expected bool
+	}{
+		{name: "Token", envVar: "GITHUB_TOKEN", expected: true},
+		{name: "Password", envVar: "db_password", expected: true},
+		{name: "AuthWord", envVar: "NPM_AUTH", expected: true},
+		{name: "AuthPrefix", envVar: "AUTH_HEADER", expected: true},
+		{name: "Author", envVar: "GIT_AUTHOR_NAME", expected: false},
+		{name: "AuthorAlone", envVar: "AUTHOR", expected: false},
+		{name: "OAuth", envVar: "OAUTH_CALLBACK_URL", expected: false},
+		{name: "Plain", envVar: "HOME", expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsSecretEnvVar(tc.envVar); got != tc.expected {
+				t.Errorf("Expected IsSecretEnvVar(%q) to be %t, got %t", tc.envVar, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDocumentValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		document func() Document
+		config   ExecutionConfig
+		expected []ValidationIssue
+	}{
+		{
+			name: "Passing",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{"GOOS"})
+
+				return document
+			},
+			config:   DefaultSecureConfig(),
+			expected: []ValidationIssue{},
+		},
+		{
+			name: "Fail-Issues",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				build := document.CreateSection("Build")
+				build.WriteExecutable("bash", []string{"go", "build"}, []string{})
+				build.WriteExecutable("bsh", []string{"echo", "hi"}, []string{})
+				build.WriteExecutable("bash", []string{}, []string{})
+				build.Content = append(build.Content, Executable{
+					Shell:       "bash",
+					Cmd:         []string{"make"},
+					Environment: []string{"API-KEY"},
+					EnvValues:   map[string]string{"1MODE": "ci"},
+				})
+				build.Content = append(build.Content, Executable{Shell: "bash", Cmd: []string{"make"}, Steps: [][]string{{"make", "test"}}})
+				cleanup := document.CreateSection("Deploy").CreateSection("Cleanup")
+				cleanup.WriteExecutable("sh", []string{"sudo", "rm", "-rf", "build"}, []string{})
+				cleanup.CreateList(BULLET).AppendNode(Pipeline{Stages: []Executable{
+					{Shell: "bash", Cmd: []string{"cat", "log"}},
+					{Cmd: []string{"grep", "error"}},
+				}})
+
+				return document
+			},
+			config: ExecutionConfig{BlockDangerousCommands: true, BlockedCommands: DefaultBlockedCommands()},
+			expected: []ValidationIssue{
+				{Path: "MyDoc > Build", Command: "echo hi", Message: "unknown shell 'bsh'"},
+				{Path: "MyDoc > Build", Message: "executable has no command"},
+				{Path: "MyDoc > Build", Command: "make", Message: "invalid environment variable name 'API-KEY'"},
+				{Path: "MyDoc > Build", Command: "make", Message: "invalid environment variable name '1MODE'"},
+				{Path: "MyDoc > Build", Message: "executable cannot have both Cmd and Steps"},
+				{Path: "MyDoc > Deploy > Cleanup", Command: "sudo rm -rf build", Message: "dangerous command blocked: sudo"},
+				{Path: "MyDoc > Deploy > Cleanup", Command: "cat log | grep error", Message: "executable has no shell"},
+			},
+		},
+		{
+			name: "Fail-ShellNotAllowed",
+			document: func() Document {
+				document := Document{Name: "MyDoc"}
+				document.CreateSection("Scripts").WriteExecutable("zsh", []string{"echo", "hi"}, []string{})
+
+				return document
+			},
+			config: ExecutionConfig{AllowedShells: []string{"sh", "bash"}},
+			expected: []ValidationIssue{
+				{Path: "MyDoc > Scripts", Command: "echo hi", Message: "shell not allowed: zsh (allowed: [sh bash])"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := tc.document().Validate(tc.config)
+
+			if !reflect.DeepEqual(issues, tc.expected) {
+				t.Errorf("Expected issues %v, got %v", tc.expected, issues)
+			}
+		})
+	}
+}
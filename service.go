@@ -1,11 +1,26 @@
 package doyoucompute
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// ErrHandWrittenFile is returned by RenderFile when overwrite protection is enabled
+// and the file at the output path does not carry the GeneratedMarker.
+var ErrHandWrittenFile = errors.New("refusing to overwrite file that was not generated by doyoucompute")
+
 // Repository provides abstraction for file system operations, allowing the service
 // to load and save content without being tied to specific storage implementations.
 type Repository interface {
@@ -17,6 +32,50 @@ type Repository interface {
 	Save(path string, content string) error
 }
 
+// RepositoryContext is the context-aware counterpart of Repository. Repositories backed by
+// slow or remote storage should implement it so that loads and saves can be cancelled or
+// bounded by a deadline. The Service prefers it over Repository whenever it is available.
+type RepositoryContext interface {
+	// LoadContext reads content from the specified file path, aborting if ctx is cancelled.
+	LoadContext(ctx context.Context, path string) (string, error)
+	// SaveContext writes content to the specified file path, aborting if ctx is cancelled.
+	SaveContext(ctx context.Context, path string, content string) error
+}
+
+// repositoryAdapter lets a Repository without context support be used as a RepositoryContext.
+type repositoryAdapter struct {
+	repository Repository
+}
+
+// AdaptRepository wraps a Repository so it satisfies RepositoryContext. Because the wrapped
+// repository cannot be interrupted, cancellation is only checked before each operation starts.
+// Repositories that already implement RepositoryContext are returned as-is.
+func AdaptRepository(repo Repository) RepositoryContext {
+	if repoCtx, ok := repo.(RepositoryContext); ok {
+		return repoCtx
+	}
+
+	return repositoryAdapter{repository: repo}
+}
+
+// LoadContext loads the file using the wrapped repository unless ctx is already done.
+func (r repositoryAdapter) LoadContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return r.repository.Load(path)
+}
+
+// SaveContext saves the file using the wrapped repository unless ctx is already done.
+func (r repositoryAdapter) SaveContext(ctx context.Context, path string, content string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.repository.Save(path, content)
+}
+
 // Service orchestrates the core functionality of the runnable documentation system,
 // coordinating between content rendering, script execution, and file operations.
 type Service struct {
@@ -24,6 +83,23 @@ type Service struct {
 	taskRunner        Runner
 	fileRenderer      Renderer[string]
 	executionRenderer Renderer[[]CommandPlan]
+
+	overwriteProtection bool
+	compareOptions      CompareOptions
+	hashAlgorithm       HashAlgorithm
+	skipUnchanged       bool
+	documentResolver    DocumentResolver
+	executionMode       ExecutionMode
+	concurrency         int
+	dryRun              bool
+	confirmMode         ConfirmMode
+	stdout              io.Writer
+	stderr              io.Writer
+	hooks               Hooks
+	logger              Logger
+	includeTags         []string
+	excludeTags         []string
+	diffContext         int
 }
 
 // ALL_SECTIONS is a constant used to indicate that all sections should be processed
@@ -38,6 +114,7 @@ func NewService(repo Repository, runner Runner, fileRenderer Renderer[string], e
 		taskRunner:        runner,
 		fileRenderer:      fileRenderer,
 		executionRenderer: executionRenderer,
+		diffContext:       DefaultDiffContext,
 	}
 }
 
@@ -75,15 +152,210 @@ func WithExecutionRenderer(renderer Renderer[[]CommandPlan]) OptionsServiceFunc
 	}
 }
 
+// WithOverwriteProtection makes RenderFile refuse to overwrite existing files that
+// do not contain the GeneratedMarker, since those were most likely written by hand.
+// Protection only applies when the file renderer implements Stamper.
+func WithOverwriteProtection(enabled bool) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.overwriteProtection = enabled
+
+		return nil
+	}
+}
+
+// WithExecutionMode sets whether ExecuteScript keeps running commands after one fails
+// (ContinueOnError, the default) or skips the rest of the plan (FailFast).
+func WithExecutionMode(mode ExecutionMode) OptionsServiceFunc {
+	return func(s *Service) error {
+		if mode != ContinueOnError && mode != FailFast {
+			return fmt.Errorf("invalid execution mode: %d", mode)
+		}
+
+		s.executionMode = mode
+
+		return nil
+	}
+}
+
+// WithConcurrency makes ExecuteScript run up to n top-level sections at once. Commands within
+// a section still run in order. A value of 1 runs everything sequentially, and values below 1
+// are rejected.
+func WithConcurrency(n int) OptionsServiceFunc {
+	return func(s *Service) error {
+		if n < 1 {
+			return fmt.Errorf("invalid concurrency %d (expected at least 1)", n)
+		}
+
+		s.concurrency = n
+
+		return nil
+	}
+}
+
+// WithDiffContext sets how many unchanged lines CompareFile shows around each change of the
+// diff. It defaults to DefaultDiffContext.
+func WithDiffContext(lines int) OptionsServiceFunc {
+	return func(s *Service) error {
+		if lines < 0 {
+			return fmt.Errorf("invalid diff context %d (expected at least 0)", lines)
+		}
+
+		s.diffContext = lines
+
+		return nil
+	}
+}
+
+// WithDryRun makes ExecuteScript validate commands without running them. The task runner
+// must implement DryRunner.
+func WithDryRun(enabled bool) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.dryRun = enabled
+
+		return nil
+	}
+}
+
+// WithConfirmation makes ExecuteScript ask to confirm the commands selected by mode before
+// running them. The task runner must implement ConfirmingRunner.
+func WithConfirmation(mode ConfirmMode) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.confirmMode = mode
+
+		return nil
+	}
+}
+
+// WithCommandOutput makes ExecuteScript write the output of commands to stdout and stderr
+// rather than the terminal, such as to keep stdout free for a report. The task runner must
+// implement RedirectingRunner.
+func WithCommandOutput(stdout, stderr io.Writer) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.stdout, s.stderr = stdout, stderr
+
+		return nil
+	}
+}
+
+// WithExecutionHooks sets callbacks ExecuteScript invokes before and after every command,
+// such as for emitting metrics or notifications.
+func WithExecutionHooks(hooks Hooks) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.hooks = hooks
+
+		return nil
+	}
+}
+
+// WithExecutionLogger sets the logger ExecuteScript logs warnings about the run to, such as
+// panicking hooks. Messages about individual commands go to the task runner's logger.
+func WithExecutionLogger(logger Logger) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.logger = logger
+
+		return nil
+	}
+}
+
+// WithTags limits execution plans to commands labelled with at least one of tags, either
+// directly or through the sections they are in.
+func WithTags(tags ...string) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.includeTags = tags
+
+		return nil
+	}
+}
+
+// WithSkipTags leaves commands labelled with any of tags out of execution plans, even when
+// they also carry a tag selected by WithTags.
+func WithSkipTags(tags ...string) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.excludeTags = tags
+
+		return nil
+	}
+}
+
+// WithLineEndingNormalization makes CompareFile convert CRLF line endings to LF in both the
+// rendered document and the existing file before hashing, so files checked out with
+// different line endings still match. It sets CompareOptions.NormalizeLineEndings.
+func WithLineEndingNormalization(enabled bool) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.compareOptions.NormalizeLineEndings = enabled
+
+		return nil
+	}
+}
+
+// WithHashAlgorithm sets the hash CompareFile reports for the rendered document and the
+// existing file. It defaults to HashSHA256; HashMD5 is kept for comparing with stored hashes.
+func WithHashAlgorithm(algorithm HashAlgorithm) OptionsServiceFunc {
+	return func(s *Service) error {
+		if algorithm != HashSHA256 && algorithm != HashMD5 {
+			return fmt.Errorf("invalid hash algorithm %d", int(algorithm))
+		}
+
+		s.hashAlgorithm = algorithm
+
+		return nil
+	}
+}
+
+// WithSkipUnchanged makes RenderFile, RenderSection, and RenderFormats leave files alone when
+// they already hold the rendered content, compared by the hash set with WithHashAlgorithm, so
+// rewriting an unchanged document does not touch its modification time and retrigger file watchers.
+func WithSkipUnchanged(enabled bool) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.skipUnchanged = enabled
+
+		return nil
+	}
+}
+
+// WithCompareOptions sets how CompareFile normalizes the rendered document and the existing
+// file before comparing them, replacing every earlier setting, including the one made by
+// WithLineEndingNormalization.
+func WithCompareOptions(opts CompareOptions) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.compareOptions = opts
+
+		return nil
+	}
+}
+
+// WithDocumentResolver sets the resolver used to replace SectionRef nodes with the sections
+// they reference before a document is rendered or planned.
+func WithDocumentResolver(resolver DocumentResolver) OptionsServiceFunc {
+	return func(s *Service) error {
+		s.documentResolver = resolver
+
+		return nil
+	}
+}
+
 // DefaultService creates a service instance with FileRepository,
-// MarkdownRender, ExecutionRenderer, and TaskRunner with DefaultSecureConfig
+// MarkdownRender, ExecutionRenderer, and TaskRunner with DefaultSecureConfig, logging
+// through the standard logger.
 // It takes in any number of OptionsServiceFunc to configure the options of the service
 func DefaultService(opts ...OptionsServiceFunc) (*Service, error) {
+	fileRenderer, err := NewMarkdownRenderer()
+	if err != nil {
+		return nil, err
+	}
+
+	taskRunner, err := NewTaskRunner(DefaultSecureConfig())
+	if err != nil {
+		return nil, err
+	}
+
 	svc := Service{
 		repository:        NewFileRepository(),
-		taskRunner:        NewTaskRunner(DefaultSecureConfig()),
-		fileRenderer:      NewMarkdownRenderer(),
+		taskRunner:        taskRunner,
+		fileRenderer:      fileRenderer,
 		executionRenderer: NewExecutionRenderer(),
+		logger:            log.Default(),
+		diffContext:       DefaultDiffContext,
 	}
 
 	for _, opt := range opts {
@@ -95,15 +367,410 @@ func DefaultService(opts ...OptionsServiceFunc) (*Service, error) {
 	return &svc, nil
 }
 
+// With returns a copy of the service with the provided options applied on top of
+// its current configuration. The original service is left unchanged.
+func (s Service) With(opts ...OptionsServiceFunc) (*Service, error) {
+	svc := s
+
+	for _, opt := range opts {
+		if err := opt(&svc); err != nil {
+			return nil, err
+		}
+	}
+
+	return &svc, nil
+}
+
+// resolve replaces section references in the document when a resolver is configured.
+// Without one the document is returned unchanged and any references fail when rendered.
+func (s Service) resolve(document *Document) (*Document, error) {
+	if s.documentResolver == nil {
+		return document, nil
+	}
+
+	resolved, err := ResolveSectionRefs(*document, s.documentResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
+// render produces the final file content for a document, stamping it with the
+// GeneratedMarker when the file renderer supports it.
+func (s Service) render(document *Document) (string, error) {
+	document, err := s.resolve(document)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := s.fileRenderer.Render(document)
+	if err != nil {
+		return "", err
+	}
+
+	if stamper, ok := s.fileRenderer.(Stamper); ok {
+		content = stamper.Stamp(content)
+	}
+
+	return content, nil
+}
+
+// checkOverwrite returns ErrHandWrittenFile when overwrite protection is enabled and
+// an existing file at outpath lacks the GeneratedMarker. Missing files are always safe to write.
+func (s Service) checkOverwrite(ctx context.Context, outpath string) error {
+	if !s.overwriteProtection {
+		return nil
+	}
+
+	if _, ok := s.fileRenderer.(Stamper); !ok {
+		return nil
+	}
+
+	existing, err := AdaptRepository(s.repository).LoadContext(ctx, outpath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !strings.Contains(existing, GeneratedMarker) {
+		return fmt.Errorf("%w: '%s' does not contain the generated marker and may have been edited by hand", ErrHandWrittenFile, outpath)
+	}
+
+	return nil
+}
+
 // RenderFile generates the final content for a document and saves it to the specified output path.
-// Returns an error if rendering fails or the file cannot be saved.
+// Returns an error if rendering fails or the file cannot be saved. When overwrite protection is
+// enabled, existing files without the GeneratedMarker are left untouched and ErrHandWrittenFile is returned.
+// With WithSkipUnchanged, files that already hold the rendered content are not written.
 func (s Service) RenderFile(document *Document, outpath string) error {
-	content, err := s.fileRenderer.Render(document)
+	return s.RenderFileContext(context.Background(), document, outpath)
+}
+
+// RenderFileContext is like RenderFile but passes ctx down to the repository so a slow
+// save can be cancelled or bounded by a deadline.
+func (s Service) RenderFileContext(ctx context.Context, document *Document, outpath string) error {
+	if err := s.checkOverwrite(ctx, outpath); err != nil {
+		return err
+	}
+
+	content, err := s.render(document)
+	if err != nil {
+		return err
+	}
+
+	return s.save(ctx, outpath, content)
+}
+
+// save writes rendered content to outpath, leaving the file alone if it already holds the
+// content and WithSkipUnchanged is set.
+func (s Service) save(ctx context.Context, outpath, content string) error {
+	repository := AdaptRepository(s.repository)
+	if s.skipUnchanged {
+		existing, err := repository.LoadContext(ctx, outpath)
+		if err == nil && s.algorithm().sum(existing) == s.algorithm().sum(content) {
+			return nil
+		}
+	}
+
+	return repository.SaveContext(ctx, outpath, content)
+}
+
+// RenderTarget pairs a renderer with the path its output is saved to.
+type RenderTarget struct {
+	// Renderer renders the document for this target
+	Renderer Renderer[string]
+	// Path is where the rendered content is saved
+	Path string
+}
+
+// RenderTargetResult is the outcome of rendering a document for one RenderTarget.
+type RenderTargetResult struct {
+	// Path is where the target is saved to
+	Path string
+	// Written is true if the file at Path holds the rendered content
+	Written bool
+	// Error is why the target was not written, or nil if it was
+	Error error
+}
+
+// ErrTargetNotWritten is the error of RenderFormats targets left unwritten because another
+// target failed.
+var ErrTargetNotWritten = errors.New("target not written because another target failed")
+
+// RenderFormats renders a document once per target, each with its own renderer, and saves each
+// result to its path, such as to generate README.md and docs/readme.html from one document.
+// Every target is rendered before any file is written, so a rendering failure leaves all files
+// untouched. Files are then saved in order, stopping at the first that fails. The results
+// report for every target whether its file was written; the error names the failed target.
+func (s Service) RenderFormats(document *Document, targets []RenderTarget) ([]RenderTargetResult, error) {
+	return s.RenderFormatsContext(context.Background(), document, targets)
+}
+
+// RenderFormatsContext is like RenderFormats but passes ctx down to the repository.
+func (s Service) RenderFormatsContext(ctx context.Context, document *Document, targets []RenderTarget) ([]RenderTargetResult, error) {
+	results := make([]RenderTargetResult, len(targets))
+	for idx, target := range targets {
+		results[idx] = RenderTargetResult{Path: target.Path, Error: ErrTargetNotWritten}
+	}
+
+	fail := func(idx int, err error) ([]RenderTargetResult, error) {
+		results[idx].Error = err
+
+		return results, fmt.Errorf("target %d (%s): %w", idx, targets[idx].Path, err)
+	}
+
+	contents := make([]string, len(targets))
+	for idx, target := range targets {
+		if target.Renderer == nil {
+			return fail(idx, errors.New("renderer cannot be nil"))
+		}
+
+		if target.Path == "" {
+			return fail(idx, errors.New("path cannot be empty"))
+		}
+
+		svc := s
+		svc.fileRenderer = target.Renderer
+
+		if err := svc.checkOverwrite(ctx, target.Path); err != nil {
+			return fail(idx, err)
+		}
+
+		content, err := svc.render(document)
+		if err != nil {
+			return fail(idx, err)
+		}
+		contents[idx] = content
+	}
+
+	for idx, target := range targets {
+		if err := s.save(ctx, target.Path, contents[idx]); err != nil {
+			return fail(idx, err)
+		}
+
+		results[idx].Written = true
+		results[idx].Error = nil
+	}
+
+	return results, nil
+}
+
+// RenderString renders a document and returns its content, stamped the same way as RenderFile,
+// such as to serve it over HTTP without writing a file.
+func (s Service) RenderString(document *Document) (string, error) {
+	return s.render(document)
+}
+
+// RenderToWriter renders a document and writes it to w, stamped the same way as RenderFile.
+// When the file renderer implements StreamRenderer the output is written as the document is
+// walked instead of being built in memory first.
+func (s Service) RenderToWriter(document *Document, w io.Writer) error {
+	document, err := s.resolve(document)
+	if err != nil {
+		return err
+	}
+
+	if streamer, ok := s.fileRenderer.(StreamRenderer); ok {
+		err = streamer.RenderTo(document, w)
+	} else {
+		var content string
+		if content, err = s.fileRenderer.Render(document); err == nil {
+			_, err = io.WriteString(w, content)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Stamping only appends to the rendered content, so the stamp can be written on its own
+	if stamper, ok := s.fileRenderer.(Stamper); ok {
+		if _, err := io.WriteString(w, stamper.Stamp("")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RenderSection renders a single section of a document and saves it to outpath, for snippets that
+// other documents include. Nested sections are addressed by path, such as "Quick Start/Installation".
+// The section's heading is rendered at the top level. Returns an error naming the available sections
+// if the path does not match one.
+func (s Service) RenderSection(document *Document, sectionPath string, outpath string) error {
+	return s.RenderSectionContext(context.Background(), document, sectionPath, outpath)
+}
+
+// RenderSectionContext is like RenderSection but passes ctx down to the repository so a slow
+// save can be cancelled or bounded by a deadline.
+func (s Service) RenderSectionContext(ctx context.Context, document *Document, sectionPath string, outpath string) error {
+	if err := s.checkOverwrite(ctx, outpath); err != nil {
+		return err
+	}
+
+	document, err := s.resolve(document)
+	if err != nil {
+		return err
+	}
+
+	section, err := lookupSection(*document, sectionPath)
 	if err != nil {
 		return err
 	}
 
-	return s.repository.Save(outpath, content)
+	var content string
+	if nodeRenderer, ok := s.fileRenderer.(NodeRenderer); ok {
+		content, err = nodeRenderer.RenderNode(section)
+	} else {
+		content, err = s.fileRenderer.Render(section)
+	}
+	if err != nil {
+		return err
+	}
+
+	if stamper, ok := s.fileRenderer.(Stamper); ok {
+		content = stamper.Stamp(content)
+	}
+
+	return s.save(ctx, outpath, content)
+}
+
+// HashAlgorithm is the hash function CompareFile reports the contents it compares by. Hashes
+// identify the contents; they are not used for security.
+type HashAlgorithm int
+
+const (
+	// HashSHA256 hashes with SHA-256. It is the default
+	HashSHA256 HashAlgorithm = iota + 1
+	// HashMD5 hashes with MD5, for comparing with hashes stored by earlier versions
+	HashMD5
+)
+
+// String returns the name of the algorithm: "sha256" or "md5".
+func (h HashAlgorithm) String() string {
+	switch h {
+	case HashSHA256:
+		return "sha256"
+	case HashMD5:
+		return "md5"
+	}
+
+	return fmt.Sprintf("HashAlgorithm(%d)", int(h))
+}
+
+// ParseHashAlgorithm converts "sha256" or "md5" into a HashAlgorithm.
+func ParseHashAlgorithm(value string) (HashAlgorithm, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "sha256":
+		return HashSHA256, nil
+	case "md5":
+		return HashMD5, nil
+	}
+
+	return 0, fmt.Errorf("invalid hash algorithm '%s' (expected sha256 or md5)", value)
+}
+
+// sum returns the hex encoded hash of content.
+func (h HashAlgorithm) sum(content string) string {
+	if h == HashMD5 {
+		sum := md5.Sum([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareOptions normalizes the rendered document and the existing file before CompareFile
+// compares them, so differences that do not matter are not reported as mismatches. The diff of
+// a mismatch is between the normalized contents.
+type CompareOptions struct {
+	// NormalizeLineEndings converts CRLF line endings to LF
+	NormalizeLineEndings bool
+	// TrimTrailingWhitespace removes the spaces and tabs at the end of every line
+	TrimTrailingWhitespace bool
+	// CollapseBlankLines replaces runs of blank lines, including those holding only
+	// whitespace, with a single empty line
+	CollapseBlankLines bool
+	// IgnoreFrontmatter removes the frontmatter block at the start of the content, along with
+	// the blank lines after it
+	IgnoreFrontmatter bool
+}
+
+// normalize applies the options to content.
+func (c CompareOptions) normalize(content string) string {
+	if c.NormalizeLineEndings {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	if c.IgnoreFrontmatter {
+		content = stripFrontmatter(content)
+	}
+
+	if !c.TrimTrailingWhitespace && !c.CollapseBlankLines {
+		return content
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	normalized := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		text, newline := strings.CutSuffix(line, "\n")
+		if c.TrimTrailingWhitespace {
+			// A carriage return is kept so line endings stay as they are
+			ending := ""
+			if newline {
+				ending = "\n"
+			}
+			if trimmed, ok := strings.CutSuffix(text, "\r"); ok {
+				text, ending = trimmed, "\r"+ending
+			}
+			line = strings.TrimRight(text, " \t") + ending
+		}
+
+		if c.CollapseBlankLines {
+			isBlank := newline && strings.TrimSpace(line) == ""
+			if isBlank && blank {
+				continue
+			}
+			blank = isBlank
+		}
+
+		normalized = append(normalized, line)
+	}
+
+	return strings.Join(normalized, "")
+}
+
+// stripFrontmatter removes a frontmatter block delimited by "---" lines from the start of
+// content, along with the blank lines after it. Content without one is returned unchanged.
+func stripFrontmatter(content string) string {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(first, " \t\r") != "---" {
+		return content
+	}
+
+	for rest != "" {
+		var line string
+		line, rest, _ = strings.Cut(rest, "\n")
+		if strings.TrimRight(line, " \t\r") != "---" {
+			continue
+		}
+
+		for {
+			line, after, found := strings.Cut(rest, "\n")
+			if !found || strings.TrimSpace(line) != "" {
+				return rest
+			}
+			rest = after
+		}
+	}
+
+	return content
 }
 
 // ComparisonResult contains the results of comparing a document's rendered content
@@ -111,72 +778,532 @@ func (s Service) RenderFile(document *Document, outpath string) error {
 type ComparisonResult struct {
 	// Matches indicates whether the document content matches the existing file
 	Matches bool
-	// DocumentHash is the MD5 hash of the rendered document content
+	// DocumentHash is the hash of the rendered document content, hex encoded
 	DocumentHash string
-	// FileHash is the MD5 hash of the existing file content
+	// FileHash is the hash of the existing file content, hex encoded
 	FileHash string
+	// Algorithm is the hash function of DocumentHash and FileHash
+	Algorithm HashAlgorithm
+	// Diff is the unified diff turning the file content into the rendered document, or empty
+	// if they match
+	Diff string
+}
+
+// algorithm returns the hash algorithm set with WithHashAlgorithm, or HashSHA256 if none is.
+func (s Service) algorithm() HashAlgorithm {
+	if s.hashAlgorithm == 0 {
+		return HashSHA256
+	}
+
+	return s.hashAlgorithm
 }
 
 // CompareFile renders a document and compares its content with an existing file,
-// returning detailed comparison results including MD5 hashes for verification.
+// returning detailed comparison results including content hashes for verification and,
+// when they differ, a unified diff with the context set by WithDiffContext.
 func (s Service) CompareFile(document *Document, pathToFile string) (ComparisonResult, error) {
-	content, err := s.fileRenderer.Render(document)
+	return s.CompareFileContext(context.Background(), document, pathToFile)
+}
+
+// CompareFileContext is like CompareFile but passes ctx down to the repository so a slow
+// load can be cancelled or bounded by a deadline.
+func (s Service) CompareFileContext(ctx context.Context, document *Document, pathToFile string) (ComparisonResult, error) {
+	content, err := s.render(document)
 	if err != nil {
 		return ComparisonResult{}, err
 	}
 
-	loadedContent, err := s.repository.Load(pathToFile)
+	loadedContent, err := AdaptRepository(s.repository).LoadContext(ctx, pathToFile)
 	if err != nil {
 		return ComparisonResult{}, err
 	}
 
-	expectedHash := md5.Sum([]byte(content))
-	currentHash := md5.Sum([]byte(loadedContent))
+	content = s.compareOptions.normalize(content)
+	loadedContent = s.compareOptions.normalize(loadedContent)
 
-	return ComparisonResult{
-		Matches:      expectedHash == currentHash,
-		DocumentHash: hex.EncodeToString(expectedHash[:]),
-		FileHash:     hex.EncodeToString(currentHash[:]),
-	}, nil
+	algorithm := s.algorithm()
+	result := ComparisonResult{
+		Matches:      content == loadedContent,
+		DocumentHash: algorithm.sum(content),
+		FileHash:     algorithm.sum(loadedContent),
+		Algorithm:    algorithm,
+	}
+	if !result.Matches {
+		result.Diff = UnifiedDiff(loadedContent, content, pathToFile, document.Name+" (rendered)", s.diffContext)
+	}
+
+	return result, nil
+}
+
+// ErrContentMismatch is the error CompareAll reports for files that do not match their documents.
+var ErrContentMismatch = errors.New("file does not match document")
+
+// DocumentRenderResult is the outcome of rendering a document of a DocumentSet.
+type DocumentRenderResult struct {
+	// Name is the name of the document
+	Name string
+	// Path is where the document was rendered to
+	Path string
+	// Error is why rendering failed, or nil if the file was written
+	Error error
+}
+
+// DocumentComparisonResult is the outcome of comparing a document of a DocumentSet with its file.
+type DocumentComparisonResult struct {
+	// Name is the name of the document
+	Name string
+	// Path is the file the document was compared with
+	Path string
+	// Result holds the comparison, when it could be made
+	Result ComparisonResult
+	// Error is why the comparison could not be made, or nil if it was
+	Error error
+}
+
+// RenderAll renders every document of the set to its path. A failure does not stop the others
+// from being rendered; the error joins the failures, each naming its document.
+func (s Service) RenderAll(set *DocumentSet) ([]DocumentRenderResult, error) {
+	return s.RenderAllContext(context.Background(), set)
+}
+
+// RenderAllContext is like RenderAll but passes ctx down to the repository.
+func (s Service) RenderAllContext(ctx context.Context, set *DocumentSet) ([]DocumentRenderResult, error) {
+	results := make([]DocumentRenderResult, 0, set.Len())
+	var errs []error
+
+	for _, entry := range set.Entries() {
+		result := DocumentRenderResult{Name: entry.Document.Name, Path: entry.Path}
+		result.Error = s.RenderFileContext(ctx, &entry.Document, entry.Path)
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("document '%s' (%s): %w", result.Name, result.Path, result.Error))
+		}
+
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// CompareAll compares every document of the set with its file. The error joins the files that
+// could not be compared and those that do not match, wrapping ErrContentMismatch, each naming
+// its document; it is nil when every file matches.
+func (s Service) CompareAll(set *DocumentSet) ([]DocumentComparisonResult, error) {
+	return s.CompareAllContext(context.Background(), set)
+}
+
+// CompareAllContext is like CompareAll but passes ctx down to the repository.
+func (s Service) CompareAllContext(ctx context.Context, set *DocumentSet) ([]DocumentComparisonResult, error) {
+	results := make([]DocumentComparisonResult, 0, set.Len())
+	var errs []error
+
+	for _, entry := range set.Entries() {
+		result := DocumentComparisonResult{Name: entry.Document.Name, Path: entry.Path}
+		result.Result, result.Error = s.CompareFileContext(ctx, &entry.Document, entry.Path)
+
+		switch {
+		case result.Error != nil:
+			errs = append(errs, fmt.Errorf("document '%s' (%s): %w", result.Name, result.Path, result.Error))
+		case !result.Result.Matches:
+			errs = append(errs, fmt.Errorf("document '%s' (%s): %w", result.Name, result.Path, ErrContentMismatch))
+		}
+
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
 }
 
 // PlanScriptExecution analyzes a document and creates an execution plan for all executable
 // content blocks. If sectionName is provided, only executable blocks from that section
-// are included. Use ALL_SECTIONS constant to include all sections.
+// are included. Use ALL_SECTIONS constant to include all sections. Section names match
+// regardless of case, and may also be given as the slug of their heading, such as "quick-start".
+//
+// A sectionName containing '/' is a path of nested section names starting below the document,
+// such as "Quick Start/Installation", which tells apart sections with the same name under
+// different parents. A "#N" suffix selects only the Nth command of the matching ones, counting
+// from 1, such as "Quick Start/Installation#2"; on its own, "#N" selects the Nth command of the
+// whole document.
+//
+// Commands are then filtered by the tags set with WithTags and WithSkipTags, including those
+// inherited from their sections.
 func (s Service) PlanScriptExecution(document *Document, sectionName string) ([]CommandPlan, error) {
+	document, err := s.resolve(document)
+	if err != nil {
+		return []CommandPlan{}, err
+	}
+
 	executionPlan, err := s.executionRenderer.Render(document)
 	if err != nil {
 		return []CommandPlan{}, err
 	}
 
-	if sectionName == "" {
-		return executionPlan, nil
+	executionPlan, err = filterPlans(executionPlan, sectionName)
+	if err != nil {
+		return []CommandPlan{}, err
 	}
 
-	var commands []CommandPlan
+	return filterTags(executionPlan, s.includeTags, s.excludeTags), nil
+}
 
-	for _, commandPlan := range executionPlan {
-		if commandPlan.Context.Name == sectionName {
-			commands = append(commands, commandPlan)
+// filterTags keeps the plans carrying any of the include tags, or all plans if there are
+// none, except those carrying any of the exclude tags.
+func filterTags(plans []CommandPlan, include, exclude []string) []CommandPlan {
+	if len(include) == 0 && len(exclude) == 0 {
+		return plans
+	}
+
+	filtered := []CommandPlan{}
+	for _, plan := range plans {
+		if len(include) > 0 && !slices.ContainsFunc(include, plan.HasTag) {
+			continue
+		}
+
+		if slices.ContainsFunc(exclude, plan.HasTag) {
+			continue
+		}
+
+		filtered = append(filtered, plan)
+	}
+
+	return filtered
+}
+
+// planSectionPath returns the path of the section a plan is in, below the document.
+func planSectionPath(plan CommandPlan) string {
+	if len(plan.Path) < 2 {
+		return plan.Context.Name
+	}
+
+	return strings.Join(plan.Path[1:], SectionRefSeparator)
+}
+
+// splitCommandIndex splits a "#N" suffix off a section filter, returning 0 if there is none.
+func splitCommandIndex(filter string) (string, int, error) {
+	idx := strings.LastIndex(filter, "#")
+	if idx == -1 {
+		return filter, 0, nil
+	}
+
+	index, err := strconv.Atoi(filter[idx+1:])
+	if err != nil || index < 1 {
+		return "", 0, fmt.Errorf("invalid command index '%s' in section '%s' (expected a number starting at 1)", filter[idx+1:], filter)
+	}
+
+	return filter[:idx], index, nil
+}
+
+// sectionNameMatches reports whether a section name matches a name given in a filter, ignoring
+// case. The filter may also give the name's slug, as used in heading anchors.
+func sectionNameMatches(name, filter string) bool {
+	return strings.EqualFold(name, filter) || slugify(name) == strings.ToLower(filter)
+}
+
+// planSectionMatches reports whether a plan is in the section a filter names, either by the
+// section's name or, for filters with several segments, by its path below the document.
+func planSectionMatches(plan CommandPlan, segments []string) bool {
+	if len(segments) == 1 {
+		return sectionNameMatches(plan.Context.Name, segments[0])
+	}
+
+	path := []string{plan.Context.Name}
+	if len(plan.Path) >= 2 {
+		path = plan.Path[1:]
+	}
+
+	if len(path) != len(segments) {
+		return false
+	}
+
+	for idx, segment := range segments {
+		if !sectionNameMatches(path[idx], segment) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+// suggestSections returns up to three candidates close to what was asked for, closest first.
+// A candidate is close when one starts with the other or when few edits separate them,
+// ignoring case.
+func suggestSections(asked string, candidates []string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	asked = strings.ToLower(asked)
+	threshold := max(2, len([]rune(asked))/3)
+
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := levenshtein(asked, lower)
+
+		if distance <= threshold || strings.HasPrefix(lower, asked) || strings.HasPrefix(asked, lower) {
+			suggestions = append(suggestions, suggestion{name: candidate, distance: distance})
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b suggestion) int { return a.distance - b.distance })
+
+	names := make([]string, 0, 3)
+	for _, suggestion := range suggestions[:min(len(suggestions), 3)] {
+		names = append(names, "'"+suggestion.name+"'")
+	}
+
+	return names
+}
+
+// filterPlans keeps the plans matching a section filter, as described on PlanScriptExecution.
+func filterPlans(plans []CommandPlan, filter string) ([]CommandPlan, error) {
+	if filter == ALL_SECTIONS {
+		return plans, nil
+	}
+
+	sectionName, index, err := splitCommandIndex(filter)
+	if err != nil {
+		return []CommandPlan{}, err
+	}
+
+	segments := strings.Split(sectionName, SectionRefSeparator)
+	if len(segments) > 1 {
+		for idx, segment := range segments {
+			segments[idx] = strings.TrimSpace(segment)
+		}
+		sectionName = strings.Join(segments, SectionRefSeparator)
+	}
+
+	commands := plans
+	if sectionName != ALL_SECTIONS {
+		commands = nil
+		for _, plan := range plans {
+			if planSectionMatches(plan, segments) {
+				commands = append(commands, plan)
+			}
 		}
 	}
 
 	if len(commands) == 0 {
-		return []CommandPlan{}, fmt.Errorf("no executable blocks found for section '%s'", sectionName)
+		var paths, names []string
+		for _, plan := range plans {
+			if path := planSectionPath(plan); !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+			if !slices.Contains(names, plan.Context.Name) {
+				names = append(names, plan.Context.Name)
+			}
+		}
+
+		candidates := names
+		if len(segments) > 1 {
+			candidates = paths
+		}
+
+		if suggestions := suggestSections(sectionName, candidates); len(suggestions) > 0 {
+			return []CommandPlan{}, fmt.Errorf("no executable blocks found for section '%s', did you mean %s? (valid paths: %s)", sectionName, strings.Join(suggestions, " or "), strings.Join(paths, ", "))
+		}
+
+		return []CommandPlan{}, fmt.Errorf("no executable blocks found for section '%s' (valid paths: %s)", sectionName, strings.Join(paths, ", "))
 	}
 
-	return commands, nil
+	if index == 0 {
+		return commands, nil
+	}
+
+	if index > len(commands) {
+		scope := fmt.Sprintf("section '%s'", sectionName)
+		if sectionName == ALL_SECTIONS {
+			scope = "the document"
+		}
+
+		return []CommandPlan{}, fmt.Errorf("cannot select command #%d of %s: it has %d command(s)", index, scope, len(commands))
+	}
+
+	return commands[index-1 : index], nil
 }
 
 // ExecuteScript creates an execution plan for the specified document section and runs
-// all executable blocks, returning the results of each executed command.
+// all executable blocks, returning the results of each command. With the FailFast execution
+// mode, the commands after the first failure are skipped. When concurrency is configured,
+// top-level sections run in parallel. In dry-run mode, commands are validated but not run.
 func (s Service) ExecuteScript(document *Document, sectionName string) ([]TaskResult, error) {
+	return s.ExecuteScriptContext(context.Background(), document, sectionName)
+}
+
+// ExecuteScriptContext is ExecuteScript with a context. Cancelling ctx stops the running
+// command and the commands that did not start yet are reported as SKIPPED.
+func (s Service) ExecuteScriptContext(ctx context.Context, document *Document, sectionName string) ([]TaskResult, error) {
 	executionPlan, err := s.PlanScriptExecution(document, sectionName)
 	if err != nil {
 		return []TaskResult{}, err
 	}
 
-	results := RunExecutionPlan(executionPlan, s.taskRunner)
+	runner := s.taskRunner
+	if s.dryRun {
+		dryRunner, ok := runner.(DryRunner)
+		if !ok {
+			return []TaskResult{}, errors.New("task runner does not support dry runs")
+		}
+
+		runner = dryRunner.DryRun()
+	}
+
+	if s.confirmMode != 0 {
+		confirmingRunner, ok := runner.(ConfirmingRunner)
+		if !ok {
+			return []TaskResult{}, errors.New("task runner does not support confirmation")
+		}
+
+		runner = confirmingRunner.Confirming(s.confirmMode)
+	}
+
+	if s.stdout != nil || s.stderr != nil {
+		redirectingRunner, ok := runner.(RedirectingRunner)
+		if !ok {
+			return []TaskResult{}, errors.New("task runner does not support redirecting output")
+		}
+
+		runner = redirectingRunner.WithOutput(s.stdout, s.stderr)
+	}
+
+	opts := []RunOption{WithMode(s.executionMode), WithHooks(s.hooks), WithLogger(s.logger)}
+
+	if s.concurrency > 1 {
+		return RunExecutionPlanParallelContext(ctx, executionPlan, runner, s.concurrency, opts...), nil
+	}
+
+	results := RunExecutionPlanContext(ctx, executionPlan, runner, opts...)
 
 	return results, nil
 }
+
+// CheckPrerequisites verifies that the tools required by the commands of the specified document
+// section are installed, without running anything. Commands restricted to other platforms
+// than the current one are ignored. Returns an error naming every missing tool.
+func (s Service) CheckPrerequisites(document *Document, sectionName string) error {
+	executionPlan, err := s.PlanScriptExecution(document, sectionName)
+	if err != nil {
+		return err
+	}
+
+	var tools []string
+	for _, commandPlan := range executionPlan {
+		if commandPlan.RunsOn(runtime.GOOS) {
+			tools = append(tools, commandPlan.Requires...)
+		}
+	}
+
+	return CheckTools(tools)
+}
+
+// ExecuteScriptWithReport runs the document like ExecuteScript and returns a Report of the
+// run, which can be marshaled to JSON for machine consumption.
+func (s Service) ExecuteScriptWithReport(document *Document, sectionName string) (Report, error) {
+	return s.ExecuteScriptWithReportContext(context.Background(), document, sectionName)
+}
+
+// ExecuteScriptWithReportContext is ExecuteScriptWithReport with a context, cancelled the
+// same way as ExecuteScriptContext.
+func (s Service) ExecuteScriptWithReportContext(ctx context.Context, document *Document, sectionName string) (Report, error) {
+	startedAt := time.Now()
+
+	results, err := s.ExecuteScriptContext(ctx, document, sectionName)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return Report{
+		Document:   document.Name,
+		Section:    sectionName,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Results:    results,
+	}, nil
+}
+
+// RemoteDigest describes the current content digest of a Remote that has no Pin.
+type RemoteDigest struct {
+	// SectionName identifies which document section the remote content is included in
+	SectionName string
+	// Index is the position of the remote among the document's unpinned remotes, starting at 1
+	Index int
+	// Digest is the sha256 hex digest of the remote's current content
+	Digest string
+}
+
+// RemoteDigests reads every Remote in the document that has no Pin and returns the digest
+// of its current content, so it can be pinned. Remotes are read the way Document.Stats reads
+// them, leaving the document unchanged and renderable afterwards.
+func (s Service) RemoteDigests(document *Document) ([]RemoteDigest, error) {
+	var digests []RemoteDigest
+	remotes := remoteReader{}
+
+	var collect func(nodes []Node, contextPath ContextPath) error
+	collect = func(nodes []Node, contextPath ContextPath) error {
+		for _, node := range nodes {
+			switch node.Type() {
+			case SectionType:
+				if err := collect(node.(Structurer).Children(), contextPath.Push(node.(Structurer).Identifier())); err != nil {
+					return err
+				}
+				continue
+			case RemoteType:
+				if remote, ok := nodeAs[Remote](node); !ok || remote.Pin != "" {
+					continue
+				}
+
+				content, err := remotes.read(node)
+				if err != nil {
+					return fmt.Errorf("reading remote in section '%s': %w", contextPath.CurrentSection(), err)
+				}
+
+				digests = append(digests, RemoteDigest{
+					SectionName: contextPath.CurrentSection(),
+					Index:       len(digests) + 1,
+					Digest:      ContentDigest(content),
+				})
+				continue
+			}
+
+			if structure, ok := node.(Structurer); ok {
+				if err := collect(structure.Children(), contextPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if err := collect(document.Children(), ContextPath{}.Push(document.Identifier())); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
@@ -1,10 +1,23 @@
 package doyoucompute
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 type FakeFileRepo struct {
@@ -21,7 +34,7 @@ func (f *FakeFileRepo) Load(path string) (string, error) {
 	file, ok := f.files[path]
 
 	if !ok {
-		return "", errors.New("file not found")
+		return "", fmt.Errorf("%s: %w", path, fs.ErrNotExist)
 	}
 
 	return file, nil
@@ -33,6 +46,26 @@ func (f *FakeFileRepo) Save(path string, content string) error {
 	return nil
 }
 
+// SlowFakeFileRepo implements RepositoryContext and blocks on Save until
+// either the delay has passed or the context is cancelled.
+type SlowFakeFileRepo struct {
+	*FakeFileRepo
+	delay time.Duration
+}
+
+func (f *SlowFakeFileRepo) LoadContext(ctx context.Context, path string) (string, error) {
+	return f.Load(path)
+}
+
+func (f *SlowFakeFileRepo) SaveContext(ctx context.Context, path string, content string) error {
+	select {
+	case <-time.After(f.delay):
+		return f.Save(path, content)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type MockTaskRunner struct {
 }
 
@@ -48,10 +81,12 @@ func (m MockTaskRunner) Run(plan CommandPlan) TaskResult {
 }
 
 func newService() Service {
+	fileRenderer, _ := NewMarkdownRenderer()
+
 	return NewService(
 		NewFakeFileRepo(),
 		MockTaskRunner{},
-		NewMarkdownRenderer(),
+		fileRenderer,
 		NewExecutionRenderer(),
 	)
 }
@@ -159,227 +194,2076 @@ func TestRenderFile(t *testing.T) {
 	}
 }
 
-func TestCompareFile(t *testing.T) {
+func TestRenderFileHTML(t *testing.T) {
+	repo := NewFakeFileRepo()
+
+	renderer, err := NewHTMLRenderer()
+	if err != nil {
+		t.Fatalf("unexpected error creating renderer: %s", err.Error())
+	}
+
+	svc, err := DefaultService(WithRepository(repo), WithFileRenderer(renderer))
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %s", err.Error())
+	}
+
+	document := newDocument()
+	if err := svc.RenderFile(&document, "test.html"); err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	content := repo.files["test.html"]
+	if !strings.HasPrefix(content, "<h1 id=\"mydoc\">MyDoc</h1>\n") {
+		t.Errorf("expected rendered file to start with the document heading, got %s", content)
+	}
+
+	if !strings.HasSuffix(content, "<!-- "+GeneratedMarker+" -->\n") {
+		t.Errorf("expected rendered file to end with the generated marker, got %s", content)
+	}
+
+	comparisonResult, err := svc.CompareFile(&document, "test.html")
+	if err != nil {
+		t.Fatalf("unexpected error comparing: %s", err.Error())
+	}
+
+	if !comparisonResult.Matches {
+		t.Errorf("expected comparison match, Document Hash %s, File Hash %s", comparisonResult.DocumentHash, comparisonResult.FileHash)
+	}
+}
+
+func TestRenderToWriter(t *testing.T) {
+	repo := NewFakeFileRepo()
+
+	svc, err := DefaultService(WithRepository(repo))
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %s", err.Error())
+	}
+
+	document := newDocument()
+	if err := svc.RenderFile(&document, "test.md"); err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	var builder strings.Builder
+	if err := svc.RenderToWriter(&document, &builder); err != nil {
+		t.Fatalf("unexpected error rendering to writer: %s", err.Error())
+	}
+
+	if builder.String() != repo.files["test.md"] {
+		t.Errorf("expected writer content %q to match rendered file %q", builder.String(), repo.files["test.md"])
+	}
+}
+
+func TestRenderString(t *testing.T) {
 	tests := []struct {
-		name         string
-		document     Document
-		outpath      string
-		errorMessage string
-		matches      bool
+		name   string
+		format string
 	}{
-		{
-			name:         "Passing",
-			document:     newDocument(),
-			outpath:      "test.md",
-			errorMessage: "",
-			matches:      true,
-		},
+		{name: "Passing-Markdown", format: FormatMarkdown},
+		{name: "Passing-HTML", format: FormatHTML},
+		{name: "Passing-JSON", format: FormatJSON},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			testServiceOperation(
-				t,
-				func(s *Service) (ComparisonResult, error) {
-					err := s.RenderFile(&tc.document, tc.outpath)
-					if err != nil {
-						t.Errorf("unexpected error %s rendering file", err.Error())
-					}
+			renderer, err := NewFileRenderer(tc.format, 0)
+			if err != nil {
+				t.Fatalf("unexpected error creating renderer: %s", err.Error())
+			}
 
-					return s.CompareFile(&tc.document, tc.outpath)
-				},
-				tc.errorMessage,
-				func(cr ComparisonResult, s *Service, t *testing.T) {
-					if cr.Matches != tc.matches {
-						t.Errorf("expected comparison to be %v, Document Hash %s, File Hash %s", tc.matches, cr.DocumentHash, cr.FileHash)
-					}
-				},
-			)
+			repo := NewFakeFileRepo()
+			svc, err := DefaultService(WithRepository(repo), WithFileRenderer(renderer))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			document := newDocument()
+			if err := svc.RenderFile(&document, "test.out"); err != nil {
+				t.Fatalf("unexpected error rendering: %s", err.Error())
+			}
+
+			content, err := svc.RenderString(&document)
+			if err != nil {
+				t.Fatalf("unexpected error rendering to a string: %s", err.Error())
+			}
+
+			if content != repo.files["test.out"] {
+				t.Errorf("expected string %q to match rendered file %q", content, repo.files["test.out"])
+			}
 		})
 	}
 }
 
-func TestPlanScriptExecution(t *testing.T) {
+func TestCompareFileLineEndings(t *testing.T) {
 	tests := []struct {
-		name         string
-		document     Document
-		errorMessage string
-		expected     []CommandPlan
+		name          string
+		normalization bool
+		matches       bool
 	}{
 		{
-			name:         "Passing",
-			document:     newDocument(),
-			errorMessage: "",
-			expected: []CommandPlan{
-				{
-					Shell: "bash",
-					Args:  []string{"echo", "hello", "world"},
-					Context: SectionInfo{
-						Name:  "INTRO",
-						Level: 2,
-					},
-				},
-				{
-					Shell: "bash",
-					Args:  []string{"go", "get"},
-					Context: SectionInfo{
-						Name:  "Quick Start",
-						Level: 3,
-					},
-				},
-			},
+			name:          "Passing-Normalized",
+			normalization: true,
+			matches:       true,
+		},
+		{
+			name:          "Passing-NotNormalized",
+			normalization: false,
+			matches:       false,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			testServiceOperation(
-				t,
-				func(s *Service) ([]CommandPlan, error) {
-					return s.PlanScriptExecution(&tc.document, ALL_SECTIONS)
-				},
-				tc.errorMessage,
-				func(cp []CommandPlan, s *Service, t *testing.T) {
-					for idx, expected := range tc.expected {
-						found := cp[idx]
+			repo := NewFakeFileRepo()
 
-						if found.Context != expected.Context {
-							t.Errorf("Expected context %v, got %v", expected.Context, found.Context)
-						}
+			lfService, err := DefaultService(WithRepository(repo))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
 
-						if found.Shell != expected.Shell {
-							t.Errorf("Expected context %s, got %s", expected.Shell, found.Shell)
-						}
+			document := newDocument()
+			if err := lfService.RenderFile(&document, "test.md"); err != nil {
+				t.Fatalf("unexpected error rendering: %s", err.Error())
+			}
 
-						for idx, expectedArg := range expected.Args {
-							foundArg := found.Args[idx]
+			renderer, err := NewMarkdownRenderer(WithLineEnding(LineEndingCRLF))
+			if err != nil {
+				t.Fatalf("unexpected error creating renderer: %s", err.Error())
+			}
 
-							if expectedArg != foundArg {
-								t.Errorf("expected arg %s at index %d, found %s", expectedArg, idx, foundArg)
-							}
-						}
-					}
-				},
-			)
+			crlfService, err := lfService.With(WithFileRenderer(renderer), WithLineEndingNormalization(tc.normalization))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			comparisonResult, err := crlfService.CompareFile(&document, "test.md")
+			if err != nil {
+				t.Fatalf("unexpected error comparing: %s", err.Error())
+			}
 
+			if comparisonResult.Matches != tc.matches {
+				t.Errorf("expected match %t, Document Hash %s, File Hash %s", tc.matches, comparisonResult.DocumentHash, comparisonResult.FileHash)
+			}
 		})
 	}
 }
 
-func TestExecuteScript(t *testing.T) {
+func TestRenderSection(t *testing.T) {
 	tests := []struct {
-		name              string
-		document          Document
-		taskRunnerResults []TaskResult
-		errorMessage      string
+		name         string
+		sectionPath  string
+		errorMessage string
+		expected     string
 	}{
 		{
-			name:     "Passing",
-			document: newDocument(),
-			taskRunnerResults: []TaskResult{
-				{
-					SectionName: "INTRO",
-					Command:     "echo hello world",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-				{
-					SectionName: "Quick Start",
-					Command:     "go get",
-					Status:      COMPLETED,
-					Error:       nil,
-				},
-			},
-			errorMessage: "",
+			name:        "Passing-TopLevel",
+			sectionPath: "INTRO",
+			expected:    "# INTRO\n\nThis is an introduction. And another sentence here.\n\n```bash\necho hello world\n```\n\n## Quick Start\n\nInstall dependencies\n\n```bash\ngo get\n```\n\n<!-- " + GeneratedMarker + " -->\n",
+		},
+		{
+			name:        "Passing-Nested",
+			sectionPath: "INTRO/Quick Start",
+			expected:    "# Quick Start\n\nInstall dependencies\n\n```bash\ngo get\n```\n\n<!-- " + GeneratedMarker + " -->\n",
+		},
+		{
+			name:         "Fail-MissingTopLevel",
+			sectionPath:  "Usage",
+			errorMessage: "section 'Usage' not found in document 'MyDoc' (available: INTRO)",
+		},
+		{
+			name:         "Fail-MissingNested",
+			sectionPath:  "INTRO/Installation",
+			errorMessage: "section 'INTRO/Installation' not found in document 'MyDoc' (available: Quick Start)",
+		},
+		{
+			name:         "Fail-NoSubsections",
+			sectionPath:  "INTRO/Quick Start/Linux",
+			errorMessage: "section 'INTRO/Quick Start/Linux' not found in document 'MyDoc': no sections available",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			testServiceOperation(
-				t,
-				func(s *Service) ([]TaskResult, error) {
-					return s.ExecuteScript(&tc.document, ALL_SECTIONS)
-				},
-				tc.errorMessage,
-				func(tr []TaskResult, s *Service, t *testing.T) {
-					for idx, result := range tr {
-						expected := tc.taskRunnerResults[idx]
-						if expected != result {
-							t.Errorf("Expected TaskResults %v, got %v", expected, result)
-						}
-					}
-				},
-			)
+			repo := NewFakeFileRepo()
+
+			svc, err := DefaultService(WithRepository(repo))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			document := newDocument()
+			err = svc.RenderSection(&document, tc.sectionPath, "snippet.md")
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if content := repo.files["snippet.md"]; content != tc.expected {
+				t.Errorf("expected content %q, got %q", tc.expected, content)
+			}
 		})
 	}
 }
 
-func TestDefaultService(t *testing.T) {
-	type expected struct {
-		repository        Repository
-		runner            Runner
-		fileRenderer      Renderer[string]
-		executionRenderer Renderer[[]CommandPlan]
+func TestRenderFileNotebook(t *testing.T) {
+	repo := NewFakeFileRepo()
+
+	svc, err := DefaultService(WithRepository(repo), WithFileRenderer(NewNotebookRenderer()))
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %s", err.Error())
+	}
+
+	document := newDocument()
+	if err := svc.RenderFile(&document, "tutorial.ipynb"); err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	var notebook NotebookFile
+	if err := json.Unmarshal([]byte(repo.files["tutorial.ipynb"]), &notebook); err != nil {
+		t.Fatalf("expected rendered file to be valid notebook JSON: %s", err.Error())
+	}
+
+	if len(notebook.Cells) == 0 {
+		t.Errorf("expected rendered notebook to contain cells")
 	}
+}
 
+func TestRenderFileOverwriteProtection(t *testing.T) {
 	tests := []struct {
 		name         string
-		opts         []OptionsServiceFunc
+		existing     map[string]string
+		protection   bool
+		outpath      string
 		errorMessage string
-		expected     expected
 	}{
 		{
-			name: "default",
-			opts: []OptionsServiceFunc{},
-			expected: expected{
-				repository:        FileRepository{},
-				runner:            TaskRunner{config: DefaultSecureConfig()},
-				fileRenderer:      Markdown{},
-				executionRenderer: Executioner{},
-			},
+			name:         "Passing-FreshRender",
+			existing:     map[string]string{},
+			protection:   true,
+			outpath:      "test.md",
 			errorMessage: "",
 		},
 		{
-			name: "overrides",
-			opts: []OptionsServiceFunc{
-				WithRepository(NewFakeFileRepo()),
-				WithTaskRunner(&MockRunner{}),
+			name: "Passing-RegenerateGeneratedFile",
+			existing: map[string]string{
+				"test.md": "# Old\n\n<!-- " + GeneratedMarker + " -->\n",
 			},
-			expected: expected{
-				repository:        &FakeFileRepo{},
-				runner:            &MockRunner{},
-				fileRenderer:      Markdown{},
-				executionRenderer: Executioner{},
+			protection:   true,
+			outpath:      "test.md",
+			errorMessage: "",
+		},
+		{
+			name: "Passing-HandWrittenFileWithoutProtection",
+			existing: map[string]string{
+				"test.md": "# My lovingly hand-written notes\n",
 			},
+			protection:   false,
+			outpath:      "test.md",
 			errorMessage: "",
 		},
+		{
+			name: "Fail-HandWrittenFile",
+			existing: map[string]string{
+				"test.md": "# My lovingly hand-written notes\n",
+			},
+			protection:   true,
+			outpath:      "test.md",
+			errorMessage: "refusing to overwrite file that was not generated by doyoucompute: 'test.md' does not contain the generated marker and may have been edited by hand",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			svc, err := DefaultService(tc.opts...)
-
-			checkErrors(tc.errorMessage, err, t)
-			if tc.errorMessage != "" {
-				return
+			repo := NewFakeFileRepo()
+			for path, content := range tc.existing {
+				repo.files[path] = content
 			}
 
-			if reflect.TypeOf(svc.repository) != reflect.TypeOf(tc.expected.repository) {
-				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.repository), reflect.TypeOf(svc.repository))
+			svc, err := DefaultService(WithRepository(repo), WithOverwriteProtection(tc.protection))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
 			}
 
-			if reflect.TypeOf(svc.taskRunner) != reflect.TypeOf(tc.expected.runner) {
-				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.runner), reflect.TypeOf(svc.taskRunner))
+			document := newDocument()
+			err = svc.RenderFile(&document, tc.outpath)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				if !errors.Is(err, ErrHandWrittenFile) {
+					t.Errorf("expected error to wrap ErrHandWrittenFile, got %v", err)
+				}
+
+				if repo.files[tc.outpath] != tc.existing[tc.outpath] {
+					t.Errorf("expected hand-written file to be left untouched, got %s", repo.files[tc.outpath])
+				}
+				return
 			}
 
-			if reflect.TypeOf(svc.fileRenderer) != reflect.TypeOf(tc.expected.fileRenderer) {
-				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.fileRenderer), reflect.TypeOf(svc.fileRenderer))
+			if !strings.Contains(repo.files[tc.outpath], GeneratedMarker) {
+				t.Errorf("expected rendered file to contain the generated marker, got %s", repo.files[tc.outpath])
 			}
+		})
+	}
+}
 
-			if reflect.TypeOf(svc.executionRenderer) != reflect.TypeOf(tc.expected.executionRenderer) {
+func TestRenderFileContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		repo         func() (Repository, *FakeFileRepo)
+		ctx          func() (context.Context, context.CancelFunc)
+		errorMessage string
+		saved        bool
+	}{
+		{
+			name: "Passing-ContextRepository",
+			repo: func() (Repository, *FakeFileRepo) {
+				files := NewFakeFileRepo()
+				return &SlowFakeFileRepo{FakeFileRepo: files, delay: time.Millisecond}, files
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Second)
+			},
+			errorMessage: "",
+			saved:        true,
+		},
+		{
+			name: "Fail-CancelledMidSave",
+			repo: func() (Repository, *FakeFileRepo) {
+				files := NewFakeFileRepo()
+				return &SlowFakeFileRepo{FakeFileRepo: files, delay: 5 * time.Second}, files
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			errorMessage: "context deadline exceeded",
+			saved:        false,
+		},
+		{
+			name: "Passing-LegacyRepositoryAdapter",
+			repo: func() (Repository, *FakeFileRepo) {
+				files := NewFakeFileRepo()
+				return files, files
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			errorMessage: "",
+			saved:        true,
+		},
+		{
+			name: "Fail-LegacyRepositoryAdapterCancelled",
+			repo: func() (Repository, *FakeFileRepo) {
+				files := NewFakeFileRepo()
+				return files, files
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			errorMessage: "context canceled",
+			saved:        false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, files := tc.repo()
+			svc, err := DefaultService(WithRepository(repo))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+
+			document := newDocument()
+			err = svc.RenderFileContext(ctx, &document, "test.md")
+
+			checkErrors(tc.errorMessage, err, t)
+
+			if _, ok := files.files["test.md"]; ok != tc.saved {
+				t.Errorf("expected saved to be %v, got %v", tc.saved, ok)
+			}
+		})
+	}
+}
+
+func TestRemoteDigests(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     Document
+		errorMessage string
+		expected     []RemoteDigest
+	}{
+		{
+			name: "Passing",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					Remote{Reader: strings.NewReader("doot")},
+					Section{
+						Name: "INTRO",
+						Content: []Node{
+							Remote{Reader: strings.NewReader("pinned"), Pin: ContentDigest("pinned")},
+							&Section{
+								Name: "Nested",
+								Content: []Node{
+									Remote{Reader: strings.NewReader("doot doot")},
+								},
+							},
+						},
+					},
+				},
+			},
+			errorMessage: "",
+			expected: []RemoteDigest{
+				{SectionName: "MyDoc", Index: 1, Digest: "690e8ea126c616b60d6572705492457fcd9c1105e597afba9a5153c6f44f2d62"},
+				{SectionName: "Nested", Index: 2, Digest: "bdc8546a591f21ec79ba8902dd89d547112efda842b30983d58e7a2749c0778c"},
+			},
+		},
+		{
+			name: "Passing-AllPinned",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					Remote{Reader: strings.NewReader("pinned"), Pin: ContentDigest("pinned")},
+				},
+			},
+			errorMessage: "",
+			expected:     nil,
+		},
+		{
+			name: "Passing-RemotePointer",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					&Remote{Reader: strings.NewReader("doot")},
+				},
+			},
+			errorMessage: "",
+			expected: []RemoteDigest{
+				{SectionName: "MyDoc", Index: 1, Digest: "690e8ea126c616b60d6572705492457fcd9c1105e597afba9a5153c6f44f2d62"},
+			},
+		},
+		{
+			name: "Fail-UnreadableRemote",
+			document: Document{
+				Name: "MyDoc",
+				Content: []Node{
+					Section{
+						Name: "INTRO",
+						Content: []Node{
+							Remote{Reader: iotest.ErrReader(errors.New("connection reset"))},
+						},
+					},
+				},
+			},
+			errorMessage: "reading remote in section 'INTRO': connection reset",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]RemoteDigest, error) {
+					return s.RemoteDigests(&tc.document)
+				},
+				tc.errorMessage,
+				func(digests []RemoteDigest, s *Service, t *testing.T) {
+					if !reflect.DeepEqual(digests, tc.expected) {
+						t.Errorf("Expected digests %v, got %v", tc.expected, digests)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestRemoteDigestsKeepsContent(t *testing.T) {
+	document := Document{
+		Name: "MyDoc",
+		Content: []Node{
+			Section{
+				Name: "INTRO",
+				Content: []Node{
+					Remote{Reader: strings.NewReader("doot")},
+					&Remote{Reader: strings.NewReader("doot doot")},
+				},
+			},
+		},
+	}
+
+	svc := NewService(NewFakeFileRepo(), &MockRunner{}, Markdown{}, NewExecutionRenderer())
+	if _, err := svc.RemoteDigests(&document); err != nil {
+		t.Fatalf("Failed to compute digests: %v", err)
+	}
+
+	content, err := Markdown{}.Render(&document)
+	if err != nil {
+		t.Fatalf("Failed to render document: %v", err)
+	}
+
+	if !strings.Contains(content, "doot\n") || !strings.Contains(content, "doot doot") {
+		t.Errorf("Expected the remote content to be rendered after computing digests, got %q", content)
+	}
+}
+
+func TestCompareFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     Document
+		outpath      string
+		edit         func(content string) string
+		errorMessage string
+		matches      bool
+		diff         string
+	}{
+		{
+			name:         "Passing",
+			document:     newDocument(),
+			outpath:      "test.md",
+			errorMessage: "",
+			matches:      true,
+		},
+		{
+			name:     "Passing-OneLineChanged",
+			document: newDocument(),
+			outpath:  "test.md",
+			edit: func(content string) string {
+				return strings.Replace(content, "Install dependencies", "Install everything", 1)
+			},
+			matches: false,
+			diff: `--- test.md
++++ MyDoc (rendered)
+@@ -10,7 +10,7 @@
+ 
+ ### Quick Start
+ 
+-Install everything
++Install dependencies
+ 
+ ` + "```bash" + `
+ go get
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) (ComparisonResult, error) {
+					err := s.RenderFile(&tc.document, tc.outpath)
+					if err != nil {
+						t.Errorf("unexpected error %s rendering file", err.Error())
+					}
+
+					if tc.edit != nil {
+						content, _ := s.repository.Load(tc.outpath)
+						s.repository.Save(tc.outpath, tc.edit(content))
+					}
+
+					return s.CompareFile(&tc.document, tc.outpath)
+				},
+				tc.errorMessage,
+				func(cr ComparisonResult, s *Service, t *testing.T) {
+					if cr.Matches != tc.matches {
+						t.Errorf("expected comparison to be %v, Document Hash %s, File Hash %s", tc.matches, cr.DocumentHash, cr.FileHash)
+					}
+
+					if cr.Diff != tc.diff {
+						t.Errorf("expected diff\n%s\ngot\n%s", tc.diff, cr.Diff)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestCompareFileOptions(t *testing.T) {
+	trailingWhitespace := func(content string) string { return strings.ReplaceAll(content, "\n", " \t\n") }
+	extraBlankLines := func(content string) string { return strings.ReplaceAll(content, "\n\n", "\n\n  \n\n") }
+	crlf := func(content string) string { return strings.ReplaceAll(content, "\n", "\r\n") }
+	reorderedFrontmatter := func(content string) string {
+		_, body, _ := strings.Cut(strings.TrimPrefix(content, "---\n"), "---\n")
+		return "---\ntitle: Contributing\nowner: docs\n---\n" + body
+	}
+
+	tests := []struct {
+		name    string
+		edits   []func(string) string
+		options CompareOptions
+		matches bool
+	}{
+		{name: "Passing-TrailingWhitespaceMismatch", edits: []func(string) string{trailingWhitespace}},
+		{name: "Passing-TrailingWhitespace", edits: []func(string) string{trailingWhitespace}, options: CompareOptions{TrimTrailingWhitespace: true}, matches: true},
+		{name: "Passing-BlankLinesMismatch", edits: []func(string) string{extraBlankLines}},
+		{name: "Passing-BlankLines", edits: []func(string) string{extraBlankLines}, options: CompareOptions{CollapseBlankLines: true}, matches: true},
+		{name: "Passing-LineEndingsMismatch", edits: []func(string) string{crlf}},
+		{name: "Passing-LineEndings", edits: []func(string) string{crlf}, options: CompareOptions{NormalizeLineEndings: true}, matches: true},
+		{name: "Passing-FrontmatterMismatch", edits: []func(string) string{reorderedFrontmatter}},
+		{name: "Passing-Frontmatter", edits: []func(string) string{reorderedFrontmatter}, options: CompareOptions{IgnoreFrontmatter: true}, matches: true},
+		{
+			name:    "Passing-CombinedNeedsEveryOption",
+			edits:   []func(string) string{reorderedFrontmatter, extraBlankLines, trailingWhitespace, crlf},
+			options: CompareOptions{TrimTrailingWhitespace: true, CollapseBlankLines: true, IgnoreFrontmatter: true},
+		},
+		{
+			name:    "Passing-Combined",
+			edits:   []func(string) string{reorderedFrontmatter, extraBlankLines, trailingWhitespace, crlf},
+			options: CompareOptions{NormalizeLineEndings: true, TrimTrailingWhitespace: true, CollapseBlankLines: true, IgnoreFrontmatter: true},
+			matches: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newDocument()
+			document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"title": "Contributing", "owner": "docs"}})
+
+			testServiceOperation(
+				t,
+				func(s *Service) (ComparisonResult, error) {
+					if err := s.RenderFile(&document, "test.md"); err != nil {
+						t.Fatalf("unexpected error %s rendering file", err.Error())
+					}
+
+					content, _ := s.repository.Load("test.md")
+					for _, edit := range tc.edits {
+						content = edit(content)
+					}
+					s.repository.Save("test.md", content)
+
+					svc, err := s.With(WithCompareOptions(tc.options))
+					if err != nil {
+						return ComparisonResult{}, err
+					}
+
+					return svc.CompareFile(&document, "test.md")
+				},
+				"",
+				func(cr ComparisonResult, s *Service, t *testing.T) {
+					if cr.Matches != tc.matches {
+						t.Errorf("expected comparison to be %v, got diff\n%s", tc.matches, cr.Diff)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestCompareFileHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name         string
+		algorithm    HashAlgorithm
+		edit         bool
+		errorMessage string
+		expected     HashAlgorithm
+		hash         func(content string) string
+		matches      bool
+	}{
+		{
+			name:     "Passing-DefaultSHA256",
+			expected: HashSHA256,
+			hash: func(content string) string {
+				sum := sha256.Sum256([]byte(content))
+				return hex.EncodeToString(sum[:])
+			},
+			matches: true,
+		},
+		{
+			name:      "Passing-MD5",
+			algorithm: HashMD5,
+			expected:  HashMD5,
+			hash: func(content string) string {
+				sum := md5.Sum([]byte(content))
+				return hex.EncodeToString(sum[:])
+			},
+			matches: true,
+		},
+		{
+			name:      "Passing-SHA256Mismatch",
+			algorithm: HashSHA256,
+			edit:      true,
+			expected:  HashSHA256,
+			hash: func(content string) string {
+				sum := sha256.Sum256([]byte(content))
+				return hex.EncodeToString(sum[:])
+			},
+		},
+		{
+			name:      "Passing-MD5Mismatch",
+			algorithm: HashMD5,
+			edit:      true,
+			expected:  HashMD5,
+			hash: func(content string) string {
+				sum := md5.Sum([]byte(content))
+				return hex.EncodeToString(sum[:])
+			},
+		},
+		{
+			name:         "Fail-UnknownAlgorithm",
+			algorithm:    HashAlgorithm(9),
+			errorMessage: "invalid hash algorithm 9",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newDocument()
+			var fileContent string
+
+			testServiceOperation(
+				t,
+				func(s *Service) (ComparisonResult, error) {
+					if err := s.RenderFile(&document, "test.md"); err != nil {
+						t.Fatalf("unexpected error %s rendering file", err.Error())
+					}
+
+					fileContent, _ = s.repository.Load("test.md")
+					if tc.edit {
+						fileContent += "Edited by hand\n"
+						s.repository.Save("test.md", fileContent)
+					}
+
+					svc := s
+					if tc.algorithm != 0 {
+						var err error
+						if svc, err = s.With(WithHashAlgorithm(tc.algorithm)); err != nil {
+							return ComparisonResult{}, err
+						}
+					}
+
+					return svc.CompareFile(&document, "test.md")
+				},
+				tc.errorMessage,
+				func(cr ComparisonResult, s *Service, t *testing.T) {
+					if cr.Matches != tc.matches {
+						t.Errorf("expected comparison to be %v", tc.matches)
+					}
+
+					if cr.Algorithm != tc.expected {
+						t.Errorf("expected algorithm %s, got %s", tc.expected, cr.Algorithm)
+					}
+
+					if expected := tc.hash(fileContent); cr.FileHash != expected {
+						t.Errorf("expected file hash %s, got %s", expected, cr.FileHash)
+					}
+
+					if (cr.DocumentHash == cr.FileHash) != tc.matches {
+						t.Errorf("expected hashes to match %v, got %s and %s", tc.matches, cr.DocumentHash, cr.FileHash)
+					}
+				},
+			)
+		})
+	}
+}
+
+func newTestDocumentSet(t *testing.T) *DocumentSet {
+	t.Helper()
+
+	other := newDocument()
+	other.Name = "Other"
+	third := newDocument()
+	third.Name = "Third"
+
+	set, err := NewDocumentSet([]Document{newDocument(), other, third}, []string{"mydoc.md", "other.md", "third.md"})
+	if err != nil {
+		t.Fatalf("unexpected error creating document set: %s", err.Error())
+	}
+
+	return set
+}
+
+func TestRenderAll(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     map[string]string
+		errorMessage string
+		failed       []string
+	}{
+		{
+			name: "Passing",
+		},
+		{
+			name:     "Passing-AggregatesFailures",
+			existing: map[string]string{"other.md": "# Written by hand\n", "third.md": "# Also by hand\n"},
+			errorMessage: "document 'Other' (other.md): " + ErrHandWrittenFile.Error() + ": 'other.md' does not contain the generated marker and may have been edited by hand\n" +
+				"document 'Third' (third.md): " + ErrHandWrittenFile.Error() + ": 'third.md' does not contain the generated marker and may have been edited by hand",
+			failed: []string{"Other", "Third"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewFakeFileRepo()
+			for path, content := range tc.existing {
+				repo.Save(path, content)
+			}
+
+			svc := NewService(repo, &MockRunner{}, Markdown{}, NewExecutionRenderer())
+			protected, err := svc.With(WithOverwriteProtection(true))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			results, err := protected.RenderAll(newTestDocumentSet(t))
+			checkErrors(tc.errorMessage, err, t)
+
+			if len(results) != 3 {
+				t.Fatalf("expected 3 results, got %d", len(results))
+			}
+
+			for _, result := range results {
+				failed := slices.Contains(tc.failed, result.Name)
+				if failed != (result.Error != nil) {
+					t.Errorf("expected %s to fail %v, got %v", result.Name, failed, result.Error)
+				}
+
+				if _, err := repo.Load(result.Path); err != nil {
+					t.Errorf("expected %s to exist: %v", result.Path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareAll(t *testing.T) {
+	set := newTestDocumentSet(t)
+	repo := NewFakeFileRepo()
+	svc := NewService(repo, &MockRunner{}, Markdown{}, NewExecutionRenderer())
+
+	if _, err := svc.RenderAll(set); err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	// MyDoc matches, Other was edited, and Third was never written
+	repo.Save("other.md", "# Edited by hand\n")
+	delete(repo.files, "third.md")
+
+	results, err := svc.CompareAll(set)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !errors.Is(err, ErrContentMismatch) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected error to wrap ErrContentMismatch and fs.ErrNotExist, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "document 'Other' (other.md)") || !strings.Contains(err.Error(), "document 'Third' (third.md)") || strings.Contains(err.Error(), "MyDoc") {
+		t.Errorf("expected error to name Other and Third only, got %v", err)
+	}
+
+	expected := []struct {
+		name    string
+		matches bool
+		failed  bool
+	}{
+		{name: "MyDoc", matches: true},
+		{name: "Other"},
+		{name: "Third", failed: true},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+
+	for idx, result := range results {
+		if result.Name != expected[idx].name || result.Result.Matches != expected[idx].matches || (result.Error != nil) != expected[idx].failed {
+			t.Errorf("expected %+v, got %s matching %v with error %v", expected[idx], result.Name, result.Result.Matches, result.Error)
+		}
+	}
+
+	if result := results[1]; result.Result.Diff == "" {
+		t.Error("expected a diff for the mismatched document")
+	}
+}
+
+func TestPlanScriptExecution(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     Document
+		errorMessage string
+		expected     []CommandPlan
+	}{
+		{
+			name:         "Passing",
+			document:     newDocument(),
+			errorMessage: "",
+			expected: []CommandPlan{
+				{
+					Shell: "bash",
+					Args:  []string{"echo", "hello", "world"},
+					Context: SectionInfo{
+						Name:  "INTRO",
+						Level: 2,
+					},
+				},
+				{
+					Shell: "bash",
+					Args:  []string{"go", "get"},
+					Context: SectionInfo{
+						Name:  "Quick Start",
+						Level: 3,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]CommandPlan, error) {
+					return s.PlanScriptExecution(&tc.document, ALL_SECTIONS)
+				},
+				tc.errorMessage,
+				func(cp []CommandPlan, s *Service, t *testing.T) {
+					for idx, expected := range tc.expected {
+						found := cp[idx]
+
+						if found.Context != expected.Context {
+							t.Errorf("Expected context %v, got %v", expected.Context, found.Context)
+						}
+
+						if found.Shell != expected.Shell {
+							t.Errorf("Expected context %s, got %s", expected.Shell, found.Shell)
+						}
+
+						for idx, expectedArg := range expected.Args {
+							foundArg := found.Args[idx]
+
+							if expectedArg != foundArg {
+								t.Errorf("expected arg %s at index %d, found %s", expectedArg, idx, foundArg)
+							}
+						}
+					}
+				},
+			)
+
+		})
+	}
+}
+
+func TestPlanScriptExecutionFilters(t *testing.T) {
+	document := Document{Name: "Monorepo"}
+	backend := document.CreateSection("Backend")
+	backend.CreateSection("Setup").WriteExecutable("bash", []string{"go", "mod", "download"}, []string{})
+	backend.WriteExecutable("bash", []string{"go", "build"}, []string{})
+	frontend := document.CreateSection("Frontend")
+	frontendSetup := frontend.CreateSection("Setup")
+	frontendSetup.WriteExecutable("bash", []string{"npm", "ci"}, []string{})
+	frontendSetup.WriteExecutable("bash", []string{"npm", "run", "build"}, []string{})
+
+	tests := []struct {
+		name         string
+		section      string
+		errorMessage string
+		expected     []string
+	}{
+		{
+			name:     "Passing-SectionName",
+			section:  "Setup",
+			expected: []string{"go mod download", "npm ci", "npm run build"},
+		},
+		{
+			name:     "Passing-Path",
+			section:  "Frontend/Setup",
+			expected: []string{"npm ci", "npm run build"},
+		},
+		{
+			name:     "Passing-PathWithSpaces",
+			section:  "Backend / Setup",
+			expected: []string{"go mod download"},
+		},
+		{
+			name:     "Passing-ParentExcludesNested",
+			section:  "Backend",
+			expected: []string{"go build"},
+		},
+		{
+			name:     "Passing-PathWithIndex",
+			section:  "Frontend/Setup#2",
+			expected: []string{"npm run build"},
+		},
+		{
+			name:     "Passing-DocumentIndex",
+			section:  "#2",
+			expected: []string{"go build"},
+		},
+		{
+			name:         "Fail-TrailingSeparator",
+			section:      "Backend/",
+			errorMessage: "no executable blocks found for section 'Backend/', did you mean 'Backend' or 'Backend/Setup'? (valid paths: Backend/Setup, Backend, Frontend/Setup)",
+		},
+		{
+			name:         "Fail-UnknownPath",
+			section:      "Docs/Setup",
+			errorMessage: "no executable blocks found for section 'Docs/Setup' (valid paths: Backend/Setup, Backend, Frontend/Setup)",
+		},
+		{
+			name:         "Fail-IndexOutOfRange",
+			section:      "Backend/Setup#2",
+			errorMessage: "cannot select command #2 of section 'Backend/Setup': it has 1 command(s)",
+		},
+		{
+			name:         "Fail-InvalidIndex",
+			section:      "Setup#first",
+			errorMessage: "invalid command index 'first' in section 'Setup#first' (expected a number starting at 1)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]CommandPlan, error) {
+					return s.PlanScriptExecution(&document, tc.section)
+				},
+				tc.errorMessage,
+				func(cp []CommandPlan, s *Service, t *testing.T) {
+					var commands []string
+					for _, plan := range cp {
+						commands = append(commands, strings.Join(plan.Args, " "))
+					}
+
+					if !reflect.DeepEqual(commands, tc.expected) {
+						t.Errorf("Expected commands %v, got %v", tc.expected, commands)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestPlanScriptExecutionTags(t *testing.T) {
+	document := Document{Name: "Project"}
+	setup := document.CreateSection("Setup")
+	setup.AddTags("setup")
+	setup.WriteExecutable("bash", []string{"go", "mod", "download"}, []string{})
+	setup.Content = append(setup.Content, Executable{Shell: "bash", Cmd: []string{"make", "lint"}, Tags: []string{"ci-only"}})
+	cleanup := document.CreateSection("Cleanup")
+	cleanup.AddTags("destructive")
+	cleanup.WriteExecutable("bash", []string{"rm", "-rf", "build"}, []string{})
+	database := cleanup.CreateSection("Database")
+	database.AddTags("ci-only")
+	database.WriteExecutable("bash", []string{"dropdb", "test"}, []string{})
+	document.CreateSection("Test").Content = []Node{Executable{Shell: "bash", Cmd: []string{"go", "test", "./..."}, Tags: []string{"ci-only"}}}
+
+	tests := []struct {
+		name     string
+		section  string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "Passing-NoTags",
+			expected: []string{"go mod download", "make lint", "rm -rf build", "dropdb test", "go test ./..."},
+		},
+		{
+			name:     "Passing-IncludeInherited",
+			include:  []string{"setup"},
+			expected: []string{"go mod download", "make lint"},
+		},
+		{
+			name:     "Passing-IncludeAny",
+			include:  []string{"ci-only", "destructive"},
+			expected: []string{"make lint", "rm -rf build", "dropdb test", "go test ./..."},
+		},
+		{
+			name:     "Passing-ExcludeInherited",
+			exclude:  []string{"destructive"},
+			expected: []string{"go mod download", "make lint", "go test ./..."},
+		},
+		{
+			name:     "Passing-ExcludeWinsOverInclude",
+			include:  []string{"ci-only"},
+			exclude:  []string{"destructive"},
+			expected: []string{"make lint", "go test ./..."},
+		},
+		{
+			name:     "Passing-WithSection",
+			section:  "Setup",
+			include:  []string{"ci-only"},
+			expected: []string{"make lint"},
+		},
+		{
+			name:    "Passing-NoMatches",
+			include: []string{"release"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]CommandPlan, error) {
+					svc, err := s.With(WithTags(tc.include...), WithSkipTags(tc.exclude...))
+					if err != nil {
+						return nil, err
+					}
+
+					return svc.PlanScriptExecution(&document, tc.section)
+				},
+				"",
+				func(cp []CommandPlan, s *Service, t *testing.T) {
+					var commands []string
+					for _, plan := range cp {
+						commands = append(commands, strings.Join(plan.Args, " "))
+					}
+
+					if !reflect.DeepEqual(commands, tc.expected) {
+						t.Errorf("Expected commands %v, got %v", tc.expected, commands)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestPlanScriptExecutionSectionMatching(t *testing.T) {
+	tests := []struct {
+		name         string
+		section      string
+		errorMessage string
+		expected     []string
+	}{
+		{
+			name:     "Passing-LowerCase",
+			section:  "quick start",
+			expected: []string{"go get"},
+		},
+		{
+			name:     "Passing-UpperCase",
+			section:  "QUICK START",
+			expected: []string{"go get"},
+		},
+		{
+			name:     "Passing-Slug",
+			section:  "quick-start",
+			expected: []string{"go get"},
+		},
+		{
+			name:     "Passing-PathWithSlugs",
+			section:  "intro/quick-start",
+			expected: []string{"go get"},
+		},
+		{
+			name:     "Passing-MixedCase",
+			section:  "Intro",
+			expected: []string{"echo hello world"},
+		},
+		{
+			name:         "Fail-Typo",
+			section:      "quick strat",
+			errorMessage: "no executable blocks found for section 'quick strat', did you mean 'Quick Start'? (valid paths: INTRO, INTRO/Quick Start)",
+		},
+		{
+			name:         "Fail-Prefix",
+			section:      "Quick",
+			errorMessage: "no executable blocks found for section 'Quick', did you mean 'Quick Start'? (valid paths: INTRO, INTRO/Quick Start)",
+		},
+		{
+			name:         "Fail-PathTypo",
+			section:      "intro/quik start",
+			errorMessage: "no executable blocks found for section 'intro/quik start', did you mean 'INTRO/Quick Start' or 'INTRO'? (valid paths: INTRO, INTRO/Quick Start)",
+		},
+		{
+			name:         "Fail-NoSuggestion",
+			section:      "Deploy",
+			errorMessage: "no executable blocks found for section 'Deploy' (valid paths: INTRO, INTRO/Quick Start)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]CommandPlan, error) {
+					document := newDocument()
+
+					return s.PlanScriptExecution(&document, tc.section)
+				},
+				tc.errorMessage,
+				func(cp []CommandPlan, s *Service, t *testing.T) {
+					var commands []string
+					for _, plan := range cp {
+						commands = append(commands, strings.Join(plan.Args, " "))
+					}
+
+					if !reflect.DeepEqual(commands, tc.expected) {
+						t.Errorf("Expected commands %v, got %v", tc.expected, commands)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestPlanScriptExecutionSectionRefs(t *testing.T) {
+	tests := []struct {
+		name         string
+		resolver     DocumentResolver
+		errorMessage string
+		expected     []SectionInfo
+	}{
+		{
+			name:     "Passing",
+			resolver: newSharedDocuments(),
+			expected: []SectionInfo{
+				{Name: "Setup", Level: 4, Origin: "Shared/Development/Setup"},
+				{Name: "Build", Level: 2},
+			},
+		},
+		{
+			name:         "Fail-NoResolver",
+			errorMessage: "rendering \"README > Contributing\": unresolved section reference 'Shared/Outer': configure a document resolver to include sections from other documents",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]CommandPlan, error) {
+					svc, err := s.With(WithDocumentResolver(tc.resolver))
+					if err != nil {
+						return nil, err
+					}
+
+					document := Document{Name: "README"}
+					document.CreateSection("Contributing").AddSectionRef("Shared", "Outer")
+					document.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{})
+
+					return svc.PlanScriptExecution(&document, ALL_SECTIONS)
+				},
+				tc.errorMessage,
+				func(cp []CommandPlan, s *Service, t *testing.T) {
+					if len(cp) != len(tc.expected) {
+						t.Fatalf("Expected %d plans, got %d", len(tc.expected), len(cp))
+					}
+
+					for idx, expected := range tc.expected {
+						if cp[idx].Context != expected {
+							t.Errorf("Expected context %v, got %v", expected, cp[idx].Context)
+						}
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestPlanScriptExecutionEmbeddedDocument(t *testing.T) {
+	testServiceOperation(
+		t,
+		func(s *Service) ([]CommandPlan, error) {
+			contributing := Document{Name: "Contributing"}
+			contributing.CreateSection("Testing").WriteExecutable("bash", []string{"go", "test", "./..."}, []string{})
+
+			document := Document{Name: "README"}
+			document.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{})
+			if err := document.AddDocument(contributing); err != nil {
+				return nil, err
+			}
+
+			return s.PlanScriptExecution(&document, ALL_SECTIONS)
+		},
+		"",
+		func(cp []CommandPlan, s *Service, t *testing.T) {
+			expected := []SectionInfo{
+				{Name: "Build", Level: 2},
+				{Name: "Testing", Level: 3},
+			}
+
+			if len(cp) != len(expected) {
+				t.Fatalf("Expected %d plans, got %d", len(expected), len(cp))
+			}
+
+			for idx, info := range expected {
+				if cp[idx].Context != info {
+					t.Errorf("Expected context %v, got %v", info, cp[idx].Context)
+				}
+			}
+		},
+	)
+}
+
+func TestPlanScriptExecutionMergedDocuments(t *testing.T) {
+	testServiceOperation(
+		t,
+		func(s *Service) ([]CommandPlan, error) {
+			install := Document{Name: "Install"}
+			install.CreateSection("Build").WriteExecutable("bash", []string{"go", "build"}, []string{})
+
+			guide := Document{Name: "Testing"}
+			guide.WriteIntro().Text("Run the tests.")
+			guide.CreateSection("Unit").CreateSection("Fast").WriteExecutable("bash", []string{"go", "test", "-short", "./..."}, []string{})
+
+			handbook, err := MergeDocuments("Handbook", install, guide)
+			if err != nil {
+				return nil, err
+			}
+
+			return s.PlanScriptExecution(&handbook, ALL_SECTIONS)
+		},
+		"",
+		func(cp []CommandPlan, s *Service, t *testing.T) {
+			expected := []struct {
+				context SectionInfo
+				path    []string
+			}{
+				{context: SectionInfo{Name: "Build", Level: 3}, path: []string{"Handbook", "Install", "Build"}},
+				{context: SectionInfo{Name: "Fast", Level: 4}, path: []string{"Handbook", "Testing", "Unit", "Fast"}},
+			}
+
+			if len(cp) != len(expected) {
+				t.Fatalf("Expected %d plans, got %d", len(expected), len(cp))
+			}
+
+			for idx, plan := range expected {
+				if cp[idx].Context != plan.context {
+					t.Errorf("Expected context %v, got %v", plan.context, cp[idx].Context)
+				}
+				if !reflect.DeepEqual(cp[idx].Path, plan.path) {
+					t.Errorf("Expected path %v, got %v", plan.path, cp[idx].Path)
+				}
+			}
+		},
+	)
+}
+
+func TestExecuteScript(t *testing.T) {
+	tests := []struct {
+		name              string
+		document          Document
+		taskRunnerResults []TaskResult
+		errorMessage      string
+	}{
+		{
+			name:     "Passing",
+			document: newDocument(),
+			taskRunnerResults: []TaskResult{
+				{
+					SectionName: "INTRO",
+					Command:     "echo hello world",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+				{
+					SectionName: "Quick Start",
+					Command:     "go get",
+					Status:      COMPLETED,
+					Error:       nil,
+				},
+			},
+			errorMessage: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testServiceOperation(
+				t,
+				func(s *Service) ([]TaskResult, error) {
+					return s.ExecuteScript(&tc.document, ALL_SECTIONS)
+				},
+				tc.errorMessage,
+				func(tr []TaskResult, s *Service, t *testing.T) {
+					for idx, result := range tr {
+						expected := tc.taskRunnerResults[idx]
+						if expected != result {
+							t.Errorf("Expected TaskResults %v, got %v", expected, result)
+						}
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestExecuteScriptExecutionMode(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             ExecutionMode
+		expectedStatuses []TaskStatus
+		errorMessage     string
+	}{
+		{
+			name:             "Passing-ContinueOnError",
+			mode:             ContinueOnError,
+			expectedStatuses: []TaskStatus{FAILED, COMPLETED},
+		},
+		{
+			name:             "Passing-FailFast",
+			mode:             FailFast,
+			expectedStatuses: []TaskStatus{FAILED, SKIPPED},
+		},
+		{
+			name:         "Failing-InvalidMode",
+			mode:         ExecutionMode(42),
+			errorMessage: "invalid execution mode: 42",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := &MockRunner{results: []TaskResult{{Status: FAILED, Error: errors.New("exit status 1")}}}
+			svc := NewService(NewFakeFileRepo(), runner, Markdown{}, NewExecutionRenderer())
+
+			configured, err := svc.With(WithExecutionMode(tc.mode))
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			document := newDocument()
+			results, err := configured.ExecuteScript(&document, ALL_SECTIONS)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(results) != len(tc.expectedStatuses) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expectedStatuses), len(results))
+			}
+
+			for idx, result := range results {
+				if result.Status != tc.expectedStatuses[idx] {
+					t.Errorf("Result %d: Expected status %v, got %v", idx, tc.expectedStatuses[idx], result.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteScriptConcurrency(t *testing.T) {
+	tests := []struct {
+		name         string
+		concurrency  int
+		errorMessage string
+	}{
+		{
+			name:        "Passing-Sequential",
+			concurrency: 1,
+		},
+		{
+			name:        "Passing-Parallel",
+			concurrency: 4,
+		},
+		{
+			name:         "Failing-InvalidConcurrency",
+			concurrency:  0,
+			errorMessage: "invalid concurrency 0 (expected at least 1)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(NewFakeFileRepo(), newTimedRunner(0), Markdown{}, NewExecutionRenderer())
+
+			configured, err := svc.With(WithConcurrency(tc.concurrency))
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			document := newDocument()
+			results, err := configured.ExecuteScript(&document, ALL_SECTIONS)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := []string{"echo hello world", "go get"}
+			if len(results) != len(expected) {
+				t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+			}
+
+			for idx, result := range results {
+				if result.Command != expected[idx] {
+					t.Errorf("Result %d: Expected command %q, got %q", idx, expected[idx], result.Command)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteScriptDryRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		runner       Runner
+		errorMessage string
+	}{
+		{
+			name:   "Passing",
+			runner: newTestTaskRunner(t, DefaultSecureConfig()),
+		},
+		{
+			name:         "Failing-UnsupportedRunner",
+			runner:       &MockRunner{},
+			errorMessage: "task runner does not support dry runs",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(NewFakeFileRepo(), tc.runner, Markdown{}, NewExecutionRenderer())
+
+			configured, err := svc.With(WithDryRun(true))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			document := Document{Name: "Deploy"}
+			document.CreateSection("Release").WriteExecutable("sh", []string{"exit", "1"}, []string{})
+
+			results, err := configured.ExecuteScript(&document, ALL_SECTIONS)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if len(results) != 1 || results[0].Status != COMPLETED || !results[0].DryRun {
+				t.Errorf("Expected a single dry run result, got %v", results)
+			}
+		})
+	}
+}
+
+func TestExecuteScriptConfirmation(t *testing.T) {
+	confirmer := &scriptedConfirmer{answers: []bool{false, true}}
+	config := DefaultSecureConfig()
+	config.Logger = nil
+	config.Confirmer = confirmer
+
+	tests := []struct {
+		name             string
+		runner           Runner
+		errorMessage     string
+		expectedStatuses []TaskStatus
+	}{
+		{
+			name:             "Passing",
+			runner:           newTestTaskRunner(t, config),
+			expectedStatuses: []TaskStatus{SKIPPED, COMPLETED},
+		},
+		{
+			name:         "Failing-UnsupportedRunner",
+			runner:       &MockRunner{},
+			errorMessage: "task runner does not support confirmation",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(NewFakeFileRepo(), tc.runner, Markdown{}, NewExecutionRenderer())
+
+			configured, err := svc.With(WithConfirmation(ConfirmBeforeRun))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			document := Document{Name: "Deploy"}
+			release := document.CreateSection("Release")
+			release.WriteExecutable("sh", []string{"exit", "1"}, []string{})
+			release.WriteExecutable("sh", []string{"true"}, []string{})
+
+			results, err := configured.ExecuteScript(&document, ALL_SECTIONS)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			var statuses []TaskStatus
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+
+			if !reflect.DeepEqual(statuses, tc.expectedStatuses) {
+				t.Errorf("Expected statuses %v, got %v", tc.expectedStatuses, statuses)
+			}
+		})
+	}
+}
+
+func TestExecuteScriptCommandOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		runner         func(t *testing.T) Runner
+		errorMessage   string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Passing",
+			runner:         func(t *testing.T) Runner { return newTestTaskRunner(t, DefaultSecureConfig()) },
+			expectedStdout: "out\n",
+			expectedStderr: "err\n",
+		},
+		{
+			name:           "Passing-Buffered",
+			runner:         func(t *testing.T) Runner { return newTestTaskRunner(t, DefaultSecureConfig()).Buffered() },
+			expectedStdout: "out\n",
+			expectedStderr: "err\n",
+		},
+		{
+			name:         "Failing-UnsupportedRunner",
+			runner:       func(t *testing.T) Runner { return &MockRunner{} },
+			errorMessage: "task runner does not support redirecting output",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			svc := NewService(NewFakeFileRepo(), tc.runner(t), Markdown{}, NewExecutionRenderer())
+			configured, err := svc.With(WithCommandOutput(&stdout, &stderr))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			document := Document{Name: "Build"}
+			document.CreateSection("Output").WriteExecutable("sh", []string{"echo", "out;", "echo", "err", ">&2"}, []string{})
+
+			_, err = configured.ExecuteScript(&document, ALL_SECTIONS)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if stdout.String() != tc.expectedStdout || stderr.String() != tc.expectedStderr {
+				t.Errorf("Expected stdout %q and stderr %q, got %q and %q", tc.expectedStdout, tc.expectedStderr, stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestExecuteScriptContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runner := &MockRunner{}
+	svc := NewService(NewFakeFileRepo(), runner, Markdown{}, NewExecutionRenderer())
+
+	document := newDocument()
+	results, err := svc.ExecuteScriptContext(ctx, &document, ALL_SECTIONS)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(runner.calls) != 0 {
+		t.Errorf("Expected no commands to run, got %d", len(runner.calls))
+	}
+
+	for idx, result := range results {
+		if result.Status != SKIPPED || !errors.Is(result.Error, ErrRunCancelled) {
+			t.Errorf("Result %d: Expected a cancelled SKIPPED result, got %v (error: %v)", idx, result.Status, result.Error)
+		}
+	}
+}
+
+func TestExecuteScriptWithReport(t *testing.T) {
+	runner := &MockRunner{results: []TaskResult{
+		{SectionName: "INTRO", Command: "echo hello world", Status: COMPLETED},
+		{SectionName: "Quick Start", Command: "go get", Status: FAILED, Error: errors.New("exit status 1")},
+	}}
+	svc := NewService(NewFakeFileRepo(), runner, Markdown{}, NewExecutionRenderer())
+
+	document := newDocument()
+	before := time.Now()
+	report, err := svc.ExecuteScriptWithReport(&document, ALL_SECTIONS)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if report.Document != document.Name || report.Section != ALL_SECTIONS {
+		t.Errorf("Expected report for %q with section %q, got %q and %q", document.Name, ALL_SECTIONS, report.Document, report.Section)
+	}
+
+	if report.StartedAt.Before(before) || report.FinishedAt.Before(report.StartedAt) {
+		t.Errorf("Unexpected timestamps %s and %s", report.StartedAt, report.FinishedAt)
+	}
+
+	expected := ReportCounts{Total: 2, Completed: 1, Failed: 1}
+	if report.Counts() != expected {
+		t.Errorf("Expected counts %+v, got %+v", expected, report.Counts())
+	}
+}
+
+func TestCheckPrerequisites(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     func() Document
+		section      string
+		errorMessage string
+	}{
+		{
+			name: "Passing",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				document.CreateSection("Install").Content = []Node{
+					Executable{Shell: "sh", Cmd: []string{"sh", "-c", "true"}, Requires: []string{"sh"}},
+				}
+
+				return document
+			},
+			section:      ALL_SECTIONS,
+			errorMessage: "",
+		},
+		{
+			name: "Passing-OtherPlatform",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				document.CreateSection("Install").Content = []Node{
+					Executable{Shell: "sh", Cmd: []string{"missing"}, Requires: []string{"doyoucompute-missing-tool"}, Platforms: []string{"plan9-but-not-really"}},
+				}
+
+				return document
+			},
+			section:      ALL_SECTIONS,
+			errorMessage: "",
+		},
+		{
+			name: "Passing-OtherSection",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				document.CreateSection("Install").Content = []Node{
+					Executable{Shell: "sh", Cmd: []string{"true"}, Requires: []string{"sh"}},
+				}
+				document.CreateSection("Deploy").Content = []Node{
+					Executable{Shell: "sh", Cmd: []string{"terraform", "apply"}, Requires: []string{"doyoucompute-missing-tool"}},
+				}
+
+				return document
+			},
+			section:      "Install",
+			errorMessage: "",
+		},
+		{
+			name: "Fail-MissingTools",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				document.CreateSection("Build").Content = []Node{
+					Executable{Shell: "sh", Cmd: []string{"docker", "build", "."}, Requires: []string{"sh", "doyoucompute-missing-tool"}},
+				}
+				document.CreateSection("Deploy").WritePipeline(
+					Executable{Shell: "sh", Cmd: []string{"terraform", "plan"}, Requires: []string{"doyoucompute-other-tool"}},
+					Executable{Shell: "sh", Cmd: []string{"tee", "plan.txt"}, Requires: []string{"doyoucompute-missing-tool"}},
+				)
+
+				return document
+			},
+			section:      ALL_SECTIONS,
+			errorMessage: "missing tools: doyoucompute-missing-tool, doyoucompute-other-tool",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newService()
+			document := tc.document()
+
+			err := svc.CheckPrerequisites(&document, tc.section)
+
+			checkErrors(tc.errorMessage, err, t)
+		})
+	}
+}
+
+func TestDefaultService(t *testing.T) {
+	type expected struct {
+		repository        Repository
+		runner            Runner
+		fileRenderer      Renderer[string]
+		executionRenderer Renderer[[]CommandPlan]
+	}
+
+	tests := []struct {
+		name         string
+		opts         []OptionsServiceFunc
+		errorMessage string
+		expected     expected
+	}{
+		{
+			name: "default",
+			opts: []OptionsServiceFunc{},
+			expected: expected{
+				repository:        FileRepository{},
+				runner:            TaskRunner{config: DefaultSecureConfig()},
+				fileRenderer:      Markdown{},
+				executionRenderer: Executioner{},
+			},
+			errorMessage: "",
+		},
+		{
+			name: "overrides",
+			opts: []OptionsServiceFunc{
+				WithRepository(NewFakeFileRepo()),
+				WithTaskRunner(&MockRunner{}),
+			},
+			expected: expected{
+				repository:        &FakeFileRepo{},
+				runner:            &MockRunner{},
+				fileRenderer:      Markdown{},
+				executionRenderer: Executioner{},
+			},
+			errorMessage: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := DefaultService(tc.opts...)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if reflect.TypeOf(svc.repository) != reflect.TypeOf(tc.expected.repository) {
+				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.repository), reflect.TypeOf(svc.repository))
+			}
+
+			if reflect.TypeOf(svc.taskRunner) != reflect.TypeOf(tc.expected.runner) {
+				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.runner), reflect.TypeOf(svc.taskRunner))
+			}
+
+			if reflect.TypeOf(svc.fileRenderer) != reflect.TypeOf(tc.expected.fileRenderer) {
+				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.fileRenderer), reflect.TypeOf(svc.fileRenderer))
+			}
+
+			if reflect.TypeOf(svc.executionRenderer) != reflect.TypeOf(tc.expected.executionRenderer) {
 				t.Errorf("Expected repository to be %v, got %v", reflect.TypeOf(tc.expected.executionRenderer), reflect.TypeOf(svc.executionRenderer))
 			}
 		})
 	}
 }
+
+func TestRenderFileSkipUnchanged(t *testing.T) {
+	tests := []struct {
+		name          string
+		section       string
+		skipUnchanged bool
+		changed       bool
+		expectWrite   bool
+	}{
+		{
+			name:          "Passing-SkipsUnchanged",
+			skipUnchanged: true,
+			changed:       false,
+			expectWrite:   false,
+		},
+		{
+			name:          "Passing-WritesChanged",
+			skipUnchanged: true,
+			changed:       true,
+			expectWrite:   true,
+		},
+		{
+			name:          "Passing-WritesUnchangedWhenDisabled",
+			skipUnchanged: false,
+			changed:       false,
+			expectWrite:   true,
+		},
+		{
+			name:          "Passing-SkipsUnchangedSection",
+			section:       "INTRO",
+			skipUnchanged: true,
+			changed:       false,
+			expectWrite:   false,
+		},
+		{
+			name:          "Passing-WritesChangedSection",
+			section:       "INTRO",
+			skipUnchanged: true,
+			changed:       true,
+			expectWrite:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "README.md")
+
+			svc, err := DefaultService(WithRepository(NewFileRepository()), WithSkipUnchanged(tc.skipUnchanged))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			render := func(document *Document) error {
+				if tc.section != "" {
+					return svc.RenderSection(document, tc.section, path)
+				}
+
+				return svc.RenderFile(document, path)
+			}
+
+			document := newDocument()
+			if err := render(&document); err != nil {
+				t.Fatalf("unexpected error rendering file: %s", err.Error())
+			}
+
+			past := time.Now().Add(-time.Hour).Truncate(time.Second)
+			if err := os.Chtimes(path, past, past); err != nil {
+				t.Fatalf("failed to set modification time: %s", err.Error())
+			}
+
+			if tc.changed && tc.section != "" {
+				section, _ := document.FindSection(tc.section)
+				section.WriteParagraph().Text("Something new")
+			} else if tc.changed {
+				document.WriteIntro().Text("Something new")
+			}
+
+			if err := render(&document); err != nil {
+				t.Fatalf("unexpected error rendering file: %s", err.Error())
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat file: %s", err.Error())
+			}
+
+			written := !info.ModTime().Equal(past)
+			if written != tc.expectWrite {
+				t.Errorf("expected write %t, got %t (modification time %v)", tc.expectWrite, written, info.ModTime())
+			}
+		})
+	}
+}
+
+// stubRenderer renders every node to a fixed string, or fails with err if set.
+type stubRenderer struct {
+	content string
+	err     error
+}
+
+func (s stubRenderer) Render(node Node) (string, error) {
+	return s.content, s.err
+}
+
+// failingSaveRepo is a FakeFileRepo whose Save fails for one path.
+type failingSaveRepo struct {
+	*FakeFileRepo
+	failPath string
+}
+
+func (f failingSaveRepo) Save(path string, content string) error {
+	if path == f.failPath {
+		return errors.New("disk full")
+	}
+
+	return f.FakeFileRepo.Save(path, content)
+}
+
+func TestRenderFormats(t *testing.T) {
+	markdown, err := NewMarkdownRenderer()
+	if err != nil {
+		t.Fatalf("unexpected error creating renderer: %s", err.Error())
+	}
+
+	tests := []struct {
+		name            string
+		targets         []RenderTarget
+		failPath        string
+		expectedWritten []bool
+		errorMessage    string
+	}{
+		{
+			name: "Passing-MarkdownAndStub",
+			targets: []RenderTarget{
+				{Renderer: markdown, Path: "README.md"},
+				{Renderer: stubRenderer{content: "<h1>MyDoc</h1>"}, Path: "docs/readme.html"},
+			},
+			expectedWritten: []bool{true, true},
+			errorMessage:    "",
+		},
+		{
+			name:            "Passing-NoTargets",
+			targets:         []RenderTarget{},
+			expectedWritten: []bool{},
+			errorMessage:    "",
+		},
+		{
+			name: "Fail-SecondTargetRenderError",
+			targets: []RenderTarget{
+				{Renderer: markdown, Path: "README.md"},
+				{Renderer: stubRenderer{err: errors.New("unsupported node")}, Path: "docs/readme.html"},
+			},
+			expectedWritten: []bool{false, false},
+			errorMessage:    "target 1 (docs/readme.html): unsupported node",
+		},
+		{
+			name: "Fail-SecondTargetSaveError",
+			targets: []RenderTarget{
+				{Renderer: markdown, Path: "README.md"},
+				{Renderer: stubRenderer{content: "<h1>MyDoc</h1>"}, Path: "docs/readme.html"},
+			},
+			failPath:        "docs/readme.html",
+			expectedWritten: []bool{true, false},
+			errorMessage:    "target 1 (docs/readme.html): disk full",
+		},
+		{
+			name: "Fail-NilRenderer",
+			targets: []RenderTarget{
+				{Renderer: nil, Path: "README.md"},
+			},
+			expectedWritten: []bool{false},
+			errorMessage:    "target 0 (README.md): renderer cannot be nil",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := failingSaveRepo{FakeFileRepo: NewFakeFileRepo(), failPath: tc.failPath}
+
+			svc, err := DefaultService(WithRepository(repo))
+			if err != nil {
+				t.Fatalf("unexpected error creating service: %s", err.Error())
+			}
+
+			document := newDocument()
+			results, err := svc.RenderFormats(&document, tc.targets)
+
+			checkErrors(tc.errorMessage, err, t)
+
+			if len(results) != len(tc.targets) {
+				t.Fatalf("expected %d results, got %d", len(tc.targets), len(results))
+			}
+
+			for idx, result := range results {
+				if result.Path != tc.targets[idx].Path {
+					t.Errorf("expected result %d path %s, got %s", idx, tc.targets[idx].Path, result.Path)
+				}
+
+				if result.Written != tc.expectedWritten[idx] {
+					t.Errorf("expected result %d written %t, got %t", idx, tc.expectedWritten[idx], result.Written)
+				}
+
+				if result.Written != (result.Error == nil) {
+					t.Errorf("expected result %d to have an error only when not written, got %v", idx, result.Error)
+				}
+
+				_, saved := repo.files[result.Path]
+				if saved != result.Written {
+					t.Errorf("expected %s saved %t, got %t", result.Path, result.Written, saved)
+				}
+			}
+
+			if tc.errorMessage == "" && len(tc.targets) > 0 {
+				if !strings.Contains(repo.files["README.md"], "# MyDoc") {
+					t.Errorf("expected markdown target to be rendered with markdown, got %s", repo.files["README.md"])
+				}
+
+				if repo.files["docs/readme.html"] != "<h1>MyDoc</h1>" {
+					t.Errorf("expected stub target to be rendered with the stub, got %s", repo.files["docs/readme.html"])
+				}
+			}
+		})
+	}
+}
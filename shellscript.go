@@ -0,0 +1,200 @@
+package doyoucompute
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// scriptInterpreters maps interpreters to the invocation that runs a script passed as an argument,
+// used when an executable holds a multi-line script for a shell other than sh or bash.
+var scriptInterpreters = map[string]string{
+	"python":  "python3 -c",
+	"python3": "python3 -c",
+	"node":    "node -e",
+	"ruby":    "ruby -e",
+	"perl":    "perl -e",
+}
+
+// scriptInvocation returns the arguments that run a multi-line script with an interpreter, such as
+// python3 -c followed by the script, and false when there is no known way to pass it inline.
+func scriptInvocation(shell, script string) ([]string, bool) {
+	invocation, ok := scriptInterpreters[shell]
+	if !ok {
+		return nil, false
+	}
+
+	return append(strings.Fields(invocation), script), true
+}
+
+// ShellScript implements the Renderer interface to export a document's executables as a
+// standalone bash script. Each command is preceded by a comment with its section path and
+// checks for the environment variables it requires, and the script stops at the first failure.
+// Commands appear in the order the task runner runs them, with setup commands first and teardown
+// commands last within their section, though a failure stops the script before its teardown.
+type ShellScript struct{}
+
+// NewShellScriptRenderer creates a new ShellScript renderer instance.
+func NewShellScriptRenderer() ShellScript {
+	return ShellScript{}
+}
+
+// isShellSafe reports whether r can appear in a bash word without quoting.
+func isShellSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_@%+=:,./-", r)
+}
+
+// envNames returns the names of the environment values in sorted order, so anything built
+// from them is stable across runs.
+func envNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// envAssignments formats environment values as shell variable assignments.
+func envAssignments(values map[string]string) string {
+	names := envNames(values)
+
+	assignments := make([]string, len(names))
+	for idx, name := range names {
+		assignments[idx] = name + "=" + shellQuote(values[name])
+	}
+
+	return strings.Join(assignments, " ")
+}
+
+// shellQuote quotes an argument for bash, leaving arguments that need no quoting untouched.
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool { return !isShellSafe(r) }) == -1 {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// command formats a single command the way the task runner would run it. sh and bash commands are
+// written verbatim so variables expand; other interpreters receive their arguments quoted.
+func (s ShellScript) command(shell string, args []string) (string, error) {
+	if shell == "sh" || shell == "bash" {
+		return strings.Join(args, " "), nil
+	}
+
+	if len(args) == 1 && strings.Contains(args[0], "\n") {
+		invocation, ok := scriptInvocation(shell, args[0])
+		if !ok {
+			return "", fmt.Errorf("cannot export multi-line %s script: no known way to invoke %s with an inline script", shell, shell)
+		}
+
+		args = invocation
+	}
+
+	quoted := make([]string, len(args))
+	for idx, arg := range args {
+		quoted[idx] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, " "), nil
+}
+
+func (s ShellScript) renderPlan(plan CommandPlan) (string, error) {
+	var builder strings.Builder
+
+	builder.WriteString("# " + strings.Join(plan.Path, " > ") + "\n")
+
+	for _, envVar := range plan.Environment {
+		fmt.Fprintf(&builder, ": \"${%s:?%s must be set}\"\n", envVar, envVar)
+	}
+
+	command, err := s.commandLine(plan)
+	if err != nil {
+		return "", err
+	}
+
+	builder.WriteString(command)
+
+	return builder.String(), nil
+}
+
+// commandLine formats a plan as a single bash command line, piping its stages into each other.
+func (s ShellScript) commandLine(plan CommandPlan) (string, error) {
+	stages := plan.Stages
+	if len(stages) == 0 {
+		stages = []CommandPlan{plan}
+	}
+
+	commands := make([]string, len(stages))
+	for idx, stage := range stages {
+		command, err := s.command(stage.Shell, stage.Args)
+		if err != nil {
+			return "", err
+		}
+
+		// Assignments prefixed to the command only apply to that command
+		if len(stage.EnvValues) > 0 {
+			command = envAssignments(stage.EnvValues) + " " + command
+		}
+
+		// A subshell keeps the directory change from leaking into the rest of the script
+		if stage.WorkingDir != "" {
+			command = fmt.Sprintf("(cd %s && %s)", shellQuote(stage.WorkingDir), command)
+		}
+
+		commands[idx] = command
+	}
+
+	return strings.Join(commands, " | "), nil
+}
+
+// renderBlocks renders a block for every command within node. The Executioner builds the plans,
+// so the script runs exactly what the task runner would, in the same order: setup commands first
+// and teardown commands after the other commands of their section.
+func (s ShellScript) renderBlocks(node Node) ([]string, error) {
+	plans, err := Executioner{}.Render(node)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]string, len(plans))
+	for idx, plan := range plans {
+		block, err := s.renderPlan(plan)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks[idx] = block
+	}
+
+	return blocks, nil
+}
+
+// Stamp appends the GeneratedMarker to the script as a comment.
+func (s ShellScript) Stamp(content string) string {
+	return fmt.Sprintf("%s\n# %s\n", content, GeneratedMarker)
+}
+
+// Render converts a document's executables into a bash script.
+// This is the main entry point for the Renderer interface implementation.
+func (s ShellScript) Render(node Node) (string, error) {
+	blocks, err := s.renderBlocks(node)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("#!/usr/bin/env bash\n")
+	builder.WriteString("set -euo pipefail\n")
+
+	for _, block := range blocks {
+		builder.WriteString("\n")
+		builder.WriteString(block)
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), nil
+}
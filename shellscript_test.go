@@ -0,0 +1,120 @@
+package doyoucompute
+
+import "testing"
+
+func TestShellScriptRender(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     func() Document
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing",
+			document: newDocument,
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# MyDoc > INTRO\necho hello world\n\n# MyDoc > INTRO > Quick Start\ngo get\n",
+		},
+		{
+			name: "Passing-EnvironmentAndPipelines",
+			document: func() Document {
+				document := Document{Name: "Deploy"}
+				release := document.CreateSection("Release")
+				release.WriteExecutable("bash", []string{"./deploy.sh", "--token", "$API_TOKEN"}, []string{"API_TOKEN", "REGION"})
+				release.WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"cat", "release.log"}},
+					Executable{Shell: "grep", Cmd: []string{"grep", "it's done"}},
+				)
+				release.CreateList(BULLET).AppendNode(Executable{Shell: "sh", Cmd: []string{"echo", "listed"}})
+
+				return document
+			},
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# Deploy > Release\n: \"${API_TOKEN:?API_TOKEN must be set}\"\n: \"${REGION:?REGION must be set}\"\n./deploy.sh --token $API_TOKEN\n\n# Deploy > Release\ncat release.log | grep 'it'\\''s done'\n\n# Deploy > Release\necho listed\n",
+		},
+		{
+			name: "Passing-Scripts",
+			document: func() Document {
+				document := Document{Name: "Scripts"}
+				scripts := document.CreateSection("Scripts")
+				scripts.Content = append(scripts.Content,
+					Executable{Shell: "bash", Cmd: []string{"set -e", "go vet ./..."}, JoinWith: "\n"},
+					Executable{Shell: "python", Cmd: []string{"import sys", "print(sys.version)"}, JoinWith: "\n"},
+					Executable{Shell: "python", Cmd: []string{"python3", "-c", "print('hi')"}},
+				)
+
+				return document
+			},
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# Scripts > Scripts\nset -e\ngo vet ./...\n\n# Scripts > Scripts\npython3 -c 'import sys\nprint(sys.version)'\n\n# Scripts > Scripts\npython3 -c 'print('\\''hi'\\'')'\n",
+		},
+		{
+			name: "Passing-WorkingDir",
+			document: func() Document {
+				document := Document{Name: "Build"}
+				document.CreateSection("Web").Content = []Node{
+					Executable{Shell: "bash", Cmd: []string{"npm", "ci"}, WorkingDir: "web app"},
+				}
+
+				return document
+			},
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# Build > Web\n(cd 'web app' && npm ci)\n",
+		},
+		{
+			name: "Passing-EnvValues",
+			document: func() Document {
+				document := Document{Name: "Build"}
+				document.CreateSection("Go").Content = []Node{
+					Executable{Shell: "bash", Cmd: []string{"go", "build"}, EnvValues: map[string]string{"GOOS": "linux", "CGO_ENABLED": "0", "LDFLAGS": "-s -w"}},
+				}
+
+				return document
+			},
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# Build > Go\nCGO_ENABLED=0 GOOS=linux LDFLAGS='-s -w' go build\n",
+		},
+		{
+			name: "Passing-SetupAndTeardown",
+			document: func() Document {
+				document := Document{Name: "Test"}
+				section := document.CreateSection("Integration")
+				section.WriteExecutable("bash", []string{"go", "test", "./..."}, []string{})
+				section.WriteTeardown("bash", []string{"docker", "compose", "down"}, []string{})
+				section.WriteSetup("bash", []string{"docker", "compose", "up", "-d"}, []string{})
+
+				return document
+			},
+			expected: "#!/usr/bin/env bash\nset -euo pipefail\n\n# Test > Integration\ndocker compose up -d\n\n# Test > Integration\ngo test ./...\n\n# Test > Integration\ndocker compose down\n",
+		},
+		{
+			name: "Failing-UnknownInterpreterScript",
+			document: func() Document {
+				document := Document{Name: "Scripts"}
+				document.CreateSection("Lua").Content = []Node{
+					Executable{Shell: "lua", Cmd: []string{"print(1)", "print(2)"}, JoinWith: "\n"},
+				}
+
+				return document
+			},
+			errorMessage: "cannot export multi-line lua script: no known way to invoke lua with an inline script",
+		},
+		{
+			name: "Failing-UnresolvedSectionRef",
+			document: func() Document {
+				document := Document{Name: "Refs"}
+				document.AddSectionRef("Shared", "Setup")
+
+				return document
+			},
+			errorMessage: "rendering \"Refs\": unresolved section reference 'Shared/Setup': configure a document resolver to include sections from other documents",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+			content, err := NewShellScriptRenderer().Render(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if content != tc.expected {
+				t.Errorf("Expected content %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WordsPerMinute is the reading speed DocumentStats.ReadingTime is estimated with.
+const WordsPerMinute = 200
+
+// DocumentStats holds counts of the content of a document, computed by Document.Stats.
+type DocumentStats struct {
+	// Sections is the number of sections at any depth
+	Sections int
+	// SectionsByDepth counts the sections at each depth, where top-level sections are at depth 1
+	SectionsByDepth map[int]int
+	// Paragraphs is the number of paragraphs
+	Paragraphs int
+	// Executables is the number of executables and pipelines
+	Executables int
+	// CodeBlocks is the number of code blocks that are not executed
+	CodeBlocks int
+	// Tables is the number of tables
+	Tables int
+	// Links is the number of links
+	Links int
+	// Remotes is the number of remote content elements, whose content is counted in Words
+	Remotes int
+	// Words is the number of words of prose: headings, text, links, quotes, admonitions, tables,
+	// and remote content. Code blocks, executables, and comments are not counted
+	Words int
+	// ReadingTime is the time it takes to read Words at WordsPerMinute
+	ReadingTime time.Duration
+}
+
+// documentStatsJSON is the JSON representation of DocumentStats.
+type documentStatsJSON struct {
+	Sections        int         `json:"sections"`
+	SectionsByDepth map[int]int `json:"sections_by_depth"`
+	Paragraphs      int         `json:"paragraphs"`
+	Executables     int         `json:"executables"`
+	CodeBlocks      int         `json:"code_blocks"`
+	Tables          int         `json:"tables"`
+	Links           int         `json:"links"`
+	Remotes         int         `json:"remotes"`
+	Words           int         `json:"words"`
+	ReadingTimeMS   int64       `json:"reading_time_ms"`
+}
+
+// MarshalJSON encodes the stats with snake_case keys and the reading time in whole milliseconds.
+func (d DocumentStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(documentStatsJSON{
+		Sections:        d.Sections,
+		SectionsByDepth: d.SectionsByDepth,
+		Paragraphs:      d.Paragraphs,
+		Executables:     d.Executables,
+		CodeBlocks:      d.CodeBlocks,
+		Tables:          d.Tables,
+		Links:           d.Links,
+		Remotes:         d.Remotes,
+		Words:           d.Words,
+		ReadingTimeMS:   d.ReadingTime.Milliseconds(),
+	})
+}
+
+// Stats walks the document and counts its content without changing it. Each remote is read
+// once, and readers that can seek are put back where they were, so the document can still be
+// rendered afterwards. Unresolved section references are not counted. Returns an error if a
+// remote cannot be read.
+func (d *Document) Stats() (DocumentStats, error) {
+	stats := DocumentStats{SectionsByDepth: map[int]int{}}
+	stats.Words += countWords(d.Name)
+
+	if err := collectStats(d.Content, ContextPath{}.Push(d.Name), &stats, remoteReader{}); err != nil {
+		return DocumentStats{}, err
+	}
+
+	stats.ReadingTime = (time.Duration(stats.Words) * time.Minute / WordsPerMinute).Round(time.Second)
+
+	return stats, nil
+}
+
+// collectStats adds the counts of nodes and their children to stats, reading remotes with remotes.
+func collectStats(nodes []Node, path ContextPath, stats *DocumentStats, remotes remoteReader) error {
+	for _, node := range nodes {
+		switch node.Type() {
+		case SectionType:
+			section := node.(Structurer)
+			childPath := path.Push(section.Identifier())
+
+			stats.Sections++
+			stats.SectionsByDepth[len(childPath)-1]++
+			stats.Words += countWords(section.Identifier())
+
+			if err := collectStats(section.Children(), childPath, stats, remotes); err != nil {
+				return err
+			}
+			continue
+		case ParagraphType:
+			stats.Paragraphs++
+		case ExecutableType, PipelineType:
+			stats.Executables++
+		case CodeBlockType:
+			stats.CodeBlocks++
+		case TableType:
+			stats.Tables++
+			if table, ok := nodeAs[Table](node); ok {
+				stats.Words += countWords(strings.Join(table.Headers, " "))
+			}
+		case LinkType:
+			stats.Links++
+			if link, ok := nodeAs[Link](node); ok {
+				stats.Words += countWords(link.Text)
+			}
+		case CollapsibleType:
+			stats.Words += countWords(node.(Structurer).Identifier())
+		case RemoteType:
+			content, err := remotes.read(node)
+			if err != nil {
+				return fmt.Errorf("reading remote in section '%s': %w", path.CurrentSection(), err)
+			}
+
+			stats.Remotes++
+			stats.Words += countWords(content)
+			continue
+		case HeaderType, TextType, BoldType, ItalicType, StrikethroughType, CodeType, CrossRefType, BlockQuoteType, AdmonitionType:
+			if content, err := node.(Contenter).Materialize(); err == nil {
+				stats.Words += countWords(content.Content)
+			}
+		case TableRowType:
+			if content, err := node.(Contenter).Materialize(); err == nil {
+				if items, ok := content.Metadata["Items"].([]string); ok {
+					stats.Words += countWords(strings.Join(items, " "))
+				}
+			}
+		}
+
+		if structurer, ok := node.(Structurer); ok {
+			if err := collectStats(structurer.Children(), path, stats, remotes); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// remoteReader holds the content of the remotes read so far, keyed by their reader, so a
+// reader shared by several nodes is only read once.
+type remoteReader map[io.Reader]string
+
+// read returns the content of the remote held by node without changing it. Readers that can
+// seek, such as files or strings.Reader, are put back where they were, and those created by
+// NewRemoteURL download the content again on their next read, so the document can still be
+// rendered afterwards.
+func (r remoteReader) read(node Node) (string, error) {
+	remote, ok := nodeAs[Remote](node)
+	if !ok {
+		return "", nil
+	}
+
+	comparable := remote.Reader != nil && reflect.TypeOf(remote.Reader).Comparable()
+	if comparable {
+		if content, ok := r[remote.Reader]; ok {
+			return content, nil
+		}
+	}
+
+	seeker, canSeek := remote.Reader.(io.Seeker)
+	var offset int64
+	if canSeek {
+		var err error
+		if offset, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			canSeek = false
+		}
+	}
+
+	content, err := remote.Materialize()
+
+	if canSeek {
+		if _, seekErr := seeker.Seek(offset, io.SeekStart); seekErr != nil && err == nil {
+			err = seekErr
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if comparable {
+		r[remote.Reader] = content.Content
+	}
+
+	return content.Content, nil
+}
+
+// countWords counts the words of text. A word is a run of characters between whitespace that
+// holds at least one letter or digit, so "don't", "well-known", and "v1.2" are one word each,
+// while markup such as "-", "#", or "|" is not a word.
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,222 @@
+package doyoucompute
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocumentStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     func() Document
+		expected     DocumentStats
+		errorMessage string
+	}{
+		{
+			name:     "Passing-StandardDocument",
+			document: newDocument,
+			expected: DocumentStats{
+				Sections:        2,
+				SectionsByDepth: map[int]int{1: 1, 2: 1},
+				Paragraphs:      1,
+				Executables:     2,
+				Words:           14,
+				ReadingTime:     4 * time.Second,
+			},
+		},
+		{
+			name: "Passing-AllContent",
+			document: func() Document {
+				document, _ := NewDocument("Guide")
+
+				section := document.CreateSection("Usage")
+				section.WriteParagraph().Text("Read the").Link("docs", "https://example.com").Text("first.")
+				section.WriteCodeBlock("go", []string{"fmt.Println(\"not counted\")"}, Static)
+				section.WritePipeline(
+					Executable{Shell: "bash", Cmd: []string{"cat", "file"}},
+					Executable{Shell: "bash", Cmd: []string{"wc", "-l"}},
+				)
+				section.AddTable([]string{"Flag", "Usage"}, []TableRow{{Values: []string{"--force", "overwrite files"}}})
+				section.WriteComment("not counted either")
+
+				nested := section.CreateSection("Details")
+				nested.WriteRemoteContent(Remote{Reader: strings.NewReader("# Remote\n\nFrom somewhere - else.\n")})
+
+				return document
+			},
+			expected: DocumentStats{
+				Sections:        2,
+				SectionsByDepth: map[int]int{1: 1, 2: 1},
+				Paragraphs:      1,
+				Executables:     1,
+				CodeBlocks:      1,
+				Tables:          1,
+				Links:           1,
+				Remotes:         1,
+				Words:           16,
+				ReadingTime:     5 * time.Second,
+			},
+		},
+		{
+			name: "Fail-RemoteDigestMismatch",
+			document: func() Document {
+				document, _ := NewDocument("Guide")
+				document.CreateSection("Usage").WriteRemoteContent(Remote{Reader: strings.NewReader("changed"), Pin: ContentDigest("original")})
+
+				return document
+			},
+			errorMessage: "reading remote in section 'Usage': " + ErrRemoteDigestMismatch.Error() + ": expected " + ContentDigest("original") + ", got " + ContentDigest("changed") + " (update the Pin to " + ContentDigest("changed") + " if the new content is expected)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+
+			stats, err := document.Stats()
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if !reflect.DeepEqual(stats, tc.expected) {
+				t.Errorf("expected stats %+v, got %+v", tc.expected, stats)
+			}
+		})
+	}
+}
+
+func TestDocumentStatsKeepsRemoteContent(t *testing.T) {
+	document, err := NewDocument("Guide")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+	document.CreateSection("Usage").WriteRemoteContent(Remote{Reader: strings.NewReader("remote words here")})
+
+	if _, err := document.Stats(); err != nil {
+		t.Fatalf("unexpected error computing stats: %s", err.Error())
+	}
+
+	stats, err := document.Stats()
+	if err != nil {
+		t.Fatalf("unexpected error computing stats again: %s", err.Error())
+	}
+
+	if stats.Words != 5 {
+		t.Errorf("expected remote words to be counted again, got %d words", stats.Words)
+	}
+
+	svc, err := DefaultService(WithRepository(NewFakeFileRepo()))
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %s", err.Error())
+	}
+
+	content, err := svc.RenderString(&document)
+	if err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	if !strings.Contains(content, "remote words here") {
+		t.Errorf("expected rendered document to hold the remote content, got %s", content)
+	}
+}
+
+func TestDocumentStatsLeavesDocumentUnchanged(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("fetched remote words"))
+	}))
+	defer server.Close()
+
+	remote, err := NewRemoteURL(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error creating remote: %s", err.Error())
+	}
+
+	document, err := NewDocument("Guide")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+	document.CreateSection("Usage").WriteRemoteContent(remote)
+
+	stats, err := document.Stats()
+	if err != nil {
+		t.Fatalf("unexpected error computing stats: %s", err.Error())
+	}
+
+	if stats.Words != 5 {
+		t.Errorf("expected remote words to be counted, got %d words", stats.Words)
+	}
+
+	section, _ := document.FindSection("Usage")
+	if got, ok := nodeAs[Remote](section.Content[0]); !ok || got.Reader != remote.Reader {
+		t.Errorf("expected the remote to keep its reader, got %v", section.Content[0])
+	}
+
+	for render := 1; render <= 2; render++ {
+		content, err := Markdown{}.Render(&document)
+		if err != nil {
+			t.Fatalf("unexpected error rendering: %s", err.Error())
+		}
+
+		if !strings.Contains(content, "fetched remote words") {
+			t.Errorf("expected render %d to hold the remote content, got %s", render, content)
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("expected the remote to be fetched for the stats and each render, got %d requests", requests)
+	}
+}
+
+func TestDocumentStatsJSON(t *testing.T) {
+	stats := DocumentStats{
+		Sections:        1,
+		SectionsByDepth: map[int]int{1: 1},
+		Words:           300,
+		ReadingTime:     90 * time.Second,
+	}
+
+	encoded, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("unexpected error encoding stats: %s", err.Error())
+	}
+
+	expected := `{"sections":1,"sections_by_depth":{"1":1},"paragraphs":0,"executables":0,"code_blocks":0,"tables":0,"links":0,"remotes":0,"words":300,"reading_time_ms":90000}`
+	if string(encoded) != expected {
+		t.Errorf("expected %s, got %s", expected, string(encoded))
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "Passing-Empty", text: "", expected: 0},
+		{name: "Passing-Whitespace", text: " \t\n ", expected: 0},
+		{name: "Passing-Sentence", text: "This is an introduction.", expected: 4},
+		{name: "Passing-MultipleLines", text: "one\ntwo\tthree  four", expected: 4},
+		{name: "Passing-Contractions", text: "don't can't", expected: 2},
+		{name: "Passing-Hyphenated", text: "well-known", expected: 1},
+		{name: "Passing-Versions", text: "v1.2 3.14", expected: 2},
+		{name: "Passing-MarkupIgnored", text: "# Title - item | cell | --- >", expected: 3},
+		{name: "Passing-Unicode", text: "naïve café 日本語", expected: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if count := countWords(tc.text); count != tc.expected {
+				t.Errorf("expected %d words, got %d", tc.expected, count)
+			}
+		})
+	}
+}
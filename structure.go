@@ -1,8 +1,15 @@
 package doyoucompute
 
 import (
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 // MARK: Frontmatter
@@ -12,6 +19,9 @@ import (
 type Frontmatter struct {
 	// Data holds the parsed frontmatter key-value pairs
 	Data map[string]interface{}
+
+	// order records the keys set through the Set methods, which are rendered first in that order
+	order []string
 }
 
 // NewFrontmatter creates a new Frontmatter instance with the provided data map.
@@ -62,18 +72,153 @@ func (t Table) Children() []Node {
 // Identifier returns an empty string as tables do not have specific identifiers.
 func (t Table) Identifier() string { return "" }
 
+// ErrRowTooLong is returned when a row has more columns than the table has headers.
+var ErrRowTooLong = errors.New("Row length exceeds number of headers")
+
 // AddRow appends a new row to the table with the provided column values.
-// Returns an error if the number of values exceeds the number of headers.
+// Returns ErrRowTooLong if the number of values exceeds the number of headers.
 func (t *Table) AddRow(row ...string) error {
-	if len(row) > len(t.Headers) {
-		return errors.New("Row length exceeds number of headers")
+	return t.AddTableRow(TableRow{Values: row})
+}
+
+// AddRichRow appends a new row whose columns are nodes, such as Text, Code, or Link.
+// Returns ErrRowTooLong if the number of cells exceeds the number of headers.
+func (t *Table) AddRichRow(cells ...Node) error {
+	return t.AddTableRow(TableRow{Cells: cells})
+}
+
+// AddTableRow appends an existing row, holding either plain values or rich cells.
+// Returns ErrRowTooLong if the row has more columns than the table has headers.
+func (t *Table) AddTableRow(row TableRow) error {
+	if err := t.checkRow(row); err != nil {
+		return err
+	}
+
+	t.Items = append(t.Items, row)
+
+	return nil
+}
+
+// AddRows appends a row for each set of column values. The rows are checked before any are
+// added, so on error the table is left unchanged. Returns an error wrapping ErrRowTooLong
+// naming the first row with more values than the table has headers.
+func (t *Table) AddRows(rows [][]string) error {
+	for idx, row := range rows {
+		if err := t.checkRow(TableRow{Values: row}); err != nil {
+			return fmt.Errorf("row %d: %w", idx+1, err)
+		}
+	}
+
+	for _, row := range rows {
+		t.Items = append(t.Items, TableRow{Values: row})
 	}
 
-	t.Items = append(t.Items, TableRow{Values: row})
+	return nil
+}
+
+// checkRow returns ErrRowTooLong if row has more columns than the table has headers.
+func (t *Table) checkRow(row TableRow) error {
+	if len(row.Values) > len(t.Headers) || len(row.Cells) > len(t.Headers) {
+		return ErrRowTooLong
+	}
 
 	return nil
 }
 
+// CSVOption configures how NewTableFromCSV reads its input.
+type CSVOption func(c *csvConfig) error
+
+type csvConfig struct {
+	delimiter rune
+	headers   []string
+	pad       bool
+}
+
+// WithCSVDelimiter sets the field delimiter, such as '\t' for TSV input. Defaults to ','.
+func WithCSVDelimiter(delimiter rune) CSVOption {
+	return func(c *csvConfig) error {
+		if delimiter == '\r' || delimiter == '\n' || delimiter == '"' {
+			return fmt.Errorf("invalid CSV delimiter %q", delimiter)
+		}
+
+		c.delimiter = delimiter
+
+		return nil
+	}
+}
+
+// WithCSVHeaders supplies the table headers explicitly, so every record in the input is read as a row.
+func WithCSVHeaders(headers ...string) CSVOption {
+	return func(c *csvConfig) error {
+		if len(headers) == 0 {
+			return errors.New("CSV headers cannot be empty")
+		}
+
+		c.headers = headers
+
+		return nil
+	}
+}
+
+// WithCSVPadding pads records with fewer fields than there are headers with empty values.
+func WithCSVPadding(enabled bool) CSVOption {
+	return func(c *csvConfig) error {
+		c.pad = enabled
+
+		return nil
+	}
+}
+
+// NewTableFromCSV creates a Table from CSV input, reading the first record as the headers
+// and the remaining records as rows. Returns an error if the input is malformed or a record
+// has more fields than there are headers.
+func NewTableFromCSV(r io.Reader, opts ...CSVOption) (*Table, error) {
+	config := csvConfig{delimiter: ','}
+
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = config.delimiter
+	reader.FieldsPerRecord = -1 // ragged records are reported against the header count below
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+
+	headers := config.headers
+	firstRow := 1
+	if headers == nil {
+		if len(records) == 0 {
+			return nil, errors.New("CSV input has no header record")
+		}
+
+		headers = records[0]
+	} else {
+		firstRow = 0
+	}
+
+	table := NewTable(headers, make([]TableRow, 0, len(records)))
+
+	for idx, record := range records[min(firstRow, len(records)):] {
+		if len(record) > len(headers) {
+			return nil, fmt.Errorf("CSV record %d has %d fields, but the table has %d headers", idx+firstRow+1, len(record), len(headers))
+		}
+
+		for config.pad && len(record) < len(headers) {
+			record = append(record, "")
+		}
+
+		table.Items = append(table.Items, TableRow{Values: record})
+	}
+
+	return table, nil
+}
+
 // MARK: List
 
 // ListTypeE represents the different types of lists that can be rendered.
@@ -84,10 +229,26 @@ const (
 	BULLET ListTypeE = iota + 1
 	// NUMBERED represents an ordered list with numbers
 	NUMBERED
+	// TASK represents a GitHub-flavored task list where each item has a checkbox
+	TASK
 )
 
+// String returns the name of the list type: "bullet", "numbered", or "task".
+func (l ListTypeE) String() string {
+	switch l {
+	case BULLET:
+		return "bullet"
+	case NUMBERED:
+		return "numbered"
+	case TASK:
+		return "task"
+	}
+
+	return fmt.Sprintf("ListTypeE(%d)", int(l))
+}
+
 // Prefix returns the string prefix used for rendering this list type.
-// Returns "-" for bullet lists and "1." for numbered lists.
+// Returns "-" for bullet and task lists and "1." for numbered lists.
 func (l ListTypeE) Prefix() string {
 	switch l {
 	case BULLET:
@@ -100,19 +261,23 @@ func (l ListTypeE) Prefix() string {
 	return "-"
 }
 
-// List represents a container for rendering content as either bulleted or numbered lists.
+// List represents a container for rendering content as bulleted, numbered, or task lists.
 type List struct {
-	// Items contains the text content for each list item
-	Items []Text
-	// TypeOfList specifies whether this is a bulleted or numbered list
+	// Items contains the content of each list item. A List item is rendered as a
+	// nested list beneath the item before it.
+	Items []Node
+	// TypeOfList specifies whether this is a bulleted, numbered, or task list
 	TypeOfList ListTypeE
+	// Checked holds the checkbox state of each item in a task list, by index.
+	// Items without an entry are unchecked.
+	Checked []bool
 }
 
 // NewList creates a new List instance of the specified type with an empty items slice.
 func NewList(typeOfList ListTypeE) *List {
 	return &List{
 		TypeOfList: typeOfList,
-		Items:      make([]Text, 0),
+		Items:      make([]Node, 0),
 	}
 }
 
@@ -121,27 +286,79 @@ func (l List) Type() ContentType { return ListType }
 
 // Children returns all list items as Node interfaces, allowing the list
 // to be treated as a parent node in a document tree structure.
-func (l List) Children() []Node {
-	nodes := make([]Node, len(l.Items))
-
-	for idx, item := range l.Items {
-		nodes[idx] = item
-	}
-
-	return nodes
-}
+func (l List) Children() []Node { return l.Items }
 
 // Identifier returns an empty string as lists do not have specific identifiers.
 func (l List) Identifier() string { return "" }
 
 // Push adds a new item to the beginning of the list.
+// Items pushed onto a task list are unchecked.
 func (l *List) Push(val string) {
-	l.Items = append([]Text{Text(val)}, l.Items...)
+	l.Items = append([]Node{Text(val)}, l.Items...)
+
+	if len(l.Checked) > 0 {
+		l.Checked = append([]bool{false}, l.Checked...)
+	}
 }
 
 // Append adds a new item to the end of the list.
+// Items appended to a task list are unchecked.
 func (l *List) Append(val string) {
-	l.Items = append(l.Items, Text(val))
+	l.AppendTask(val, false)
+}
+
+// AppendTask adds a new item with the given checkbox state to the end of the list.
+// The checkbox state is only rendered for task lists.
+func (l *List) AppendTask(val string, checked bool) {
+	l.appendItem(Text(val), checked)
+}
+
+// AppendNode adds any node, such as a Paragraph or an Executable, to the end of the list.
+func (l *List) AppendNode(node Node) {
+	l.appendItem(node, false)
+}
+
+// AppendParagraph creates a new paragraph at the end of the list and returns it for editing,
+// allowing list items that mix text with links and inline code.
+func (l *List) AppendParagraph() *Paragraph {
+	paragraph := NewParagraph()
+
+	l.AppendNode(paragraph)
+
+	return paragraph
+}
+
+// AppendList adds a nested list to the end of the list, which is rendered
+// indented beneath the preceding item.
+func (l *List) AppendList(list *List) {
+	l.appendItem(list, false)
+}
+
+// CreateList creates a new nested list of the specified type beneath the last item
+// and returns it for editing.
+func (l *List) CreateList(listType ListTypeE) *List {
+	list := NewList(listType)
+
+	l.AppendList(list)
+
+	return list
+}
+
+func (l *List) appendItem(item Node, checked bool) {
+	l.Items = append(l.Items, item)
+
+	if checked || len(l.Checked) > 0 {
+		for len(l.Checked) < len(l.Items)-1 {
+			l.Checked = append(l.Checked, false)
+		}
+
+		l.Checked = append(l.Checked, checked)
+	}
+}
+
+// IsChecked reports whether the item at idx is checked.
+func (l List) IsChecked(idx int) bool {
+	return idx < len(l.Checked) && l.Checked[idx]
 }
 
 // MARK: Container
@@ -183,6 +400,42 @@ func (p *Paragraph) Code(val string) *Paragraph {
 	return p
 }
 
+// LineBreak adds a hard line break to the paragraph and returns the paragraph for method chaining.
+// Consecutive breaks collapse into one, and breaks at the start or end of a paragraph are dropped.
+func (p *Paragraph) LineBreak() *Paragraph {
+	p.Items = append(p.Items, LineBreak{})
+
+	return p
+}
+
+// Bold adds bold text to the paragraph and returns the paragraph for method chaining.
+func (p *Paragraph) Bold(val string) *Paragraph {
+	p.Items = append(p.Items, Bold(val))
+
+	return p
+}
+
+// Italic adds italic text to the paragraph and returns the paragraph for method chaining.
+func (p *Paragraph) Italic(val string) *Paragraph {
+	p.Items = append(p.Items, Italic(val))
+
+	return p
+}
+
+// Strikethrough adds struck-out text to the paragraph and returns the paragraph for method chaining.
+func (p *Paragraph) Strikethrough(val string) *Paragraph {
+	p.Items = append(p.Items, Strikethrough(val))
+
+	return p
+}
+
+// Math adds an inline math expression to the paragraph and returns the paragraph for method chaining.
+func (p *Paragraph) Math(expr string) *Paragraph {
+	p.Items = append(p.Items, MathInline(expr))
+
+	return p
+}
+
 // Link adds a hyperlink element to the paragraph and returns the paragraph for method chaining.
 func (p *Paragraph) Link(text, url string) *Paragraph {
 	p.Items = append(p.Items, Link{Text: text, Url: url})
@@ -190,6 +443,95 @@ func (p *Paragraph) Link(text, url string) *Paragraph {
 	return p
 }
 
+// Image adds an image element to the paragraph and returns the paragraph for method chaining.
+func (p *Paragraph) Image(alt, url string) *Paragraph {
+	p.Items = append(p.Items, Image{AltText: alt, Url: url})
+
+	return p
+}
+
+// Badge adds a status badge, an image linking to url, and returns the paragraph for method chaining.
+func (p *Paragraph) Badge(label, url, imageUrl string) *Paragraph {
+	return p.AddBadge(Badge{Label: label, Url: url, ImageUrl: imageUrl})
+}
+
+// AddBadge adds an existing badge, such as one from NewLicenseBadge, and returns the paragraph
+// for method chaining.
+func (p *Paragraph) AddBadge(badge Badge) *Paragraph {
+	p.Items = append(p.Items, badge)
+
+	return p
+}
+
+// CrossRef adds a link to another section of the same document and returns the paragraph
+// for method chaining. When text is empty, the target section name is used as the link text.
+func (p *Paragraph) CrossRef(text, target string) *Paragraph {
+	p.Items = append(p.Items, CrossRef{Text: text, Target: target})
+
+	return p
+}
+
+// MARK: Collapsible
+
+// Collapsible represents content hidden behind an expandable summary line, such as a
+// long troubleshooting guide. Its content belongs to the enclosing section, so headings
+// inside it keep the levels they would have without it.
+type Collapsible struct {
+	// Summary is the text shown while the content is collapsed
+	Summary string
+	// Content holds the elements revealed when the collapsible is expanded
+	Content []Node
+}
+
+// NewCollapsible creates a new Collapsible with the specified summary and empty content.
+func NewCollapsible(summary string) *Collapsible {
+	return &Collapsible{
+		Summary: summary,
+		Content: make([]Node, 0),
+	}
+}
+
+// Type returns the ContentType for this collapsible element.
+func (c Collapsible) Type() ContentType { return CollapsibleType }
+
+// Children returns all content within the collapsible as Node interfaces.
+func (c Collapsible) Children() []Node { return c.Content }
+
+// Identifier returns the summary as the collapsible's identifier.
+func (c Collapsible) Identifier() string { return c.Summary }
+
+// AddNode appends any node to the collapsible's content.
+func (c *Collapsible) AddNode(node Node) {
+	c.Content = append(c.Content, node)
+}
+
+// CreateSection creates a new subsection inside the collapsible and returns it for editing.
+func (c *Collapsible) CreateSection(name string) *Section {
+	section := newSection(name)
+
+	c.Content = append(c.Content, &section)
+
+	return &section
+}
+
+// WriteParagraph creates a new paragraph inside the collapsible and returns it for editing.
+func (c *Collapsible) WriteParagraph() *Paragraph {
+	paragraph := NewParagraph()
+
+	c.Content = append(c.Content, paragraph)
+
+	return paragraph
+}
+
+// CreateCollapsible creates a nested collapsible with the given summary and returns it for editing.
+func (c *Collapsible) CreateCollapsible(summary string) *Collapsible {
+	collapsible := NewCollapsible(summary)
+
+	c.Content = append(c.Content, collapsible)
+
+	return collapsible
+}
+
 // MARK: Section
 
 // Section represents a named container that holds various types of content elements,
@@ -199,16 +541,52 @@ type Section struct {
 	Name string
 	// Content holds all the content elements within this section
 	Content []Node
+	// Tags label every command in the section and its subsections, such as "ci-only", so runs
+	// can include or exclude them
+	Tags []string
+	// Overrides changes the runner's execution config for the commands of the section and its
+	// subsections
+	Overrides ConfigOverride
+	// Field makes the section an input of a GitHub issue form when rendered by IssueForm. Other
+	// renderers ignore it
+	Field *FormField
+
+	// origin records where the section was included from when it was produced by resolving a SectionRef
+	origin string
 }
 
-// NewSection creates a new Section with the specified name and empty content.
-func NewSection(name string) Section {
+// NewSection creates a new Section with the specified name and empty content. Leading and
+// trailing whitespace is trimmed from the name. Returns an error if the name is empty or
+// spans more than one line.
+func NewSection(name string) (Section, error) {
+	nameTrimmed, err := validateName("section", name)
+	if err != nil {
+		return Section{}, err
+	}
+
+	return newSection(nameTrimmed), nil
+}
+
+// newSection creates a section without checking its name, for builders that can't return an
+// error. Sections with invalid names are reported by Valid and Lint.
+func newSection(name string) Section {
 	return Section{
 		Name:    name,
 		Content: make([]Node, 0),
 	}
 }
 
+// MustNewSection is like NewSection but panics if the name is invalid. It is meant for
+// sections with fixed names, such as those of documents defined in code.
+func MustNewSection(name string) Section {
+	section, err := NewSection(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return section
+}
+
 // Children returns all content within the section as Node interfaces.
 func (s Section) Children() []Node { return s.Content }
 
@@ -218,11 +596,11 @@ func (s Section) Type() ContentType { return SectionType }
 // Identifier returns the section name as its identifier.
 func (s Section) Identifier() string { return s.Name }
 
+// Valid returns an error if the section name is empty or spans more than one line.
 func (s Section) Valid() error {
-	if s.Name == "" {
-		return errors.New("section name cannot be empty")
-	}
-	return nil
+	_, err := validateName("section", s.Name)
+
+	return err
 }
 
 // AddIntro prepends a paragraph to the beginning of the section content.
@@ -239,22 +617,40 @@ func (s *Section) WriteIntro() *Paragraph {
 	return paragraph
 }
 
-// AddSection appends an existing section as a subsection.
-func (s *Section) AddSection(section Section) {
+// AddSection appends an existing section as a subsection. The section is added by reference,
+// so content written to it afterwards is part of the document.
+func (s *Section) AddSection(section *Section) {
 	s.Content = append(s.Content, section)
 }
 
 // CreateSection creates a new subsection with the given name and returns it for editing.
+// Having no error to return, it does not check the name: invalid names are reported by Lint,
+// and NewSection with AddSection checks the name up front.
 func (s *Section) CreateSection(name string) *Section {
-	section := NewSection(name)
+	section := newSection(name)
 
 	s.Content = append(s.Content, &section)
 
 	return &section
 }
 
+// AddSectionRef appends a reference to a section of another document, which is
+// replaced by a copy of that section when the document is resolved.
+func (s *Section) AddSectionRef(docName, sectionPath string) {
+	s.Content = append(s.Content, SectionRef{DocName: docName, SectionPath: sectionPath})
+}
+
+// CreateCollapsible creates a new collapsible block with the given summary and returns it for editing.
+func (s *Section) CreateCollapsible(summary string) *Collapsible {
+	collapsible := NewCollapsible(summary)
+
+	s.Content = append(s.Content, collapsible)
+
+	return collapsible
+}
+
 // AddParagraph appends an existing paragraph to the section.
-func (s *Section) AddParagraph(paragraph Paragraph) {
+func (s *Section) AddParagraph(paragraph *Paragraph) {
 	s.Content = append(s.Content, paragraph)
 }
 
@@ -285,9 +681,13 @@ func (s *Section) CreateTable(headers []string) *Table {
 
 // AddList creates and adds a list of the specified type with the given items.
 func (s *Section) AddList(listType ListTypeE, items []Text) {
-	list := List{TypeOfList: listType, Items: items}
+	list := List{TypeOfList: listType, Items: make([]Node, len(items))}
+
+	for idx, item := range items {
+		list.Items[idx] = item
+	}
 
-	s.Content = append(s.Content, list)
+	s.Content = append(s.Content, &list)
 }
 
 // CreateList creates a new list of the specified type and returns it for editing.
@@ -302,22 +702,34 @@ func (s *Section) CreateList(listType ListTypeE) *List {
 // WriteCodeBlock adds either an executable or non-executable code block based on the executable parameter.
 // If executable is Exec, creates an Executable; otherwise creates a CodeBlock.
 func (s *Section) WriteCodeBlock(blockType string, cmd []string, executable CodeBlockExecType) {
-	var newContent Node
+	s.Content = append(s.Content, newCodeBlock(blockType, cmd, executable))
+}
 
+// newCodeBlock returns an Executable if executable is Exec, and a CodeBlock otherwise.
+func newCodeBlock(blockType string, cmd []string, executable CodeBlockExecType) Node {
 	if executable == Exec {
-		newContent = Executable{
+		return Executable{
 			Shell:       blockType,
 			Cmd:         cmd,
 			Environment: nil,
 		}
-	} else {
-		newContent = CodeBlock{
-			BlockType: blockType,
-			Cmd:       cmd,
-		}
 	}
 
-	s.Content = append(s.Content, newContent)
+	return CodeBlock{
+		BlockType: blockType,
+		Cmd:       cmd,
+	}
+}
+
+// AddTags labels every command in the section and its subsections with tags.
+func (s *Section) AddTags(tags ...string) {
+	s.Tags = append(s.Tags, tags...)
+}
+
+// Override sets the execution config overrides of the commands in the section and its
+// subsections, such as a longer timeout for a slow section.
+func (s *Section) Override(overrides ConfigOverride) {
+	s.Overrides = overrides
 }
 
 func (s *Section) WriteExecutable(shell string, cmd []string, env []string) {
@@ -330,11 +742,95 @@ func (s *Section) WriteExecutable(shell string, cmd []string, env []string) {
 	s.Content = append(s.Content, executable)
 }
 
+// WriteSteps adds an executable made of several commands run one after another as a single
+// logical step, such as creating a build directory and configuring it. It is rendered as one
+// code block with a command per line, and a failing step skips the steps after it.
+func (s *Section) WriteSteps(shell string, steps [][]string, env []string) {
+	s.Content = append(s.Content, Executable{
+		Shell:       shell,
+		Steps:       steps,
+		Environment: env,
+	})
+}
+
+// WriteSetup adds an executable that runs before the other commands of the section, such as
+// starting a database container. If it fails, the rest of the section is skipped.
+func (s *Section) WriteSetup(shell string, cmd []string, env []string) {
+	s.Content = append(s.Content, Executable{
+		Shell:       shell,
+		Cmd:         cmd,
+		Environment: env,
+		Phase:       PhaseSetup,
+	})
+}
+
+// WriteTeardown adds an executable that runs after the other commands of the section, even
+// when they failed, such as stopping a database container.
+func (s *Section) WriteTeardown(shell string, cmd []string, env []string) {
+	s.Content = append(s.Content, Executable{
+		Shell:       shell,
+		Cmd:         cmd,
+		Environment: env,
+		Phase:       PhaseTeardown,
+	})
+}
+
+// WritePipeline adds a pipeline that connects the output of each stage to the input of the next.
+func (s *Section) WritePipeline(stages ...Executable) {
+	s.Content = append(s.Content, Pipeline{Stages: stages})
+}
+
 // WriteBlockQuote adds a block quote with the specified content to the section.
 func (s *Section) WriteBlockQuote(value string) {
 	s.Content = append(s.Content, BlockQuote(value))
 }
 
+// WriteBlockQuoteLines adds a block quote spanning multiple lines to the section.
+// Empty lines are kept inside the quote, so paragraphs can be separated within it.
+func (s *Section) WriteBlockQuoteLines(lines ...string) {
+	s.WriteBlockQuote(strings.Join(lines, "\n"))
+}
+
+// WriteAdmonition adds a callout of the specified kind with the given body text to the section.
+// Returns an error if the kind is invalid.
+func (s *Section) WriteAdmonition(kind AdmonitionKind, text string) error {
+	if err := kind.Valid(); err != nil {
+		return err
+	}
+
+	s.Content = append(s.Content, Admonition{Kind: kind, Body: text})
+
+	return nil
+}
+
+// WriteHTML adds a raw HTML block to the section, which is rendered verbatim.
+func (s *Section) WriteHTML(content string) {
+	s.Content = append(s.Content, HTMLBlock(content))
+}
+
+// WriteMathBlock adds a display math block with the given lines to the section.
+func (s *Section) WriteMathBlock(lines []string) {
+	s.Content = append(s.Content, MathBlock{Lines: lines})
+}
+
+// WriteMermaid adds a mermaid diagram with the given lines to the section.
+// Returns an error if the diagram is empty or does not start with a known diagram type.
+func (s *Section) WriteMermaid(lines []string) error {
+	diagram := Mermaid{Lines: lines}
+	if err := diagram.Valid(); err != nil {
+		return err
+	}
+
+	s.Content = append(s.Content, diagram)
+
+	return nil
+}
+
+// WriteImage adds an image with the specified alt text, URL, and optional title to the section.
+func (s *Section) WriteImage(alt, url, title string) {
+	s.Content = append(s.Content, Image{AltText: alt, Url: url, Title: title})
+}
+
 // WriteRemoteContent adds remote content to the section.
 func (s *Section) WriteRemoteContent(remote Remote) {
 	s.Content = append(s.Content, remote)
@@ -345,6 +841,37 @@ func (s *Section) WriteComment(value string) {
 	s.Content = append(s.Content, Comment(value))
 }
 
+// FindSection returns the subsection at the path of section names below this section, such as
+// FindSection("Setup", "Linux"), for editing. See Document.FindSection.
+func (s *Section) FindSection(path ...string) (*Section, bool) {
+	return findSectionPath(s.Content, path)
+}
+
+// RemoveSection removes the first subsection with the given name. See Document.RemoveSection.
+func (s *Section) RemoveSection(name string) bool {
+	var removed bool
+	s.Content, removed = removeSection(s.Content, name)
+
+	return removed
+}
+
+// ReplaceSection puts section in place of the first subsection with the given name.
+// See Document.ReplaceSection.
+func (s *Section) ReplaceSection(name string, section *Section) error {
+	return replaceSection(s.Content, name, section)
+}
+
+// InsertSectionAt inserts section so it becomes the subsection at index. See Document.InsertSectionAt.
+func (s *Section) InsertSectionAt(index int, section *Section) error {
+	content, err := insertSectionAt(s.Content, index, section)
+	if err != nil {
+		return err
+	}
+	s.Content = content
+
+	return nil
+}
+
 // MARK: Document
 
 // Document represents the top-level container for a complete document with optional
@@ -358,12 +885,13 @@ type Document struct {
 	Content []Node
 }
 
-// NewDocument creates a new Document with the specified name and empty content.
+// NewDocument creates a new Document with the specified name and empty content. Leading and
+// trailing whitespace is trimmed from the name. Returns an error if the name is empty or spans
+// more than one line.
 func NewDocument(name string) (Document, error) {
-	nameTrimmed := strings.TrimSpace(name)
-
-	if nameTrimmed == "" {
-		return Document{}, errors.New("document name cannot be empty")
+	nameTrimmed, err := validateName("document", name)
+	if err != nil {
+		return Document{}, err
 	}
 
 	return Document{
@@ -372,6 +900,33 @@ func NewDocument(name string) (Document, error) {
 	}, nil
 }
 
+// MustNewDocument is like NewDocument but panics if the name is invalid. It is meant for
+// documents with fixed names, such as those defined in code.
+func MustNewDocument(name string) Document {
+	document, err := NewDocument(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return document
+}
+
+// validateName trims the name of a document or section, named by kind in errors, and returns
+// an error if it is empty or spans more than one line, as it is rendered as a heading.
+func validateName(kind, name string) (string, error) {
+	nameTrimmed := strings.TrimSpace(name)
+
+	if nameTrimmed == "" {
+		return "", fmt.Errorf("%s name cannot be empty", kind)
+	}
+
+	if strings.ContainsAny(nameTrimmed, "\r\n") {
+		return "", fmt.Errorf("%s name cannot span more than one line: %q", kind, nameTrimmed)
+	}
+
+	return nameTrimmed, nil
+}
+
 // Type returns the ContentType for this document element.
 func (d Document) Type() ContentType { return DocumentType }
 
@@ -381,11 +936,12 @@ func (d Document) Children() []Node { return d.Content }
 // Identifier returns the document name as its identifier.
 func (d Document) Identifier() string { return d.Name }
 
+// Valid returns an error if the document name is empty, only whitespace, or spans more than
+// one line, the same checks NewDocument makes.
 func (d Document) Valid() error {
-	if d.Name == "" {
-		return errors.New("document name cannot be empty")
-	}
-	return nil
+	_, err := validateName("document", d.Name)
+
+	return err
 }
 
 // AddIntro prepends a paragraph to the beginning of the document content.
@@ -393,9 +949,19 @@ func (d *Document) AddIntro(content *Paragraph) {
 	d.Content = append([]Node{content}, d.Content...)
 }
 
-// AddFrontmatter sets the frontmatter metadata for the document.
-func (d *Document) AddFrontmatter(f Frontmatter) {
-	d.Frontmatter = f
+// AddFrontmatter sets the frontmatter metadata for the document. When schemas are given the
+// frontmatter is validated against each first, and an error is returned without setting it
+// if it doesn't match.
+func (d *Document) AddFrontmatter(f Frontmatter, schemas ...FrontmatterSchema) error {
+	for _, schema := range schemas {
+		if err := f.Validate(schema); err != nil {
+			return err
+		}
+	}
+
+	d.Frontmatter = f
+
+	return nil
 }
 
 // HasFrontmatter returns true if the document has frontmatter data.
@@ -420,16 +986,908 @@ func (d *Document) WriteIntro() *Paragraph {
 	return paragraph
 }
 
-// AddSection appends an existing section to the document.
-func (d *Document) AddSection(section Section) {
+// AddSection appends an existing section to the document. The section is added by reference,
+// so content written to it afterwards is part of the document.
+func (d *Document) AddSection(section *Section) {
 	d.Content = append(d.Content, section)
 }
 
+// AddSectionRef appends a reference to a section of another document, which is
+// replaced by a copy of that section when the document is resolved.
+func (d *Document) AddSectionRef(docName, sectionPath string) {
+	d.Content = append(d.Content, SectionRef{DocName: docName, SectionPath: sectionPath})
+}
+
+// ErrFrontmatterConflict is returned when embedding a document whose frontmatter sets a key
+// the host document already sets to a different value, under FrontmatterConflictError.
+var ErrFrontmatterConflict = errors.New("frontmatter conflict")
+
+// FrontmatterConflictPolicy decides which value is kept when an embedded document's
+// frontmatter sets a key the host document already sets.
+type FrontmatterConflictPolicy int
+
+const (
+	// FrontmatterKeepFirst keeps the host document's value
+	FrontmatterKeepFirst FrontmatterConflictPolicy = iota + 1
+	// FrontmatterLastWins replaces the host document's value with the embedded document's
+	FrontmatterLastWins
+	// FrontmatterConflictError fails with ErrFrontmatterConflict when the values differ
+	FrontmatterConflictError
+)
+
+// String returns the name of the policy.
+func (f FrontmatterConflictPolicy) String() string {
+	switch f {
+	case FrontmatterKeepFirst:
+		return "keep-first"
+	case FrontmatterLastWins:
+		return "last-wins"
+	case FrontmatterConflictError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// EmbedOption configures how AddDocument embeds another document.
+type EmbedOption func(c *embedConfig) error
+
+type embedConfig struct {
+	mergeFrontmatter bool
+	conflictPolicy   FrontmatterConflictPolicy
+}
+
+// WithMergedFrontmatter merges the embedded document's frontmatter into the host document's.
+// Keys already set on the host document keep their values. By default the embedded
+// document's frontmatter is ignored.
+func WithMergedFrontmatter() EmbedOption {
+	return WithFrontmatterConflict(FrontmatterKeepFirst)
+}
+
+// WithFrontmatterConflict merges the embedded document's frontmatter into the host document's,
+// resolving keys set by both with policy.
+func WithFrontmatterConflict(policy FrontmatterConflictPolicy) EmbedOption {
+	return func(c *embedConfig) error {
+		if policy < FrontmatterKeepFirst || policy > FrontmatterConflictError {
+			return fmt.Errorf("invalid frontmatter conflict policy: %d", policy)
+		}
+
+		c.mergeFrontmatter = true
+		c.conflictPolicy = policy
+
+		return nil
+	}
+}
+
+// AddDocument embeds another document as a section named after it, so a document such as
+// CONTRIBUTING can be reused inside a README. The embedded document's sections are nested one
+// level deeper, and its executables are planned under their own section names. The embedded
+// content is copied, so later changes to other do not show up in the document or the reverse.
+// Returns an error if the embedded document has no name, an option is invalid, or its
+// frontmatter conflicts with the host document's under FrontmatterConflictError. On error the
+// host document is left unchanged.
+func (d *Document) AddDocument(other Document, opts ...EmbedOption) error {
+	if err := other.Valid(); err != nil {
+		return err
+	}
+
+	config := embedConfig{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return err
+		}
+	}
+
+	if config.mergeFrontmatter && other.HasFrontmatter() {
+		if err := d.mergeFrontmatter(other, config.conflictPolicy); err != nil {
+			return err
+		}
+	}
+
+	section := newSection(other.Name)
+	section.Content = append(section.Content, cloneNodes(other.Content)...)
+
+	d.Content = append(d.Content, &section)
+
+	return nil
+}
+
+// cloneNodes deep copies the containers among children, such as sections, paragraphs, and
+// lists, so the copy can be changed through their pointers without affecting the original.
+func cloneNodes(children []Node) []Node {
+	if children == nil {
+		return nil
+	}
+
+	cloned := make([]Node, len(children))
+
+	for idx, child := range children {
+		cloned[idx] = cloneNode(child)
+	}
+
+	return cloned
+}
+
+func cloneNode(node Node) Node {
+	switch n := node.(type) {
+	case Section:
+		return cloneSection(n)
+	case *Section:
+		section := cloneSection(*n)
+		return &section
+	case Collapsible:
+		n.Content = cloneNodes(n.Content)
+		return n
+	case *Collapsible:
+		return &Collapsible{Summary: n.Summary, Content: cloneNodes(n.Content)}
+	case Paragraph:
+		n.Items = cloneNodes(n.Items)
+		return n
+	case *Paragraph:
+		return &Paragraph{Items: cloneNodes(n.Items)}
+	case List:
+		return cloneList(n)
+	case *List:
+		list := cloneList(*n)
+		return &list
+	case Table:
+		return cloneTable(n)
+	case *Table:
+		table := cloneTable(*n)
+		return &table
+	}
+
+	return node
+}
+
+func cloneSection(section Section) Section {
+	section.Content = cloneNodes(section.Content)
+	section.Tags = slices.Clone(section.Tags)
+	if section.Field != nil {
+		field := *section.Field
+		section.Field = &field
+	}
+
+	return section
+}
+
+func cloneList(list List) List {
+	list.Items = cloneNodes(list.Items)
+	list.Checked = slices.Clone(list.Checked)
+
+	return list
+}
+
+func cloneTable(table Table) Table {
+	table.Headers = slices.Clone(table.Headers)
+	table.Items = slices.Clone(table.Items)
+	for idx, row := range table.Items {
+		table.Items[idx] = TableRow{Values: slices.Clone(row.Values), Cells: cloneNodes(row.Cells)}
+	}
+
+	return table
+}
+
+// mergeFrontmatter copies the frontmatter of other into the document, resolving keys set by
+// both with policy. Conflicts are checked before any key is copied.
+func (d *Document) mergeFrontmatter(other Document, policy FrontmatterConflictPolicy) error {
+	keys := slices.Sorted(maps.Keys(other.Frontmatter.Data))
+
+	if policy == FrontmatterConflictError {
+		for _, key := range keys {
+			existing, exists := d.Frontmatter.Data[key]
+			if exists && !reflect.DeepEqual(existing, other.Frontmatter.Data[key]) {
+				return fmt.Errorf("%w: key '%s' of '%s' is already set to a different value", ErrFrontmatterConflict, key, other.Name)
+			}
+		}
+	}
+
+	if d.Frontmatter.Data == nil {
+		d.Frontmatter.Data = make(map[string]interface{}, len(keys))
+	}
+
+	for _, key := range keys {
+		if _, exists := d.Frontmatter.Data[key]; exists && policy == FrontmatterKeepFirst {
+			continue
+		}
+
+		d.Frontmatter.Data[key] = other.Frontmatter.Data[key]
+	}
+
+	return nil
+}
+
+// MergeDocuments creates a document named name holding each of docs as a top-level section
+// named after it, in order, such as a handbook built from several guides. Sections of the
+// merged documents are nested one level deeper, and their executables are planned under their
+// own section names. Frontmatter is merged, failing with ErrFrontmatterConflict if two
+// documents set a key to different values. Returns an error if name or a document name is invalid.
+func MergeDocuments(name string, docs ...Document) (Document, error) {
+	return MergeDocumentsWith(name, []EmbedOption{WithFrontmatterConflict(FrontmatterConflictError)}, docs...)
+}
+
+// MergeDocumentsWith is like MergeDocuments but embeds each document with opts, such as
+// WithFrontmatterConflict(FrontmatterLastWins). Frontmatter is ignored unless opts merge it.
+func MergeDocumentsWith(name string, opts []EmbedOption, docs ...Document) (Document, error) {
+	merged, err := NewDocument(name)
+	if err != nil {
+		return Document{}, err
+	}
+
+	for idx, doc := range docs {
+		if err := merged.AddDocument(doc, opts...); err != nil {
+			return Document{}, fmt.Errorf("document %d (%s): %w", idx+1, doc.Name, err)
+		}
+	}
+
+	return merged, nil
+}
+
+// WriteTableOfContents appends a table of contents listing the document's sections, nested
+// up to maxDepth levels below the title. A maxDepth of 0 lists every level.
+func (d *Document) WriteTableOfContents(maxDepth int) {
+	d.Content = append(d.Content, TableOfContents{MaxDepth: maxDepth})
+}
+
 // CreateSection creates a new section with the given name and returns it for editing.
+// Having no error to return, it does not check the name: invalid names are reported by Lint,
+// and NewSection with AddSection checks the name up front.
 func (d *Document) CreateSection(name string) *Section {
-	s := NewSection(name)
+	s := newSection(name)
 
 	d.Content = append(d.Content, &s)
 
 	return &s
 }
+
+// WriteParagraph creates a new paragraph directly under the document title, after any content
+// already written, and returns it for editing.
+func (d *Document) WriteParagraph() *Paragraph {
+	paragraph := NewParagraph()
+
+	d.Content = append(d.Content, paragraph)
+
+	return paragraph
+}
+
+// WriteCodeBlock adds either an executable or non-executable code block directly under the
+// document title, as Section.WriteCodeBlock does. Executables are planned under the document.
+func (d *Document) WriteCodeBlock(blockType string, cmd []string, executable CodeBlockExecType) {
+	d.Content = append(d.Content, newCodeBlock(blockType, cmd, executable))
+}
+
+// CreateList creates a new list of the specified type directly under the document title and
+// returns it for editing.
+func (d *Document) CreateList(listType ListTypeE) *List {
+	list := List{TypeOfList: listType}
+
+	d.Content = append(d.Content, &list)
+
+	return &list
+}
+
+// CreateTable creates a new table with the given headers directly under the document title
+// and returns it for editing.
+func (d *Document) CreateTable(headers []string) *Table {
+	table := Table{Headers: headers, Items: make([]TableRow, 0)}
+
+	d.Content = append(d.Content, &table)
+
+	return &table
+}
+
+// WriteBlockQuote adds a block quote with the specified content directly under the document title.
+func (d *Document) WriteBlockQuote(value string) {
+	d.Content = append(d.Content, BlockQuote(value))
+}
+
+// WriteComment adds a comment directly under the document title.
+func (d *Document) WriteComment(value string) {
+	d.Content = append(d.Content, Comment(value))
+}
+
+// WriteRemoteContent adds remote content directly under the document title.
+func (d *Document) WriteRemoteContent(remote Remote) {
+	d.Content = append(d.Content, remote)
+}
+
+// FindSection returns the section at the path of section names from the top of the document,
+// such as FindSection("Development", "Setup"), so it can be edited after it was built. The
+// first section with a matching name is used at each level, and sections inside collapsibles
+// are not searched. A section added by value is replaced in its parent by a pointer to it, so
+// changes through the returned section show up when the document is rendered. Returns false if
+// the path is empty or no section matches.
+func (d *Document) FindSection(path ...string) (*Section, bool) {
+	return findSectionPath(d.Content, path)
+}
+
+// Sections returns the top-level sections of the document in order, for editing. Sections added
+// by value are replaced in the document by pointers to them, as they are by FindSection.
+func (d *Document) Sections() []*Section {
+	sections := []*Section{}
+	for idx := range d.Content {
+		if section, ok := sectionAt(d.Content, idx); ok {
+			sections = append(sections, section)
+		}
+	}
+
+	return sections
+}
+
+// RemoveSection removes the first top-level section with the given name, such as a License
+// section a template adds that internal docs don't need. Returns false if no section matches.
+func (d *Document) RemoveSection(name string) bool {
+	var removed bool
+	d.Content, removed = removeSection(d.Content, name)
+
+	return removed
+}
+
+// ReplaceSection puts section in the place of the first top-level section with the given name,
+// such as to swap a template's Installation section for a customized one. Returns an error
+// wrapping ErrSectionNotFound if no section matches.
+func (d *Document) ReplaceSection(name string, section *Section) error {
+	return replaceSection(d.Content, name, section)
+}
+
+// InsertSectionAt inserts section so it becomes the top-level section at index, counting only
+// sections: 0 places it before the first section, after any intro, and the number of sections
+// places it after the last. Returns an error if index is out of range.
+func (d *Document) InsertSectionAt(index int, section *Section) error {
+	content, err := insertSectionAt(d.Content, index, section)
+	if err != nil {
+		return err
+	}
+	d.Content = content
+
+	return nil
+}
+
+// ErrSectionNotFound is returned when no section has the name an operation looks for.
+var ErrSectionNotFound = errors.New("section not found")
+
+// sectionIndex returns the index in content of the first section with the given name, or -1.
+func sectionIndex(content []Node, name string) int {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1
+	}
+
+	for idx, child := range content {
+		if sectionName(child) == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// removeSection returns content without the first section with the given name.
+func removeSection(content []Node, name string) ([]Node, bool) {
+	idx := sectionIndex(content, name)
+	if idx < 0 {
+		return content, false
+	}
+
+	return slices.Delete(content, idx, idx+1), true
+}
+
+// replaceSection puts section in place of the first section in content with the given name.
+func replaceSection(content []Node, name string, section *Section) error {
+	idx := sectionIndex(content, name)
+	if idx < 0 {
+		return fmt.Errorf("%w: '%s'", ErrSectionNotFound, name)
+	}
+	content[idx] = section
+
+	return nil
+}
+
+// insertSectionAt returns content with section inserted before the section at index, or after
+// everything else when index is the number of sections.
+func insertSectionAt(content []Node, index int, section *Section) ([]Node, error) {
+	count := 0
+	for idx, child := range content {
+		if child.Type() != SectionType {
+			continue
+		}
+
+		if count == index {
+			return slices.Insert(content, idx, Node(section)), nil
+		}
+		count++
+	}
+
+	if index != count {
+		return content, fmt.Errorf("section index %d out of range [0, %d]", index, count)
+	}
+
+	return append(content, section), nil
+}
+
+// findSectionPath walks the path of section names down from content.
+func findSectionPath(content []Node, path []string) (*Section, bool) {
+	var found *Section
+
+	for _, name := range path {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, false
+		}
+
+		matched := false
+
+		for idx, child := range content {
+			if sectionName(child) != name {
+				continue
+			}
+
+			found, _ = sectionAt(content, idx)
+			content = found.Content
+			matched = true
+			break
+		}
+
+		if !matched {
+			return nil, false
+		}
+	}
+
+	return found, found != nil
+}
+
+// sectionName returns the name of a section node, or "" for other nodes.
+func sectionName(node Node) string {
+	switch section := node.(type) {
+	case Section:
+		return section.Name
+	case *Section:
+		return section.Name
+	}
+
+	return ""
+}
+
+// sectionAt returns the section at content[idx] for editing, replacing a section held by value
+// with a pointer to it. Returns false if the node is not a section.
+func sectionAt(content []Node, idx int) (*Section, bool) {
+	switch node := content[idx].(type) {
+	case *Section:
+		return node, true
+	case Section:
+		content[idx] = &node
+
+		return &node, true
+	default:
+		return nil, false
+	}
+}
+
+// MARK: SectionRef
+
+// SectionRefSeparator separates the names of nested sections in a SectionRef path.
+const SectionRefSeparator = "/"
+
+// SectionRef is a placeholder for a section of another document. ResolveSectionRefs replaces
+// it with a copy of the referenced section, whose headings are rebased to wherever the
+// reference appears. Rendering an unresolved SectionRef fails.
+type SectionRef struct {
+	// DocName is the name of the document that contains the referenced section
+	DocName string
+	// SectionPath is the path of section names from the top of the document to the
+	// referenced section, separated by SectionRefSeparator (e.g., "Development/Setup")
+	SectionPath string
+}
+
+// NewSectionRef creates a reference to the section at sectionPath in the named document.
+func NewSectionRef(docName, sectionPath string) SectionRef {
+	return SectionRef{DocName: docName, SectionPath: sectionPath}
+}
+
+// Type returns the ContentType for this section reference.
+func (r SectionRef) Type() ContentType { return SectionRefType }
+
+// String returns the reference as "document/section path".
+func (r SectionRef) String() string {
+	return r.DocName + SectionRefSeparator + r.SectionPath
+}
+
+// Materialize always fails, as references must be resolved before rendering.
+func (r SectionRef) Materialize() (MaterializedContent, error) {
+	return MaterializedContent{}, fmt.Errorf("unresolved section reference '%s': configure a document resolver to include sections from other documents", r)
+}
+
+// DocumentResolver looks up documents by name so section references can be resolved.
+type DocumentResolver interface {
+	Lookup(name string) (Document, bool)
+}
+
+// DocumentRegistry is a DocumentResolver backed by a map of document names to documents.
+type DocumentRegistry map[string]Document
+
+// Lookup returns the document registered under name.
+func (r DocumentRegistry) Lookup(name string) (Document, bool) {
+	document, ok := r[name]
+	return document, ok
+}
+
+// ErrDuplicateDocument is the error of adding a document to a DocumentSet under a name it
+// already holds.
+var ErrDuplicateDocument = errors.New("duplicate document name")
+
+// DocumentSetEntry is a document of a DocumentSet with the path it is rendered to.
+type DocumentSetEntry struct {
+	// Document is the document, named by its Name
+	Document Document
+	// Path is where the document is rendered to and compared with
+	Path string
+}
+
+// DocumentSet holds documents by name with the paths they are rendered to, so every one of
+// them can be rendered or compared in one call with Service.RenderAll and Service.CompareAll.
+// Documents keep the order they were added in. It is also a DocumentResolver, so the documents
+// of a set can reference each other's sections. The zero value is an empty set.
+type DocumentSet struct {
+	entries []DocumentSetEntry
+	index   map[string]int
+}
+
+// NewDocumentSet creates a set holding documents, each rendered to the path of the same index.
+// Returns an error if the numbers of documents and paths differ or a name is used twice.
+func NewDocumentSet(documents []Document, paths []string) (*DocumentSet, error) {
+	if len(documents) != len(paths) {
+		return nil, fmt.Errorf("got %d documents but %d paths", len(documents), len(paths))
+	}
+
+	set := &DocumentSet{}
+	for idx, document := range documents {
+		if err := set.Add(document, paths[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// Add adds a document rendered to path. Returns an error wrapping ErrDuplicateDocument if the
+// set already holds a document with the same name, or an error if the path is empty or the
+// name is invalid.
+func (d *DocumentSet) Add(document Document, path string) error {
+	if document.Name == "" {
+		return errors.New("document has no name")
+	}
+
+	if err := document.Valid(); err != nil {
+		return err
+	}
+
+	if path == "" {
+		return fmt.Errorf("document '%s' has no output path", document.Name)
+	}
+
+	if _, ok := d.index[document.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateDocument, document.Name)
+	}
+
+	if d.index == nil {
+		d.index = map[string]int{}
+	}
+	d.index[document.Name] = len(d.entries)
+	d.entries = append(d.entries, DocumentSetEntry{Document: document, Path: path})
+
+	return nil
+}
+
+// Entries returns the documents of the set with their paths, in the order they were added.
+func (d *DocumentSet) Entries() []DocumentSetEntry {
+	return append([]DocumentSetEntry(nil), d.entries...)
+}
+
+// Len returns the number of documents in the set.
+func (d *DocumentSet) Len() int { return len(d.entries) }
+
+// Lookup returns the document of the set named name.
+func (d *DocumentSet) Lookup(name string) (Document, bool) {
+	idx, ok := d.index[name]
+	if !ok {
+		return Document{}, false
+	}
+
+	return d.entries[idx].Document, true
+}
+
+// includedFrom returns the origin of a section produced by resolving a SectionRef.
+func includedFrom(node Node) string {
+	switch section := node.(type) {
+	case Section:
+		return section.origin
+	case *Section:
+		return section.origin
+	}
+
+	return ""
+}
+
+// findSection walks the path of section names from the top of a document.
+func findSection(document Document, sectionPath string) (Section, bool) {
+	section, err := lookupSection(document, sectionPath)
+
+	return section, err == nil
+}
+
+// lookupSection walks the path of section names from the top of a document, reporting a
+// missing section with the names of the sections available where the lookup failed.
+func lookupSection(document Document, sectionPath string) (Section, error) {
+	children := document.Content
+	var found Section
+
+	for _, name := range strings.Split(sectionPath, SectionRefSeparator) {
+		available := []string{}
+		matched := false
+
+		for _, child := range children {
+			var section Section
+			switch node := child.(type) {
+			case Section:
+				section = node
+			case *Section:
+				section = *node
+			default:
+				continue
+			}
+
+			if section.Name == strings.TrimSpace(name) {
+				found = section
+				children = section.Content
+				matched = true
+				break
+			}
+
+			available = append(available, section.Name)
+		}
+
+		if !matched {
+			if len(available) == 0 {
+				return Section{}, fmt.Errorf("section '%s' not found in document '%s': no sections available", sectionPath, document.Name)
+			}
+
+			return Section{}, fmt.Errorf("section '%s' not found in document '%s' (available: %s)", sectionPath, document.Name, strings.Join(available, ", "))
+		}
+	}
+
+	return found, nil
+}
+
+// ResolveSectionRefs returns a copy of the document in which every SectionRef in the content of the
+// document, its sections, or collapsibles is replaced by a deep copy of the referenced section. References inside
+// included sections are resolved too. Returns an error if a referenced document or section does not
+// exist, or if the references form a cycle. The original document is not modified.
+func ResolveSectionRefs(document Document, resolver DocumentResolver) (Document, error) {
+	content, err := resolveChildren(document.Content, resolver, nil)
+	if err != nil {
+		return Document{}, err
+	}
+
+	document.Content = content
+
+	return document, nil
+}
+
+func resolveChildren(children []Node, resolver DocumentResolver, stack []string) ([]Node, error) {
+	resolved := make([]Node, len(children))
+
+	for idx, child := range children {
+		node, err := resolveNode(child, resolver, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[idx] = node
+	}
+
+	return resolved, nil
+}
+
+func resolveNode(node Node, resolver DocumentResolver, stack []string) (Node, error) {
+	switch n := node.(type) {
+	case SectionRef:
+		return resolveRef(n, resolver, stack)
+	case Section:
+		content, err := resolveChildren(n.Content, resolver, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Content = content
+		return n, nil
+	case *Section:
+		content, err := resolveChildren(n.Content, resolver, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		section := *n
+		section.Content = content
+		return &section, nil
+	case Collapsible:
+		content, err := resolveChildren(n.Content, resolver, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Content = content
+		return n, nil
+	case *Collapsible:
+		content, err := resolveChildren(n.Content, resolver, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Collapsible{Summary: n.Summary, Content: content}, nil
+	}
+
+	return node, nil
+}
+
+func resolveRef(ref SectionRef, resolver DocumentResolver, stack []string) (Node, error) {
+	key := ref.String()
+
+	for _, seen := range stack {
+		if seen == key {
+			return nil, fmt.Errorf("section reference cycle: %s -> %s", strings.Join(stack, " -> "), key)
+		}
+	}
+
+	document, ok := resolver.Lookup(ref.DocName)
+	if !ok {
+		return nil, fmt.Errorf("section reference '%s': document '%s' not found", key, ref.DocName)
+	}
+
+	section, ok := findSection(document, ref.SectionPath)
+	if !ok {
+		return nil, fmt.Errorf("section reference '%s': section '%s' not found in document '%s'", key, ref.SectionPath, ref.DocName)
+	}
+
+	content, err := resolveChildren(section.Content, resolver, append(stack[:len(stack):len(stack)], key))
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := Section{Name: section.Name, Content: content, Tags: section.Tags, Overrides: section.Overrides, origin: key}
+	if section.Field != nil {
+		field := *section.Field
+		resolved.Field = &field
+	}
+
+	return resolved, nil
+}
+
+// MARK: Anchors
+
+// sectionAnchor records a heading produced by the document tree along with its anchor slug.
+type sectionAnchor struct {
+	// Name is the section name, which cross references target
+	Name string
+	// Heading is the text of the rendered heading, which includes the section number when numbered
+	Heading string
+	Slug    string
+	Level   int
+	// Standalone is set for headings written as Header content rather than by a section
+	Standalone bool
+}
+
+// slugify converts heading text into a GitHub-compatible anchor: lowercased, with
+// punctuation removed and spaces replaced by dashes.
+func slugify(heading string) string {
+	var builder strings.Builder
+
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			builder.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// slugger hands out unique slugs, suffixing repeated headings with -1, -2, and so on
+// the same way GitHub does.
+type slugger map[string]int
+
+func (s slugger) slug(heading string) string {
+	base := slugify(heading)
+	slug := base
+
+	for {
+		if _, seen := s[slug]; !seen {
+			break
+		}
+
+		s[base]++
+		slug = fmt.Sprintf("%s-%d", base, s[base])
+	}
+
+	s[slug] = 0
+
+	return slug
+}
+
+// collectAnchors walks the tree in render order and returns every heading it produces,
+// with levels matching those the markdown renderer assigns. When numbered is set, section
+// headings carry the same dotted numbers the renderer prefixes them with.
+func collectAnchors(node Node, numbered bool) []sectionAnchor {
+	var anchors []sectionAnchor
+	slugs := slugger{}
+
+	// siblings counts the sections numbered so far under the enclosing document or section,
+	// and is nil for the root, which is never numbered
+	var walk func(node Node, level int, number string, siblings *int)
+	walk = func(node Node, level int, number string, siblings *int) {
+		switch node.Type() {
+		case DocumentType, SectionType:
+			structure := node.(Structurer)
+			heading := structure.Identifier()
+
+			if numbered && node.Type() == SectionType && siblings != nil {
+				*siblings++
+				number = childSectionNumber(number, *siblings)
+				heading = fmt.Sprintf("%s %s", number, heading)
+			}
+
+			anchors = append(anchors, sectionAnchor{
+				Name:    structure.Identifier(),
+				Heading: heading,
+				Slug:    slugs.slug(heading),
+				Level:   level + 1,
+			})
+
+			var children int
+			for _, child := range structure.Children() {
+				walk(child, level+1, number, &children)
+			}
+
+			return
+		case HeaderType:
+			if content, ok := node.(Contenter); ok {
+				if materialized, err := content.Materialize(); err == nil {
+					anchors = append(anchors, sectionAnchor{
+						Name:       materialized.Content,
+						Heading:    materialized.Content,
+						Slug:       slugs.slug(materialized.Content),
+						Level:      level,
+						Standalone: true,
+					})
+				}
+			}
+
+			return
+		}
+
+		if structure, ok := node.(Structurer); ok {
+			for _, child := range structure.Children() {
+				walk(child, level, number, siblings)
+			}
+		}
+	}
+
+	walk(node, 0, "", nil)
+
+	return anchors
+}
+
+// Slugs returns the anchor slugs for every heading in the document, starting with the
+// document title, in the order the headings appear when rendered. Repeated headings
+// receive -1, -2, ... suffixes, matching the anchors GitHub generates.
+func (d Document) Slugs() []string {
+	anchors := collectAnchors(d, false)
+
+	slugs := make([]string, len(anchors))
+	for idx, anchor := range anchors {
+		slugs[idx] = anchor.Slug
+	}
+
+	return slugs
+}
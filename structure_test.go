@@ -1,6 +1,7 @@
 package doyoucompute
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -91,6 +92,252 @@ func TestTableAddRow(t *testing.T) {
 	}
 }
 
+func TestTableAddRichRow(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       []string
+		cells        []Node
+		errorMessage string
+	}{
+		{
+			name:         "Pass-RichRow",
+			header:       []string{"flag", "docs"},
+			cells:        []Node{Code("--force"), Link{Text: "render", Url: "#render"}},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-RowTooLong",
+			header:       []string{"flag"},
+			cells:        []Node{Code("--force"), Text("extra")},
+			errorMessage: "Row length exceeds number of headers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Table {
+					table := NewTable(tc.header, []TableRow{})
+					table.AddRow("plain", "row")
+
+					return table
+				},
+				func(table *Table) ([]TableRow, error) {
+					err := table.AddRichRow(tc.cells...)
+
+					return table.Items, err
+				},
+				tc.errorMessage,
+				func(rows []TableRow, table *Table, t *testing.T) {
+					if len(rows) != 2 {
+						t.Fatalf("Expected 2 rows, found %d", len(rows))
+					}
+
+					if rows[0].Rich() || !rows[1].Rich() {
+						t.Errorf("Expected only the second row to be rich")
+					}
+
+					if !reflect.DeepEqual(rows[1].Cells, tc.cells) {
+						t.Errorf("Expected cells %v, got %v", tc.cells, rows[1].Cells)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestTableAddTableRow(t *testing.T) {
+	tests := []struct {
+		name         string
+		row          TableRow
+		errorMessage string
+	}{
+		{
+			name:         "Pass-Values",
+			row:          TableRow{Values: []string{"--force", "overwrite files"}},
+			errorMessage: "",
+		},
+		{
+			name:         "Pass-Cells",
+			row:          TableRow{Cells: []Node{Code("--force"), Text("overwrite files")}},
+			errorMessage: "",
+		},
+		{
+			name:         "Pass-FewerColumns",
+			row:          TableRow{Values: []string{"--force"}},
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-ValuesTooLong",
+			row:          TableRow{Values: []string{"--force", "overwrite files", "extra"}},
+			errorMessage: "Row length exceeds number of headers",
+		},
+		{
+			name:         "Fail-CellsTooLong",
+			row:          TableRow{Cells: []Node{Code("--force"), Text("overwrite files"), Text("extra")}},
+			errorMessage: "Row length exceeds number of headers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table := NewTable([]string{"flag", "usage"}, []TableRow{})
+
+			err := table.AddTableRow(tc.row)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				if !errors.Is(err, ErrRowTooLong) {
+					t.Errorf("Expected error to be ErrRowTooLong, got %v", err)
+				}
+				if len(table.Items) != 0 {
+					t.Errorf("Expected no rows after error, found %d", len(table.Items))
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(table.Items, []TableRow{tc.row}) {
+				t.Errorf("Expected rows %v, got %v", []TableRow{tc.row}, table.Items)
+			}
+		})
+	}
+}
+
+func TestTableAddRows(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         [][]string
+		expected     int
+		errorMessage string
+	}{
+		{
+			name:         "Pass-SeveralRows",
+			rows:         [][]string{{"a", "b"}, {"c"}, {"d", "e"}},
+			expected:     4,
+			errorMessage: "",
+		},
+		{
+			name:         "Pass-NoRows",
+			rows:         nil,
+			expected:     1,
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-RowTooLongAddsNothing",
+			rows:         [][]string{{"a", "b"}, {"c", "d", "e"}},
+			expected:     1,
+			errorMessage: "row 2: Row length exceeds number of headers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table := NewTable([]string{"first", "second"}, []TableRow{})
+			table.AddRow("existing", "row")
+
+			err := table.AddRows(tc.rows)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" && !errors.Is(err, ErrRowTooLong) {
+				t.Errorf("Expected error to wrap ErrRowTooLong, got %v", err)
+			}
+
+			if len(table.Items) != tc.expected {
+				t.Fatalf("Expected %d rows, found %d", tc.expected, len(table.Items))
+			}
+
+			for idx, row := range tc.rows {
+				if tc.errorMessage == "" && !reflect.DeepEqual(table.Items[idx+1].Values, row) {
+					t.Errorf("Expected row %d to be %v, got %v", idx+1, row, table.Items[idx+1].Values)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTableFromCSV(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		options         []CSVOption
+		expectedHeaders []string
+		expectedRows    [][]string
+		errorMessage    string
+	}{
+		{
+			name:            "Pass-QuotedFields",
+			input:           "name,usage\nrender,\"Render a document, as markdown\"\nplan,\"a | b\"\n",
+			expectedHeaders: []string{"name", "usage"},
+			expectedRows:    [][]string{{"render", "Render a document, as markdown"}, {"plan", "a | b"}},
+			errorMessage:    "",
+		},
+		{
+			name:            "Pass-TSV",
+			input:           "name\tusage\nrender\tRender, then save\n",
+			options:         []CSVOption{WithCSVDelimiter('\t')},
+			expectedHeaders: []string{"name", "usage"},
+			expectedRows:    [][]string{{"render", "Render, then save"}},
+			errorMessage:    "",
+		},
+		{
+			name:            "Pass-ExplicitHeaders",
+			input:           "render,\"x | y\"\nplan,z\n",
+			options:         []CSVOption{WithCSVHeaders("Command", "Notes")},
+			expectedHeaders: []string{"Command", "Notes"},
+			expectedRows:    [][]string{{"render", "x | y"}, {"plan", "z"}},
+			errorMessage:    "",
+		},
+		{
+			name:            "Pass-PadShortRecords",
+			input:           "a,b,c\n1\n1,2,3\n",
+			options:         []CSVOption{WithCSVPadding(true)},
+			expectedHeaders: []string{"a", "b", "c"},
+			expectedRows:    [][]string{{"1", "", ""}, {"1", "2", "3"}},
+			errorMessage:    "",
+		},
+		{
+			name:         "Fail-RaggedRecord",
+			input:        "a,b\n1,2\n1,2,3\n",
+			errorMessage: "CSV record 3 has 3 fields, but the table has 2 headers",
+		},
+		{
+			name:         "Fail-Empty",
+			input:        "",
+			errorMessage: "CSV input has no header record",
+		},
+		{
+			name:         "Fail-Malformed",
+			input:        "a,b\n\"unterminated,2\n",
+			errorMessage: "reading CSV: parse error on line 2, column 17: extraneous or missing \" in quoted-field",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := NewTableFromCSV(strings.NewReader(tc.input), tc.options...)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if !reflect.DeepEqual(table.Headers, tc.expectedHeaders) {
+				t.Errorf("Expected headers %v, got %v", tc.expectedHeaders, table.Headers)
+			}
+
+			rows := make([][]string, len(table.Items))
+			for idx, row := range table.Items {
+				rows[idx] = row.Values
+			}
+
+			if !reflect.DeepEqual(rows, tc.expectedRows) {
+				t.Errorf("Expected rows %v, got %v", tc.expectedRows, rows)
+			}
+		})
+	}
+}
+
 func TestTableChildren(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -250,7 +497,7 @@ func TestListPush(t *testing.T) {
 			testOperation(
 				t,
 				func() *List {
-					initialItems := make([]Text, tc.startingLength)
+					initialItems := make([]Node, tc.startingLength)
 
 					for idx := range tc.startingLength {
 						initialItems[idx] = Text(fmt.Sprintf("item-%d", idx))
@@ -263,7 +510,7 @@ func TestListPush(t *testing.T) {
 				func(list *List) (Text, error) {
 					list.Push(tc.newItem)
 
-					return list.Items[0], nil
+					return list.Items[0].(Text), nil
 				},
 				tc.errorMessage,
 				func(firstItem Text, list *List, t *testing.T) {
@@ -306,7 +553,7 @@ func TestListAppend(t *testing.T) {
 			testOperation(
 				t,
 				func() *List {
-					initialItems := make([]Text, tc.startingLength)
+					initialItems := make([]Node, tc.startingLength)
 
 					for idx := range tc.startingLength {
 						initialItems[idx] = Text(fmt.Sprintf("item-%d", idx))
@@ -319,7 +566,7 @@ func TestListAppend(t *testing.T) {
 				func(list *List) (Text, error) {
 					list.Append(tc.newItem)
 
-					return list.Items[len(list.Items)-1], nil
+					return list.Items[len(list.Items)-1].(Text), nil
 				},
 				tc.errorMessage,
 				func(firstItem Text, list *List, t *testing.T) {
@@ -336,6 +583,73 @@ func TestListAppend(t *testing.T) {
 	}
 }
 
+func TestListAppendTask(t *testing.T) {
+	tests := []struct {
+		name           string
+		startingLength int
+		newItem        string
+		checked        bool
+		errorMessage   string
+	}{
+		{
+			name:           "Pass-Checked",
+			startingLength: 3,
+			newItem:        "done item",
+			checked:        true,
+			errorMessage:   "",
+		},
+		{
+			name:           "Pass-Unchecked",
+			startingLength: 0,
+			newItem:        "write tests",
+			checked:        false,
+			errorMessage:   "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *List {
+					list := NewList(TASK)
+
+					for idx := range tc.startingLength {
+						list.Append(fmt.Sprintf("item-%d", idx))
+					}
+
+					return list
+				},
+				func(list *List) (Text, error) {
+					list.AppendTask(tc.newItem, tc.checked)
+
+					return list.Items[len(list.Items)-1].(Text), nil
+				},
+				tc.errorMessage,
+				func(lastItem Text, list *List, t *testing.T) {
+					if len(list.Items) != tc.startingLength+1 {
+						t.Errorf("Expected to have %d items, found %d", tc.startingLength+1, len(list.Items))
+					}
+
+					if lastItem != Text(tc.newItem) {
+						t.Errorf("Expected last item to be %v, found %v", Text(tc.newItem), lastItem)
+					}
+
+					if list.IsChecked(len(list.Items)-1) != tc.checked {
+						t.Errorf("Expected last item checked to be %t", tc.checked)
+					}
+
+					for idx := range tc.startingLength {
+						if list.IsChecked(idx) {
+							t.Errorf("Expected item %d to be unchecked", idx)
+						}
+					}
+				},
+			)
+		})
+	}
+}
+
 func TestListIdentifier(t *testing.T) {
 	list := NewList(BULLET)
 
@@ -536,6 +850,57 @@ func TestParagraphCode(t *testing.T) {
 		})
 	}
 }
+func TestParagraphEmphasis(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     func(p *Paragraph) *Paragraph
+		expectedItems []Node
+		errorMessage  string
+	}{
+		{
+			name: "Pass-Bold",
+			operation: func(p *Paragraph) *Paragraph {
+				return p.Text("Sik").Bold("text").Code("go vet")
+			},
+			expectedItems: []Node{Text("Sik"), Bold("text"), Code("go vet")},
+		},
+		{
+			name: "Pass-Italic",
+			operation: func(p *Paragraph) *Paragraph {
+				return p.Italic("Sik").Link("text", "https://example.com")
+			},
+			expectedItems: []Node{Italic("Sik"), Link{Text: "text", Url: "https://example.com"}},
+		},
+		{
+			name: "Pass-Strikethrough",
+			operation: func(p *Paragraph) *Paragraph {
+				return p.Text("Sik").Strikethrough("--old").Link("text", "https://example.com")
+			},
+			expectedItems: []Node{Text("Sik"), Strikethrough("--old"), Link{Text: "text", Url: "https://example.com"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Paragraph {
+					return NewParagraph()
+				},
+				func(p *Paragraph) ([]Node, error) {
+					return tc.operation(p).Children(), nil
+				},
+				tc.errorMessage,
+				func(result []Node, paragraph *Paragraph, t *testing.T) {
+					if !reflect.DeepEqual(result, tc.expectedItems) {
+						t.Errorf("Expected items %v, got %v", tc.expectedItems, result)
+					}
+				},
+			)
+		})
+	}
+}
+
 func TestParagraphLink(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -610,25 +975,27 @@ func TestParagraphLink(t *testing.T) {
 	}
 }
 
-// MARK: Section
-func TestSectionChildren(t *testing.T) {
+func TestParagraphImage(t *testing.T) {
 	tests := []struct {
-		name         string
-		sectionName  string
-		numChildren  int
-		errorMessage string
+		name          string
+		alt           string
+		url           string
+		existingItems int
+		errorMessage  string
 	}{
 		{
-			name:         "Pass-SomeChildren",
-			sectionName:  "Cool Section",
-			numChildren:  10,
-			errorMessage: "",
+			name:          "Pass-NoExistingItems",
+			alt:           "Build status",
+			url:           "https://example.com/badge.svg",
+			existingItems: 0,
+			errorMessage:  "",
 		},
 		{
-			name:         "Pass-NoChildren",
-			sectionName:  "Very Cool Section",
-			numChildren:  0,
-			errorMessage: "",
+			name:          "Pass-SomeItems",
+			alt:           "Coverage",
+			url:           "https://example.com/coverage.svg",
+			existingItems: 3,
+			errorMessage:  "",
 		},
 	}
 
@@ -636,26 +1003,38 @@ func TestSectionChildren(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			testOperation(
 				t,
-				func() *Section {
-					section := NewSection(tc.sectionName)
+				func() *Paragraph {
+					para := NewParagraph()
 
-					for idx := range tc.numChildren {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+					for idx := range tc.existingItems {
+						para.Text(fmt.Sprintf("Text idx %d", idx))
 					}
 
-					return &section
+					return para
 				},
-				func(p *Section) ([]Node, error) {
+				func(p *Paragraph) ([]Node, error) {
+					p = p.Image(tc.alt, tc.url)
+
 					return p.Children(), nil
 				},
 				tc.errorMessage,
-				func(result []Node, paragraph *Section, t *testing.T) {
-					if len(paragraph.Content) != tc.numChildren {
-						t.Errorf("Expected %d children, found %d", tc.numChildren, len(paragraph.Content))
+				func(result []Node, paragraph *Paragraph, t *testing.T) {
+					if len(paragraph.Items) != tc.existingItems+1 {
+						t.Errorf("Expected %d children, got %d", tc.existingItems+1, len(paragraph.Children()))
 					}
 
-					if !reflect.DeepEqual(result, paragraph.Content) {
-						t.Errorf("Expected result %v, got %v", paragraph.Content, result)
+					lastItem := result[len(paragraph.Children())-1]
+
+					if lastItem.Type() != ImageType {
+						t.Errorf("Expected error type to be %d got %d", ImageType, lastItem.Type())
+					}
+
+					image := lastItem.(Image)
+					if image.AltText != tc.alt {
+						t.Errorf("Expected alt text %s, got %s", tc.alt, image.AltText)
+					}
+					if image.Url != tc.url {
+						t.Errorf("Expected url %s, got %s", tc.url, image.Url)
 					}
 				},
 			)
@@ -663,12 +1042,144 @@ func TestSectionChildren(t *testing.T) {
 	}
 }
 
-func TestSectionAddIntro(t *testing.T) {
+// MARK: Section
+func TestNewSection(t *testing.T) {
 	tests := []struct {
-		name          string
-		introContent  string
-		existingItems int
-		errorMessage  string
+		name         string
+		sectionName  string
+		expected     string
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			sectionName:  "Quick Start",
+			expected:     "Quick Start",
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-TrimsWhitespace",
+			sectionName:  "  Quick Start\n",
+			expected:     "Quick Start",
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-Empty",
+			sectionName:  "",
+			errorMessage: "section name cannot be empty",
+		},
+		{
+			name:         "Fail-Whitespace",
+			sectionName:  " \t ",
+			errorMessage: "section name cannot be empty",
+		},
+		{
+			name:         "Fail-MultiLine",
+			sectionName:  "Quick\nStart",
+			errorMessage: `section name cannot span more than one line: "Quick\nStart"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			section, err := NewSection(tc.sectionName)
+
+			checkErrors(tc.errorMessage, err, t)
+			if section.Name != tc.expected {
+				t.Errorf("Expected name %q, got %q", tc.expected, section.Name)
+			}
+
+			if tc.errorMessage == "" {
+				if err := section.Valid(); err != nil {
+					t.Errorf("Expected section to be valid, got %s", err.Error())
+				}
+				return
+			}
+
+			if err := (Section{Name: tc.sectionName}).Valid(); err == nil || err.Error() != tc.errorMessage {
+				t.Errorf("Expected Valid to return %q, got %v", tc.errorMessage, err)
+			}
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected MustNewSection to panic")
+				}
+			}()
+			MustNewSection(tc.sectionName)
+		})
+	}
+}
+
+func TestSectionFactoryInvalidName(t *testing.T) {
+	called := false
+	_, err := SectionFactory("", func(s *Section) error {
+		called = true
+		return nil
+	})
+
+	checkErrors("section name cannot be empty", err, t)
+	if called {
+		t.Errorf("Expected builders not to run for an invalid name")
+	}
+}
+
+func TestSectionChildren(t *testing.T) {
+	tests := []struct {
+		name         string
+		sectionName  string
+		numChildren  int
+		errorMessage string
+	}{
+		{
+			name:         "Pass-SomeChildren",
+			sectionName:  "Cool Section",
+			numChildren:  10,
+			errorMessage: "",
+		},
+		{
+			name:         "Pass-NoChildren",
+			sectionName:  "Very Cool Section",
+			numChildren:  0,
+			errorMessage: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Section {
+					section := MustNewSection(tc.sectionName)
+
+					for idx := range tc.numChildren {
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
+					}
+
+					return &section
+				},
+				func(p *Section) ([]Node, error) {
+					return p.Children(), nil
+				},
+				tc.errorMessage,
+				func(result []Node, paragraph *Section, t *testing.T) {
+					if len(paragraph.Content) != tc.numChildren {
+						t.Errorf("Expected %d children, found %d", tc.numChildren, len(paragraph.Content))
+					}
+
+					if !reflect.DeepEqual(result, paragraph.Content) {
+						t.Errorf("Expected result %v, got %v", paragraph.Content, result)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestSectionAddIntro(t *testing.T) {
+	tests := []struct {
+		name          string
+		introContent  string
+		existingItems int
+		errorMessage  string
 	}{
 		{
 			name:          "Passing-NoItems",
@@ -689,10 +1200,10 @@ func TestSectionAddIntro(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -754,10 +1265,10 @@ func TestSectionWriteIntro(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -818,16 +1329,16 @@ func TestSectionAddSection(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
 				},
 				func(s *Section) ([]Node, error) {
-					s.AddSection(NewSection(tc.sectionName))
+					s.AddSection(&Section{Name: tc.sectionName})
 
 					return s.Children(), nil
 				},
@@ -843,8 +1354,8 @@ func TestSectionAddSection(t *testing.T) {
 						t.Errorf("Expected error type to be %d got %d", SectionType, lastItem.Type())
 					}
 
-					if lastItem.(Section).Name != tc.sectionName {
-						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(Section).Name)
+					if lastItem.(*Section).Name != tc.sectionName {
+						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(*Section).Name)
 					}
 				},
 			)
@@ -878,10 +1389,10 @@ func TestSectionCreateSection(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -904,7 +1415,7 @@ func TestSectionCreateSection(t *testing.T) {
 					}
 
 					if lastItem.(*Section).Name != tc.sectionName {
-						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(Section).Name)
+						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(*Section).Name)
 					}
 				},
 			)
@@ -938,10 +1449,10 @@ func TestSectionWriteParagraph(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1008,10 +1519,10 @@ func TestSectionAddTable(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1067,10 +1578,10 @@ func TestSectionCreateTable(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1127,10 +1638,10 @@ func TestSectionAddList(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1183,10 +1694,10 @@ func TestSectionCreateList(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1253,10 +1764,10 @@ func TestSectionWriteCodeBlock(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1317,10 +1828,10 @@ func TestSectionWriteBlockQuote(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1347,6 +1858,329 @@ func TestSectionWriteBlockQuote(t *testing.T) {
 	}
 }
 
+func TestDocumentAddDocument(t *testing.T) {
+	newContributing := func() Document {
+		document := Document{Name: "Contributing"}
+		document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"title": "Contributing", "owner": "docs"}})
+		document.WriteIntro().Text("Thanks for helping out.")
+		document.CreateSection("Testing").WriteExecutable("bash", []string{"go", "test", "./..."}, []string{})
+
+		return document
+	}
+
+	tests := []struct {
+		name                string
+		embedded            Document
+		options             []EmbedOption
+		expectedFrontmatter map[string]interface{}
+		errorMessage        string
+	}{
+		{
+			name:                "Passing-IgnoresFrontmatter",
+			embedded:            newContributing(),
+			expectedFrontmatter: map[string]interface{}{"title": "README"},
+		},
+		{
+			name:                "Passing-MergesFrontmatter",
+			embedded:            newContributing(),
+			options:             []EmbedOption{WithMergedFrontmatter()},
+			expectedFrontmatter: map[string]interface{}{"title": "README", "owner": "docs"},
+		},
+		{
+			name:                "Passing-LastWins",
+			embedded:            newContributing(),
+			options:             []EmbedOption{WithFrontmatterConflict(FrontmatterLastWins)},
+			expectedFrontmatter: map[string]interface{}{"title": "Contributing", "owner": "docs"},
+		},
+		{
+			name:                "Failing-Conflict",
+			embedded:            newContributing(),
+			options:             []EmbedOption{WithFrontmatterConflict(FrontmatterConflictError)},
+			expectedFrontmatter: map[string]interface{}{"title": "README"},
+			errorMessage:        "frontmatter conflict: key 'title' of 'Contributing' is already set to a different value",
+		},
+		{
+			name:                "Failing-InvalidPolicy",
+			embedded:            newContributing(),
+			options:             []EmbedOption{WithFrontmatterConflict(0)},
+			expectedFrontmatter: map[string]interface{}{"title": "README"},
+			errorMessage:        "invalid frontmatter conflict policy: 0",
+		},
+		{
+			name:                "Failing-NoName",
+			embedded:            Document{},
+			expectedFrontmatter: map[string]interface{}{"title": "README"},
+			errorMessage:        "document name cannot be empty",
+		},
+		{
+			name:                "Failing-MultiLineName",
+			embedded:            Document{Name: "Contributing\nGuide"},
+			expectedFrontmatter: map[string]interface{}{"title": "README"},
+			errorMessage:        `document name cannot span more than one line: "Contributing\nGuide"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := Document{Name: "README"}
+			document.AddFrontmatter(Frontmatter{Data: map[string]interface{}{"title": "README"}})
+			document.CreateSection("Usage")
+
+			err := document.AddDocument(tc.embedded, tc.options...)
+
+			checkErrors(tc.errorMessage, err, t)
+			if !reflect.DeepEqual(document.Frontmatter.Data, tc.expectedFrontmatter) {
+				t.Errorf("Expected frontmatter %v, got %v", tc.expectedFrontmatter, document.Frontmatter.Data)
+			}
+
+			if tc.errorMessage != "" {
+				if len(document.Content) != 1 {
+					t.Errorf("Expected 1 child, found %d", len(document.Content))
+				}
+				return
+			}
+
+			if len(document.Content) != 2 {
+				t.Fatalf("Expected 2 children, found %d", len(document.Content))
+			}
+
+			embedded, ok := document.Content[1].(*Section)
+			if !ok {
+				t.Fatalf("Expected *Section, got %T", document.Content[1])
+			}
+
+			if embedded.Name != tc.embedded.Name {
+				t.Errorf("Expected section name %s, got %s", tc.embedded.Name, embedded.Name)
+			}
+
+			if !reflect.DeepEqual(embedded.Content, tc.embedded.Content) {
+				t.Errorf("Expected section content %v, got %v", tc.embedded.Content, embedded.Content)
+			}
+		})
+	}
+}
+
+func TestDocumentAddDocumentCopiesContent(t *testing.T) {
+	contributing := Document{Name: "Contributing"}
+	original := contributing.CreateSection("Testing")
+	original.WriteParagraph().Text("Run the tests.")
+
+	document := Document{Name: "README"}
+	if err := document.AddDocument(contributing); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	original.WriteParagraph().Text("Added to the original.")
+
+	embedded, ok := document.FindSection("Contributing", "Testing")
+	if !ok {
+		t.Fatalf("Expected embedded section Testing")
+	}
+
+	if len(embedded.Content) != 1 {
+		t.Errorf("Expected changes to the original to stay out of the document, found %d children", len(embedded.Content))
+	}
+
+	embedded.Content[0].(*Paragraph).Text(" Changed in the document.")
+
+	if len(original.Content[0].(*Paragraph).Items) != 1 {
+		t.Errorf("Expected changes to the document to stay out of the original")
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	newGuide := func(name string, frontmatter map[string]interface{}) Document {
+		document := MustNewDocument(name)
+		if frontmatter != nil {
+			document.AddFrontmatter(Frontmatter{Data: frontmatter})
+		}
+		document.WriteIntro().Text("An intro.")
+		document.CreateSection("Setup").CreateSection("Linux").WriteParagraph().Text("Install it.")
+
+		return document
+	}
+
+	tests := []struct {
+		name                string
+		merge               func() (Document, error)
+		expectedFrontmatter map[string]interface{}
+		errorMessage        string
+	}{
+		{
+			name: "Passing-NoFrontmatter",
+			merge: func() (Document, error) {
+				return MergeDocuments("Handbook", newGuide("Install", nil), newGuide("Deploy", nil))
+			},
+			expectedFrontmatter: nil,
+		},
+		{
+			name: "Passing-MergesFrontmatter",
+			merge: func() (Document, error) {
+				return MergeDocuments("Handbook",
+					newGuide("Install", map[string]interface{}{"owner": "docs"}),
+					newGuide("Deploy", nil),
+				)
+			},
+			expectedFrontmatter: map[string]interface{}{"owner": "docs"},
+		},
+		{
+			name: "Passing-SameValueIsNotAConflict",
+			merge: func() (Document, error) {
+				return MergeDocuments("Handbook",
+					newGuide("Install", map[string]interface{}{"owner": "docs"}),
+					newGuide("Deploy", map[string]interface{}{"owner": "docs", "team": "ops"}),
+				)
+			},
+			expectedFrontmatter: map[string]interface{}{"owner": "docs", "team": "ops"},
+		},
+		{
+			name: "Passing-LastWins",
+			merge: func() (Document, error) {
+				return MergeDocumentsWith("Handbook", []EmbedOption{WithFrontmatterConflict(FrontmatterLastWins)},
+					newGuide("Install", map[string]interface{}{"owner": "docs"}),
+					newGuide("Deploy", map[string]interface{}{"owner": "ops"}),
+				)
+			},
+			expectedFrontmatter: map[string]interface{}{"owner": "ops"},
+		},
+		{
+			name: "Failing-Conflict",
+			merge: func() (Document, error) {
+				return MergeDocuments("Handbook",
+					newGuide("Install", map[string]interface{}{"owner": "docs"}),
+					newGuide("Deploy", map[string]interface{}{"owner": "ops"}),
+				)
+			},
+			errorMessage: "document 2 (Deploy): frontmatter conflict: key 'owner' of 'Deploy' is already set to a different value",
+		},
+		{
+			name: "Failing-EmptyName",
+			merge: func() (Document, error) {
+				return MergeDocuments(" ", newGuide("Install", nil))
+			},
+			errorMessage: "document name cannot be empty",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := tc.merge()
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				if strings.Contains(tc.errorMessage, "conflict") && !errors.Is(err, ErrFrontmatterConflict) {
+					t.Errorf("Expected error to wrap ErrFrontmatterConflict, got %v", err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(merged.Frontmatter.Data, tc.expectedFrontmatter) {
+				t.Errorf("Expected frontmatter %v, got %v", tc.expectedFrontmatter, merged.Frontmatter.Data)
+			}
+
+			expected := []string{"# Handbook", "## Install", "An intro.", "### Setup", "#### Linux", "## Deploy", "An intro.", "### Setup", "#### Linux"}
+			if outline := renderedOutline(t, &merged); !reflect.DeepEqual(outline, expected) {
+				t.Errorf("Expected outline %q, got %q", expected, outline)
+			}
+		})
+	}
+}
+
+func TestDocumentSlugs(t *testing.T) {
+	tests := []struct {
+		name     string
+		document func() Document
+		expected []string
+	}{
+		{
+			name: "Passing-DuplicateNames",
+			document: func() Document {
+				document := Document{Name: "Setup"}
+				linux := document.CreateSection("Linux")
+				linux.CreateSection("Setup")
+				linux.CreateSection("Setup")
+				document.CreateSection("Setup-1")
+				document.CreateSection("Linux")
+
+				return document
+			},
+			expected: []string{"setup", "linux", "setup-1", "setup-2", "setup-1-1", "linux-1"},
+		},
+		{
+			name: "Passing-Punctuation",
+			document: func() Document {
+				document := Document{Name: "doyoucompute"}
+				document.CreateSection("What's new?")
+				document.CreateSection("C++ & Go: a comparison")
+				document.CreateSection("snake_case and kebab-case")
+				document.CreateSection("Ünïcödé Heading")
+				collapsible := document.CreateSection("FAQ").CreateCollapsible("More")
+				collapsible.CreateSection("Why `make validate`?")
+
+				return document
+			},
+			expected: []string{
+				"doyoucompute",
+				"whats-new",
+				"c--go-a-comparison",
+				"snake_case-and-kebab-case",
+				"ünïcödé-heading",
+				"faq",
+				"why-make-validate",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slugs := tc.document().Slugs()
+
+			if !reflect.DeepEqual(slugs, tc.expected) {
+				t.Errorf("Expected slugs %v, got %v", tc.expected, slugs)
+			}
+		})
+	}
+}
+
+func TestSectionWriteBlockQuoteLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected BlockQuote
+	}{
+		{
+			name:     "Passing-SingleLine",
+			lines:    []string{"Cool quote"},
+			expected: BlockQuote("Cool quote"),
+		},
+		{
+			name:     "Passing-BlankLines",
+			lines:    []string{"First", "", "Second"},
+			expected: BlockQuote("First\n\nSecond"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			section := MustNewSection("test")
+			section.WriteBlockQuoteLines(tc.lines...)
+
+			if len(section.Content) != 1 {
+				t.Fatalf("Expected 1 child, found %d", len(section.Content))
+			}
+
+			quote, ok := section.Content[0].(BlockQuote)
+			if !ok {
+				t.Fatalf("Expected BlockQuote, got %T", section.Content[0])
+			}
+
+			if quote != tc.expected {
+				t.Errorf("Expected quote %q, got %q", tc.expected, quote)
+			}
+		})
+	}
+}
+
 func TestSectionWriteRemoteContent(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1373,10 +2207,10 @@ func TestSectionWriteRemoteContent(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
@@ -1412,31 +2246,54 @@ func TestSectionWriteRemoteContent(t *testing.T) {
 	}
 }
 
-func TestSectionWriteComment(t *testing.T) {
+func TestSectionWriteAdmonition(t *testing.T) {
 	tests := []struct {
 		name          string
-		content       string
+		kind          AdmonitionKind
+		body          string
 		existingItems int
 		errorMessage  string
-	}{}
+	}{
+		{
+			name:          "Pass-NoExistingItems",
+			kind:          NOTE,
+			body:          "Requires Go 1.23",
+			existingItems: 0,
+			errorMessage:  "",
+		},
+		{
+			name:          "Pass-SomeItems",
+			kind:          CAUTION,
+			body:          "Irreversible",
+			existingItems: 2,
+			errorMessage:  "",
+		},
+		{
+			name:          "Fail-InvalidKind",
+			kind:          AdmonitionKind(0),
+			body:          "Irreversible",
+			existingItems: 0,
+			errorMessage:  "invalid admonition kind: 0",
+		},
+	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			testOperation(
 				t,
 				func() *Section {
-					section := NewSection("test")
+					section := MustNewSection("test")
 
 					for idx := range tc.existingItems {
-						section.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &section
 				},
 				func(s *Section) ([]Node, error) {
-					s.WriteComment(tc.content)
+					err := s.WriteAdmonition(tc.kind, tc.body)
 
-					return s.Children(), nil
+					return s.Children(), err
 				},
 				tc.errorMessage,
 				func(result []Node, section *Section, t *testing.T) {
@@ -1444,19 +2301,9 @@ func TestSectionWriteComment(t *testing.T) {
 						t.Errorf("Expected %d children, found %d", tc.existingItems+1, len(section.Content))
 					}
 
-					// Get the last item to ensure it's a section
-					lastItem := result[len(section.Children())-1]
-					if lastItem.Type() != CommentType {
-						t.Errorf("Expected error type to be %d got %d", CommentType, lastItem.Type())
-					}
-
-					materialized, err := lastItem.(Comment).Materialize()
-					if err != nil {
-						t.Errorf("Unexpected error %s", err.Error())
-					}
-
-					if materialized.Content != tc.content {
-						t.Errorf("Got content %s, expected %s", materialized.Content, tc.content)
+					expected := Admonition{Kind: tc.kind, Body: tc.body}
+					if lastItem := result[len(section.Children())-1]; lastItem != expected {
+						t.Errorf("Expected admonition %v, got %v", expected, lastItem)
 					}
 				},
 			)
@@ -1464,25 +2311,21 @@ func TestSectionWriteComment(t *testing.T) {
 	}
 }
 
-// MARK: Document
-func TestDocumentAddIntro(t *testing.T) {
+func TestSectionWriteMermaid(t *testing.T) {
 	tests := []struct {
-		name          string
-		introContent  string
-		existingItems int
-		errorMessage  string
+		name         string
+		lines        []string
+		errorMessage string
 	}{
 		{
-			name:          "Passing-NoItems",
-			introContent:  "Cool intro",
-			errorMessage:  "",
-			existingItems: 0,
+			name:         "Pass-Flowchart",
+			lines:        []string{"flowchart LR", "    A --> B"},
+			errorMessage: "",
 		},
 		{
-			name:          "Passing-SomeItems",
-			introContent:  "Cool intro",
-			errorMessage:  "",
-			existingItems: 10,
+			name:         "Fail-Empty",
+			lines:        []string{},
+			errorMessage: "mermaid diagram cannot be empty",
 		},
 	}
 
@@ -1490,11 +2333,228 @@ func TestDocumentAddIntro(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			testOperation(
 				t,
-				func() *Document {
+				func() *Section {
+					section := MustNewSection("test")
+					return &section
+				},
+				func(s *Section) ([]Node, error) {
+					err := s.WriteMermaid(tc.lines)
+
+					return s.Children(), err
+				},
+				tc.errorMessage,
+				func(result []Node, section *Section, t *testing.T) {
+					if len(result) != 1 {
+						t.Fatalf("Expected 1 child, found %d", len(result))
+					}
+
+					if !reflect.DeepEqual(result[0], Mermaid{Lines: tc.lines}) {
+						t.Errorf("Expected diagram %v, got %v", Mermaid{Lines: tc.lines}, result[0])
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestSectionWriteImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		alt           string
+		url           string
+		title         string
+		existingItems int
+		errorMessage  string
+	}{
+		{
+			name:          "Pass-NoExistingItems",
+			alt:           "Architecture",
+			url:           "docs/arch.png",
+			title:         "How it fits together",
+			existingItems: 0,
+			errorMessage:  "",
+		},
+		{
+			name:          "Pass-SomeItems",
+			alt:           "Architecture",
+			url:           "docs/arch.png",
+			existingItems: 2,
+			errorMessage:  "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Section {
+					section := MustNewSection("test")
+
+					for idx := range tc.existingItems {
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
+					}
+
+					return &section
+				},
+				func(s *Section) ([]Node, error) {
+					s.WriteImage(tc.alt, tc.url, tc.title)
+
+					return s.Children(), nil
+				},
+				tc.errorMessage,
+				func(result []Node, section *Section, t *testing.T) {
+					if len(section.Content) != tc.existingItems+1 {
+						t.Errorf("Expected %d children, found %d", tc.existingItems+1, len(section.Content))
+					}
+
+					lastItem := result[len(section.Children())-1]
+					if lastItem.Type() != ImageType {
+						t.Errorf("Expected error type to be %d got %d", ImageType, lastItem.Type())
+					}
+
+					expected := Image{AltText: tc.alt, Url: tc.url, Title: tc.title}
+					if lastItem.(Image) != expected {
+						t.Errorf("Expected image %v, got %v", expected, lastItem)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestSectionWriteComment(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		existingItems int
+		errorMessage  string
+	}{}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Section {
+					section := MustNewSection("test")
+
+					for idx := range tc.existingItems {
+						section.CreateSection(fmt.Sprintf("Section %d", idx))
+					}
+
+					return &section
+				},
+				func(s *Section) ([]Node, error) {
+					s.WriteComment(tc.content)
+
+					return s.Children(), nil
+				},
+				tc.errorMessage,
+				func(result []Node, section *Section, t *testing.T) {
+					if len(section.Content) != tc.existingItems+1 {
+						t.Errorf("Expected %d children, found %d", tc.existingItems+1, len(section.Content))
+					}
+
+					// Get the last item to ensure it's a section
+					lastItem := result[len(section.Children())-1]
+					if lastItem.Type() != CommentType {
+						t.Errorf("Expected error type to be %d got %d", CommentType, lastItem.Type())
+					}
+
+					materialized, err := lastItem.(Comment).Materialize()
+					if err != nil {
+						t.Errorf("Unexpected error %s", err.Error())
+					}
+
+					if materialized.Content != tc.content {
+						t.Errorf("Got content %s, expected %s", materialized.Content, tc.content)
+					}
+				},
+			)
+		})
+	}
+}
+
+// MARK: Document
+func TestNewDocument(t *testing.T) {
+	tests := []struct {
+		name         string
+		documentName string
+		expected     string
+		errorMessage string
+	}{
+		{
+			name:         "Passing",
+			documentName: "README",
+			expected:     "README",
+			errorMessage: "",
+		},
+		{
+			name:         "Passing-TrimsWhitespace",
+			documentName: " README ",
+			expected:     "README",
+			errorMessage: "",
+		},
+		{
+			name:         "Fail-Empty",
+			documentName: "",
+			errorMessage: "document name cannot be empty",
+		},
+		{
+			name:         "Fail-MultiLine",
+			documentName: "READ\r\nME",
+			errorMessage: `document name cannot span more than one line: "READ\r\nME"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document, err := NewDocument(tc.documentName)
+
+			checkErrors(tc.errorMessage, err, t)
+			if document.Name != tc.expected {
+				t.Errorf("Expected name %q, got %q", tc.expected, document.Name)
+			}
+
+			defer func() {
+				if panicked := recover() != nil; panicked != (tc.errorMessage != "") {
+					t.Errorf("Expected MustNewDocument to panic: %t, panicked: %t", tc.errorMessage != "", panicked)
+				}
+			}()
+			MustNewDocument(tc.documentName)
+		})
+	}
+}
+
+func TestDocumentAddIntro(t *testing.T) {
+	tests := []struct {
+		name          string
+		introContent  string
+		existingItems int
+		errorMessage  string
+	}{
+		{
+			name:          "Passing-NoItems",
+			introContent:  "Cool intro",
+			errorMessage:  "",
+			existingItems: 0,
+		},
+		{
+			name:          "Passing-SomeItems",
+			introContent:  "Cool intro",
+			errorMessage:  "",
+			existingItems: 10,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testOperation(
+				t,
+				func() *Document {
 					document, _ := NewDocument("test")
 
 					for idx := range tc.existingItems {
-						document.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						document.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &document
@@ -1559,7 +2619,7 @@ func TestDocumentWriteIntro(t *testing.T) {
 					document, _ := NewDocument("test")
 
 					for idx := range tc.existingItems {
-						document.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						document.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &document
@@ -1623,13 +2683,13 @@ func TestDocumentAddSection(t *testing.T) {
 					document, _ := NewDocument("test")
 
 					for idx := range tc.existingItems {
-						document.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						document.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &document
 				},
 				func(d *Document) ([]Node, error) {
-					d.AddSection(NewSection(tc.sectionName))
+					d.AddSection(&Section{Name: tc.sectionName})
 
 					return d.Children(), nil
 				},
@@ -1645,8 +2705,8 @@ func TestDocumentAddSection(t *testing.T) {
 						t.Errorf("Expected error type to be %d got %d", SectionType, lastItem.Type())
 					}
 
-					if lastItem.(Section).Name != tc.sectionName {
-						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(Section).Name)
+					if lastItem.(*Section).Name != tc.sectionName {
+						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(*Section).Name)
 					}
 				},
 			)
@@ -1683,7 +2743,7 @@ func TestDocumentCreateSection(t *testing.T) {
 					document, _ := NewDocument("test")
 
 					for idx := range tc.existingItems {
-						document.AddSection(NewSection(fmt.Sprintf("Section %d", idx)))
+						document.CreateSection(fmt.Sprintf("Section %d", idx))
 					}
 
 					return &document
@@ -1706,7 +2766,7 @@ func TestDocumentCreateSection(t *testing.T) {
 					}
 
 					if lastItem.(*Section).Name != tc.sectionName {
-						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(Section).Name)
+						t.Errorf("Expected last section to have name %s, got %s", tc.sectionName, lastItem.(*Section).Name)
 					}
 				},
 			)
@@ -1745,3 +2805,769 @@ func TestDocumentAddFrontmatter(t *testing.T) {
 		})
 	}
 }
+
+// contentWriter is the builder surface shared by documents and sections.
+type contentWriter interface {
+	WriteParagraph() *Paragraph
+	WriteCodeBlock(blockType string, cmd []string, executable CodeBlockExecType)
+	CreateList(listType ListTypeE) *List
+	CreateTable(headers []string) *Table
+	WriteBlockQuote(value string)
+	WriteComment(value string)
+	WriteRemoteContent(remote Remote)
+}
+
+func TestDocumentContentBuilders(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(w contentWriter)
+		expected string
+	}{
+		{
+			name:     "WriteParagraph",
+			build:    func(w contentWriter) { w.WriteParagraph().Text("Some text.") },
+			expected: "# MyDoc\n\nSome text.",
+		},
+		{
+			name:     "WriteCodeBlock-Exec",
+			build:    func(w contentWriter) { w.WriteCodeBlock("bash", []string{"make", "test"}, Exec) },
+			expected: "# MyDoc\n\n```bash\nmake test\n```",
+		},
+		{
+			name:     "WriteCodeBlock-Static",
+			build:    func(w contentWriter) { w.WriteCodeBlock("json", []string{`{"key": "value"}`}, Static) },
+			expected: "# MyDoc\n\n```json\n{\"key\": \"value\"}\n```",
+		},
+		{
+			name: "CreateList",
+			build: func(w contentWriter) {
+				list := w.CreateList(TASK)
+				list.AppendTask("Tests pass", false)
+			},
+			expected: "# MyDoc\n\n- [ ] Tests pass",
+		},
+		{
+			name: "CreateTable",
+			build: func(w contentWriter) {
+				table := w.CreateTable([]string{"Flag", "Usage"})
+				table.AddRow("--force", "overwrite files")
+			},
+			expected: "# MyDoc\n\n| Flag | Usage |\n| ---- | ---- |\n| --force | overwrite files |",
+		},
+		{
+			name:     "WriteBlockQuote",
+			build:    func(w contentWriter) { w.WriteBlockQuote("Quoted.") },
+			expected: "# MyDoc\n\n> Quoted.",
+		},
+		{
+			name:     "WriteComment",
+			build:    func(w contentWriter) { w.WriteComment("Describe the change.") },
+			expected: "# MyDoc\n\n<!-- Describe the change. -->",
+		},
+		{
+			name:     "WriteRemoteContent",
+			build:    func(w contentWriter) { w.WriteRemoteContent(Remote{Reader: strings.NewReader("Remote text.")}) },
+			expected: "# MyDoc\n\nRemote text.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := MustNewDocument("MyDoc")
+			tc.build(&document)
+
+			section := MustNewSection("MyDoc")
+			tc.build(&section)
+
+			// Documents end with a final newline, sections don't
+			content := strings.TrimRight(renderMarkdown(t, &document), "\n")
+			if content != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+
+			if sectionContent := strings.TrimRight(renderMarkdown(t, &section), "\n"); content != sectionContent {
+				t.Errorf("Expected document to render like a section, got %q and %q", content, sectionContent)
+			}
+
+			documentPlans, err := Executioner{}.Render(&document)
+			if err != nil {
+				t.Fatalf("unexpected error planning document: %s", err.Error())
+			}
+
+			sectionPlans, err := Executioner{}.Render(&section)
+			if err != nil {
+				t.Fatalf("unexpected error planning section: %s", err.Error())
+			}
+
+			if !reflect.DeepEqual(documentPlans, sectionPlans) {
+				t.Errorf("Expected document to plan like a section, got %v and %v", documentPlans, sectionPlans)
+			}
+		})
+	}
+}
+
+// MARK: SectionRef
+func newSharedDocuments() DocumentRegistry {
+	return DocumentRegistry{
+		"Shared": Document{
+			Name: "Shared",
+			Content: []Node{
+				Section{
+					Name: "Development",
+					Content: []Node{
+						&Section{
+							Name: "Setup",
+							Content: []Node{
+								Paragraph{Items: []Node{Text("Install go")}},
+								Executable{Shell: "sh", Cmd: []string{"go", "mod", "download"}},
+							},
+						},
+					},
+				},
+				Section{
+					Name:    "Outer",
+					Content: []Node{SectionRef{DocName: "Shared", SectionPath: "Development/Setup"}},
+				},
+				Section{
+					Name:    "Loop",
+					Content: []Node{SectionRef{DocName: "Shared", SectionPath: "Loop"}},
+				},
+			},
+		},
+	}
+}
+
+func TestDocumentSet(t *testing.T) {
+	tests := []struct {
+		name         string
+		documents    []Document
+		paths        []string
+		errorMessage string
+		expected     []string
+	}{
+		{
+			name:      "Passing",
+			documents: []Document{{Name: "README"}, {Name: "CONTRIBUTING"}},
+			paths:     []string{"README.md", "CONTRIBUTING.md"},
+			expected:  []string{"README", "CONTRIBUTING"},
+		},
+		{
+			name:         "Fail-DuplicateName",
+			documents:    []Document{{Name: "README"}, {Name: "README"}},
+			paths:        []string{"README.md", "docs/README.md"},
+			errorMessage: "duplicate document name: README",
+		},
+		{
+			name:         "Fail-NoPath",
+			documents:    []Document{{Name: "README"}},
+			paths:        []string{""},
+			errorMessage: "document 'README' has no output path",
+		},
+		{
+			name:         "Fail-NoName",
+			documents:    []Document{{}},
+			paths:        []string{"README.md"},
+			errorMessage: "document has no name",
+		},
+		{
+			name:         "Fail-WhitespaceName",
+			documents:    []Document{{Name: "  "}},
+			paths:        []string{"README.md"},
+			errorMessage: "document name cannot be empty",
+		},
+		{
+			name:         "Fail-MultiLineName",
+			documents:    []Document{{Name: "READ\nME"}},
+			paths:        []string{"README.md"},
+			errorMessage: `document name cannot span more than one line: "READ\nME"`,
+		},
+		{
+			name:         "Fail-PathCount",
+			documents:    []Document{{Name: "README"}},
+			paths:        []string{},
+			errorMessage: "got 1 documents but 0 paths",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set, err := NewDocumentSet(tc.documents, tc.paths)
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				if strings.Contains(tc.errorMessage, "duplicate") && !errors.Is(err, ErrDuplicateDocument) {
+					t.Errorf("expected error to wrap ErrDuplicateDocument, got %v", err)
+				}
+				return
+			}
+
+			var names []string
+			for idx, entry := range set.Entries() {
+				names = append(names, entry.Document.Name)
+				if entry.Path != tc.paths[idx] {
+					t.Errorf("expected path %s, got %s", tc.paths[idx], entry.Path)
+				}
+			}
+
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected documents %v, got %v", tc.expected, names)
+			}
+
+			for _, name := range tc.expected {
+				if document, ok := set.Lookup(name); !ok || document.Name != name {
+					t.Errorf("expected to look up %s", name)
+				}
+			}
+
+			if _, ok := set.Lookup("missing"); ok {
+				t.Error("expected lookup of a missing document to fail")
+			}
+		})
+	}
+}
+
+func TestResolveSectionRefs(t *testing.T) {
+	tests := []struct {
+		name         string
+		ref          SectionRef
+		errorMessage string
+		expected     string
+	}{
+		{
+			name:     "Passing",
+			ref:      NewSectionRef("Shared", "Development/Setup"),
+			expected: "# README\n\n## Contributing\n\n### Setup\n\nInstall go\n\n```sh\ngo mod download\n```\n",
+		},
+		{
+			name:     "Passing-NestedRefs",
+			ref:      NewSectionRef("Shared", "Outer"),
+			expected: "# README\n\n## Contributing\n\n### Outer\n\n#### Setup\n\nInstall go\n\n```sh\ngo mod download\n```\n",
+		},
+		{
+			name:         "Fail-Cycle",
+			ref:          NewSectionRef("Shared", "Loop"),
+			errorMessage: "section reference cycle: Shared/Loop -> Shared/Loop",
+		},
+		{
+			name:         "Fail-DocumentNotFound",
+			ref:          NewSectionRef("Missing", "Development"),
+			errorMessage: "section reference 'Missing/Development': document 'Missing' not found",
+		},
+		{
+			name:         "Fail-SectionNotFound",
+			ref:          NewSectionRef("Shared", "Development/Teardown"),
+			errorMessage: "section reference 'Shared/Development/Teardown': section 'Development/Teardown' not found in document 'Shared'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := Document{Name: "README"}
+			section := document.CreateSection("Contributing")
+			section.AddSectionRef(tc.ref.DocName, tc.ref.SectionPath)
+
+			resolved, err := ResolveSectionRefs(document, newSharedDocuments())
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" {
+				return
+			}
+
+			if _, ok := section.Content[0].(SectionRef); !ok {
+				t.Errorf("expected the original document to keep its section reference")
+			}
+
+			content, err := Markdown{}.Render(&resolved)
+			if err != nil {
+				t.Fatalf("unexpected error rendering resolved document: %s", err.Error())
+			}
+
+			if content != tc.expected {
+				t.Errorf("Expected content %s, got %s", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestDocumentFindSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     []string
+		found    bool
+		expected string
+	}{
+		{name: "Passing-TopLevel", path: []string{"INTRO"}, found: true, expected: "INTRO"},
+		{name: "Passing-Nested", path: []string{"INTRO", "Quick Start"}, found: true, expected: "Quick Start"},
+		{name: "Passing-TrimsNames", path: []string{" INTRO ", "Quick Start "}, found: true, expected: "Quick Start"},
+		{name: "Fail-NotFound", path: []string{"INTRO", "Troubleshooting"}, found: false},
+		{name: "Fail-NotASection", path: []string{"INTRO", "This is an introduction."}, found: false},
+		{name: "Fail-EmptyPath", path: []string{}, found: false},
+		{name: "Fail-EmptyName", path: []string{""}, found: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newDocument()
+
+			section, found := document.FindSection(tc.path...)
+			if found != tc.found {
+				t.Fatalf("expected found %t, got %t", tc.found, found)
+			}
+
+			if !found {
+				if section != nil {
+					t.Errorf("expected no section, got %v", section)
+				}
+				return
+			}
+
+			if section.Name != tc.expected {
+				t.Errorf("expected section %s, got %s", tc.expected, section.Name)
+			}
+		})
+	}
+}
+
+func TestDocumentFindSectionMutation(t *testing.T) {
+	tests := []struct {
+		name     string
+		document func() Document
+		path     []string
+	}{
+		{
+			name:     "Passing-ValueHeldSections",
+			document: newDocument,
+			path:     []string{"INTRO", "Quick Start"},
+		},
+		{
+			name: "Passing-PointerHeldSections",
+			document: func() Document {
+				document, _ := NewDocument("MyDoc")
+				document.CreateSection("INTRO").CreateSection("Quick Start").WriteParagraph().Text("Install dependencies")
+
+				return document
+			},
+			path: []string{"INTRO", "Quick Start"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := tc.document()
+
+			section, found := document.FindSection(tc.path...)
+			if !found {
+				t.Fatalf("expected section %v to be found", tc.path)
+			}
+			section.WriteParagraph().Text("Appended later.")
+
+			again, _ := document.FindSection(tc.path...)
+			if again != section {
+				t.Errorf("expected the same section to be returned again")
+			}
+
+			renderer, err := NewMarkdownRenderer()
+			if err != nil {
+				t.Fatalf("unexpected error creating renderer: %s", err.Error())
+			}
+
+			content, err := renderer.Render(&document)
+			if err != nil {
+				t.Fatalf("unexpected error rendering: %s", err.Error())
+			}
+
+			if !strings.Contains(content, "Appended later.") {
+				t.Errorf("expected rendered document to hold the appended paragraph, got %s", content)
+			}
+		})
+	}
+}
+
+func TestSectionFindSection(t *testing.T) {
+	section := MustNewSection("Development")
+	section.CreateSection("Setup").CreateSection("Linux")
+
+	found, ok := section.FindSection("Setup", "Linux")
+	if !ok || found.Name != "Linux" {
+		t.Fatalf("expected to find Linux, got %v", found)
+	}
+
+	if _, ok := section.FindSection("Linux"); ok {
+		t.Errorf("expected nested section not to be found at the top level")
+	}
+}
+
+func TestDocumentSections(t *testing.T) {
+	document, err := NewDocument("MyDoc")
+	if err != nil {
+		t.Fatalf("unexpected error creating document: %s", err.Error())
+	}
+
+	document.WriteIntro().Text("Intro")
+	document.CreateSection("First")
+	document.AddSection(&Section{Name: "Second"})
+	document.CreateSection("Third").CreateSection("Nested")
+
+	sections := document.Sections()
+
+	names := make([]string, len(sections))
+	for idx, section := range sections {
+		names[idx] = section.Name
+	}
+
+	if !reflect.DeepEqual(names, []string{"First", "Second", "Third"}) {
+		t.Fatalf("expected top-level sections in order, got %v", names)
+	}
+
+	sections[1].WriteComment("edited")
+	if found, _ := document.FindSection("Second"); len(found.Content) != 1 {
+		t.Errorf("expected edit through Sections to be kept, got %v", found.Content)
+	}
+}
+
+// newTemplateDocument returns a document with an intro and three sections, like a README template.
+func newTemplateDocument() Document {
+	document, _ := NewDocument("MyDoc")
+	document.CreateSection("Installation").WriteParagraph().Text("go get it")
+	document.AddSection(&Section{Name: "Usage", Content: []Node{Text("use it")}})
+	document.CreateSection("License").WriteParagraph().Text("MIT")
+	document.AddIntro(&Paragraph{Items: []Node{Text("An intro.")}})
+
+	return document
+}
+
+// renderedOutline renders a document as markdown and returns its headings and intro, in order.
+func renderedOutline(t *testing.T, node Node) []string {
+	t.Helper()
+
+	renderer, err := NewMarkdownRenderer()
+	if err != nil {
+		t.Fatalf("unexpected error creating renderer: %s", err.Error())
+	}
+
+	content, err := renderer.Render(node)
+	if err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	var outline []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "#") || line == "An intro." {
+			outline = append(outline, line)
+		}
+	}
+
+	return outline
+}
+
+func TestDocumentSectionMutations(t *testing.T) {
+	tests := []struct {
+		name         string
+		mutate       func(document *Document) error
+		expected     []string
+		errorMessage string
+	}{
+		{
+			name: "Passing-RemoveSection",
+			mutate: func(document *Document) error {
+				if !document.RemoveSection("License") {
+					return errors.New("expected License to be removed")
+				}
+				return nil
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## Usage"},
+		},
+		{
+			name: "Passing-RemoveValueHeldSection",
+			mutate: func(document *Document) error {
+				if !document.RemoveSection("Usage") {
+					return errors.New("expected Usage to be removed")
+				}
+				return nil
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## License"},
+		},
+		{
+			name: "Passing-RemoveMissingSection",
+			mutate: func(document *Document) error {
+				if document.RemoveSection("Contributing") {
+					return errors.New("expected nothing to be removed")
+				}
+				return nil
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## License"},
+		},
+		{
+			name: "Passing-RemoveFirstDuplicate",
+			mutate: func(document *Document) error {
+				document.AddSection(&Section{Name: "Usage"})
+				if !document.RemoveSection("Usage") {
+					return errors.New("expected Usage to be removed")
+				}
+				return nil
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## License", "## Usage"},
+		},
+		{
+			name: "Passing-ReplaceSection",
+			mutate: func(document *Document) error {
+				return document.ReplaceSection("Installation", &Section{Name: "Custom Installation"})
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Custom Installation", "## Usage", "## License"},
+		},
+		{
+			name: "Fail-ReplaceMissingSection",
+			mutate: func(document *Document) error {
+				return document.ReplaceSection("Contributing", &Section{Name: "Custom"})
+			},
+			expected:     []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## License"},
+			errorMessage: "section not found: 'Contributing'",
+		},
+		{
+			name: "Passing-InsertFirstKeepsIntro",
+			mutate: func(document *Document) error {
+				return document.InsertSectionAt(0, &Section{Name: "Overview"})
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Overview", "## Installation", "## Usage", "## License"},
+		},
+		{
+			name: "Passing-InsertMiddle",
+			mutate: func(document *Document) error {
+				return document.InsertSectionAt(2, &Section{Name: "Configuration"})
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## Configuration", "## License"},
+		},
+		{
+			name: "Passing-InsertLast",
+			mutate: func(document *Document) error {
+				return document.InsertSectionAt(3, &Section{Name: "Contributing"})
+			},
+			expected: []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## License", "## Contributing"},
+		},
+		{
+			name: "Fail-InsertPastEnd",
+			mutate: func(document *Document) error {
+				return document.InsertSectionAt(4, &Section{Name: "Contributing"})
+			},
+			expected:     []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## License"},
+			errorMessage: "section index 4 out of range [0, 3]",
+		},
+		{
+			name: "Fail-InsertNegative",
+			mutate: func(document *Document) error {
+				return document.InsertSectionAt(-1, &Section{Name: "Contributing"})
+			},
+			expected:     []string{"# MyDoc", "An intro.", "## Installation", "## Usage", "## License"},
+			errorMessage: "section index -1 out of range [0, 3]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newTemplateDocument()
+
+			err := tc.mutate(&document)
+
+			checkErrors(tc.errorMessage, err, t)
+			if tc.errorMessage != "" && !strings.Contains(tc.errorMessage, "out of range") && !errors.Is(err, ErrSectionNotFound) {
+				t.Errorf("expected error to wrap ErrSectionNotFound, got %v", err)
+			}
+
+			if outline := renderedOutline(t, &document); !reflect.DeepEqual(outline, tc.expected) {
+				t.Errorf("expected outline %q, got %q", tc.expected, outline)
+			}
+		})
+	}
+}
+
+func TestSectionSectionMutations(t *testing.T) {
+	section := MustNewSection("Guide")
+	section.WriteIntro().Text("An intro.")
+	section.CreateSection("Setup")
+	section.CreateSection("Teardown")
+
+	if err := section.InsertSectionAt(1, &Section{Name: "Usage"}); err != nil {
+		t.Fatalf("unexpected error inserting: %s", err.Error())
+	}
+
+	if err := section.ReplaceSection("Setup", &Section{Name: "Installation"}); err != nil {
+		t.Fatalf("unexpected error replacing: %s", err.Error())
+	}
+
+	if !section.RemoveSection("Teardown") {
+		t.Fatalf("expected Teardown to be removed")
+	}
+
+	expected := []string{"# Guide", "An intro.", "## Installation", "## Usage"}
+	if outline := renderedOutline(t, section); !reflect.DeepEqual(outline, expected) {
+		t.Errorf("expected outline %q, got %q", expected, outline)
+	}
+
+	if err := section.InsertSectionAt(3, &Section{Name: "Extra"}); err == nil {
+		t.Errorf("expected an out of range error")
+	}
+}
+
+// renderMarkdown renders a node as markdown.
+func renderMarkdown(t *testing.T, node Node) string {
+	t.Helper()
+
+	renderer, err := NewMarkdownRenderer()
+	if err != nil {
+		t.Fatalf("unexpected error creating renderer: %s", err.Error())
+	}
+
+	content, err := renderer.Render(node)
+	if err != nil {
+		t.Fatalf("unexpected error rendering: %s", err.Error())
+	}
+
+	return content
+}
+
+func TestSectionMutationAfterAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		// add puts content into the section and returns a func that edits it afterwards
+		add func(s *Section) func()
+	}{
+		{
+			name: "AddSection",
+			add: func(s *Section) func() {
+				sub := MustNewSection("Sub")
+				s.AddSection(&sub)
+				return func() { sub.WriteParagraph().Text("marker") }
+			},
+		},
+		{
+			name: "CreateSection",
+			add: func(s *Section) func() {
+				sub := s.CreateSection("Sub")
+				return func() { sub.WriteParagraph().Text("marker") }
+			},
+		},
+		{
+			name: "AddParagraph",
+			add: func(s *Section) func() {
+				paragraph := NewParagraph()
+				s.AddParagraph(paragraph)
+				return func() { paragraph.Text("marker") }
+			},
+		},
+		{
+			name: "WriteParagraph",
+			add: func(s *Section) func() {
+				paragraph := s.WriteParagraph()
+				return func() { paragraph.Text("marker") }
+			},
+		},
+		{
+			name: "AddList",
+			add: func(s *Section) func() {
+				s.AddList(BULLET, []Text{"first"})
+				return func() { s.Children()[0].(*List).Append("marker") }
+			},
+		},
+		{
+			name: "CreateList",
+			add: func(s *Section) func() {
+				list := s.CreateList(BULLET)
+				return func() { list.Append("marker") }
+			},
+		},
+		{
+			name: "AddTable",
+			add: func(s *Section) func() {
+				s.AddTable([]string{"Name"}, nil)
+				return func() { s.Children()[0].(*Table).AddRow("marker") }
+			},
+		},
+		{
+			name: "CreateTable",
+			add: func(s *Section) func() {
+				table := s.CreateTable([]string{"Name"})
+				return func() { table.AddRow("marker") }
+			},
+		},
+		{
+			name: "AddIntro",
+			add: func(s *Section) func() {
+				paragraph := NewParagraph()
+				s.AddIntro(paragraph)
+				return func() { paragraph.Text("marker") }
+			},
+		},
+		{
+			name: "WriteIntro",
+			add: func(s *Section) func() {
+				paragraph := s.WriteIntro()
+				return func() { paragraph.Text("marker") }
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document, _ := NewDocument("MyDoc")
+			section := MustNewSection("Guide")
+
+			mutate := tc.add(&section)
+			document.AddSection(&section)
+			mutate()
+
+			if content := renderMarkdown(t, &document); !strings.Contains(content, "marker") {
+				t.Errorf("expected edit made after adding to be rendered, got %q", content)
+			}
+		})
+	}
+}
+
+func TestDocumentMutationAfterAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		// add puts content into the document and returns a func that edits it afterwards
+		add func(d *Document) func()
+	}{
+		{
+			name: "AddSection",
+			add: func(d *Document) func() {
+				section := MustNewSection("Guide")
+				d.AddSection(&section)
+				return func() { section.WriteParagraph().Text("marker") }
+			},
+		},
+		{
+			name: "CreateSection",
+			add: func(d *Document) func() {
+				section := d.CreateSection("Guide")
+				return func() { section.WriteParagraph().Text("marker") }
+			},
+		},
+		{
+			name: "AddIntro",
+			add: func(d *Document) func() {
+				paragraph := NewParagraph()
+				d.AddIntro(paragraph)
+				return func() { paragraph.Text("marker") }
+			},
+		},
+		{
+			name: "WriteIntro",
+			add: func(d *Document) func() {
+				paragraph := d.WriteIntro()
+				return func() { paragraph.Text("marker") }
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document, _ := NewDocument("MyDoc")
+
+			tc.add(&document)()
+
+			if content := renderMarkdown(t, &document); !strings.Contains(content, "marker") {
+				t.Errorf("expected edit made after adding to be rendered, got %q", content)
+			}
+		})
+	}
+}
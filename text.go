@@ -0,0 +1,402 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// PlainText implements the Renderer interface to produce text without any markdown syntax,
+// for terminals and email bodies. Headings are underlined or uppercased, code is indented,
+// and links are written as "text (url)".
+type PlainText struct {
+	unknownNodes UnknownNodePolicy
+
+	// anchors holds the headings of the tree being rendered, used for the table of contents
+	anchors []sectionAnchor
+}
+
+// NewPlainTextRenderer creates a new PlainText renderer instance configured by the provided options.
+// Returns an error if any option is invalid.
+func NewPlainTextRenderer(opts ...OptionBuilder[PlainText]) (PlainText, error) {
+	renderer := PlainText{
+		unknownNodes: UnknownNodesError,
+	}
+
+	if err := ApplyOptions(&renderer, opts...); err != nil {
+		return PlainText{}, err
+	}
+
+	return renderer, nil
+}
+
+// WithPlainTextUnknownNodes sets how the PlainText renderer handles node types it has no
+// handler for. Defaults to UnknownNodesError.
+func WithPlainTextUnknownNodes(policy UnknownNodePolicy) OptionBuilder[PlainText] {
+	return func(p *PlainText) (Finalizer[PlainText], error) {
+		if policy < UnknownNodesError || policy > UnknownNodesText {
+			return nil, fmt.Errorf("invalid unknown node policy: %d", policy)
+		}
+
+		p.unknownNodes = policy
+
+		return nil, nil
+	}
+}
+
+// writeHeader underlines the two top levels of headings and uppercases the rest.
+func (p PlainText) writeHeader(builder *strings.Builder, content string, level int) {
+	switch {
+	case level <= 1:
+		builder.WriteString(content + "\n" + strings.Repeat("=", utf8.RuneCountInString(content)))
+	case level == 2:
+		builder.WriteString(content + "\n" + strings.Repeat("-", utf8.RuneCountInString(content)))
+	default:
+		builder.WriteString(strings.ToUpper(content))
+	}
+}
+
+func (p PlainText) renderChildren(children []Node, contextPath *ContextPath) ([]string, error) {
+	results := make([]string, 0, len(children))
+
+	for _, leaf := range children {
+		leafContent, err := p.renderWithTracking(leaf, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, leafContent)
+	}
+
+	return results, nil
+}
+
+func (p PlainText) renderHeadedStructure(s Structurer, contextPath *ContextPath) (string, error) {
+	ctxPath := contextPath.Push(s.Identifier())
+	contextPath = &ctxPath
+
+	childContent, err := p.renderChildren(s.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	p.writeHeader(&builder, s.Identifier(), ctxPath.CurrentLevel())
+	for _, child := range childContent {
+		builder.WriteString("\n\n")
+		builder.WriteString(child)
+	}
+
+	return builder.String(), nil
+}
+
+// renderParagraph joins the paragraph's items with single spaces, starting a new line
+// where the paragraph has a line break.
+func (p PlainText) renderParagraph(paragraph Structurer, contextPath *ContextPath) (string, error) {
+	var builder strings.Builder
+	pendingBreak := false
+
+	for _, item := range paragraph.Children() {
+		if item.Type() == LineBreakType {
+			pendingBreak = builder.Len() > 0
+			continue
+		}
+
+		content, err := p.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if builder.Len() > 0 {
+			if pendingBreak {
+				builder.WriteString("\n")
+			} else {
+				builder.WriteString(" ")
+			}
+		}
+		pendingBreak = false
+
+		builder.WriteString(content)
+	}
+
+	if builder.Len() == 0 {
+		return "", errSkipNode
+	}
+
+	return builder.String(), nil
+}
+
+func (p PlainText) renderList(l *List, contextPath *ContextPath) (string, error) {
+	lines := make([]string, 0, len(l.Items))
+	position := 0
+
+	for idx, item := range l.Items {
+		content, err := p.renderWithTracking(item, contextPath)
+		if errors.Is(err, errSkipNode) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if item.Type() == ListType {
+			lines = append(lines, "  "+indentLines(content, "  "))
+			continue
+		}
+
+		position++
+
+		var prefix string
+		switch l.TypeOfList {
+		case NUMBERED:
+			prefix = fmt.Sprintf("%d. ", position)
+		case TASK:
+			prefix = "- [ ] "
+			if l.IsChecked(idx) {
+				prefix = "- [x] "
+			}
+		default:
+			prefix = "- "
+		}
+
+		lines = append(lines, prefix+indentLines(content, strings.Repeat(" ", len(prefix))))
+	}
+
+	if len(lines) == 0 {
+		return "", errSkipNode
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// renderTable aligns the columns with spaces and underlines the headers with dashes.
+func (p PlainText) renderTable(t *Table, contextPath *ContextPath) (string, error) {
+	rows := [][]string{t.Headers}
+
+	for _, row := range t.Items {
+		var cells []string
+
+		if row.Rich() {
+			for _, cell := range row.Cells {
+				content, err := p.renderWithTracking(cell, contextPath)
+				if errors.Is(err, errSkipNode) {
+					content, err = "", nil
+				}
+				if err != nil {
+					return "", err
+				}
+
+				cells = append(cells, strings.ReplaceAll(content, "\n", " "))
+			}
+		} else {
+			cells = append(cells, row.Values...)
+		}
+
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(t.Headers))
+	for _, row := range rows {
+		for idx, cell := range row {
+			if idx < len(widths) {
+				widths[idx] = max(widths[idx], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	separator := make([]string, len(widths))
+	for idx, width := range widths {
+		separator[idx] = strings.Repeat("-", width)
+	}
+	rows = append(rows[:1], append([][]string{separator}, rows[1:]...)...)
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]string, len(widths))
+		for idx, width := range widths {
+			var cell string
+			if idx < len(row) {
+				cell = row[idx]
+			}
+			cells[idx] = cell + strings.Repeat(" ", width-utf8.RuneCountInString(cell))
+		}
+
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (p PlainText) renderCollapsible(c Structurer, contextPath *ContextPath) (string, error) {
+	childContent, err := p.renderChildren(c.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(append([]string{c.Identifier()}, childContent...), "\n\n"), nil
+}
+
+func (p PlainText) renderStructureNode(structureNode Structurer, contextPath *ContextPath) (string, error) {
+	switch structureNode.Type() {
+	case DocumentType, SectionType:
+		return p.renderHeadedStructure(structureNode, contextPath)
+	case ParagraphType:
+		return p.renderParagraph(structureNode, contextPath)
+	case ListType:
+		if list, ok := structureNode.(List); ok {
+			return p.renderList(&list, contextPath)
+		}
+		return p.renderList(structureNode.(*List), contextPath)
+	case TableType:
+		if table, ok := structureNode.(Table); ok {
+			return p.renderTable(&table, contextPath)
+		}
+		return p.renderTable(structureNode.(*Table), contextPath)
+	case CollapsibleType:
+		return p.renderCollapsible(structureNode, contextPath)
+	case FrontmatterType:
+		return "", errSkipNode
+	}
+
+	return p.renderUnknown(structureNode, contextPath, errors.New("unhandled structure node type"))
+}
+
+// renderUnknown is the shared fallback for content and structure nodes without a handler.
+func (p PlainText) renderUnknown(node Node, contextPath *ContextPath, unhandled error) (string, error) {
+	structure, ok := node.(Structurer)
+	if !ok || p.unknownNodes != UnknownNodesText {
+		return p.unknownNodes.resolve(node, unhandled)
+	}
+
+	childContent, err := p.renderChildren(structure.Children(), contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(childContent) == 0 {
+		return "", errSkipNode
+	}
+
+	return strings.Join(childContent, "\n\n"), nil
+}
+
+// withTarget writes a link as "text (url)", or just the url when they are the same.
+func (p PlainText) withTarget(text string, metadata map[string]interface{}) (string, error) {
+	url, err := getStringFromMetadata(metadata, "Url")
+	if err != nil {
+		return "", err
+	}
+
+	if text == "" || text == url {
+		return url, nil
+	}
+
+	return fmt.Sprintf("%s (%s)", text, url), nil
+}
+
+func (p PlainText) renderTableOfContents(content MaterializedContent) (string, error) {
+	maxDepth, ok := content.Metadata["MaxDepth"].(int)
+	if !ok {
+		return "", errors.New("metadata key 'MaxDepth' not found or not an int")
+	}
+
+	var lines []string
+
+	for _, anchor := range p.anchors {
+		depth := anchor.Level - 1
+		if anchor.Standalone || depth < 1 || (maxDepth > 0 && depth > maxDepth) {
+			continue
+		}
+
+		lines = append(lines, strings.Repeat("  ", depth-1)+"- "+anchor.Heading)
+	}
+
+	if len(lines) == 0 {
+		return "", errSkipNode
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (p PlainText) renderContent(contentNode Contenter, contextPath *ContextPath) (string, error) {
+	content, err := contentNode.Materialize()
+	if err != nil {
+		return "", err
+	}
+
+	switch contentNode.Type() {
+	case HeaderType:
+		var builder strings.Builder
+		p.writeHeader(&builder, content.Content, contextPath.CurrentLevel())
+		return builder.String(), nil
+	case TextType, BoldType, ItalicType, StrikethroughType, CodeType, MathInlineType, CrossRefType:
+		return content.Content, nil
+	case LinkType, ImageType, BadgeType:
+		return p.withTarget(content.Content, content.Metadata)
+	case CodeBlockType, MermaidType, MathBlockType, ExecutableType, PipelineType:
+		return "    " + indentLines(content.Content, "    "), nil
+	case BlockQuoteType:
+		return "  " + indentLines(content.Content, "  "), nil
+	case AdmonitionType:
+		kind, err := getStringFromMetadata(content.Metadata, "Kind")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s: %s", kind, content.Content), nil
+	case RemoteType:
+		return strings.Trim(content.Content, "\n"), nil
+	case TableRowType:
+		items, err := getStringsFromMetadata(content.Metadata, "Items")
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(items, "  "), nil
+	case TableOfContentsType:
+		return p.renderTableOfContents(content)
+	case HTMLBlockType, CommentType, LineBreakType: // markup and notes for editors have no plain text form
+		return "", errSkipNode
+	}
+
+	return p.renderUnknown(contentNode, contextPath, errors.New("unknown content node type"))
+}
+
+func (p PlainText) renderWithTracking(node Node, contextPath *ContextPath) (string, error) {
+	switch node.Type() {
+	case DocumentType, SectionType, ParagraphType, ListType, TableType, FrontmatterType, CollapsibleType:
+		return p.renderStructureNode(node.(Structurer), contextPath)
+	}
+
+	if contentNode, ok := node.(Contenter); ok {
+		return p.renderContent(contentNode, contextPath)
+	}
+
+	if structureNode, ok := node.(Structurer); ok {
+		return p.renderStructureNode(structureNode, contextPath)
+	}
+
+	return p.renderUnknown(node, contextPath, errors.New("unknown content node type"))
+}
+
+// Render converts a node into plain text, starting with an empty context path.
+// This is the main entry point for the Renderer interface implementation.
+func (p PlainText) Render(node Node) (string, error) {
+	p.anchors = collectAnchors(node, false)
+
+	content, err := p.renderWithTracking(node, &ContextPath{})
+	if errors.Is(err, errSkipNode) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return content + "\n", nil
+}
@@ -0,0 +1,86 @@
+package doyoucompute
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlainTextRender(t *testing.T) {
+	document := Document{Name: "Guide"}
+	document.WriteIntro().Text("Read the").Link("docs", "https://example.com").Text("first, then run").Code("make").LineBreak().Bold("Done.")
+	document.WriteTableOfContents(0)
+
+	install := document.CreateSection("Install")
+	install.WriteParagraph().Text("Pick your platform.")
+	linux := install.CreateSection("Linux")
+	linux.Content = append(linux.Content, Executable{Shell: "bash", Cmd: []string{"set -e", "make install"}, JoinWith: "\n"})
+	steps := linux.CreateList(NUMBERED)
+	steps.Append("Download")
+	steps.CreateList(BULLET).Append("Verify the checksum")
+	steps.Append("Install")
+	linux.WriteComment("for editors only")
+	linux.WriteHTML("<br>")
+
+	usage := document.CreateSection("Usage")
+	table := usage.CreateTable([]string{"Flag", "Meaning"})
+	table.AddRow("--path", "Where to write")
+	table.AddRow("--format", "a|b")
+	usage.WriteBlockQuote("Quoted advice.")
+
+	expected := strings.Join([]string{
+		"Guide",
+		"=====",
+		"",
+		"Read the docs (https://example.com) first, then run make",
+		"Done.",
+		"",
+		"- Install",
+		"  - Linux",
+		"- Usage",
+		"",
+		"Install",
+		"-------",
+		"",
+		"Pick your platform.",
+		"",
+		"LINUX",
+		"",
+		"    set -e",
+		"    make install",
+		"",
+		"1. Download",
+		"  - Verify the checksum",
+		"2. Install",
+		"",
+		"Usage",
+		"-----",
+		"",
+		"Flag      Meaning",
+		"--------  --------------",
+		"--path    Where to write",
+		"--format  a|b",
+		"",
+		"  Quoted advice.",
+		"",
+	}, "\n")
+
+	renderer, err := NewPlainTextRenderer()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	content, err := renderer.Render(&document)
+
+	checkErrors("", err, t)
+	if content != expected {
+		t.Errorf("Expected content %q, got %q", expected, content)
+	}
+
+	// The table cell deliberately contains a pipe, so only check the rest of the output for syntax
+	withoutCell := strings.Replace(content, "a|b", "", 1)
+	for _, syntax := range []string{"#", "`", "|", "*", "<"} {
+		if strings.Contains(withoutCell, syntax) {
+			t.Errorf("Expected no %q in plain text output, got %q", syntax, content)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package doyoucompute
+
+import "slices"
+
+// WalkFunc is called by Walk for every node it visits, with the path of the document and
+// sections the node is in. A document or section is visited with the path of its parents.
+// Returning false for descend skips the node's children. Returning an error stops the walk.
+type WalkFunc func(node Node, path ContextPath) (descend bool, err error)
+
+// Walk visits node and its descendants depth first, in document order, calling visit for each.
+// The children of every Structurer are visited, including those of paragraphs, lists, tables,
+// and collapsibles; documents and sections add themselves to the path of their children. Errors
+// returned by visit are wrapped in a RenderError holding the path they were raised under.
+func Walk(node Node, visit WalkFunc) error {
+	return walk(node, ContextPath{}, visit)
+}
+
+// walk visits node under path, as Walk does.
+func walk(node Node, path ContextPath, visit WalkFunc) error {
+	descend, err := visit(node, path)
+	if err != nil {
+		return wrapRenderError(err, path)
+	}
+
+	structurer, ok := node.(Structurer)
+	if !descend || !ok {
+		return nil
+	}
+
+	childPath := path
+	if node.Type() == DocumentType || node.Type() == SectionType {
+		// Paths handed to visit may be kept, so siblings must not share the slot pushed onto
+		childPath = slices.Clip(path).Push(structurer.Identifier())
+		if origin := includedFrom(node); origin != "" {
+			childPath[len(childPath)-1].Origin = origin
+		}
+	}
+
+	for _, child := range structurer.Children() {
+		if err := walk(child, childPath, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nodeAs returns the T held by node, whether stored by value or pointer.
+func nodeAs[T Node](node Node) (T, bool) {
+	switch n := any(node).(type) {
+	case T:
+		return n, true
+	case *T:
+		return *n, true
+	}
+
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,211 @@
+package doyoucompute
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// describeNode names a node for comparing visitation orders.
+func describeNode(node Node) string {
+	if structurer, ok := node.(Structurer); ok && structurer.Identifier() != "" {
+		return fmt.Sprintf("%s(%s)", node.Type(), structurer.Identifier())
+	}
+
+	if contenter, ok := node.(Contenter); ok {
+		if content, err := contenter.Materialize(); err == nil && content.Content != "" {
+			return fmt.Sprintf("%s(%s)", node.Type(), content.Content)
+		}
+	}
+
+	return node.Type().String()
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name     string
+		prune    ContentType
+		expected []string
+	}{
+		{
+			name: "Passing-VisitsInDocumentOrder",
+			expected: []string{
+				"Document(MyDoc) @ ",
+				"Section(INTRO) @ MyDoc",
+				"Paragraph @ MyDoc > INTRO",
+				"Text(This is an introduction.) @ MyDoc > INTRO",
+				"Text(And another sentence here.) @ MyDoc > INTRO",
+				"Executable(echo hello world) @ MyDoc > INTRO",
+				"Section(Quick Start) @ MyDoc > INTRO",
+				"Text(Install dependencies) @ MyDoc > INTRO > Quick Start",
+				"Executable(go get) @ MyDoc > INTRO > Quick Start",
+			},
+		},
+		{
+			name:  "Passing-PrunesSections",
+			prune: SectionType,
+			expected: []string{
+				"Document(MyDoc) @ ",
+				"Section(INTRO) @ MyDoc",
+			},
+		},
+		{
+			name:  "Passing-PrunesParagraphs",
+			prune: ParagraphType,
+			expected: []string{
+				"Document(MyDoc) @ ",
+				"Section(INTRO) @ MyDoc",
+				"Paragraph @ MyDoc > INTRO",
+				"Executable(echo hello world) @ MyDoc > INTRO",
+				"Section(Quick Start) @ MyDoc > INTRO",
+				"Text(Install dependencies) @ MyDoc > INTRO > Quick Start",
+				"Executable(go get) @ MyDoc > INTRO > Quick Start",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			document := newDocument()
+
+			var visited []string
+			err := Walk(document, func(node Node, path ContextPath) (bool, error) {
+				visited = append(visited, describeNode(node)+" @ "+path.String())
+
+				return node.Type() != tc.prune, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if !reflect.DeepEqual(visited, tc.expected) {
+				t.Errorf("expected visits %q, got %q", tc.expected, visited)
+			}
+		})
+	}
+}
+
+func TestWalkError(t *testing.T) {
+	errStop := errors.New("stop here")
+
+	tests := []struct {
+		name         string
+		stopAt       ContentType
+		expectedPath string
+		errorMessage string
+	}{
+		{
+			name:         "Fail-NestedExecutable",
+			stopAt:       ExecutableType,
+			expectedPath: "MyDoc > INTRO",
+			errorMessage: `rendering "MyDoc > INTRO": stop here`,
+		},
+		{
+			name:         "Fail-Root",
+			stopAt:       DocumentType,
+			errorMessage: "stop here",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			visits := 0
+			err := Walk(newDocument(), func(node Node, path ContextPath) (bool, error) {
+				visits++
+				if node.Type() == tc.stopAt {
+					return false, errStop
+				}
+
+				return true, nil
+			})
+
+			checkErrors(tc.errorMessage, err, t)
+
+			if !errors.Is(err, errStop) {
+				t.Errorf("expected error to wrap the visit error, got %v", err)
+			}
+
+			var renderErr *RenderError
+			if errors.As(err, &renderErr) != (tc.expectedPath != "") {
+				t.Errorf("expected a RenderError only for nested nodes, got %v", err)
+			} else if renderErr != nil && renderErr.Path != tc.expectedPath {
+				t.Errorf("expected path %s, got %s", tc.expectedPath, renderErr.Path)
+			}
+
+			if tc.stopAt == ExecutableType && visits != 6 {
+				t.Errorf("expected the walk to stop at the first executable, got %d visits", visits)
+			}
+		})
+	}
+}
+
+func TestWalkPaths(t *testing.T) {
+	document := Document{
+		Name: "MyDoc",
+		Content: []Node{
+			Section{Name: "First", Content: []Node{Text("one")}},
+			Section{Name: "Second", Content: []Node{Text("two")}, origin: "Shared/Second"},
+		},
+	}
+
+	paths := map[string]ContextPath{}
+	err := Walk(document, func(node Node, path ContextPath) (bool, error) {
+		if node.Type() == TextType {
+			paths[string(node.(Text))] = path
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if paths["one"].String() != "MyDoc > First" {
+		t.Errorf("expected kept path of the first section to be unchanged, got %s", paths["one"])
+	}
+
+	if paths["two"].String() != "MyDoc > Second" {
+		t.Errorf("expected path of the second section, got %s", paths["two"])
+	}
+
+	if paths["one"].Current().Origin != "" || paths["two"].Current().Origin != "Shared/Second" {
+		t.Errorf("expected only the included section to have an origin, got %q and %q", paths["one"].Current().Origin, paths["two"].Current().Origin)
+	}
+}
+
+func TestNodeAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     Node
+		expected Link
+		found    bool
+	}{
+		{
+			name:     "Value",
+			node:     Link{Text: "Docs", Url: "https://example.com"},
+			expected: Link{Text: "Docs", Url: "https://example.com"},
+			found:    true,
+		},
+		{
+			name:     "Pointer",
+			node:     &Link{Text: "Docs", Url: "https://example.com"},
+			expected: Link{Text: "Docs", Url: "https://example.com"},
+			found:    true,
+		},
+		{
+			name: "Other type",
+			node: Text("Docs"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			link, found := nodeAs[Link](tc.node)
+
+			if found != tc.found || !reflect.DeepEqual(link, tc.expected) {
+				t.Errorf("Expected %v (found %v), got %v (found %v)", tc.expected, tc.found, link, found)
+			}
+		})
+	}
+}